@@ -89,14 +89,18 @@ func InitializeAKC() {
 		lib.SetCRDClientset(crdClient)
 	}
 
-	dynamicClient, err := lib.NewDynamicClientSet(cfg)
+	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		utils.AviLog.Warnf("Error while creating dynamic client %v", err)
+		utils.AviLog.Fatalf("Error building kubernetes clientset: %s", err.Error())
+	}
+	if !lib.GetAdvancedL4() {
+		lib.SetIngressClassEnabled(kubeClient)
+		lib.SetCRDsEnabled(kubeClient)
 	}
 
-	kubeClient, err := kubernetes.NewForConfig(cfg)
+	dynamicClient, err := lib.NewDynamicClientSet(cfg)
 	if err != nil {
-		utils.AviLog.Fatalf("Error building kubernetes clientset: %s", err.Error())
+		utils.AviLog.Warnf("Error while creating dynamic client %v", err)
 	}
 
 	oshiftClient, err := oshiftclient.NewForConfig(cfg)
@@ -143,6 +147,8 @@ func InitializeAKC() {
 	waitGroupMap["graph"] = wgGraph
 	go c.InitController(informers, registeredInformers, ctrlCh, stopCh, quickSyncCh, waitGroupMap)
 	<-stopCh
+	// the audit records of the operations sent until the workers return are kept
+	defer utils.CloseAuditLog()
 	close(ctrlCh)
 	doneChan := make(chan struct{})
 	go func() {
@@ -155,6 +161,8 @@ func InitializeAKC() {
 	timeout := 60 * time.Second
 	select {
 	case <-doneChan:
+		// the workers are done, persist the cache for the next bootup
+		k8s.SaveCacheSnapshot()
 		return
 	case <-time.After(timeout):
 		utils.AviLog.Warnf("Timed out while waiting for threads to return, going to stop AKO. Time waited 60 seconds")
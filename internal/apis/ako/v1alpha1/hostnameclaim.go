@@ -0,0 +1,55 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HostnameClaim is a top-level type
+type HostnameClaim struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status HostnameClaimStatus `json:"status,omitempty"`
+
+	Spec HostnameClaimSpec `json:"spec,omitempty"`
+}
+
+// HostnameClaimSpec reserves the fqdn for Ingresses/Routes in the
+// namespace of the claim
+type HostnameClaimSpec struct {
+	Fqdn string `json:"fqdn,omitempty"`
+}
+
+// HostnameClaimStatus holds the status of the HostnameClaim
+type HostnameClaimStatus struct {
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HostnameClaimList has the list of HostnameClaim objects
+type HostnameClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []HostnameClaim `json:"items"`
+}
@@ -47,8 +47,18 @@ type HostRuleVirtualHost struct {
 
 // HostRuleTLS holds secure host specific properties
 type HostRuleTLS struct {
-	SSLKeyCertificate HostRuleSecret `json:"sslKeyCertificate,omitempty"`
-	Termination       string         `json:"termination,omitempty"`
+	SSLKeyCertificate HostRuleSecret            `json:"sslKeyCertificate,omitempty"`
+	Termination       string                    `json:"termination,omitempty"`
+	ClientCertificate HostRuleClientCertificate `json:"clientCertificate,omitempty"`
+}
+
+// HostRuleClientCertificate enables client certificate (mTLS) authentication
+// on the virtualhost. CASecret refers to a K8s Secret in the HostRule namespace,
+// carrying the CA bundle under ca.crt and optionally a CRL under ca.crl
+type HostRuleClientCertificate struct {
+	CASecret      string `json:"caSecret,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	SubjectHeader string `json:"subjectHeader,omitempty"`
 }
 
 // HostRuleSecret is required to provide distinction between Avi SSLKeyCertificate
@@ -53,8 +53,12 @@ type HTTPRuleLBPolicy struct {
 
 // HTTPRuleTLS holds secure path/pool specific properties
 type HTTPRuleTLS struct {
-	Type       string `json:"type,omitempty"`
-	SSLProfile string `json:"sslProfile,omitempty"`
+	Type                string `json:"type,omitempty"`
+	SSLProfile          string `json:"sslProfile,omitempty"`
+	DestinationCA       string `json:"destinationCA,omitempty"`
+	DestinationCASecret string `json:"destinationCASecret,omitempty"`
+	ServerName          string `json:"serverName,omitempty"`
+	SSLKeyCertificate   string `json:"sslKeyCertificate,omitempty"`
 }
 
 // HTTPRuleStatus holds the status of the HTTPRule
@@ -52,6 +52,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&HostRuleList{},
 		&HTTPRule{},
 		&HTTPRuleList{},
+		&HostnameClaim{},
+		&HostnameClaimList{},
+		&AviInfraSetting{},
+		&AviInfraSettingList{},
 	)
 
 	scheme.AddKnownTypes(
@@ -24,6 +24,150 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSetting) DeepCopyInto(out *AviInfraSetting) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Status = in.Status
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSetting.
+func (in *AviInfraSetting) DeepCopy() *AviInfraSetting {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSetting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AviInfraSetting) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingL7Settings) DeepCopyInto(out *AviInfraSettingL7Settings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingL7Settings.
+func (in *AviInfraSettingL7Settings) DeepCopy() *AviInfraSettingL7Settings {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingL7Settings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingList) DeepCopyInto(out *AviInfraSettingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AviInfraSetting, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingList.
+func (in *AviInfraSettingList) DeepCopy() *AviInfraSettingList {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AviInfraSettingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingNetwork) DeepCopyInto(out *AviInfraSettingNetwork) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingNetwork.
+func (in *AviInfraSettingNetwork) DeepCopy() *AviInfraSettingNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingSeGroup) DeepCopyInto(out *AviInfraSettingSeGroup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingSeGroup.
+func (in *AviInfraSettingSeGroup) DeepCopy() *AviInfraSettingSeGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingSeGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingSpec) DeepCopyInto(out *AviInfraSettingSpec) {
+	*out = *in
+	out.SeGroup = in.SeGroup
+	out.Network = in.Network
+	out.L7Settings = in.L7Settings
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingSpec.
+func (in *AviInfraSettingSpec) DeepCopy() *AviInfraSettingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AviInfraSettingStatus) DeepCopyInto(out *AviInfraSettingStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AviInfraSettingStatus.
+func (in *AviInfraSettingStatus) DeepCopy() *AviInfraSettingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AviInfraSettingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPRule) DeepCopyInto(out *HTTPRule) {
 	*out = *in
@@ -172,6 +316,99 @@ func (in *HTTPRuleTLS) DeepCopy() *HTTPRuleTLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameClaim) DeepCopyInto(out *HostnameClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Status = in.Status
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameClaim.
+func (in *HostnameClaim) DeepCopy() *HostnameClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HostnameClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameClaimList) DeepCopyInto(out *HostnameClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HostnameClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameClaimList.
+func (in *HostnameClaimList) DeepCopy() *HostnameClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HostnameClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameClaimSpec) DeepCopyInto(out *HostnameClaimSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameClaimSpec.
+func (in *HostnameClaimSpec) DeepCopy() *HostnameClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameClaimStatus) DeepCopyInto(out *HostnameClaimStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameClaimStatus.
+func (in *HostnameClaimStatus) DeepCopy() *HostnameClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostRule) DeepCopyInto(out *HostRule) {
 	*out = *in
@@ -200,6 +437,22 @@ func (in *HostRule) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostRuleClientCertificate) DeepCopyInto(out *HostRuleClientCertificate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostRuleClientCertificate.
+func (in *HostRuleClientCertificate) DeepCopy() *HostRuleClientCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(HostRuleClientCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostRuleHTTPPolicy) DeepCopyInto(out *HostRuleHTTPPolicy) {
 	*out = *in
@@ -307,6 +560,7 @@ func (in *HostRuleStatus) DeepCopy() *HostRuleStatus {
 func (in *HostRuleTLS) DeepCopyInto(out *HostRuleTLS) {
 	*out = *in
 	out.SSLKeyCertificate = in.SSLKeyCertificate
+	out.ClientCertificate = in.ClientCertificate
 	return
 }
 
@@ -19,7 +19,6 @@ import (
 	"os"
 	"sync"
 
-	"github.com/avinetworks/sdk/go/session"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
@@ -29,15 +28,41 @@ import (
 var AviClientInstance *utils.AviRestClientPool
 var clientonce sync.Once
 
+var ctrlCredentials *utils.AviControllerCredentials
+var ctrlCredentialsLock sync.Mutex
+
+// SetControllerCredentials sets the credentials the avi clients are built with, in place of the ones in the
+// environment, and returns true if they changed.
+func SetControllerCredentials(credentials utils.AviControllerCredentials) bool {
+	ctrlCredentialsLock.Lock()
+	defer ctrlCredentialsLock.Unlock()
+	if ctrlCredentials != nil && *ctrlCredentials == credentials {
+		return false
+	}
+	ctrlCredentials = &credentials
+	return true
+}
+
+func getControllerCredentials() utils.AviControllerCredentials {
+	ctrlCredentialsLock.Lock()
+	defer ctrlCredentialsLock.Unlock()
+	if ctrlCredentials != nil {
+		return *ctrlCredentials
+	}
+	return utils.AviControllerCredentials{
+		Username: os.Getenv("CTRL_USERNAME"),
+		Password: os.Getenv("CTRL_PASSWORD"),
+	}
+}
+
 // This class is in control of AKC. It uses utils from the common project.
 func SharedAVIClients() *utils.AviRestClientPool {
 	var err error
 	var connectionStatus string
 
-	ctrlUsername := os.Getenv("CTRL_USERNAME")
-	ctrlPassword := os.Getenv("CTRL_PASSWORD")
+	credentials := getControllerCredentials()
 	ctrlIpAddress := os.Getenv("CTRL_IPADDRESS")
-	if ctrlUsername == "" || ctrlPassword == "" || ctrlIpAddress == "" {
+	if credentials.Username == "" || (credentials.Password == "" && credentials.AuthToken == "") || ctrlIpAddress == "" {
 		utils.AviLog.Fatal("AVI controller information missing. Update them in kubernetes secret or via environment variables.")
 	}
 
@@ -46,11 +71,10 @@ func SharedAVIClients() *utils.AviRestClientPool {
 		if shardSize != 0 {
 			if AviClientInstance == nil || len(AviClientInstance.AviClient) == 0 {
 				// initializing shardSize+1 clients in pool, the +1 is used by CRD ref verification calls
-				AviClientInstance, err = utils.NewAviRestClientPool(
+				AviClientInstance, err = utils.NewAviRestClientPoolWithCredentials(
 					shardSize+1,
 					ctrlIpAddress,
-					ctrlUsername,
-					ctrlPassword,
+					credentials,
 				)
 				connectionStatus = utils.AVIAPI_CONNECTED
 				if err != nil {
@@ -58,12 +82,7 @@ func SharedAVIClients() *utils.AviRestClientPool {
 					utils.AviLog.Error("AVI controller initilization failed")
 				}
 				// set the tenant and controller version in avisession obj
-				for _, client := range AviClientInstance.AviClient {
-					SetTenant := session.SetTenant(lib.GetTenant())
-					SetTenant(client.AviSession)
-					SetVersion := session.SetVersion(utils.CtrlVersion)
-					SetVersion(client.AviSession)
-				}
+				AviClientInstance.SetTenantAndVersion(lib.GetTenant(), utils.CtrlVersion)
 			}
 		} else {
 			connectionStatus = utils.AVIAPI_DISCONNECTED
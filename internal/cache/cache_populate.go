@@ -0,0 +1,153 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/avinetworks/sdk/go/clients"
+	"github.com/avinetworks/sdk/go/session"
+)
+
+// AviClientQueue hands out the avi clients to the goroutines populating the cache. A client is
+// checked out for a single request, so that an avi session is never used by two goroutines at once.
+type AviClientQueue chan *clients.AviClient
+
+func NewAviClientQueue(aviClients ...*clients.AviClient) AviClientQueue {
+	clientQueue := make(AviClientQueue, len(aviClients))
+	for _, client := range aviClients {
+		clientQueue <- client
+	}
+	return clientQueue
+}
+
+func (q AviClientQueue) GetCollectionRaw(uri string) (session.AviCollectionResult, error) {
+	client := <-q
+	defer func() { q <- client }()
+	return AviGetCollectionRaw(client, uri)
+}
+
+// WithClient checks out a client for the calls made by fn.
+func (q AviClientQueue) WithClient(fn func(client *clients.AviClient) error) error {
+	client := <-q
+	defer func() { q <- client }()
+	return fn(client)
+}
+
+// GetCollectionPages fetches all the pages of a collection, and returns the results in order along with
+// the total count reported by the controller. The page size is learnt from the first page, the pages
+// following it are fetched concurrently with at most lib.CACHE_POPULATE_PAGE_FANOUT requests in flight.
+func (q AviClientQueue) GetCollectionPages(uri string) ([]json.RawMessage, int, error) {
+	result, err := q.GetCollectionRaw(uri)
+	if err != nil {
+		return nil, 0, err
+	}
+	var elems []json.RawMessage
+	if err = json.Unmarshal(result.Results, &elems); err != nil {
+		return nil, 0, err
+	}
+	if result.Next == "" || len(elems) == 0 {
+		return elems, result.Count, nil
+	}
+
+	numPages := (result.Count + len(elems) - 1) / len(elems)
+	pages := make([][]json.RawMessage, numPages)
+	pageErrs := make([]error, numPages)
+	pages[0] = elems
+	pageNums := make(chan int, numPages)
+	for page := 2; page <= numPages; page++ {
+		pageNums <- page
+	}
+	close(pageNums)
+
+	fanOut := lib.CACHE_POPULATE_PAGE_FANOUT
+	if numPages-1 < fanOut {
+		fanOut = numPages - 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < fanOut; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pageNums {
+				pageResult, err := q.GetCollectionRaw(uri + "&page=" + strconv.Itoa(page))
+				if err == nil {
+					err = json.Unmarshal(pageResult.Results, &pages[page-1])
+				}
+				pageErrs[page-1] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	var allElems []json.RawMessage
+	for i, page := range pages {
+		if pageErrs[i] != nil {
+			utils.AviLog.Warnf("Get uri %v returned err for page %d: %v", uri, i+1, pageErrs[i])
+			return nil, 0, pageErrs[i]
+		}
+		allElems = append(allElems, page...)
+	}
+	return allElems, result.Count, nil
+}
+
+type cachePopulateTask struct {
+	objType  string
+	objCache *AviCache
+	populate func() error
+}
+
+// runCachePopulateStages populates the object types of a stage concurrently, and moves on to the next stage
+// once all of them are done. The progress is reported per object type through the status API. The later stages
+// refer to the objects of the earlier ones, so the population stops at the first stage with a failed object type.
+func runCachePopulateStages(stages [][]cachePopulateTask) error {
+	for _, stage := range stages {
+		for _, task := range stage {
+			apimodels.RestStatus.UpdateAviObjCacheStatus(task.objType, utils.AVICACHE_NOT_STARTED, 0)
+		}
+	}
+	for _, stage := range stages {
+		var wg sync.WaitGroup
+		errs := make([]error, len(stage))
+		for i, task := range stage {
+			wg.Add(1)
+			go func(i int, task cachePopulateTask) {
+				defer wg.Done()
+				apimodels.RestStatus.UpdateAviObjCacheStatus(task.objType, utils.AVICACHE_IN_PROGRESS, 0)
+				if errs[i] = task.populate(); errs[i] != nil {
+					utils.AviLog.Warnf("Failed to populate the %s cache: %v", task.objType, errs[i])
+					apimodels.RestStatus.UpdateAviObjCacheStatus(task.objType, utils.AVICACHE_FAILED, len(task.objCache.ShallowCopy()))
+					return
+				}
+				apimodels.RestStatus.UpdateAviObjCacheStatus(task.objType, utils.AVICACHE_POPULATED, len(task.objCache.ShallowCopy()))
+			}(i, task)
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				return fmt.Errorf("failed to populate the %s cache: %v", stage[i].objType, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/avinetworks/sdk/go/clients"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CacheSnapshotVersion is bumped whenever the layout of the snapshot changes, older snapshots are ignored.
+const CacheSnapshotVersion = 1
+
+// maxConfigMapSize is the size limit of the data of a ConfigMap enforced by the API server.
+const maxConfigMapSize = 1024 * 1024
+
+// AviCacheSnapshot is the persisted copy of the object caches and of the graph checksums of the models
+// synced to the controller. Only the object types which record the last modified timestamp of the
+// controller are part of it, since those can be verified without fetching the objects again.
+type AviCacheSnapshot struct {
+	Version         int                   `json:"version"`
+	Controller      string                `json:"controller"`
+	Cloud           string                `json:"cloud"`
+	Tenant          string                `json:"tenant"`
+	Timestamp       time.Time             `json:"timestamp"`
+	VirtualServices []*AviVsCache         `json:"virtualservices"`
+	Pools           []*AviPoolCache       `json:"pools"`
+	PoolGroups      []*AviPGCache         `json:"poolgroups"`
+	HTTPPolicies    []*AviHTTPPolicyCache `json:"httppolicysets"`
+	L4Policies      []*AviL4PolicyCache   `json:"l4policysets"`
+	VSVips          []*AviVSVIPCache      `json:"vsvips"`
+	GraphChecksums  map[string]uint32     `json:"graph_checksums"`
+}
+
+type cacheSnapshotStore interface {
+	save(data []byte) error
+	load() ([]byte, error)
+}
+
+// pvcSnapshotStore keeps the snapshot as a file next to the logs, on the persistent volume claim.
+type pvcSnapshotStore struct {
+	path string
+}
+
+func (s pvcSnapshotStore) save(data []byte) error {
+	// write to a temporary file first, so that a crash never leaves a partial snapshot behind
+	tmpPath := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+func (s pvcSnapshotStore) load() ([]byte, error) {
+	return ioutil.ReadFile(s.path)
+}
+
+// configMapSnapshotStore keeps the snapshot gzipped in a ConfigMap in the AKO namespace.
+type configMapSnapshotStore struct{}
+
+func (s configMapSnapshotStore) save(data []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if buf.Len() > maxConfigMapSize {
+		return fmt.Errorf("the gzipped cache snapshot of %d bytes exceeds the %d bytes limit of a ConfigMap, the PVC store should be used instead", buf.Len(), maxConfigMapSize)
+	}
+
+	cmClient := utils.GetInformers().ClientSet.CoreV1().ConfigMaps(lib.AviNS)
+	cm, err := cmClient.Get(lib.CacheSnapshotConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: lib.AviNS, Name: lib.CacheSnapshotConfigMap},
+			BinaryData: map[string][]byte{lib.CacheSnapshotKey: buf.Bytes()},
+		}
+		_, err = cmClient.Create(cm)
+		return err
+	} else if err != nil {
+		return err
+	}
+	cm.BinaryData = map[string][]byte{lib.CacheSnapshotKey: buf.Bytes()}
+	_, err = cmClient.Update(cm)
+	return err
+}
+
+func (s configMapSnapshotStore) load() ([]byte, error) {
+	cm, err := utils.GetInformers().ClientSet.CoreV1().ConfigMaps(lib.AviNS).Get(lib.CacheSnapshotConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(cm.BinaryData[lib.CacheSnapshotKey]))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
+
+func getCacheSnapshotStore() cacheSnapshotStore {
+	switch lib.GetCacheSnapshotStore() {
+	case lib.CacheSnapshotStorePVC:
+		return pvcSnapshotStore{path: filepath.Join(os.Getenv("LOG_FILE_PATH"), lib.CacheSnapshotFile)}
+	case lib.CacheSnapshotStoreConfigMap:
+		return configMapSnapshotStore{}
+	}
+	return nil
+}
+
+// SaveCacheSnapshot persists the verifiable object caches along with the checksums of the models which
+// are in sync with the controller.
+func (c *AviObjCache) SaveCacheSnapshot(graphChecksums map[string]uint32) error {
+	store := getCacheSnapshotStore()
+	if store == nil {
+		return nil
+	}
+	snapshot := AviCacheSnapshot{
+		Version:        CacheSnapshotVersion,
+		Controller:     os.Getenv("CTRL_IPADDRESS"),
+		Cloud:          utils.CloudName,
+		Tenant:         lib.GetTenant(),
+		Timestamp:      time.Now(),
+		GraphChecksums: graphChecksums,
+	}
+	for _, vsIntf := range c.VsCacheMeta.ShallowCopy() {
+		if vs, ok := vsIntf.(*AviVsCache); ok {
+			if vsCopy, done := vs.GetVSCopy(); done {
+				snapshot.VirtualServices = append(snapshot.VirtualServices, vsCopy)
+			}
+		}
+	}
+	for _, objIntf := range c.PoolCache.DeepCopy() {
+		if obj, ok := objIntf.(*AviPoolCache); ok {
+			snapshot.Pools = append(snapshot.Pools, obj)
+		}
+	}
+	for _, objIntf := range c.PgCache.DeepCopy() {
+		if obj, ok := objIntf.(*AviPGCache); ok {
+			snapshot.PoolGroups = append(snapshot.PoolGroups, obj)
+		}
+	}
+	for _, objIntf := range c.HTTPPolicyCache.DeepCopy() {
+		if obj, ok := objIntf.(*AviHTTPPolicyCache); ok {
+			snapshot.HTTPPolicies = append(snapshot.HTTPPolicies, obj)
+		}
+	}
+	for _, objIntf := range c.L4PolicyCache.DeepCopy() {
+		if obj, ok := objIntf.(*AviL4PolicyCache); ok {
+			snapshot.L4Policies = append(snapshot.L4Policies, obj)
+		}
+	}
+	for _, objIntf := range c.VSVIPCache.DeepCopy() {
+		if obj, ok := objIntf.(*AviVSVIPCache); ok {
+			snapshot.VSVips = append(snapshot.VSVips, obj)
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err = store.save(data); err != nil {
+		return err
+	}
+	utils.AviLog.Infof("Saved cache snapshot with %d virtualservices and %d models", len(snapshot.VirtualServices), len(graphChecksums))
+	return nil
+}
+
+// LoadCacheSnapshot returns the persisted snapshot, if it was taken by this version of AKO for the same
+// controller, cloud and tenant.
+func LoadCacheSnapshot() *AviCacheSnapshot {
+	store := getCacheSnapshotStore()
+	if store == nil {
+		return nil
+	}
+	data, err := store.load()
+	if err != nil {
+		utils.AviLog.Infof("Cache snapshot not loaded, populating the cache from the controller: %v", err)
+		return nil
+	}
+	var snapshot AviCacheSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		utils.AviLog.Warnf("Unable to parse the cache snapshot, populating the cache from the controller: %v", err)
+		return nil
+	}
+	if snapshot.Version != CacheSnapshotVersion || snapshot.Controller != os.Getenv("CTRL_IPADDRESS") ||
+		snapshot.Cloud != utils.CloudName || snapshot.Tenant != lib.GetTenant() {
+		utils.AviLog.Infof("Cache snapshot version %d of controller %s, cloud %s, tenant %s does not apply, populating the cache from the controller",
+			snapshot.Version, snapshot.Controller, snapshot.Cloud, snapshot.Tenant)
+		return nil
+	}
+	utils.AviLog.Infof("Loaded cache snapshot taken at %v", snapshot.Timestamp)
+	return &snapshot
+}
+
+// restoreCacheSnapshot adds the objects of the snapshot to the caches.
+func (c *AviObjCache) restoreCacheSnapshot(snapshot *AviCacheSnapshot) {
+	for _, vs := range snapshot.VirtualServices {
+		c.VsCacheMeta.AviCacheAdd(NamespaceName{Namespace: utils.ADMIN_NS, Name: vs.Name}, vs)
+	}
+	for _, obj := range snapshot.Pools {
+		obj.HasReference = false
+		c.PoolCache.AviCacheAdd(NamespaceName{Namespace: lib.GetTenant(), Name: obj.Name}, obj)
+	}
+	for _, obj := range snapshot.PoolGroups {
+		obj.HasReference = false
+		c.PgCache.AviCacheAdd(NamespaceName{Namespace: lib.GetTenant(), Name: obj.Name}, obj)
+	}
+	for _, obj := range snapshot.HTTPPolicies {
+		obj.HasReference = false
+		c.HTTPPolicyCache.AviCacheAdd(NamespaceName{Namespace: lib.GetTenant(), Name: obj.Name}, obj)
+	}
+	for _, obj := range snapshot.L4Policies {
+		obj.HasReference = false
+		c.L4PolicyCache.AviCacheAdd(NamespaceName{Namespace: lib.GetTenant(), Name: obj.Name}, obj)
+	}
+	for _, obj := range snapshot.VSVips {
+		obj.HasReference = false
+		c.VSVIPCache.AviCacheAdd(NamespaceName{Namespace: lib.GetTenant(), Name: obj.Name}, obj)
+	}
+	c.snapshotLock.Lock()
+	c.snapshotGraphChecksums = snapshot.GraphChecksums
+	c.snapshotLock.Unlock()
+}
+
+// IsModelInSnapshot returns true if the model was in sync with the controller when the snapshot
+// was taken, with the same checksum.
+func (c *AviObjCache) IsModelInSnapshot(modelName string, checksum uint32) bool {
+	c.snapshotLock.RLock()
+	defer c.snapshotLock.RUnlock()
+	snapshotChecksum, found := c.snapshotGraphChecksums[modelName]
+	return found && snapshotChecksum == checksum
+}
+
+// ClearSnapshotGraphChecksums drops the graph checksums of the snapshot once the bootup full sync is done.
+func (c *AviObjCache) ClearSnapshotGraphChecksums() {
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+	c.snapshotGraphChecksums = nil
+}
+
+func cachedLastModified(objIntf interface{}) string {
+	switch obj := objIntf.(type) {
+	case *AviVsCache:
+		return obj.LastModified
+	case *AviPoolCache:
+		return obj.LastModified
+	case *AviPGCache:
+		return obj.LastModified
+	case *AviHTTPPolicyCache:
+		return obj.LastModified
+	case *AviL4PolicyCache:
+		return obj.LastModified
+	case *AviVSVIPCache:
+		return obj.LastModified
+	}
+	return ""
+}
+
+// verifyObjCache compares the last modified timestamps of the objects on the controller with the ones in the
+// cache. The objects modified since the snapshot was taken are fetched again, the deleted ones are removed.
+func verifyObjCache(clientQueue AviClientQueue, objType, uri string, objCache *AviCache, populateOne func(client *clients.AviClient, name string) error) error {
+	elems, _, err := clientQueue.GetCollectionPages(uri + "&fields=name,_last_modified")
+	if err != nil {
+		utils.AviLog.Warnf("Get uri %v returned err for %s %v", uri, objType, err)
+		return err
+	}
+	staleObjs := objCache.ShallowCopy()
+	var refreshed int
+	for _, elem := range elems {
+		var objCksum AviObjCksum
+		if err := json.Unmarshal(elem, &objCksum); err != nil || objCksum.Name == "" {
+			continue
+		}
+		k := NamespaceName{Namespace: lib.GetTenant(), Name: objCksum.Name}
+		delete(staleObjs, k)
+		if objIntf, found := objCache.AviCacheGet(k); found && cachedLastModified(objIntf) == objCksum.LastModified {
+			continue
+		}
+		if err := clientQueue.WithClient(func(client *clients.AviClient) error {
+			return populateOne(client, objCksum.Name)
+		}); err != nil {
+			return err
+		}
+		refreshed++
+	}
+	for key := range staleObjs {
+		utils.AviLog.Debugf("Deleting key from %s cache :%s", objType, key)
+		objCache.AviCacheDelete(key)
+	}
+	utils.AviLog.Infof("Verified %s cache against the controller, %d objects refreshed, %d removed", objType, refreshed, len(staleObjs))
+	return nil
+}
+
+// AviVerifyObjectCache verifies the caches restored from a snapshot against the controller, in the same stages
+// as AviRefreshObjectCache. The object types which are not part of the snapshot are populated in full.
+func (c *AviObjCache) AviVerifyObjectCache(clientQueue AviClientQueue, cloud string) error {
+	akoUser := lib.AKOUser
+	return runCachePopulateStages([][]cachePopulateTask{
+		{
+			{objType: "pkiprofile", objCache: c.PKIProfileCache, populate: func() error { return c.PopulatePkiProfilesToCache(clientQueue) }},
+			{objType: "applicationprofile", objCache: c.AppProfileCache, populate: func() error { return c.PopulateAppProfilesToCache(clientQueue) }},
+			{objType: "sslkeyandcertificate", objCache: c.SSLKeyCache, populate: func() error { return c.PopulateSSLKeyToCache(clientQueue, cloud) }},
+			{objType: "vsvip", objCache: c.VSVIPCache, populate: func() error {
+				return verifyObjCache(clientQueue, "vsvip", "/api/vsvip/?name.contains="+lib.GetNamePrefix()+"&include_name=true&cloud_ref.name="+cloud+"&page_size=100", c.VSVIPCache,
+					func(client *clients.AviClient, name string) error {
+						return c.AviPopulateOneVsVipCache(client, cloud, name, lib.GetTenant())
+					})
+			}},
+		},
+		{
+			{objType: "pool", objCache: c.PoolCache, populate: func() error {
+				return verifyObjCache(clientQueue, "pool", "/api/pool/?include_name=true&cloud_ref.name="+cloud+"&created_by="+akoUser+"&page_size=100", c.PoolCache,
+					func(client *clients.AviClient, name string) error {
+						return c.AviPopulateOnePoolCache(client, cloud, name, lib.GetTenant())
+					})
+			}},
+		},
+		{
+			{objType: "poolgroup", objCache: c.PgCache, populate: func() error {
+				return verifyObjCache(clientQueue, "poolgroup", "/api/poolgroup/?include_name=true&cloud_ref.name="+cloud+"&created_by="+akoUser+"&page_size=100", c.PgCache,
+					func(client *clients.AviClient, name string) error {
+						return c.AviPopulateOnePGCache(client, cloud, name, lib.GetTenant())
+					})
+			}},
+			{objType: "l4policyset", objCache: c.L4PolicyCache, populate: func() error {
+				return verifyObjCache(clientQueue, "l4policyset", "/api/l4policyset/?include_name=true&created_by="+akoUser+"&page_size=100", c.L4PolicyCache,
+					func(client *clients.AviClient, name string) error {
+						return c.AviPopulateOneVsL4PolCache(client, cloud, name, lib.GetTenant())
+					})
+			}},
+		},
+		{
+			{objType: "vsdatascriptset", objCache: c.DSCache, populate: func() error { return c.PopulateDSDataToCache(clientQueue, cloud) }},
+			{objType: "httppolicyset", objCache: c.HTTPPolicyCache, populate: func() error {
+				return verifyObjCache(clientQueue, "httppolicyset", "/api/httppolicyset/?include_name=true&created_by="+akoUser+"&page_size=100", c.HTTPPolicyCache,
+					func(client *clients.AviClient, name string) error {
+						return c.AviPopulateOneVsHttpPolCache(client, cloud, name, lib.GetTenant())
+					})
+			}},
+		},
+	})
+}
+
+// vsLastModified is the last modified timestamp of a virtualservice on the controller, along with the parent
+// reference of SNI children.
+type vsLastModified struct {
+	Name          string `json:"name"`
+	LastModified  string `json:"_last_modified"`
+	VhParentVsRef string `json:"vh_parent_vs_ref"`
+}
+
+// AviVerifyVSCache verifies the virtualservices restored from a snapshot, the ones modified on the controller
+// since are fetched again. The keys of the virtualservices found on the controller are removed from vsCacheCopy,
+// the rest are left for the caller to remove. All the virtualservices end up in the local vs cache.
+func (c *AviObjCache) AviVerifyVSCache(clientQueue AviClientQueue, cloud string, vsCacheCopy *[]NamespaceName) error {
+	uri := "/api/virtualservice/?include_name=true&cloud_ref.name=" + cloud + "&created_by=" + lib.AKOUser + "&page_size=100&fields=name,_last_modified,vh_parent_vs_ref"
+	elems, _, err := clientQueue.GetCollectionPages(uri)
+	if err != nil {
+		utils.AviLog.Warnf("Vs Get uri %v returned err %v", uri, err)
+		return err
+	}
+	var vsList []vsLastModified
+	for _, elem := range elems {
+		var vs vsLastModified
+		if err := json.Unmarshal(elem, &vs); err != nil || vs.Name == "" {
+			continue
+		}
+		vsList = append(vsList, vs)
+	}
+	// parents are refreshed ahead of the SNI children, which look up their parent in the cache
+	sort.SliceStable(vsList, func(i, j int) bool {
+		return vsList[i].VhParentVsRef == "" && vsList[j].VhParentVsRef != ""
+	})
+
+	var refreshed int
+	for _, vs := range vsList {
+		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: vs.Name}
+		*vsCacheCopy = Remove(*vsCacheCopy, k)
+		if vsIntf, found := c.VsCacheMeta.AviCacheGet(k); found && cachedLastModified(vsIntf) == vs.LastModified {
+			continue
+		}
+		if err := clientQueue.WithClient(func(client *clients.AviClient) error {
+			return c.AviObjOneVSCachePopulate(client, cloud, vs.Name, lib.GetTenant())
+		}); err != nil {
+			return err
+		}
+		refreshed++
+	}
+	for _, k := range c.VsCacheMeta.AviGetAllKeys() {
+		if vsIntf, found := c.VsCacheMeta.AviCacheGet(k); found {
+			c.VsCacheLocal.AviCacheAdd(k, vsIntf)
+			c.VsCacheMeta.AviCacheDelete(k)
+		}
+	}
+	utils.AviLog.Infof("Verified virtualservice cache against the controller, %d virtualservices refreshed", refreshed)
+	return nil
+}
@@ -15,6 +15,7 @@ package cache
 
 import (
 	"encoding/json"
+	"reflect"
 	"sync"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
@@ -137,6 +138,21 @@ func (v *AviVsCache) SetPGKeyCollection(keyCollection []NamespaceName) {
 	v.PGKeyCollection = keyCollection
 }
 
+// GetCksumAndLastModified returns the checksum and the last modified timestamp of the virtualservice last synced by AKO
+func (v *AviVsCache) GetCksumAndLastModified() (string, string) {
+	v.VSCacheLock.RLock()
+	defer v.VSCacheLock.RUnlock()
+	return v.CloudConfigCksum, v.LastModified
+}
+
+// InvalidateCksum has the next sync of the model update the virtualservice on the controller
+func (v *AviVsCache) InvalidateCksum() {
+	v.VSCacheLock.Lock()
+	defer v.VSCacheLock.Unlock()
+	v.CloudConfigCksum = ""
+	v.InvalidData = true
+}
+
 func Remove(s []NamespaceName, r NamespaceName) []NamespaceName {
 	for i, v := range s {
 		if v == r {
@@ -311,6 +327,16 @@ type AviPkiProfileCache struct {
 	HasReference     bool
 }
 
+type AviAppProfileCache struct {
+	Name             string
+	Tenant           string
+	Uuid             string
+	CloudConfigCksum string
+	LastModified     string
+	InvalidData      bool
+	HasReference     bool
+}
+
 type NextPage struct {
 	Next_uri   string
 	Collection interface{}
@@ -497,6 +523,10 @@ func (c *AviCache) AviCacheGetNameByUuid(uuid string) (interface{}, bool) {
 			if value.(*AviPkiProfileCache).Uuid == uuid {
 				return value.(*AviPkiProfileCache).Name, true
 			}
+		case *AviAppProfileCache:
+			if value.(*AviAppProfileCache).Uuid == uuid {
+				return value.(*AviAppProfileCache).Name, true
+			}
 		}
 	}
 	return nil, false
@@ -508,6 +538,22 @@ func (c *AviCache) AviCacheAdd(k interface{}, val interface{}) {
 	c.cache[k] = val
 }
 
+// AviCacheInvalidatePool has the next sync of the model update the pool on the controller, unless the pool was
+// synced again since its checksum was read. The pool objects are replaced on sync, so a copy is invalidated.
+func (c *AviCache) AviCacheInvalidatePool(k NamespaceName, cksum string) bool {
+	c.cache_lock.Lock()
+	defer c.cache_lock.Unlock()
+	pool, ok := c.cache[k].(*AviPoolCache)
+	if !ok || pool.CloudConfigCksum != cksum {
+		return false
+	}
+	poolCopy := *pool
+	poolCopy.CloudConfigCksum = ""
+	poolCopy.InvalidData = true
+	c.cache[k] = &poolCopy
+	return true
+}
+
 func (c *AviCache) AviCacheDelete(k interface{}) {
 	c.cache_lock.Lock()
 	defer c.cache_lock.Unlock()
@@ -524,3 +570,25 @@ func (c *AviCache) ShallowCopy() map[interface{}]interface{} {
 	}
 	return newMap
 }
+
+// DeepCopy returns copies of the objects in the cache, taken under the cache lock so that none of them
+// is read while it is being updated.
+func (c *AviCache) DeepCopy() []interface{} {
+	c.cache_lock.RLock()
+	defer c.cache_lock.RUnlock()
+	var objs []interface{}
+	for _, value := range c.cache {
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			utils.AviLog.Errorf("Unable to marshal: %s", err)
+			continue
+		}
+		newObj := reflect.New(reflect.TypeOf(value).Elem()).Interface()
+		if err = json.Unmarshal(bytes, newObj); err != nil {
+			utils.AviLog.Errorf("Unable to Unmarshal src: %s", err)
+			continue
+		}
+		objs = append(objs, newObj)
+	}
+	return objs
+}
@@ -46,10 +46,14 @@ type AviObjCache struct {
 	L4PolicyCache   *AviCache
 	SSLKeyCache     *AviCache
 	PKIProfileCache *AviCache
+	AppProfileCache *AviCache
 	VSVIPCache      *AviCache
 	VrfCache        *AviCache
 	VsCacheMeta     *AviCache
 	VsCacheLocal    *AviCache
+	// checksums of the models in sync with the controller, when the snapshot loaded at bootup was taken
+	snapshotLock           sync.RWMutex
+	snapshotGraphChecksums map[string]uint32
 }
 
 func NewAviObjCache() *AviObjCache {
@@ -66,6 +70,7 @@ func NewAviObjCache() *AviObjCache {
 	c.VSVIPCache = NewAviCache()
 	c.VrfCache = NewAviCache()
 	c.PKIProfileCache = NewAviCache()
+	c.AppProfileCache = NewAviCache()
 	return &c
 }
 
@@ -79,41 +84,127 @@ func SharedAviObjCache() *AviObjCache {
 	return cacheInstance
 }
 
-func (c *AviObjCache) AviRefreshObjectCache(client *clients.AviClient, cloud string) {
-	c.PopulatePkiProfilesToCache(client)
-	c.PopulatePoolsToCache(client, cloud)
-	c.PopulatePgDataToCache(client, cloud)
-	c.PopulateDSDataToCache(client, cloud)
-	c.PopulateSSLKeyToCache(client, cloud)
-	c.PopulateHttpPolicySetToCache(client, cloud)
-	c.PopulateL4PolicySetToCache(client, cloud)
-	c.PopulateVsVipDataToCache(client, cloud)
-	// Switch to the below method once the go sdk is fixed for the DBExtensions fields.
-	//c.PopulateVsKeyToCache(client, cloud)
+// AviRefreshObjectCache populates the object caches in stages, using all the clients in the queue. An object
+// type refers to the ones populated in the earlier stages by uuid, e.g. poolgroups to pools, so only
+// the types within a stage are populated in parallel.
+func (c *AviObjCache) AviRefreshObjectCache(clientQueue AviClientQueue, cloud string) error {
+	// Switch to c.PopulateVsKeyToCache(client, cloud) for the virtualservices once the go sdk is fixed for the
+	// DBExtensions fields.
+	return runCachePopulateStages([][]cachePopulateTask{
+		{
+			{objType: "pkiprofile", objCache: c.PKIProfileCache, populate: func() error { return c.PopulatePkiProfilesToCache(clientQueue) }},
+			{objType: "applicationprofile", objCache: c.AppProfileCache, populate: func() error { return c.PopulateAppProfilesToCache(clientQueue) }},
+			{objType: "sslkeyandcertificate", objCache: c.SSLKeyCache, populate: func() error { return c.PopulateSSLKeyToCache(clientQueue, cloud) }},
+			{objType: "vsvip", objCache: c.VSVIPCache, populate: func() error { return c.PopulateVsVipDataToCache(clientQueue, cloud) }},
+		},
+		{
+			{objType: "pool", objCache: c.PoolCache, populate: func() error { return c.PopulatePoolsToCache(clientQueue, cloud) }},
+		},
+		{
+			{objType: "poolgroup", objCache: c.PgCache, populate: func() error { return c.PopulatePgDataToCache(clientQueue, cloud) }},
+			{objType: "l4policyset", objCache: c.L4PolicyCache, populate: func() error { return c.PopulateL4PolicySetToCache(clientQueue, cloud) }},
+		},
+		{
+			{objType: "vsdatascriptset", objCache: c.DSCache, populate: func() error { return c.PopulateDSDataToCache(clientQueue, cloud) }},
+			{objType: "httppolicyset", objCache: c.HTTPPolicyCache, populate: func() error { return c.PopulateHttpPolicySetToCache(clientQueue, cloud) }},
+		},
+	})
 }
 
 func (c *AviObjCache) AviCacheRefresh(client *clients.AviClient, cloud string) {
 	c.AviCloudPropertiesPopulate(client, cloud)
 }
 
-func (c *AviObjCache) AviObjCachePopulate(client *clients.AviClient, version string, cloud string) ([]NamespaceName, []NamespaceName, error) {
-	SetTenant := session.SetTenant(lib.GetTenant())
-	SetTenant(client.AviSession)
-	SetVersion := session.SetVersion(version)
-	SetVersion(client.AviSession)
+// AviObjCksum is the checksum of an AKO created object, as stored on the controller.
+type AviObjCksum struct {
+	Name             string `json:"name"`
+	CloudConfigCksum string `json:"cloud_config_cksum"`
+	LastModified     string `json:"_last_modified"`
+}
+
+// AviGetAllObjCksums fetches the checksums of all the AKO created objects of a type, e.g. virtualservice or pool,
+// keyed on the object name. Only the fields required to detect out of band changes are requested.
+func AviGetAllObjCksums(client *clients.AviClient, cloud string, objType string, objCksums map[string]AviObjCksum, nextPage ...NextPage) error {
+	var uri string
+	if len(nextPage) == 1 {
+		uri = nextPage[0].Next_uri
+	} else {
+		uri = "/api/" + objType + "/?" + "include_name=true" + "&cloud_ref.name=" + cloud + "&created_by=" + lib.AKOUser + "&fields=name,cloud_config_cksum,_last_modified&page_size=100"
+	}
+
+	result, err := AviGetCollectionRaw(client, uri)
+	if err != nil {
+		utils.AviLog.Warnf("Get uri %v returned err for %s %v", uri, objType, err)
+		return err
+	}
+	var elems []AviObjCksum
+	if err = json.Unmarshal(result.Results, &elems); err != nil {
+		utils.AviLog.Warnf("Failed to unmarshal %s data, err: %v", objType, err)
+		return err
+	}
+	for _, elem := range elems {
+		if elem.Name == "" {
+			continue
+		}
+		objCksums[elem.Name] = elem
+	}
+	if result.Next != "" {
+		// It has a next page, let's recursively call the same method.
+		next_uri := strings.Split(result.Next, "/api/"+objType)
+		if len(next_uri) > 1 {
+			override_uri := "/api/" + objType + next_uri[1]
+			return AviGetAllObjCksums(client, cloud, objType, objCksums, NextPage{Next_uri: override_uri})
+		}
+	}
+	return nil
+}
+
+// AviObjCachePopulate populates the caches at bootup, the objects are fetched in parallel using all the clients.
+// The first client is used for the vrf and cloud properties.
+func (c *AviObjCache) AviObjCachePopulate(aviClients []*clients.AviClient, version string, cloud string) ([]NamespaceName, []NamespaceName, error) {
+	for _, aviClient := range aviClients {
+		SetTenant := session.SetTenant(lib.GetTenant())
+		SetTenant(aviClient.AviSession)
+		SetVersion := session.SetVersion(version)
+		SetVersion(aviClient.AviSession)
+	}
+	client := aviClients[0]
 	vsCacheCopy := []NamespaceName{}
 	allVsKeys := []NamespaceName{}
+	apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_IN_PROGRESS)
 	err := c.AviObjVrfCachePopulate(client, cloud)
 	if err != nil {
+		apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_FAILED)
 		return vsCacheCopy, allVsKeys, err
 	}
 	// Populate the VS cache
+	// A snapshot is verified against the controller, only the objects modified since it was taken are fetched.
+	snapshot := LoadCacheSnapshot()
+	if snapshot != nil {
+		c.restoreCacheSnapshot(snapshot)
+	}
 	utils.AviLog.Infof("Refreshing all object cache")
-	c.AviRefreshObjectCache(client, cloud)
+	clientQueue := NewAviClientQueue(aviClients...)
+	if snapshot != nil {
+		err = c.AviVerifyObjectCache(clientQueue, cloud)
+	} else {
+		err = c.AviRefreshObjectCache(clientQueue, cloud)
+	}
+	if err != nil {
+		apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_FAILED)
+		return vsCacheCopy, allVsKeys, err
+	}
 	vsCacheCopy = c.VsCacheMeta.AviCacheGetAllParentVSKeys()
 	allVsKeys = c.VsCacheMeta.AviGetAllKeys()
-	err = c.AviObjVSCachePopulate(client, cloud, &allVsKeys)
+	apimodels.RestStatus.UpdateAviObjCacheStatus("virtualservice", utils.AVICACHE_IN_PROGRESS, 0)
+	if snapshot != nil {
+		err = c.AviVerifyVSCache(clientQueue, cloud, &allVsKeys)
+	} else {
+		err = c.AviObjVSCachePopulate(clientQueue, cloud, &allVsKeys)
+	}
 	if err != nil {
+		apimodels.RestStatus.UpdateAviObjCacheStatus("virtualservice", utils.AVICACHE_FAILED, 0)
+		apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_FAILED)
 		return vsCacheCopy, allVsKeys, err
 	}
 	// Populate the SNI VS keys to their respective parents
@@ -125,10 +216,13 @@ func (c *AviObjCache) AviObjCachePopulate(client *clients.AviClient, version str
 		vsCacheCopy = Remove(vsCacheCopy, key)
 		c.VsCacheMeta.AviCacheDelete(key)
 	}
+	apimodels.RestStatus.UpdateAviObjCacheStatus("virtualservice", utils.AVICACHE_POPULATED, len(c.VsCacheMeta.ShallowCopy()))
 	err = c.AviCloudPropertiesPopulate(client, cloud)
 	if err != nil {
+		apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_FAILED)
 		return vsCacheCopy, allVsKeys, err
 	}
+	apimodels.RestStatus.UpdateAviCacheStatus(utils.AVICACHE_POPULATED)
 	//vsCacheCopy at this time, is left with only the deleted keys
 	return vsCacheCopy, allVsKeys, nil
 }
@@ -328,27 +422,15 @@ func (c *AviObjCache) DeleteUnmarked() {
 
 }
 
-func (c *AviObjCache) AviPopulateAllPGs(client *clients.AviClient, cloud string, pgData *[]AviPGCache, override_uri ...NextPage) (*[]AviPGCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllPGs(clientQueue AviClientQueue, cloud string, pgData *[]AviPGCache) (*[]AviPGCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/poolgroup/?" + "include_name=true&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(override_uri) == 1 {
-		uri = override_uri[0].Next_uri
-	} else {
-		uri = "/api/poolgroup/?" + "include_name=true&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for pg %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal pg data, err: %v", err)
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		pg := models.PoolGroup{}
 		err = json.Unmarshal(elems[i], &pg)
@@ -382,24 +464,14 @@ func (c *AviObjCache) AviPopulateAllPGs(client *clients.AviClient, cloud string,
 		}
 		*pgData = append(*pgData, pgCacheObj)
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/poolgroup")
-		if len(next_uri) > 1 {
-			override_uri := "/api/poolgroup" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllPGs(client, cloud, pgData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
-	return pgData, result.Count, nil
+	return pgData, count, nil
 }
 
-func (c *AviObjCache) PopulatePgDataToCache(client *clients.AviClient, cloud string) {
+func (c *AviObjCache) PopulatePgDataToCache(clientQueue AviClientQueue, cloud string) error {
 	var pgData []AviPGCache
-	c.AviPopulateAllPGs(client, cloud, &pgData)
+	if _, _, err := c.AviPopulateAllPGs(clientQueue, cloud, &pgData); err != nil {
+		return err
+	}
 
 	// Get all the PG cache data and copy them.
 	pgCacheData := c.PgCache.ShallowCopy()
@@ -428,28 +500,18 @@ func (c *AviObjCache) PopulatePgDataToCache(client *clients.AviClient, cloud str
 		utils.AviLog.Debugf("Deleting key from pg cache :%s", key)
 		c.PgCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllPkiPRofiles(client *clients.AviClient, pkiData *[]AviPkiProfileCache, override_uri ...NextPage) (*[]AviPkiProfileCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllPkiPRofiles(clientQueue AviClientQueue, pkiData *[]AviPkiProfileCache) (*[]AviPkiProfileCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/pkiprofile/?" + "&include_name=true&" + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(override_uri) == 1 {
-		uri = override_uri[0].Next_uri
-	} else {
-		uri = "/api/pkiprofile/?" + "&include_name=true&" + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for pool %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		pki := models.PKIprofile{}
 		err = json.Unmarshal(elems[i], &pki)
@@ -463,49 +525,64 @@ func (c *AviObjCache) AviPopulateAllPkiPRofiles(client *clients.AviClient, pkiDa
 			continue
 		}
 
+		var crl string
+		if len(pki.Crls) > 0 && pki.Crls[0].Body != nil {
+			crl = *pki.Crls[0].Body
+		}
 		pkiCacheObj := AviPkiProfileCache{
 			Name:             *pki.Name,
 			Uuid:             *pki.UUID,
 			Tenant:           lib.GetTenant(),
-			CloudConfigCksum: lib.SSLKeyCertChecksum(*pki.Name, string(*pki.CaCerts[0].Certificate), ""),
+			CloudConfigCksum: lib.SSLKeyCertChecksum(*pki.Name, string(*pki.CaCerts[0].Certificate), crl),
 		}
 		*pkiData = append(*pkiData, pkiCacheObj)
 
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/pkiprofile")
-		if len(next_uri) > 1 {
-			override_uri := "/api/pkiprofile" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllPkiPRofiles(client, pkiData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
 
-	return pkiData, result.Count, nil
+	return pkiData, count, nil
 }
 
-func (c *AviObjCache) AviPopulateAllPools(client *clients.AviClient, cloud string, poolData *[]AviPoolCache, override_uri ...NextPage) (*[]AviPoolCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllAppProfiles(clientQueue AviClientQueue, appProfData *[]AviAppProfileCache) (*[]AviAppProfileCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/applicationprofile/?" + "&include_name=true&" + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(override_uri) == 1 {
-		uri = override_uri[0].Next_uri
-	} else {
-		uri = "/api/pool/?" + "&include_name=true&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
-		utils.AviLog.Warnf("Get uri %v returned err for pool %v", uri, err)
+		utils.AviLog.Warnf("Get uri %v returned err for applicationprofile %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
+	for i := 0; i < len(elems); i++ {
+		appProf := models.ApplicationProfile{}
+		err = json.Unmarshal(elems[i], &appProf)
+		if err != nil {
+			utils.AviLog.Warnf("Failed to unmarshal applicationprofile data, err: %v", err)
+			continue
+		}
+
+		if appProf.Name == nil || appProf.UUID == nil || appProf.CloudConfigCksum == nil {
+			utils.AviLog.Warnf("Incomplete applicationprofile data unmarshalled, %s", utils.Stringify(appProf))
+			continue
+		}
+
+		appProfCacheObj := AviAppProfileCache{
+			Name:             *appProf.Name,
+			Uuid:             *appProf.UUID,
+			Tenant:           lib.GetTenant(),
+			CloudConfigCksum: *appProf.CloudConfigCksum,
+		}
+		*appProfData = append(*appProfData, appProfCacheObj)
+	}
+
+	return appProfData, count, nil
+}
+
+func (c *AviObjCache) AviPopulateAllPools(clientQueue AviClientQueue, cloud string, poolData *[]AviPoolCache) (*[]AviPoolCache, int, error) {
+	akoUser := lib.AKOUser
+	uri := "/api/pool/?" + "&include_name=true&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
+
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
+		utils.AviLog.Warnf("Get uri %v returned err for pool %v", uri, err)
 		return nil, 0, err
 	}
 	for i := 0; i < len(elems); i++ {
@@ -546,25 +623,15 @@ func (c *AviObjCache) AviPopulateAllPools(client *clients.AviClient, cloud strin
 		}
 		*poolData = append(*poolData, poolCacheObj)
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/pool")
-		if len(next_uri) > 1 {
-			override_uri := "/api/pool" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllPools(client, cloud, poolData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
 
-	return poolData, result.Count, nil
+	return poolData, count, nil
 }
 
-func (c *AviObjCache) PopulatePkiProfilesToCache(client *clients.AviClient, override_uri ...NextPage) {
+func (c *AviObjCache) PopulatePkiProfilesToCache(clientQueue AviClientQueue) error {
 	var pkiProfData []AviPkiProfileCache
-	c.AviPopulateAllPkiPRofiles(client, &pkiProfData)
+	if _, _, err := c.AviPopulateAllPkiPRofiles(clientQueue, &pkiProfData); err != nil {
+		return err
+	}
 
 	pkiCacheData := c.PKIProfileCache.ShallowCopy()
 	for i, pkiCacheObj := range pkiProfData {
@@ -590,11 +657,35 @@ func (c *AviObjCache) PopulatePkiProfilesToCache(client *clients.AviClient, over
 		utils.AviLog.Infof("Deleting key from pki cache :%s", key)
 		c.PKIProfileCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) PopulatePoolsToCache(client *clients.AviClient, cloud string, override_uri ...NextPage) {
+func (c *AviObjCache) PopulateAppProfilesToCache(clientQueue AviClientQueue) error {
+	var appProfData []AviAppProfileCache
+	if _, _, err := c.AviPopulateAllAppProfiles(clientQueue, &appProfData); err != nil {
+		return err
+	}
+
+	appProfCacheData := c.AppProfileCache.ShallowCopy()
+	for i, appProfCacheObj := range appProfData {
+		k := NamespaceName{Namespace: lib.GetTenant(), Name: appProfCacheObj.Name}
+		utils.AviLog.Infof("Adding key to applicationprofile cache :%s value :%s", k, appProfCacheObj.Uuid)
+		c.AppProfileCache.AviCacheAdd(k, &appProfData[i])
+		delete(appProfCacheData, k)
+	}
+	// The data that is left in appProfCacheData should be explicitly removed
+	for key := range appProfCacheData {
+		utils.AviLog.Infof("Deleting key from applicationprofile cache :%s", key)
+		c.AppProfileCache.AviCacheDelete(key)
+	}
+	return nil
+}
+
+func (c *AviObjCache) PopulatePoolsToCache(clientQueue AviClientQueue, cloud string) error {
 	var poolsData []AviPoolCache
-	c.AviPopulateAllPools(client, cloud, &poolsData)
+	if _, _, err := c.AviPopulateAllPools(clientQueue, cloud, &poolsData); err != nil {
+		return err
+	}
 
 	poolCacheData := c.PoolCache.ShallowCopy()
 	for i, poolCacheObj := range poolsData {
@@ -620,28 +711,17 @@ func (c *AviObjCache) PopulatePoolsToCache(client *clients.AviClient, cloud stri
 		utils.AviLog.Debugf("Deleting key from pool cache :%s", key)
 		c.PoolCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllVSVips(client *clients.AviClient, cloud string, vsVipData *[]AviVSVIPCache, nextPage ...NextPage) (*[]AviVSVIPCache, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllVSVips(clientQueue AviClientQueue, cloud string, vsVipData *[]AviVSVIPCache) (*[]AviVSVIPCache, error) {
+	uri := "/api/vsvip/?" + "name.contains=" + lib.GetNamePrefix() + "&include_name=true" + "&cloud_ref.name=" + cloud
 
-	if len(nextPage) == 1 {
-		uri = nextPage[0].Next_uri
-	} else {
-		uri = "/api/vsvip/?" + "name.contains=" + lib.GetNamePrefix() + "&include_name=true" + "&cloud_ref.name=" + cloud
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, _, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for vsvip %v", uri, err)
 		return nil, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal vsvip data, err: %v", err)
-		return nil, err
-	}
 	for i := 0; i < len(elems); i++ {
 		vsvip := models.VsVip{}
 		err = json.Unmarshal(elems[i], &vsvip)
@@ -673,24 +753,14 @@ func (c *AviObjCache) AviPopulateAllVSVips(client *clients.AviClient, cloud stri
 		}
 		*vsVipData = append(*vsVipData, vsVipCacheObj)
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/vsvip")
-		if len(next_uri) > 1 {
-			override_uri := "/api/vsvip" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, err := c.AviPopulateAllVSVips(client, cloud, vsVipData, nextPage)
-			if err != nil {
-				return nil, err
-			}
-		}
-	}
 	return vsVipData, nil
 }
 
-func (c *AviObjCache) PopulateVsVipDataToCache(client *clients.AviClient, cloud string) {
+func (c *AviObjCache) PopulateVsVipDataToCache(clientQueue AviClientQueue, cloud string) error {
 	var vsVipData []AviVSVIPCache
-	c.AviPopulateAllVSVips(client, cloud, &vsVipData)
+	if _, err := c.AviPopulateAllVSVips(clientQueue, cloud, &vsVipData); err != nil {
+		return err
+	}
 
 	vsVipCacheData := c.VSVIPCache.ShallowCopy()
 	for i, vsVipCacheObj := range vsVipData {
@@ -716,29 +786,18 @@ func (c *AviObjCache) PopulateVsVipDataToCache(client *clients.AviClient, cloud
 		utils.AviLog.Debugf("Deleting key from vsvip cache :%s", key)
 		c.VSVIPCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllDSs(client *clients.AviClient, cloud string, DsData *[]AviDSCache, nextPage ...NextPage) (*[]AviDSCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllDSs(clientQueue AviClientQueue, cloud string, DsData *[]AviDSCache) (*[]AviDSCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/vsdatascriptset/?" + "&include_name=true&created_by=" + akoUser
 
-	if len(nextPage) == 1 {
-		uri = nextPage[0].Next_uri
-	} else {
-		uri = "/api/vsdatascriptset/?" + "&include_name=true&created_by=" + akoUser
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for datascript %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal datascript data, err: %v", err)
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		ds := models.VSDataScriptSet{}
 		err = json.Unmarshal(elems[i], &ds)
@@ -767,27 +826,21 @@ func (c *AviObjCache) AviPopulateAllDSs(client *clients.AviClient, cloud string,
 			Uuid:       *ds.UUID,
 			PoolGroups: pgs,
 		}
-		dsCacheObj.CloudConfigCksum = lib.DSChecksum(dsCacheObj.PoolGroups)
-		*DsData = append(*DsData, dsCacheObj)
-	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/vsdatascriptset")
-		if len(next_uri) > 1 {
-			override_uri := "/api/vsdatascriptset" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllDSs(client, cloud, DsData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
+		var script string
+		if len(ds.Datascript) > 0 && ds.Datascript[0].Script != nil {
+			script = *ds.Datascript[0].Script
 		}
+		dsCacheObj.CloudConfigCksum = lib.DSChecksum(dsCacheObj.PoolGroups, script)
+		*DsData = append(*DsData, dsCacheObj)
 	}
-	return DsData, result.Count, nil
+	return DsData, count, nil
 }
 
-func (c *AviObjCache) PopulateDSDataToCache(client *clients.AviClient, cloud string, override_uri ...NextPage) {
+func (c *AviObjCache) PopulateDSDataToCache(clientQueue AviClientQueue, cloud string) error {
 	var DsData []AviDSCache
-	c.AviPopulateAllDSs(client, cloud, &DsData)
+	if _, _, err := c.AviPopulateAllDSs(clientQueue, cloud, &DsData); err != nil {
+		return err
+	}
 	dsCacheData := c.DSCache.ShallowCopy()
 	for i, DsCacheObj := range DsData {
 		k := NamespaceName{Namespace: lib.GetTenant(), Name: DsCacheObj.Name}
@@ -812,29 +865,18 @@ func (c *AviObjCache) PopulateDSDataToCache(client *clients.AviClient, cloud str
 		utils.AviLog.Debugf("Deleting key from ds cache :%s", key)
 		c.DSCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllSSLKeys(client *clients.AviClient, cloud string, SslData *[]AviSSLCache, nextPage ...NextPage) (*[]AviSSLCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllSSLKeys(clientQueue AviClientQueue, cloud string, SslData *[]AviSSLCache) (*[]AviSSLCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/sslkeyandcertificate/?" + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(nextPage) == 1 {
-		uri = nextPage[0].Next_uri
-	} else {
-		uri = "/api/sslkeyandcertificate/?" + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for sslkeyandcertificate %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal sslkeyandcertificate data, err: %v", err)
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		sslkey := models.SSLKeyAndCertificate{}
 		err = json.Unmarshal(elems[i], &sslkey)
@@ -872,27 +914,13 @@ func (c *AviObjCache) AviPopulateAllSSLKeys(client *clients.AviClient, cloud str
 		}
 		*SslData = append(*SslData, sslCacheObj)
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/sslkeyandcertificate")
-		if len(next_uri) > 1 {
-			override_uri := "/api/sslkeyandcertificate" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllSSLKeys(client, cloud, SslData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
-	return SslData, result.Count, nil
+	return SslData, count, nil
 }
 
 func (c *AviObjCache) AviPopulateOneSSLCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/sslkeyandcertificate?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/sslkeyandcertificate?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -939,7 +967,7 @@ func (c *AviObjCache) AviPopulateOneSSLCache(client *clients.AviClient,
 			CloudConfigCksum: checksum,
 			HasCARef:         hasCA,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *sslkey.Name}
+		k := NamespaceName{Namespace: tenant, Name: *sslkey.Name}
 		c.SSLKeyCache.AviCacheAdd(k, &sslCacheObj)
 		utils.AviLog.Debugf("Adding sslkey to Cache during refresh %s\n", k)
 	}
@@ -947,11 +975,9 @@ func (c *AviObjCache) AviPopulateOneSSLCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOnePKICache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/pkiprofile?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/pkiprofile?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -985,19 +1011,57 @@ func (c *AviObjCache) AviPopulateOnePKICache(client *clients.AviClient,
 			Uuid:             *pkikey.UUID,
 			CloudConfigCksum: checksum,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *pkikey.Name}
+		k := NamespaceName{Namespace: tenant, Name: *pkikey.Name}
 		c.SSLKeyCache.AviCacheAdd(k, &sslCacheObj)
 		utils.AviLog.Debugf("Adding pkikey to Cache during refresh %s\n", k)
 	}
 	return nil
 }
 
-func (c *AviObjCache) AviPopulateOnePoolCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+func (c *AviObjCache) AviPopulateOneAppProfileCache(client *clients.AviClient,
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
+	uri := "/api/applicationprofile?name=" + objName + "&created_by=" + akoUser
 
-	uri = "/api/pool?name=" + objName + "&created_by=" + akoUser
+	result, err := AviGetCollectionRaw(client, uri)
+	if err != nil {
+		utils.AviLog.Warnf("Get uri %v returned err for applicationprofile %v", uri, err)
+		return err
+	}
+	elems := make([]json.RawMessage, result.Count)
+	err = json.Unmarshal(result.Results, &elems)
+	if err != nil {
+		utils.AviLog.Warnf("Failed to unmarshal applicationprofile data, err: %v", err)
+		return err
+	}
+	for i := 0; i < len(elems); i++ {
+		appProf := models.ApplicationProfile{}
+		err = json.Unmarshal(elems[i], &appProf)
+		if err != nil {
+			utils.AviLog.Warnf("Failed to unmarshal applicationprofile data, err: %v", err)
+			continue
+		}
+		if appProf.Name == nil || appProf.UUID == nil || appProf.CloudConfigCksum == nil {
+			utils.AviLog.Warnf("Incomplete applicationprofile data unmarshalled, %s", utils.Stringify(appProf))
+			continue
+		}
+		appProfCacheObj := AviAppProfileCache{
+			Name:             *appProf.Name,
+			Uuid:             *appProf.UUID,
+			Tenant:           tenant,
+			CloudConfigCksum: *appProf.CloudConfigCksum,
+		}
+		k := NamespaceName{Namespace: tenant, Name: *appProf.Name}
+		c.AppProfileCache.AviCacheAdd(k, &appProfCacheObj)
+		utils.AviLog.Debugf("Adding applicationprofile to Cache during refresh %s\n", k)
+	}
+	return nil
+}
+
+func (c *AviObjCache) AviPopulateOnePoolCache(client *clients.AviClient,
+	cloud string, objName string, tenant string) error {
+	akoUser := lib.AKOUser
+	uri := "/api/pool?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1038,7 +1102,7 @@ func (c *AviObjCache) AviPopulateOnePoolCache(client *clients.AviClient,
 			pkiUuid := ExtractUuid(*pool.PkiProfileRef, "pkiprofile-.*.#")
 			pkiName, foundPki := c.PKIProfileCache.AviCacheGetNameByUuid(pkiUuid)
 			if foundPki {
-				pkiKey = NamespaceName{Namespace: lib.GetTenant(), Name: pkiName.(string)}
+				pkiKey = NamespaceName{Namespace: tenant, Name: pkiName.(string)}
 			}
 		}
 
@@ -1050,7 +1114,7 @@ func (c *AviObjCache) AviPopulateOnePoolCache(client *clients.AviClient,
 			ServiceMetadataObj:   svc_mdata_obj,
 			LastModified:         *pool.LastModified,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *pool.Name}
+		k := NamespaceName{Namespace: tenant, Name: *pool.Name}
 		c.PoolCache.AviCacheAdd(k, &poolCacheObj)
 		utils.AviLog.Debugf("Adding pool to Cache during refresh %s\n", k)
 	}
@@ -1058,11 +1122,9 @@ func (c *AviObjCache) AviPopulateOnePoolCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOneVsDSCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/vsdatascript?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/vsdatascript?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1106,8 +1168,12 @@ func (c *AviObjCache) AviPopulateOneVsDSCache(client *clients.AviClient,
 			Uuid:       *ds.UUID,
 			PoolGroups: pgs,
 		}
-		dsCacheObj.CloudConfigCksum = lib.DSChecksum(dsCacheObj.PoolGroups)
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *ds.Name}
+		var script string
+		if len(ds.Datascript) > 0 && ds.Datascript[0].Script != nil {
+			script = *ds.Datascript[0].Script
+		}
+		dsCacheObj.CloudConfigCksum = lib.DSChecksum(dsCacheObj.PoolGroups, script)
+		k := NamespaceName{Namespace: tenant, Name: *ds.Name}
 		c.DSCache.AviCacheAdd(k, &dsCacheObj)
 		utils.AviLog.Debugf("Adding ds to Cache during refresh %s\n", k)
 	}
@@ -1115,11 +1181,9 @@ func (c *AviObjCache) AviPopulateOneVsDSCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOnePGCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/poolgroup?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/poolgroup?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1166,7 +1230,7 @@ func (c *AviObjCache) AviPopulateOnePGCache(client *clients.AviClient,
 			LastModified:     *pg.LastModified,
 			Members:          pools,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *pg.Name}
+		k := NamespaceName{Namespace: tenant, Name: *pg.Name}
 		c.PgCache.AviCacheAdd(k, &pgCacheObj)
 		utils.AviLog.Debugf("Adding pg to Cache during refresh %s\n", k)
 	}
@@ -1174,10 +1238,8 @@ func (c *AviObjCache) AviPopulateOnePGCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOneVsVipCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
-
-	uri = "/api/vsvip?name=" + objName + "&cloud_ref.name=" + cloud
+	cloud string, objName string, tenant string) error {
+	uri := "/api/vsvip?name=" + objName + "&cloud_ref.name=" + cloud
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1222,7 +1284,7 @@ func (c *AviObjCache) AviPopulateOneVsVipCache(client *clients.AviClient,
 			LastModified: *vsvip.LastModified,
 			Vips:         vips,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *vsvip.Name}
+		k := NamespaceName{Namespace: tenant, Name: *vsvip.Name}
 		c.VSVIPCache.AviCacheAdd(k, &vsVipCacheObj)
 		utils.AviLog.Debugf("Adding vsvip to Cache during refresh %s\n", k)
 	}
@@ -1230,11 +1292,9 @@ func (c *AviObjCache) AviPopulateOneVsVipCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOneVsHttpPolCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/httppolicyset?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/httppolicyset?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1282,7 +1342,7 @@ func (c *AviObjCache) AviPopulateOneVsHttpPolCache(client *clients.AviClient,
 			PoolGroups:       poolGroups,
 			LastModified:     *httppol.LastModified,
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *httppol.Name}
+		k := NamespaceName{Namespace: tenant, Name: *httppol.Name}
 		c.HTTPPolicyCache.AviCacheAdd(k, &httpPolCacheObj)
 		utils.AviLog.Debugf("Adding httppolicy to Cache during refresh %s\n", k)
 	}
@@ -1290,11 +1350,9 @@ func (c *AviObjCache) AviPopulateOneVsHttpPolCache(client *clients.AviClient,
 }
 
 func (c *AviObjCache) AviPopulateOneVsL4PolCache(client *clients.AviClient,
-	cloud string, objName string) error {
-	var uri string
+	cloud string, objName string, tenant string) error {
 	akoUser := lib.AKOUser
-
-	uri = "/api/l4policyset?name=" + objName + "&created_by=" + akoUser
+	uri := "/api/l4policyset?name=" + objName + "&created_by=" + akoUser
 
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
@@ -1348,7 +1406,7 @@ func (c *AviObjCache) AviPopulateOneVsL4PolCache(client *clients.AviClient,
 			LastModified:     *l4pol.LastModified,
 			CloudConfigCksum: lib.L4PolicyChecksum(ports, protocol),
 		}
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: *l4pol.Name}
+		k := NamespaceName{Namespace: tenant, Name: *l4pol.Name}
 		c.L4PolicyCache.AviCacheAdd(k, &l4PolCacheObj)
 		utils.AviLog.Infof("Adding l4pol to Cache during refresh %s\n", lib.L4PolicyChecksum(ports, protocol))
 	}
@@ -1444,7 +1502,7 @@ func (c *AviObjCache) AviPopulateAllVSMeta(client *clients.AviClient, cloud stri
 						// For each PG, formulate the key and then populate the pg collection cache
 						pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName}
 						poolgroupKeys = append(poolgroupKeys, pgKey)
-						poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+						poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, lib.GetTenant())
 					}
 					dsKeys = append(dsKeys, dsKey)
 				}
@@ -1460,7 +1518,7 @@ func (c *AviObjCache) AviPopulateAllVSMeta(client *clients.AviClient, cloud stri
 				if foundpg {
 					pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName.(string)}
 					poolgroupKeys = append(poolgroupKeys, pgKey)
-					poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string))
+					poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string), lib.GetTenant())
 				}
 
 			}
@@ -1478,7 +1536,7 @@ func (c *AviObjCache) AviPopulateAllVSMeta(client *clients.AviClient, cloud stri
 						// For each PG, formulate the key and then populate the pg collection cache
 						pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName}
 						poolgroupKeys = append(poolgroupKeys, pgKey)
-						poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+						poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, lib.GetTenant())
 					}
 					httpKeys = append(httpKeys, httpKey)
 				}
@@ -1523,9 +1581,11 @@ func (c *AviObjCache) PopulateVsKeyToCache(client *clients.AviClient, cloud stri
 	}
 }
 
-func (c *AviObjCache) PopulateSSLKeyToCache(client *clients.AviClient, cloud string, override_uri ...NextPage) {
+func (c *AviObjCache) PopulateSSLKeyToCache(clientQueue AviClientQueue, cloud string) error {
 	var SslKeyData []AviSSLCache
-	c.AviPopulateAllSSLKeys(client, cloud, &SslKeyData)
+	if _, _, err := c.AviPopulateAllSSLKeys(clientQueue, cloud, &SslKeyData); err != nil {
+		return err
+	}
 	sslCacheData := c.SSLKeyCache.ShallowCopy()
 	for i, SslKeyCacheObj := range SslKeyData {
 		k := NamespaceName{Namespace: lib.GetTenant(), Name: SslKeyCacheObj.Name}
@@ -1561,29 +1621,18 @@ func (c *AviObjCache) PopulateSSLKeyToCache(client *clients.AviClient, cloud str
 		utils.AviLog.Debugf("Deleting key from sslkey cache :%s", key)
 		c.SSLKeyCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllHttpPolicySets(client *clients.AviClient, cloud string, httpPolicyData *[]AviHTTPPolicyCache, nextPage ...NextPage) (*[]AviHTTPPolicyCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllHttpPolicySets(clientQueue AviClientQueue, cloud string, httpPolicyData *[]AviHTTPPolicyCache) (*[]AviHTTPPolicyCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/httppolicyset/?" + "&include_name=true" + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(nextPage) == 1 {
-		uri = nextPage[0].Next_uri
-	} else {
-		uri = "/api/httppolicyset/?" + "&include_name=true" + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for httppolicyset %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal httppolicyset data, err: %v", err)
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		httppol := models.HTTPPolicySet{}
 		err = json.Unmarshal(elems[i], &httppol)
@@ -1619,26 +1668,17 @@ func (c *AviObjCache) AviPopulateAllHttpPolicySets(client *clients.AviClient, cl
 		*httpPolicyData = append(*httpPolicyData, httpPolCacheObj)
 
 	}
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/httppolicyset")
-		if len(next_uri) > 1 {
-			override_uri := "/api/httppolicyset" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllHttpPolicySets(client, cloud, httpPolicyData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
-	return httpPolicyData, result.Count, nil
+	return httpPolicyData, count, nil
 }
 
-func (c *AviObjCache) PopulateHttpPolicySetToCache(client *clients.AviClient, cloud string, override_uri ...NextPage) {
+func (c *AviObjCache) PopulateHttpPolicySetToCache(clientQueue AviClientQueue, cloud string) error {
 	var HttPolData []AviHTTPPolicyCache
-	_, count, err := c.AviPopulateAllHttpPolicySets(client, cloud, &HttPolData)
-	if err != nil || len(HttPolData) != count {
-		return
+	_, count, err := c.AviPopulateAllHttpPolicySets(clientQueue, cloud, &HttPolData)
+	if err != nil {
+		return err
+	}
+	if len(HttPolData) != count {
+		return fmt.Errorf("found %d of the %d objects on the controller", len(HttPolData), count)
 	}
 	httpCacheData := c.HTTPPolicyCache.ShallowCopy()
 	for i, HttpPolCacheObj := range HttPolData {
@@ -1664,29 +1704,18 @@ func (c *AviObjCache) PopulateHttpPolicySetToCache(client *clients.AviClient, cl
 		utils.AviLog.Debugf("Deleting key from httppol cache :%s", key)
 		c.HTTPPolicyCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
-func (c *AviObjCache) AviPopulateAllL4PolicySets(client *clients.AviClient, cloud string, l4PolicyData *[]AviL4PolicyCache, nextPage ...NextPage) (*[]AviL4PolicyCache, int, error) {
-	var uri string
+func (c *AviObjCache) AviPopulateAllL4PolicySets(clientQueue AviClientQueue, cloud string, l4PolicyData *[]AviL4PolicyCache) (*[]AviL4PolicyCache, int, error) {
 	akoUser := lib.AKOUser
+	uri := "/api/l4policyset/?" + "&include_name=true" + "&created_by=" + akoUser + "&page_size=100"
 
-	if len(nextPage) == 1 {
-		uri = nextPage[0].Next_uri
-	} else {
-		uri = "/api/l4policyset/?" + "&include_name=true" + "&created_by=" + akoUser + "&page_size=100"
-	}
-
-	result, err := AviGetCollectionRaw(client, uri)
+	elems, count, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Get uri %v returned err for httppolicyset %v", uri, err)
 		return nil, 0, err
 	}
-	elems := make([]json.RawMessage, result.Count)
-	err = json.Unmarshal(result.Results, &elems)
-	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal httppolicyset data, err: %v", err)
-		return nil, 0, err
-	}
 	for i := 0; i < len(elems); i++ {
 		l4pol := models.L4PolicySet{}
 		err = json.Unmarshal(elems[i], &l4pol)
@@ -1734,27 +1763,17 @@ func (c *AviObjCache) AviPopulateAllL4PolicySets(client *clients.AviClient, clou
 
 		*l4PolicyData = append(*l4PolicyData, l4PolCacheObj)
 	}
-
-	if result.Next != "" {
-		// It has a next page, let's recursively call the same method.
-		next_uri := strings.Split(result.Next, "/api/l4policyset")
-		if len(next_uri) > 1 {
-			override_uri := "/api/l4policyset" + next_uri[1]
-			nextPage := NextPage{Next_uri: override_uri}
-			_, _, err := c.AviPopulateAllL4PolicySets(client, cloud, l4PolicyData, nextPage)
-			if err != nil {
-				return nil, 0, err
-			}
-		}
-	}
-	return l4PolicyData, result.Count, nil
+	return l4PolicyData, count, nil
 }
 
-func (c *AviObjCache) PopulateL4PolicySetToCache(client *clients.AviClient, cloud string, override_uri ...NextPage) {
+func (c *AviObjCache) PopulateL4PolicySetToCache(clientQueue AviClientQueue, cloud string) error {
 	var l4PolData []AviL4PolicyCache
-	_, count, err := c.AviPopulateAllL4PolicySets(client, cloud, &l4PolData)
-	if err != nil || len(l4PolData) != count {
-		return
+	_, count, err := c.AviPopulateAllL4PolicySets(clientQueue, cloud, &l4PolData)
+	if err != nil {
+		return err
+	}
+	if len(l4PolData) != count {
+		return fmt.Errorf("found %d of the %d objects on the controller", len(l4PolData), count)
 	}
 	l4CacheData := c.L4PolicyCache.ShallowCopy()
 	for i, l4PolCacheObj := range l4PolData {
@@ -1768,6 +1787,7 @@ func (c *AviObjCache) PopulateL4PolicySetToCache(client *clients.AviClient, clou
 		utils.AviLog.Debugf("Deleting key from l4policy cache :%s", key)
 		c.L4PolicyCache.AviCacheDelete(key)
 	}
+	return nil
 }
 
 func (c *AviObjCache) AviObjVrfCachePopulate(client *clients.AviClient, cloud string) error {
@@ -1819,40 +1839,28 @@ func (c *AviObjCache) AviObjVrfCachePopulate(client *clients.AviClient, cloud st
 	return nil
 }
 
-func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud string, vsCacheCopy *[]NamespaceName, override_uri ...NextPage) error {
-	var rest_response interface{}
+// AviObjVSCachePopulate populates the vs cache. The pages of virtualservices are fetched in parallel using all
+// the clients of the queue, they are added to the cache in order so that the parents go ahead of their SNI children.
+func (c *AviObjCache) AviObjVSCachePopulate(clientQueue AviClientQueue, cloud string, vsCacheCopy *[]NamespaceName) error {
 	akoUser := lib.AKOUser
-	var uri string
-	httpCacheRefreshCount := 1 // Refresh count for http cache is attempted once per page
-	if len(override_uri) == 1 {
-		uri = override_uri[0].Next_uri
-	} else {
-		uri = "/api/virtualservice/?" + "include_name=true" + "&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
-	}
+	uri := "/api/virtualservice/?" + "include_name=true" + "&cloud_ref.name=" + cloud + "&created_by=" + akoUser + "&page_size=100"
 
-	err := AviGet(client, uri, &rest_response)
+	elems, _, err := clientQueue.GetCollectionPages(uri)
 	if err != nil {
 		utils.AviLog.Warnf("Vs Get uri %v returned err %v", uri, err)
 		return err
-	} else {
-		resp, ok := rest_response.(map[string]interface{})
-		if !ok {
-			utils.AviLog.Warnf("Vs Get uri %v returned %v type %T", uri,
-				rest_response, rest_response)
-			return errors.New("VS type is wrong")
-		}
-		utils.AviLog.Debugf("Vs Get uri %v returned %v vses", uri,
-			resp["count"])
-		results, ok := resp["results"].([]interface{})
-		if !ok {
-			utils.AviLog.Warnf("results not of type []interface{} Instead of type %T", resp["results"])
-			return errors.New("Results are not of right type for VS")
-		}
-		for _, vs_intf := range results {
-
-			vs, ok := vs_intf.(map[string]interface{})
-			if !ok {
-				utils.AviLog.Warnf("vs_intf not of type map[string] interface{}. Instead of type %T", vs_intf)
+	}
+	utils.AviLog.Debugf("Vs Get uri %v returned %v vses", uri, len(elems))
+	var httpCacheRefreshCount int
+	return clientQueue.WithClient(func(client *clients.AviClient) error {
+		for i, elem := range elems {
+			if i%100 == 0 {
+				// Refresh count for http cache is attempted once per page
+				httpCacheRefreshCount = 1
+			}
+			var vs map[string]interface{}
+			if err := json.Unmarshal(elem, &vs); err != nil {
+				utils.AviLog.Warnf("Unable to parse the vs: %v", err)
 				continue
 			}
 			svc_mdata_intf, ok := vs["service_metadata"]
@@ -1954,7 +1962,7 @@ func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud str
 									// For each PG, formulate the key and then populate the pg collection cache
 									pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName}
 									poolgroupKeys = append(poolgroupKeys, pgKey)
-									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, lib.GetTenant())
 								}
 								dsKeys = append(dsKeys, dsKey)
 								sharedVsOrL4 = true
@@ -1974,7 +1982,7 @@ func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud str
 							if foundpg {
 								pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName.(string)}
 								poolgroupKeys = append(poolgroupKeys, pgKey)
-								poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string))
+								poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string), lib.GetTenant())
 								sharedVsOrL4 = true
 							}
 						}
@@ -2009,7 +2017,7 @@ func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud str
 							if !foundhttp && !sharedVsOrL4 && httpCacheRefreshCount > 0 {
 								// We do a full refresh of the httpcache once per page, if we detect a data discrepancy
 								httpCacheRefreshCount = httpCacheRefreshCount - 1
-								c.PopulateHttpPolicySetToCache(client, cloud)
+								c.PopulateHttpPolicySetToCache(NewAviClientQueue(client), cloud)
 								httpName, foundhttp = c.HTTPPolicyCache.AviCacheGetNameByUuid(httpUuid)
 								if !foundhttp {
 									// If still the httpName is not found. Log an error saying, this VS may not behave appropriately.
@@ -2023,7 +2031,7 @@ func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud str
 									// For each PG, formulate the key and then populate the pg collection cache
 									pgKey := NamespaceName{Namespace: lib.GetTenant(), Name: pgName}
 									poolgroupKeys = append(poolgroupKeys, pgKey)
-									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, lib.GetTenant())
 								}
 								httpKeys = append(httpKeys, httpKey)
 							}
@@ -2053,28 +2061,15 @@ func (c *AviObjCache) AviObjVSCachePopulate(client *clients.AviClient, cloud str
 
 			}
 		}
-		if resp["next"] != nil {
-			// It has a next page, let's recursively call the same method.
-			next_uri := strings.Split(resp["next"].(string), "/api/virtualservice")
-			utils.AviLog.Debugf("Found next page for vs, uri: %s", next_uri)
-			if len(next_uri) > 1 {
-				override_uri := "/api/virtualservice" + next_uri[1]
-				utils.AviLog.Debugf("Next page uri for vs: %s", override_uri)
-				nextPage := NextPage{Next_uri: override_uri}
-				c.AviObjVSCachePopulate(client, cloud, vsCacheCopy, nextPage)
-			}
-		}
-	}
-	return nil
+		return nil
+	})
 }
 
-func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud string, vsName string) error {
-	// This method should be called only from layer-3 during a retry.
+func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud string, vsName string, tenant string) error {
+	// This method should be called only from layer-3 during a retry, with the client set to the tenant of the VS.
 	var rest_response interface{}
 	akoUser := lib.AKOUser
-	var uri string
-
-	uri = "/api/virtualservice?name=" + vsName + "&cloud_ref.name=" + cloud + "&created_by=" + akoUser
+	uri := "/api/virtualservice?name=" + vsName + "&cloud_ref.name=" + cloud + "&created_by=" + akoUser
 
 	utils.AviLog.Debugf("Refreshing cache for vs uri: %s", uri)
 	err := AviGet(client, uri, &rest_response)
@@ -2090,7 +2085,7 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 		}
 		utils.AviLog.Debugf("Vs Get uri %v returned %v vses", uri,
 			resp["count"])
-		k := NamespaceName{Namespace: utils.ADMIN_NS, Name: vsName}
+		k := NamespaceName{Namespace: tenant, Name: vsName}
 		objCount, _ := resp["count"]
 		if objCount == 0.0 {
 			utils.AviLog.Debugf("Empty response removing VS meta :%s", k)
@@ -2152,7 +2147,7 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 					if foundVip {
 						vsVipData, ok := vsVip.(*AviVSVIPCache)
 						if ok {
-							vipKey := NamespaceName{Namespace: lib.GetTenant(), Name: vsVipData.Name}
+							vipKey := NamespaceName{Namespace: tenant, Name: vsVipData.Name}
 							vsVipKey = append(vsVipKey, vipKey)
 							if len(vsVipData.Vips) > 0 {
 								vip = vsVipData.Vips[0]
@@ -2166,7 +2161,7 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 						sslUuid := ExtractUuidWithoutHash(ssl.(string), "sslkeyandcertificate-.*.")
 						sslName, foundssl := c.SSLKeyCache.AviCacheGetNameByUuid(sslUuid)
 						if foundssl {
-							sslKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: sslName.(string)}
+							sslKey := NamespaceName{Namespace: tenant, Name: sslName.(string)}
 							sslKeys = append(sslKeys, sslKey)
 
 							sslIntf, _ := c.SSLKeyCache.AviCacheGet(sslKey)
@@ -2175,7 +2170,7 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 							if sslData.CACertUUID != "" {
 								caName, found := c.SSLKeyCache.AviCacheGetNameByUuid(sslData.CACertUUID)
 								if found {
-									caCertKey := NamespaceName{Namespace: lib.GetTenant(), Name: caName.(string)}
+									caCertKey := NamespaceName{Namespace: tenant, Name: caName.(string)}
 									sslKeys = append(sslKeys, caCertKey)
 								}
 							}
@@ -2191,14 +2186,14 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 
 							dsName, foundDs := c.DSCache.AviCacheGetNameByUuid(dsUuid)
 							if foundDs {
-								dsKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: dsName.(string)}
+								dsKey := NamespaceName{Namespace: tenant, Name: dsName.(string)}
 								// Fetch the associated PGs with the DS.
 								dsObj, _ := c.DSCache.AviCacheGet(dsKey)
 								for _, pgName := range dsObj.(*AviDSCache).PoolGroups {
 									// For each PG, formulate the key and then populate the pg collection cache
-									pgKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: pgName}
+									pgKey := NamespaceName{Namespace: tenant, Name: pgName}
 									poolgroupKeys = append(poolgroupKeys, pgKey)
-									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, tenant)
 								}
 								dsKeys = append(dsKeys, dsKey)
 							}
@@ -2215,9 +2210,9 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 
 							pgName, foundpg := c.PgCache.AviCacheGetNameByUuid(pgUuid)
 							if foundpg {
-								pgKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: pgName.(string)}
+								pgKey := NamespaceName{Namespace: tenant, Name: pgName.(string)}
 								poolgroupKeys = append(poolgroupKeys, pgKey)
-								poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string))
+								poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName.(string), tenant)
 							}
 						}
 					}
@@ -2229,10 +2224,10 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 							l4PolUuid := ExtractUuid(l4map["l4_policy_set_ref"].(string), "l4policyset-.*.#")
 							l4Name, foundl4pol := c.L4PolicyCache.AviCacheGetNameByUuid(l4PolUuid)
 							if foundl4pol {
-								l4key := NamespaceName{Namespace: lib.GetTenant(), Name: l4Name.(string)}
+								l4key := NamespaceName{Namespace: tenant, Name: l4Name.(string)}
 								l4Obj, _ := c.L4PolicyCache.AviCacheGet(l4key)
 								for _, poolName := range l4Obj.(*AviL4PolicyCache).Pools {
-									poolKey := NamespaceName{Namespace: lib.GetTenant(), Name: poolName}
+									poolKey := NamespaceName{Namespace: tenant, Name: poolName}
 									poolKeys = append(poolKeys, poolKey)
 								}
 								l4Keys = append(l4Keys, l4key)
@@ -2249,13 +2244,13 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 
 							httpName, foundhttp := c.HTTPPolicyCache.AviCacheGetNameByUuid(httpUuid)
 							if foundhttp {
-								httpKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: httpName.(string)}
+								httpKey := NamespaceName{Namespace: tenant, Name: httpName.(string)}
 								httpObj, _ := c.HTTPPolicyCache.AviCacheGet(httpKey)
 								for _, pgName := range httpObj.(*AviHTTPPolicyCache).PoolGroups {
 									// For each PG, formulate the key and then populate the pg collection cache
-									pgKey := NamespaceName{Namespace: utils.ADMIN_NS, Name: pgName}
+									pgKey := NamespaceName{Namespace: tenant, Name: pgName}
 									poolgroupKeys = append(poolgroupKeys, pgKey)
-									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName)
+									poolKeys = c.AviPGPoolCachePopulate(client, cloud, pgName, tenant)
 								}
 								httpKeys = append(httpKeys, httpKey)
 							}
@@ -2279,6 +2274,9 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 					L4PolicyCollection:   l4Keys,
 					ServiceMetadataObj:   svc_mdata_obj,
 				}
+				if lastModified, ok := vs["_last_modified"].(string); ok {
+					vsMetaObj.LastModified = lastModified
+				}
 				c.VsCacheMeta.AviCacheAdd(k, &vsMetaObj)
 				vs_cache, found := c.VsCacheMeta.AviCacheGet(parentVSKey)
 				if found {
@@ -2297,26 +2295,26 @@ func (c *AviObjCache) AviObjOneVSCachePopulate(client *clients.AviClient, cloud
 	return nil
 }
 
-func (c *AviObjCache) AviPGPoolCachePopulate(client *clients.AviClient, cloud string, pgName string) []NamespaceName {
+func (c *AviObjCache) AviPGPoolCachePopulate(client *clients.AviClient, cloud string, pgName string, tenant string) []NamespaceName {
 	var poolKeyCollection []NamespaceName
 
-	k := NamespaceName{Namespace: lib.GetTenant(), Name: pgName}
+	k := NamespaceName{Namespace: tenant, Name: pgName}
 	// Find the pools associated with this PG and populate them
 	pgObj, ok := c.PgCache.AviCacheGet(k)
 	// Get the members from this and populate the VS ref
 	if ok {
 		for _, poolName := range pgObj.(*AviPGCache).Members {
-			k := NamespaceName{Namespace: lib.GetTenant(), Name: poolName}
+			k := NamespaceName{Namespace: tenant, Name: poolName}
 			poolKeyCollection = append(poolKeyCollection, k)
 		}
 	} else {
 		// PG not found in the cache. Let's try a refresh explicitly
-		c.AviPopulateOnePGCache(client, cloud, pgName)
+		c.AviPopulateOnePGCache(client, cloud, pgName, tenant)
 		pgObj, ok = c.PgCache.AviCacheGet(k)
 		if ok {
 			utils.AviLog.Debugf("Found PG on refresh: %s", pgName)
 			for _, poolName := range pgObj.(*AviPGCache).Members {
-				k := NamespaceName{Namespace: lib.GetTenant(), Name: poolName}
+				k := NamespaceName{Namespace: tenant, Name: poolName}
 				poolKeyCollection = append(poolKeyCollection, k)
 			}
 		} else {
@@ -2416,25 +2414,20 @@ func (c *AviObjCache) AviDNSPropertyPopulate(client *clients.AviClient, dnsUUID
 	return dnsSubDomains
 }
 
+// ValidateUserInput runs the pre-flight checks of the AKO configuration and returns false if any of the blocking
+// ones failed, in which case the sync is disabled. AKO is rebooted to retry if the cloud, the SE group or the
+// node network is not valid.
 func ValidateUserInput(client *clients.AviClient) bool {
-	// add other step0 validation logics here -> isValid := check1 && check2 && ...
-	isCloudValid := checkAndSetCloudType(client)
-	isRequiredValuesValid := checkRequiredValuesYaml()
-	if lib.GetAdvancedL4() && isCloudValid && isRequiredValuesValid {
-		return true
-	}
-
-	isSegroupValid := checkSegroupLabels(client)
-	isNodeNetworkValid := checkNodeNetwork(client)
-	isValid := isCloudValid &&
-		isSegroupValid &&
-		isNodeNetworkValid &&
-		checkPublicCloud(client) &&
-		isRequiredValuesValid &&
-		checkAndSetVRFFromNetwork(client)
+	checks, reboot := runPreflightChecks(client, false)
+	isValid := true
+	for _, check := range checks {
+		if check.Blocking && !check.Passed {
+			isValid = false
+		}
+	}
 
 	if !isValid {
-		if !isCloudValid || !isSegroupValid || !isNodeNetworkValid {
+		if reboot {
 			utils.AviLog.Warn("Invalid input detected, AKO will be rebooted to retry")
 			lib.ShutdownApi()
 		}
@@ -2443,25 +2436,24 @@ func ValidateUserInput(client *clients.AviClient) bool {
 	return isValid
 }
 
-func checkRequiredValuesYaml() bool {
+func checkRequiredValuesYaml(client *clients.AviClient, readOnly bool) error {
 	clusterName := lib.GetClusterName()
 	re := regexp.MustCompile("^[a-zA-Z0-9-_]*$")
 	if clusterName == "" {
-		utils.AviLog.Error("Required param clusterName not specified, syncing will be disabled")
-		return false
+		return errors.New("Required param clusterName not specified")
 	} else if len(clusterName) > 32 || !re.MatchString(clusterName) {
-		utils.AviLog.Error("clusterName must consist of alphanumeric characters or '-'/'_' (max 32 chars), syncing will be disabled")
-		return false
+		return errors.New("clusterName must consist of alphanumeric characters or '-'/'_' (max 32 chars)")
 	}
-	lib.SetNamePrefix()
+	if !readOnly {
+		lib.SetNamePrefix()
 
-	// after clusterName validation, set AKO User to be used in created_by fields for Avi Objects
-	lib.SetAKOUser()
+		// after clusterName validation, set AKO User to be used in created_by fields for Avi Objects
+		lib.SetAKOUser()
+	}
 
 	cloudName := os.Getenv("CLOUD_NAME")
 	if cloudName == "" {
-		utils.AviLog.Error("Required param cloudName not specified, syncing will be disabled")
-		return false
+		return errors.New("Required param cloudName not specified")
 	}
 
 	// check if config map exists
@@ -2472,153 +2464,153 @@ func checkRequiredValuesYaml() bool {
 	}
 	_, err := k8sClient.CoreV1().ConfigMaps(aviCMNamespace).Get(lib.AviConfigMap, metav1.GetOptions{})
 	if err != nil {
-		utils.AviLog.Errorf("Configmap %s/%s not found, error: %v, syncing will be disabled", lib.AviNS, lib.AviConfigMap, err)
-		return false
+		return fmt.Errorf("Configmap %s/%s not found, error: %v", lib.AviNS, lib.AviConfigMap, err)
 	}
 
-	return true
+	return nil
 }
 
-func checkSegroupLabels(client *clients.AviClient) bool {
+func checkSegroupLabels(client *clients.AviClient, readOnly bool) error {
 
 	// Not applicable for NodePort mode / disable route is set as True
 	if lib.IsNodePortMode() || os.Getenv(lib.DISABLE_STATIC_ROUTE_SYNC) == "true" {
 		utils.AviLog.Infof("Skipping the check for SE group labels ")
-		return true
+		return nil
 	}
 	// validate SE Group labels
 	segName := lib.GetSEGName()
 	if segName == "" {
-		utils.AviLog.Warnf("Service Engine Group: serviceEngineGroupName not set in values.yaml, skipping sync.")
-		return false
+		return errors.New("Service Engine Group: serviceEngineGroupName not set in values.yaml")
 	}
 	uri := "/api/serviceenginegroup/?include_name&name=" + segName + "&cloud_ref.name=" + utils.CloudName
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
-		utils.AviLog.Warnf("Get uri %v returned err %v", uri, err)
-		return false
+		return fmt.Errorf("Get uri %v returned err %v", uri, err)
 	}
 
 	if result.Count != 1 {
-		utils.AviLog.Warnf("Service Engine Group details not found with serviceEngineGroupName: %s", segName)
-		return false
+		return fmt.Errorf("Service Engine Group details not found with serviceEngineGroupName: %s", segName)
 	}
 
 	elems := make([]json.RawMessage, result.Count)
 	err = json.Unmarshal(result.Results, &elems)
 	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal data, err: %v", err)
-		return false
+		return fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
 
 	seg := models.ServiceEngineGroup{}
 	err = json.Unmarshal(elems[0], &seg)
 	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal data, err: %v", err)
-		return false
+		return fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
 
 	if seg.UUID == nil {
-		utils.AviLog.Warnf("Failed to get UUID for Service Engine Group: %s", segName)
-		return false
+		return fmt.Errorf("Failed to get UUID for Service Engine Group: %s", segName)
 	}
 
 	labels := seg.Labels
+	if len(labels) == 0 && readOnly {
+		return fmt.Errorf("Labels not set on SE group :%v. Expected Labels: %v", segName, utils.Stringify(lib.GetLabels()))
+	}
 	if len(labels) == 0 {
 		uri = "/api/serviceenginegroup/" + *seg.UUID
 		seg.Labels = lib.GetLabels()
 		response := models.ServiceEngineGroupAPIResponse{}
 		err = AviPut(client, uri, seg, response)
 		if err != nil {
-			utils.AviLog.Warnf("Setting labels on Service Engine Group :%v failed with error :%v. Expected Labels: %v", segName, err.Error(), utils.Stringify(lib.GetLabels()))
-			return false
+			return fmt.Errorf("Setting labels on Service Engine Group :%v failed with error :%v. Expected Labels: %v", segName, err.Error(), utils.Stringify(lib.GetLabels()))
 		}
 		utils.AviLog.Infof("labels: %v set on Service Engine Group :%v", utils.Stringify(lib.GetLabels()), segName)
-		return true
+		return nil
 
 	}
 
 	segLabelEq := reflect.DeepEqual(labels, lib.GetLabels())
 	if !segLabelEq {
-		utils.AviLog.Warnf("Labels does not match with cluster name for SE group :%v. Expected Labels: %v", segName, utils.Stringify(lib.GetLabels()))
-		return false
+		return fmt.Errorf("Labels does not match with cluster name for SE group :%v. Expected Labels: %v", segName, utils.Stringify(lib.GetLabels()))
 	}
 
-	return true
+	return nil
 }
 
-func checkAndSetCloudType(client *clients.AviClient) bool {
+func checkAndSetCloudType(client *clients.AviClient, readOnly bool) error {
+	cloud, err := getCloud(client)
+	if err != nil {
+		return err
+	}
+	vType := *cloud.Vtype
+
+	if readOnly {
+		// the cloud type AKO runs with only changes on a reboot
+		if vType != lib.GetCloudType() {
+			return fmt.Errorf("Cloud vType changed from %v to %v, AKO needs to be rebooted", lib.GetCloudType(), vType)
+		}
+	} else {
+		utils.AviLog.Infof("Setting cloud vType: %v", vType)
+		lib.SetCloudType(vType)
+	}
+
+	if lib.IsPublicCloud() && !lib.IsNodePortMode() {
+		return fmt.Errorf("%v not allowed in ClusterIP mode.", vType)
+	}
+
+	// IPAM is mandatory for vcenter and noaccess cloud
+	if !lib.IsPublicCloud() && cloud.IPAMProviderRef == nil {
+		return errors.New("Cloud does not have a ipam_provider_ref configured")
+	}
 
+	return nil
+}
+
+func getCloud(client *clients.AviClient) (*models.Cloud, error) {
 	uri := "/api/cloud/?include_name&name=" + utils.CloudName
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
-		utils.AviLog.Errorf("Get uri %v returned err %v", uri, err)
-		return false
+		return nil, fmt.Errorf("Get uri %v returned err %v", uri, err)
 	}
 
 	if result.Count != 1 {
-		utils.AviLog.Errorf("Cloud details not found for cloud name: %s", utils.CloudName)
-		return false
+		return nil, fmt.Errorf("Cloud details not found for cloud name: %s", utils.CloudName)
 	}
 
 	elems := make([]json.RawMessage, result.Count)
 	err = json.Unmarshal(result.Results, &elems)
 	if err != nil {
-		utils.AviLog.Errorf("Failed to unmarshal data, err: %v", err)
-		return false
+		return nil, fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
 
 	cloud := models.Cloud{}
 	err = json.Unmarshal(elems[0], &cloud)
 	if err != nil {
-		utils.AviLog.Errorf("Failed to unmarshal data, err: %v", err)
-		return false
+		return nil, fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
-	vType := *cloud.Vtype
-
-	utils.AviLog.Infof("Setting cloud vType: %v", vType)
-	lib.SetCloudType(vType)
-
-	if lib.IsPublicCloud() && !lib.IsNodePortMode() {
-		utils.AviLog.Errorf("%v not allowed in ClusterIP mode.", vType)
-		return false
-	}
-
-	// IPAM is mandatory for vcenter and noaccess cloud
-	if !lib.IsPublicCloud() && cloud.IPAMProviderRef == nil {
-		utils.AviLog.Errorf("Cloud does not have a ipam_provider_ref configured")
-		return false
-	}
-
-	return true
+	return &cloud, nil
 }
 
-func checkPublicCloud(client *clients.AviClient) bool {
+func checkPublicCloud(client *clients.AviClient, readOnly bool) error {
 	if lib.IsPublicCloud() {
 		// Handle all public cloud validations here
 		networkName := lib.GetNetworkName()
 		if networkName == "" {
-			utils.AviLog.Errorf("Required param networkName not specified, syncing will be disabled.")
-			return false
+			return errors.New("Required param networkName not specified")
 		}
 	}
 
-	return true
+	return nil
 }
 
-func checkNodeNetwork(client *clients.AviClient) bool {
+func checkNodeNetwork(client *clients.AviClient, readOnly bool) error {
 
 	// Not applicable for NodePort mode and non vcenter clouds
 	if lib.IsNodePortMode() || lib.GetCloudType() != lib.CLOUD_VCENTER {
 		utils.AviLog.Infof("Skipping the check for Node Network ")
-		return true
+		return nil
 	}
 
 	// check if node network and cidr's are valid
 	nodeNetworkMap, err := lib.GetNodeNetworkMap()
 	if err != nil {
-		utils.AviLog.Errorf("Fetching node network list failed with error: %s, syncing will be disabled.", err.Error())
-		return false
+		return fmt.Errorf("Fetching node network list failed with error: %s", err.Error())
 	}
 
 	for nodeNetworkName, nodeNetworkCIDRs := range nodeNetworkMap {
@@ -2626,87 +2618,91 @@ func checkNodeNetwork(client *clients.AviClient) bool {
 		uri := "/api/network/?include_name&name=" + nodeNetworkName + "&cloud_ref.name=" + utils.CloudName
 		result, err := AviGetCollectionRaw(client, uri)
 		if err != nil {
-			utils.AviLog.Errorf("Get uri %v returned err %v", uri, err)
-			return false
+			return fmt.Errorf("Get uri %v returned err %v", uri, err)
 		}
 		elems := make([]json.RawMessage, result.Count)
 		err = json.Unmarshal(result.Results, &elems)
 		if err != nil {
-			utils.AviLog.Warnf("Failed to unmarshal data, err: %s", err.Error())
-			return false
+			return fmt.Errorf("Failed to unmarshal data, err: %s", err.Error())
 		}
 
 		if result.Count == 0 {
-			utils.AviLog.Errorf("No networks found for networkName: %s", nodeNetworkName)
-			return false
+			return fmt.Errorf("No networks found for networkName: %s", nodeNetworkName)
 		}
 
 		for _, cidr := range nodeNetworkCIDRs {
 			_, _, err := net.ParseCIDR(cidr)
 			if err != nil {
-				utils.AviLog.Errorf("The value of CIDR couldn't be parsed. Failed with error: %v.", err.Error())
-				return false
+				return fmt.Errorf("The value of CIDR couldn't be parsed. Failed with error: %v.", err.Error())
 			}
 			mask := strings.Split(cidr, "/")[1]
 			_, err = strconv.ParseInt(mask, 10, 32)
 			if err != nil {
-				utils.AviLog.Errorf("The value of CIDR couldn't be converted to int32")
-				return false
+				return errors.New("The value of CIDR couldn't be converted to int32")
 			}
 		}
 	}
 
-	return true
+	return nil
 }
 
-func checkAndSetVRFFromNetwork(client *clients.AviClient) bool {
+func checkAndSetVRFFromNetwork(client *clients.AviClient, readOnly bool) error {
 
 	if lib.IsPublicCloud() {
 		// Need not set VRFContext for public clouds.
-		return true
+		return nil
+	}
+
+	network, err := getVipNetwork(client)
+	if err != nil {
+		return err
 	}
 
+	if lib.IsNodePortMode() {
+		utils.AviLog.Infof("Using global VRF for NodePort mode")
+		return nil
+	}
+
+	vrfRef := *network.VrfContextRef
+	vrfName := strings.Split(vrfRef, "#")[1]
+	if readOnly {
+		if vrfName != lib.GetVrf() {
+			return fmt.Errorf("VRF %s of network %s differs from the VRF %s AKO runs with", vrfName, lib.GetNetworkName(), lib.GetVrf())
+		}
+		return nil
+	}
+	utils.AviLog.Infof("Setting VRF %s found from network %s", vrfName, lib.GetNetworkName())
+	lib.SetVrf(vrfName)
+	return nil
+}
+
+func getVipNetwork(client *clients.AviClient) (*models.Network, error) {
 	networkName := lib.GetNetworkName()
 	if networkName == "" {
-		utils.AviLog.Error("Required param networkName not specified, syncing will be disabled.")
-		return false
+		return nil, errors.New("Required param networkName not specified")
 	}
 
 	uri := "/api/network/?include_name&name=" + networkName + "&cloud_ref.name=" + utils.CloudName
 	result, err := AviGetCollectionRaw(client, uri)
 	if err != nil {
-		utils.AviLog.Warnf("Get uri %v returned err %v", uri, err)
-		return false
+		return nil, fmt.Errorf("Get uri %v returned err %v", uri, err)
 	}
 	elems := make([]json.RawMessage, result.Count)
 	err = json.Unmarshal(result.Results, &elems)
 	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal data, err: %v", err)
-		return false
+		return nil, fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
 
 	if result.Count == 0 {
-		utils.AviLog.Warnf("No networks found for networkName: %s", networkName)
-		return false
+		return nil, fmt.Errorf("No networks found for networkName: %s", networkName)
 	}
 
 	network := models.Network{}
 	err = json.Unmarshal(elems[0], &network)
 	if err != nil {
-		utils.AviLog.Warnf("Failed to unmarshal data, err: %v", err)
-		return false
-	}
-
-	if lib.IsNodePortMode() {
-		utils.AviLog.Infof("Using global VRF for NodePort mode")
-		return true
+		return nil, fmt.Errorf("Failed to unmarshal data, err: %v", err)
 	}
-
-	vrfRef := *network.VrfContextRef
-	vrfName := strings.Split(vrfRef, "#")[1]
-	utils.AviLog.Infof("Setting VRF %s found from network %s", vrfName, networkName)
-	lib.SetVrf(vrfName)
-	return true
+	return &network, nil
 }
 
 func ExtractPattern(word string, pattern string) string {
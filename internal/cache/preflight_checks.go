@@ -0,0 +1,244 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/avinetworks/sdk/go/clients"
+	"github.com/avinetworks/sdk/go/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type preflightCheck struct {
+	name string
+	// a failed blocking check disables the sync
+	blocking bool
+	// a failed check with reboot set has AKO rebooted to retry
+	reboot bool
+	// advancedL4 is set for the checks which apply in advanced L4 mode as well
+	advancedL4 bool
+	// check is run with readOnly set on the re-runs, which must neither change the objects on the controller nor
+	// the settings AKO runs with
+	check func(client *clients.AviClient, readOnly bool) error
+}
+
+// preflightChecks are run in order, the first ones set the cloud type, the name prefix and the VRF the others
+// depend on. None of the others are run once the cloud check failed.
+var preflightChecks = []preflightCheck{
+	{name: "cloud", blocking: true, reboot: true, advancedL4: true, check: checkAndSetCloudType},
+	{name: "requiredValues", blocking: true, advancedL4: true, check: checkRequiredValuesYaml},
+	{name: "serviceEngineGroup", blocking: true, reboot: true, check: checkSegroupLabels},
+	{name: "nodeNetwork", blocking: true, reboot: true, check: checkNodeNetwork},
+	{name: "publicCloud", blocking: true, check: checkPublicCloud},
+	{name: "vrf", blocking: true, check: checkAndSetVRFFromNetwork},
+	{name: "ipamDnsProfiles", advancedL4: true, check: checkIPAMAndDNSProfiles},
+	{name: "vipNetwork", check: checkVipNetworkReachability},
+	{name: "controllerVersion", advancedL4: true, check: checkControllerVersion},
+	{name: "license", advancedL4: true, check: checkLicense},
+	{name: "tenant", advancedL4: true, check: checkTenantAccess},
+}
+
+// RunPreflightChecks runs the pre-flight checks again and refreshes the validation report, without enabling or
+// disabling the sync. The checks only read from the controller, the settings found at boot are kept.
+func RunPreflightChecks(client *clients.AviClient) []apimodels.ValidationCheck {
+	checks, _ := runPreflightChecks(client, true)
+	return checks
+}
+
+func runPreflightChecks(client *clients.AviClient, readOnly bool) ([]apimodels.ValidationCheck, bool) {
+	var checks []apimodels.ValidationCheck
+	reboot := false
+	for _, preflight := range preflightChecks {
+		if lib.GetAdvancedL4() && !preflight.advancedL4 {
+			continue
+		}
+		check := apimodels.ValidationCheck{Name: preflight.name, Blocking: preflight.blocking, Passed: true}
+		if err := preflight.check(client, readOnly); err != nil {
+			check.Passed = false
+			check.Message = err.Error()
+			if preflight.blocking {
+				utils.AviLog.Errorf("Pre-flight check %s failed: %v", preflight.name, err)
+			} else {
+				utils.AviLog.Warnf("Pre-flight check %s failed: %v", preflight.name, err)
+			}
+			reboot = reboot || preflight.reboot
+		}
+		checks = append(checks, check)
+		if preflight.name == "cloud" && !check.Passed {
+			// the other checks depend on the cloud type
+			break
+		}
+	}
+
+	if apimodels.ValidationStatus.UpdateValidationReport(checks) {
+		recordValidationEvents(checks)
+	}
+	return checks, reboot
+}
+
+// recordValidationEvents reports the failed checks on the AKO configmap, only called when the report changed so
+// that a configmap event re-running the checks does not repeat them.
+func recordValidationEvents(checks []apimodels.ValidationCheck) {
+	recorder := lib.GetEventRecorder()
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      "ConfigMap",
+		Namespace: lib.AviNS,
+		Name:      lib.AviConfigMap,
+	}
+	failed := 0
+	for _, check := range checks {
+		if check.Passed {
+			continue
+		}
+		failed++
+		recorder.Event(ref, corev1.EventTypeWarning, lib.ValidationFailed, fmt.Sprintf("Pre-flight check %s failed: %s", check.Name, check.Message))
+	}
+	if failed == 0 {
+		recorder.Event(ref, corev1.EventTypeNormal, lib.ValidationSucceeded, "All the pre-flight checks passed")
+	}
+}
+
+func checkIPAMAndDNSProfiles(client *clients.AviClient, readOnly bool) error {
+	cloud, err := getCloud(client)
+	if err != nil {
+		return err
+	}
+	for _, ref := range []*string{cloud.IPAMProviderRef, cloud.DNSProviderRef} {
+		if ref == nil {
+			continue
+		}
+		var profile models.IPAMDNSProviderProfile
+		uri := "/api/ipamdnsproviderprofile/" + ExtractPattern(*ref, "ipamdnsproviderprofile-[^#]*")
+		if err := AviGet(client, uri, &profile); err != nil {
+			return fmt.Errorf("Profile %s of cloud %s not found, err: %v", *ref, utils.CloudName, err)
+		}
+	}
+	if cloud.DNSProviderRef == nil {
+		return fmt.Errorf("Cloud %s does not have a dns_provider_ref configured, the FQDNs of the virtualservices are not registered", utils.CloudName)
+	}
+	return nil
+}
+
+// checkVipNetworkReachability checks that the VIPs can be allocated in the VIP network, either from one of its
+// subnets or by DHCP.
+func checkVipNetworkReachability(client *clients.AviClient, readOnly bool) error {
+	if lib.IsPublicCloud() {
+		return nil
+	}
+	network, err := getVipNetwork(client)
+	if err != nil {
+		return err
+	}
+	if network.DhcpEnabled != nil && *network.DhcpEnabled {
+		return nil
+	}
+	for _, subnet := range network.ConfiguredSubnets {
+		if subnet != nil && subnet.Prefix != nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("Network %s has neither a configured subnet nor DHCP enabled, VIPs cannot be allocated in it", lib.GetNetworkName())
+}
+
+// checkControllerVersion checks that the controller is at least at the API version AKO sends in its calls. Like the
+// license check, it calls the session directly so that an older controller without the API does not mark it down.
+func checkControllerVersion(client *clients.AviClient, readOnly bool) error {
+	var initialData struct {
+		Version struct {
+			Version string `json:"Version"`
+		} `json:"version"`
+	}
+	if err := client.AviSession.Get("/api/initial-data", &initialData); err != nil {
+		return fmt.Errorf("Unable to get the controller version, err: %v", err)
+	}
+	controllerVersion := initialData.Version.Version
+	if controllerVersion == "" {
+		return errors.New("Unable to get the controller version")
+	}
+	if compareVersions(controllerVersion, utils.CtrlVersion) < 0 {
+		return fmt.Errorf("Controller version %s is older than the API version %s used by AKO", controllerVersion, utils.CtrlVersion)
+	}
+	return nil
+}
+
+func checkLicense(client *clients.AviClient, readOnly bool) error {
+	var license models.ControllerLicense
+	if err := client.AviSession.Get("/api/licensing", &license); err != nil {
+		return fmt.Errorf("Unable to get the controller license, err: %v", err)
+	}
+	if len(license.Licenses) == 0 {
+		return nil
+	}
+	for _, singleLicense := range license.Licenses {
+		if singleLicense != nil && (singleLicense.Expired == nil || !*singleLicense.Expired) {
+			return nil
+		}
+	}
+	return errors.New("All the licenses of the controller expired")
+}
+
+func checkTenantAccess(client *clients.AviClient, readOnly bool) error {
+	tenant := lib.GetTenant()
+	uri := "/api/tenant/?include_name&name=" + tenant
+	result, err := AviGetCollectionRaw(client, uri)
+	if err != nil {
+		return fmt.Errorf("Get uri %v returned err %v", uri, err)
+	}
+	if result.Count != 1 {
+		return fmt.Errorf("Tenant %s not found or not accessible with the AKO user", tenant)
+	}
+	return nil
+}
+
+// compareVersions compares two dotted versions like 20.1.1, ignoring any build suffix, and returns -1, 0 or 1
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum = leadingNumber(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum = leadingNumber(bParts[i])
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func leadingNumber(part string) int {
+	end := 0
+	for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+		end++
+	}
+	num, _ := strconv.Atoi(part[:end])
+	return num
+}
@@ -27,8 +27,10 @@ import (
 
 type AkoV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	AviInfraSettingsGetter
 	HTTPRulesGetter
 	HostRulesGetter
+	HostnameClaimsGetter
 }
 
 // AkoV1alpha1Client is used to interact with features provided by the ako.vmware.com group.
@@ -36,6 +38,10 @@ type AkoV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *AkoV1alpha1Client) AviInfraSettings() AviInfraSettingInterface {
+	return newAviInfraSettings(c)
+}
+
 func (c *AkoV1alpha1Client) HTTPRules(namespace string) HTTPRuleInterface {
 	return newHTTPRules(c, namespace)
 }
@@ -44,6 +50,10 @@ func (c *AkoV1alpha1Client) HostRules(namespace string) HostRuleInterface {
 	return newHostRules(c, namespace)
 }
 
+func (c *AkoV1alpha1Client) HostnameClaims(namespace string) HostnameClaimInterface {
+	return newHostnameClaims(c, namespace)
+}
+
 // NewForConfig creates a new AkoV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*AkoV1alpha1Client, error) {
 	config := *c
@@ -29,6 +29,10 @@ type FakeAkoV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeAkoV1alpha1) AviInfraSettings() v1alpha1.AviInfraSettingInterface {
+	return &FakeAviInfraSettings{c}
+}
+
 func (c *FakeAkoV1alpha1) HTTPRules(namespace string) v1alpha1.HTTPRuleInterface {
 	return &FakeHTTPRules{c, namespace}
 }
@@ -37,6 +41,10 @@ func (c *FakeAkoV1alpha1) HostRules(namespace string) v1alpha1.HostRuleInterface
 	return &FakeHostRules{c, namespace}
 }
 
+func (c *FakeAkoV1alpha1) HostnameClaims(namespace string) v1alpha1.HostnameClaimInterface {
+	return &FakeHostnameClaims{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeAkoV1alpha1) RESTClient() rest.Interface {
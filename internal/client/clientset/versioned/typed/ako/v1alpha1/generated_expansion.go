@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+type AviInfraSettingExpansion interface{}
+
 type HTTPRuleExpansion interface{}
 
 type HostRuleExpansion interface{}
+
+type HostnameClaimExpansion interface{}
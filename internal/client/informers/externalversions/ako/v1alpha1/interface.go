@@ -24,10 +24,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// AviInfraSettings returns a AviInfraSettingInformer.
+	AviInfraSettings() AviInfraSettingInformer
 	// HTTPRules returns a HTTPRuleInformer.
 	HTTPRules() HTTPRuleInformer
 	// HostRules returns a HostRuleInformer.
 	HostRules() HostRuleInformer
+	// HostnameClaims returns a HostnameClaimInformer.
+	HostnameClaims() HostnameClaimInformer
 }
 
 type version struct {
@@ -41,6 +45,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// AviInfraSettings returns a AviInfraSettingInformer.
+func (v *version) AviInfraSettings() AviInfraSettingInformer {
+	return &aviInfraSettingInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // HTTPRules returns a HTTPRuleInformer.
 func (v *version) HTTPRules() HTTPRuleInformer {
 	return &hTTPRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -50,3 +59,8 @@ func (v *version) HTTPRules() HTTPRuleInformer {
 func (v *version) HostRules() HostRuleInformer {
 	return &hostRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// HostnameClaims returns a HostnameClaimInformer.
+func (v *version) HostnameClaims() HostnameClaimInformer {
+	return &hostnameClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -54,10 +54,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=ako.vmware.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("aviinfrasettings"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ako().V1alpha1().AviInfraSettings().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("httprules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ako().V1alpha1().HTTPRules().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("hostrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ako().V1alpha1().HostRules().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("hostnameclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ako().V1alpha1().HostnameClaims().Informer()}, nil
 
 	}
 
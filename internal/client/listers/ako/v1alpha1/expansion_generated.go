@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// AviInfraSettingListerExpansion allows custom methods to be added to
+// AviInfraSettingLister.
+type AviInfraSettingListerExpansion interface{}
+
 // HTTPRuleListerExpansion allows custom methods to be added to
 // HTTPRuleLister.
 type HTTPRuleListerExpansion interface{}
@@ -33,3 +37,11 @@ type HostRuleListerExpansion interface{}
 // HostRuleNamespaceListerExpansion allows custom methods to be added to
 // HostRuleNamespaceLister.
 type HostRuleNamespaceListerExpansion interface{}
+
+// HostnameClaimListerExpansion allows custom methods to be added to
+// HostnameClaimLister.
+type HostnameClaimListerExpansion interface{}
+
+// HostnameClaimNamespaceListerExpansion allows custom methods to be added to
+// HostnameClaimNamespaceLister.
+type HostnameClaimNamespaceListerExpansion interface{}
@@ -17,6 +17,7 @@ package k8s
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,9 +29,12 @@ import (
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/rest"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/retry"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/status"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -40,12 +44,18 @@ import (
 	"k8s.io/client-go/tools/record"
 )
 
+// names of the clients dedicated to the full sync and to the preflight checks run on request
+const (
+	fullSyncClient   = "fullsync"
+	validationClient = "validation"
+)
+
 func PopulateCache() error {
 	avi_rest_client_pool := avicache.SharedAVIClients()
 	avi_obj_cache := avicache.SharedAviObjCache()
 	// Randomly pickup a client.
 	if len(avi_rest_client_pool.AviClient) > 0 {
-		_, _, err := avi_obj_cache.AviObjCachePopulate(avi_rest_client_pool.AviClient[0], utils.CtrlVersion, utils.CloudName)
+		_, _, err := avi_obj_cache.AviObjCachePopulate(avi_rest_client_pool.AviClient, utils.CtrlVersion, utils.CloudName)
 		if err != nil {
 			utils.AviLog.Warnf("failed to populate avi cache with error: %v", err.Error())
 			return err
@@ -97,6 +107,13 @@ func deleteConfigFromConfigmap(cs kubernetes.Interface) bool {
 // When the configmap is created, enable sync for other k8s objects. When the configmap is disabled, disable sync.
 func (c *AviController) HandleConfigMap(k8sinfo K8sinformers, ctrlCh chan struct{}, stopCh <-chan struct{}, quickSyncCh chan struct{}) {
 	cs := k8sinfo.Cs
+	if err := LoadControllerCredentials(cs); err != nil {
+		if os.Getenv("CTRL_USERNAME") == "" || os.Getenv("CTRL_PASSWORD") == "" {
+			utils.AviLog.Fatalf("Unable to read the controller credentials from secret %s/%s, and none are set in the environment: %v",
+				lib.AviNS, lib.GetControllerSecretName(), err)
+		}
+		utils.AviLog.Warnf("Using the controller credentials set in the environment")
+	}
 	aviClientPool := avicache.SharedAVIClients()
 	if len(aviClientPool.AviClient) < 1 {
 		c.DisableSync = true
@@ -105,7 +122,17 @@ func (c *AviController) HandleConfigMap(k8sinfo K8sinformers, ctrlCh chan struct
 		lib.ShutdownApi()
 		return
 	}
+	c.addHealthChecks(aviClientPool)
 	aviclient := aviClientPool.AviClient[0]
+	models.ValidationStatus.SetRunFunc(func() {
+		// the checks are run again on a client of their own, the ones of the pool belong to the graph workers
+		client, err := aviClientPool.DedicatedClient(validationClient)
+		if err != nil {
+			utils.AviLog.Warnf("Failed to log in to the controller to run the preflight checks, err %v", err)
+			return
+		}
+		avicache.RunPreflightChecks(client)
+	})
 	c.DisableSync = !avicache.ValidateUserInput(aviclient) || deleteConfigFromConfigmap(cs)
 	lib.SetDisableSync(c.DisableSync)
 
@@ -123,6 +150,8 @@ func (c *AviController) HandleConfigMap(k8sinfo K8sinformers, ctrlCh chan struct
 			}
 			utils.AviLog.Infof("avi k8s configmap created")
 			utils.AviLog.SetLevel(cm.Data[lib.LOG_LEVEL])
+			utils.AviLog.SetSubsystemLevels(cm.Data[lib.SUBSYSTEM_LOG_LEVELS])
+			models.DiagnosticsStatus.SetEnabled(cm.Data[lib.ENABLE_DIAGNOSTICS] == "true")
 			c.DisableSync = !avicache.ValidateUserInput(aviclient) || delConfigFromData(cm.Data)
 			lib.SetDisableSync(c.DisableSync)
 			if !firstboot && avicache.ValidateUserInput(aviclient) {
@@ -146,6 +175,12 @@ func (c *AviController) HandleConfigMap(k8sinfo K8sinformers, ctrlCh chan struct
 			if oldcm.Data[lib.LOG_LEVEL] != cm.Data[lib.LOG_LEVEL] {
 				utils.AviLog.SetLevel(cm.Data[lib.LOG_LEVEL])
 			}
+			if oldcm.Data[lib.SUBSYSTEM_LOG_LEVELS] != cm.Data[lib.SUBSYSTEM_LOG_LEVELS] {
+				utils.AviLog.SetSubsystemLevels(cm.Data[lib.SUBSYSTEM_LOG_LEVELS])
+			}
+			if oldcm.Data[lib.ENABLE_DIAGNOSTICS] != cm.Data[lib.ENABLE_DIAGNOSTICS] {
+				models.DiagnosticsStatus.SetEnabled(cm.Data[lib.ENABLE_DIAGNOSTICS] == "true")
+			}
 
 			if oldcm.Data[lib.DeleteConfig] == cm.Data[lib.DeleteConfig] {
 				return
@@ -173,6 +208,7 @@ func (c *AviController) HandleConfigMap(k8sinfo K8sinformers, ctrlCh chan struct
 	}
 
 	c.informers.ConfigMapInformer.Informer().AddEventHandler(configMapEventHandler)
+	WatchControllerCredentials(cs, stopCh)
 
 	go c.informers.ConfigMapInformer.Informer().Run(stopCh)
 	if !cache.WaitForCacheSync(stopCh,
@@ -240,6 +276,8 @@ func (c *AviController) InitController(informers K8sinformers, registeredInforme
 		graphQueue = utils.SharedWorkQueue(ingestionQueueParams, graphQueueParams, slowRetryQParams, fastRetryQParams).GetQueueByName(utils.GraphLayer)
 	}
 
+	// the status updates queued by the rest layer are written off its workers
+	status.SharedStatusQueue().Run(stopCh)
 	graphQueue.SyncFunc = SyncFromNodesLayer
 	graphQueue.Run(stopCh, graphwg)
 	fullSyncInterval := os.Getenv(utils.FULL_SYNC_INTERVAL)
@@ -249,6 +287,10 @@ func (c *AviController) InitController(informers K8sinformers, registeredInforme
 		err = nil
 		interval = 1800 //seconds, hard coded.
 	}
+	// the persisted shard of each host is loaded before the first sync builds the shard models
+	if lib.IsShardAssignmentPersisted() {
+		nodes.SharedShardAssignment().Load()
+	}
 	// Set up the workers but don't start draining them.
 	c.SetupEventHandlers(informers)
 	if err != nil {
@@ -265,6 +307,21 @@ func (c *AviController) InitController(informers K8sinformers, registeredInforme
 			utils.AviLog.Warnf("Full sync interval set to 0, will not run full sync")
 		}
 	}
+	if gcInterval := lib.GetOrphanGCInterval(); gcInterval != 0 {
+		go c.RunOrphanGC(gcInterval, stopCh)
+	}
+	// with the endpoints of the controller cluster nodes given, the clients fail over to another one if the active one is down
+	if endpoints := avicache.SharedAVIClients().Endpoints; endpoints != nil && len(endpoints.Endpoints()) > 1 {
+		go endpoints.MonitorEndpoints(lib.GetControllerHealthCheckInterval(), stopCh)
+	}
+
+	if lib.IsShardAssignmentPersisted() {
+		models.ShardStatus.SetMigrateFunc(nodes.SharedShardAssignment().Migrate)
+	}
+	// the migrations also move the hosts to and off their dedicated VS
+	if lib.GetShardScheme() == lib.HOSTNAME_SHARD_SCHEME && !lib.GetAdvancedL4() {
+		go nodes.SharedShardAssignment().Run(stopCh)
+	}
 
 	ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
 	ingestionQueue.SyncFunc = SyncFromIngestionLayer
@@ -273,6 +330,7 @@ func (c *AviController) InitController(informers K8sinformers, registeredInforme
 	fastRetryQueue := utils.SharedWorkQueue().GetQueueByName(lib.FAST_RETRY_LAYER)
 	fastRetryQueue.SyncFunc = SyncFromFastRetryLayer
 	fastRetryQueue.Run(stopCh, fastretrywg)
+	models.DeadLetterStatus.SetRetryFunc(retry.RetryModelNow)
 LABEL:
 	for {
 		select {
@@ -296,7 +354,13 @@ func (c *AviController) FullSync() {
 	avi_obj_cache := avicache.SharedAviObjCache()
 	// Randomly pickup a client.
 	if len(avi_rest_client_pool.AviClient) > 0 {
-		avi_obj_cache.AviCacheRefresh(avi_rest_client_pool.AviClient[0], utils.CloudName)
+		// the full sync runs on a client of its own, the ones of the pool belong to the graph workers
+		aviClient, err := avi_rest_client_pool.DedicatedClient(fullSyncClient)
+		if err != nil {
+			utils.AviLog.Warnf("Failed to log in to the controller for the full sync, err %v", err)
+			return
+		}
+		avi_obj_cache.AviCacheRefresh(aviClient, utils.CloudName)
 		allModelsMap := objects.SharedAviGraphLister().GetAll()
 		var allModels []string
 		for modelName, _ := range allModelsMap.(map[string]interface{}) {
@@ -315,6 +379,11 @@ func (c *AviController) FullSync() {
 			// Not publishing the model anymore to layer since we don't want to support full sync for now.
 			//nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
 		}
+		// Only the models drifted on the controller are republished.
+		if lib.IsDriftReconcileEnabled() {
+			c.DriftReconcile(aviClient)
+		}
+		SaveCacheSnapshot()
 	}
 }
 
@@ -391,11 +460,40 @@ func (c *AviController) FullSyncK8s() {
 			}
 		}
 
+		if lib.IsHostnameClaimEnabled() {
+			hostnameClaimObjs, err := lib.GetCRDInformers().HostnameClaimInformer.Lister().HostnameClaims("").List(labels.Set(nil).AsSelector())
+			if err != nil {
+				utils.AviLog.Errorf("Unable to retrieve the hostnameclaims during full sync: %s", err)
+			} else {
+				for _, hostnameClaimObj := range hostnameClaimObjs {
+					key := lib.HostnameClaim + "/" + utils.ObjKey(hostnameClaimObj)
+					nodes.DequeueIngestion(key, true)
+				}
+			}
+		}
+
+		if lib.IsAviInfraSettingEnabled() {
+			aviInfraSettingObjs, err := lib.GetCRDInformers().AviInfraSettingInformer.Lister().List(labels.Set(nil).AsSelector())
+			if err != nil {
+				utils.AviLog.Errorf("Unable to retrieve the aviinfrasettings during full sync: %s", err)
+			} else {
+				for _, aviInfraSettingObj := range aviInfraSettingObjs {
+					key := lib.AviInfraSetting + "/" + utils.ObjKey(aviInfraSettingObj)
+					nodes.DequeueIngestion(key, true)
+				}
+			}
+		}
+
 		if utils.GetInformers().IngressInformer != nil {
 			ingObjs, err := utils.GetInformers().IngressInformer.Lister().ByNamespace("").List(labels.Set(nil).AsSelector())
 			if err != nil {
 				utils.AviLog.Errorf("Unable to retrieve the ingresses during full sync: %s", err)
 			} else {
+				// older ingresses are processed first, so that they retain the hosts they claimed
+				// under the FirstClaim hostname ownership policy
+				sort.SliceStable(ingObjs, func(i, j int) bool {
+					return getCreationTimestamp(ingObjs[i]).Before(getCreationTimestamp(ingObjs[j]))
+				})
 				for _, ingObj := range ingObjs {
 					key := utils.Ingress + "/" + utils.ObjKey(ingObj)
 					nodes.DequeueIngestion(key, true)
@@ -407,6 +505,9 @@ func (c *AviController) FullSyncK8s() {
 			if err != nil {
 				utils.AviLog.Errorf("Unable to retrieve the routes during full sync: %s", err)
 			} else {
+				sort.SliceStable(ingObjs, func(i, j int) bool {
+					return ingObjs[i].CreationTimestamp.Before(&ingObjs[j].CreationTimestamp)
+				})
 				for _, ingObj := range ingObjs {
 					// to do move to container-lib
 					key := utils.OshiftRoute + "/" + utils.ObjKey(ingObj)
@@ -459,7 +560,7 @@ func (c *AviController) FullSyncK8s() {
 							allModels = utils.Remove(allModels, modelName)
 						}
 						utils.AviLog.Infof("Model published L7 VS during namespace based sync: %s", modelName)
-						nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
+						publishFullSyncModel(modelName, sharedQueue)
 					}
 				}
 				// For namespace based syncs, the L4 VSes would be named: clusterName + "--" + namespace
@@ -469,7 +570,7 @@ func (c *AviController) FullSyncK8s() {
 						allModels = utils.Remove(allModels, modelName)
 					}
 					utils.AviLog.Infof("Model published L4 VS during namespace based sync: %s", modelName)
-					nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
+					publishFullSyncModel(modelName, sharedQueue)
 				}
 			} else {
 				modelName := vsCacheKey.Namespace + "/" + vsCacheKey.Name
@@ -477,7 +578,7 @@ func (c *AviController) FullSyncK8s() {
 					allModels = utils.Remove(allModels, modelName)
 				}
 				utils.AviLog.Infof("Model published in full sync %s", modelName)
-				nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
+				publishFullSyncModel(modelName, sharedQueue)
 			}
 		}
 	}
@@ -486,9 +587,11 @@ func (c *AviController) FullSyncK8s() {
 	utils.AviLog.Debugf("Newly generated models that do not exist in cache %s", utils.Stringify(allModels))
 	if allModels != nil {
 		for _, modelName := range allModels {
-			nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
+			publishFullSyncModel(modelName, sharedQueue)
 		}
 	}
+	// the snapshot only applies to the models computed at bootup
+	cache.ClearSnapshotGraphChecksums()
 	return
 }
 
@@ -520,6 +623,16 @@ func (c *AviController) DeleteModels() {
 	}
 }
 
+// getCreationTimestamp returns the creation timestamp of the object, zero if it has no object meta
+func getCreationTimestamp(obj interface{}) *metav1.Time {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return &metav1.Time{}
+	}
+	creationTimestamp := objMeta.GetCreationTimestamp()
+	return &creationTimestamp
+}
+
 func SyncFromIngestionLayer(key string, wg *sync.WaitGroup) error {
 	// This method will do all necessary graph calculations on the Graph Layer
 	// Let's route the key to the graph layer.
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strconv"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// SaveCacheSnapshot persists the Avi object cache along with the checksums of the models in sync with it.
+func SaveCacheSnapshot() {
+	if lib.GetCacheSnapshotStore() == "" {
+		return
+	}
+	graphChecksums := make(map[string]uint32)
+	for modelName, modelIntf := range objects.SharedAviGraphLister().AviGraphStore.CopyAllObjects() {
+		aviModel, ok := modelIntf.(*nodes.AviObjectGraph)
+		if ok && isModelSynced(aviModel) {
+			graphChecksums[modelName] = aviModel.GetCheckSum()
+		}
+	}
+	if err := avicache.SharedAviObjCache().SaveCacheSnapshot(graphChecksums); err != nil {
+		utils.AviLog.Warnf("Unable to save the cache snapshot: %v", err)
+	}
+}
+
+// isModelSynced returns true if the checksums of all the virtualservices of the model match the ones in the cache.
+func isModelSynced(aviModel *nodes.AviObjectGraph) bool {
+	var vsNodes []*nodes.AviVsNode
+	for _, vsNode := range aviModel.GetAviVS() {
+		vsNodes = append(vsNodes, vsNode)
+		vsNodes = append(vsNodes, vsNode.SniNodes...)
+	}
+	if len(vsNodes) == 0 {
+		return false
+	}
+	aviObjCache := avicache.SharedAviObjCache()
+	for _, vsNode := range vsNodes {
+		vsKey := avicache.NamespaceName{Namespace: vsNode.Tenant, Name: vsNode.Name}
+		vsCache, found := aviObjCache.VsCacheMeta.AviCacheGet(vsKey)
+		if !found {
+			return false
+		}
+		vsCacheObj, ok := vsCache.(*avicache.AviVsCache)
+		if !ok || vsCacheObj.InvalidData || vsCacheObj.CloudConfigCksum != strconv.Itoa(int(vsNode.GetCheckSum())) {
+			return false
+		}
+	}
+	return true
+}
+
+// publishFullSyncModel publishes a model to the rest layer during the bootup full sync. The models which have
+// not changed since the cache snapshot was taken, and whose virtualservices are still in sync, are skipped.
+func publishFullSyncModel(modelName string, sharedQueue *utils.WorkerQueue) {
+	found, modelIntf := objects.SharedAviGraphLister().Get(modelName)
+	if found && modelIntf != nil {
+		aviModel, ok := modelIntf.(*nodes.AviObjectGraph)
+		if ok && avicache.SharedAviObjCache().IsModelInSnapshot(modelName, aviModel.GetCheckSum()) && isModelSynced(aviModel) {
+			utils.AviLog.Infof("Model %s unchanged since the cache snapshot, skipping it in full sync", modelName)
+			return
+		}
+	}
+	nodes.PublishKeyToRestLayer(modelName, "fullsync", sharedQueue)
+}
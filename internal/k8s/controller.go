@@ -19,8 +19,11 @@ import (
 	"os"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/status"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
@@ -30,9 +33,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -58,6 +63,8 @@ type AviController struct {
 	dynamicInformers *lib.DynamicInformers
 	workqueue        []workqueue.RateLimitingInterface
 	DisableSync      bool
+	// informersSynced holds the HasSynced funcs of the informers started, for the readiness probe
+	informersSynced atomic.Value
 }
 
 type K8sinformers struct {
@@ -158,12 +165,19 @@ func AddRouteEventHandler(numWorkers uint32, c *AviController) cache.ResourceEve
 			route := obj.(*routev1.Route)
 			namespace, _, _ := cache.SplitMetaNamespaceKey(utils.ObjKey(route))
 			key := utils.OshiftRoute + "/" + utils.ObjKey(route)
+			if !lib.IsRouteSelected(route) {
+				utils.AviLog.Debugf("key: %s, msg: route does not match the selectors, skipping", key)
+				return
+			}
 			bkt := utils.Bkt(namespace, numWorkers)
 			if !lib.HasValidBackends(route.Spec, route.Name, namespace, key) {
 				status.UpdateRouteStatusWithErrMsg(route.Name, namespace, lib.DuplicateBackends)
+			} else if !lib.HasValidWildcardPolicy(route.Spec, route.Name, namespace, key) {
+				status.UpdateRouteStatusWithErrMsg(route.Name, namespace, lib.WildcardPolicyNotAllowed)
 			}
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -187,6 +201,7 @@ func AddRouteEventHandler(numWorkers uint32, c *AviController) cache.ResourceEve
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -194,27 +209,200 @@ func AddRouteEventHandler(numWorkers uint32, c *AviController) cache.ResourceEve
 			}
 			oldRoute := old.(*routev1.Route)
 			newRoute := cur.(*routev1.Route)
-			if isRouteUpdated(oldRoute, newRoute) {
+			oldSelected, newSelected := lib.IsRouteSelected(oldRoute), lib.IsRouteSelected(newRoute)
+			if !oldSelected && !newSelected {
+				return
+			}
+			if isRouteUpdated(oldRoute, newRoute) || oldSelected != newSelected {
 				namespace, _, _ := cache.SplitMetaNamespaceKey(utils.ObjKey(newRoute))
 				key := utils.OshiftRoute + "/" + utils.ObjKey(newRoute)
 				bkt := utils.Bkt(namespace, numWorkers)
+				if !newSelected {
+					// the route moved to another router, the avi objects are removed in the graph layer
+					status.ResetRouteStatus(newRoute.Name, namespace, key)
+					c.workqueue[bkt].AddRateLimited(key)
+					utils.AviLog.Debugf("key: %s, msg: route no longer matches the selectors, UPDATE", key)
+					return
+				}
 				if !lib.HasValidBackends(newRoute.Spec, newRoute.Name, namespace, key) {
 					status.UpdateRouteStatusWithErrMsg(newRoute.Name, namespace, lib.DuplicateBackends)
+				} else if !lib.HasValidWildcardPolicy(newRoute.Spec, newRoute.Name, namespace, key) {
+					status.UpdateRouteStatusWithErrMsg(newRoute.Name, namespace, lib.WildcardPolicyNotAllowed)
 				}
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
 			}
 		},
 	}
 	return routeEventHandler
 }
 
+// AddNamespaceEventHandler re-evaluates the ingresses, routes and services of type loadbalancer of a namespace
+// whose labels start or stop matching the namespace selector. The objects of a namespace no longer selected are
+// removed from Avi in the graph layer, and the status AKO wrote on them is reset.
+func AddNamespaceEventHandler(numWorkers uint32, c *AviController) cache.ResourceEventHandler {
+	nsEventHandler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			if c.DisableSync {
+				return
+			}
+			oldNs := old.(*corev1.Namespace)
+			newNs := cur.(*corev1.Namespace)
+			if lib.IsNamespaceLabelSelected(oldNs.Labels) == lib.IsNamespaceLabelSelected(newNs.Labels) {
+				return
+			}
+			utils.AviLog.Infof("Labels of namespace %s changed, selected: %v", newNs.Name, lib.IsNamespaceSelected(newNs.Name))
+			bkt := utils.Bkt(newNs.Name, numWorkers)
+			nsSelected := lib.IsNamespaceSelected(newNs.Name)
+			if c.informers.RouteInformer != nil {
+				routeObjs, err := c.informers.RouteInformer.Lister().Routes(newNs.Name).List(labels.Set(nil).AsSelector())
+				if err != nil {
+					utils.AviLog.Errorf("Unable to retrieve the routes in namespace %s: %s", newNs.Name, err)
+				}
+				for _, route := range routeObjs {
+					key := utils.OshiftRoute + "/" + utils.ObjKey(route)
+					if !lib.IsRouteSelected(route) {
+						status.ResetRouteStatus(route.Name, newNs.Name, key)
+					}
+					c.workqueue[bkt].AddRateLimited(key)
+					utils.AviLog.Debugf("key: %s, msg: namespace labels changed, UPDATE", key)
+				}
+			}
+			if c.informers.IngressInformer != nil {
+				ingObjs, err := c.informers.IngressInformer.Lister().ByNamespace(newNs.Name).List(labels.Set(nil).AsSelector())
+				if err != nil {
+					utils.AviLog.Errorf("Unable to retrieve the ingresses in namespace %s: %s", newNs.Name, err)
+				}
+				for _, ingObj := range ingObjs {
+					ingress, ok := utils.ToNetworkingIngress(ingObj)
+					if !ok {
+						continue
+					}
+					key := utils.Ingress + "/" + utils.ObjKey(ingress)
+					if !nsSelected && nodes.FilterIngressOnClass(ingress) {
+						status.ResetIngressStatus(ingress.Name, newNs.Name, key)
+					}
+					c.workqueue[bkt].AddRateLimited(key)
+					utils.AviLog.Debugf("key: %s, msg: namespace labels changed, UPDATE", key)
+				}
+			}
+			svcObjs, err := c.informers.ServiceInformer.Lister().Services(newNs.Name).List(labels.Set(nil).AsSelector())
+			if err != nil {
+				utils.AviLog.Errorf("Unable to retrieve the services in namespace %s: %s", newNs.Name, err)
+			}
+			for _, svc := range svcObjs {
+				if !isServiceLBType(svc) {
+					continue
+				}
+				key := utils.L4LBService + "/" + utils.ObjKey(svc)
+				if !nsSelected {
+					status.ResetL4LBStatus(svc.Name, newNs.Name, key)
+				}
+				c.workqueue[bkt].AddRateLimited(key)
+				utils.AviLog.Debugf("key: %s, msg: namespace labels changed, UPDATE", key)
+			}
+		},
+	}
+	return nsEventHandler
+}
+
+// AddIngressClassEventHandler re-evaluates the ingresses of an IngressClass which changed, the IngressClass keys
+// are bucketed on the tenant as the class is cluster scoped.
+func AddIngressClassEventHandler(numWorkers uint32, c *AviController) cache.ResourceEventHandler {
+	ingressClassEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			ingClass := obj.(*unstructured.Unstructured)
+			key := lib.IngressClass + "/" + ingClass.GetName()
+			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			ingClass, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					utils.AviLog.Errorf("couldn't get object from tombstone %#v", obj)
+					return
+				}
+				ingClass, ok = tombstone.Obj.(*unstructured.Unstructured)
+				if !ok {
+					utils.AviLog.Errorf("Tombstone contained object that is not an IngressClass: %#v", obj)
+					return
+				}
+			}
+			key := lib.IngressClass + "/" + ingClass.GetName()
+			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			if c.DisableSync {
+				return
+			}
+			oldClass := old.(*unstructured.Unstructured)
+			ingClass := cur.(*unstructured.Unstructured)
+			oldDefault := oldClass.GetAnnotations()[lib.DefaultIngressClassAnnotation]
+			if reflect.DeepEqual(oldClass.Object["spec"], ingClass.Object["spec"]) && oldDefault == ingClass.GetAnnotations()[lib.DefaultIngressClassAnnotation] {
+				return
+			}
+			key := lib.IngressClass + "/" + ingClass.GetName()
+			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+			utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
+		},
+	}
+	return ingressClassEventHandler
+}
+
+// AddIngressClassNameEventHandler re-evaluates the ingresses whose spec.ingressClassName changed, which the
+// handlers of the typed ingresses do not see.
+func AddIngressClassNameEventHandler(numWorkers uint32, c *AviController) cache.ResourceEventHandler {
+	enqueueIngress := func(ingress *unstructured.Unstructured, event string) {
+		key := utils.Ingress + "/" + ingress.GetNamespace() + "/" + ingress.GetName()
+		bkt := utils.Bkt(ingress.GetNamespace(), numWorkers)
+		c.workqueue[bkt].AddRateLimited(key)
+		utils.AviLog.Debugf("key: %s, msg: ingressClassName %s", key, event)
+	}
+	ingressClassNameEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			// the typed ingress may have been processed before its class was known here
+			if lib.GetUnstructuredIngressClassName(obj) != "" {
+				enqueueIngress(obj.(*unstructured.Unstructured), "ADD")
+			}
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			if c.DisableSync {
+				return
+			}
+			if lib.GetUnstructuredIngressClassName(old) != lib.GetUnstructuredIngressClassName(cur) {
+				enqueueIngress(cur.(*unstructured.Unstructured), "UPDATE")
+			}
+		},
+	}
+	return ingressClassNameEventHandler
+}
+
 func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 	cs := k8sinfo.Cs
 	utils.AviLog.Debugf("Creating event broadcaster")
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(utils.AviLog.Debugf)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: cs.CoreV1().Events("")})
+	lib.SetEventRecorder(eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: lib.AKOEventComponent}))
 	mcpQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
 	c.workqueue = mcpQueue.Workqueue
 	numWorkers := mcpQueue.NumWorkers
@@ -234,6 +422,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -262,6 +451,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -279,6 +469,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 				bkt := utils.Bkt(namespace, numWorkers)
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
 			}
 		},
 	}
@@ -303,6 +494,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -336,6 +528,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -360,6 +553,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 				bkt := utils.Bkt(namespace, numWorkers)
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
 			}
 		},
 	}
@@ -463,6 +657,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -487,6 +682,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -504,6 +700,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 				bkt := utils.Bkt(namespace, numWorkers)
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
 			}
 		},
 	}
@@ -519,6 +716,8 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
+			c.enqueueCASecretRules(secret, numWorkers)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -542,6 +741,8 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(namespace, numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
+			c.enqueueCASecretRules(secret, numWorkers)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -556,6 +757,8 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 				bkt := utils.Bkt(namespace, numWorkers)
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
+				c.enqueueCASecretRules(secret, numWorkers)
 			}
 		},
 	}
@@ -570,6 +773,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "ADD event", nil)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if c.DisableSync {
@@ -592,6 +796,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
 			c.workqueue[bkt].AddRateLimited(key)
 			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "DELETE event", nil)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if c.DisableSync {
@@ -604,6 +809,7 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 				bkt := utils.Bkt(lib.GetTenant(), numWorkers)
 				c.workqueue[bkt].AddRateLimited(key)
 				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				utils.AddTraceEvent(key, utils.ObjectIngestionLayer, "UPDATE event", nil)
 			} else {
 				utils.AviLog.Debugf("key: %s, msg: node object did not change\n", key)
 			}
@@ -613,6 +819,10 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 	if c.informers.IngressInformer != nil {
 		c.informers.IngressInformer.Informer().AddEventHandler(ingressEventHandler)
 		c.informers.SecretInformer.Informer().AddEventHandler(secretEventHandler)
+		if c.dynamicInformers != nil && c.dynamicInformers.IngressClassInformer != nil {
+			c.dynamicInformers.IngressClassInformer.Informer().AddEventHandler(AddIngressClassEventHandler(numWorkers, c))
+			c.dynamicInformers.NetworkingIngressInformer.Informer().AddEventHandler(AddIngressClassNameEventHandler(numWorkers, c))
+		}
 	}
 
 	if os.Getenv(lib.DISABLE_STATIC_ROUTE_SYNC) == "true" && !lib.IsNodePortMode() {
@@ -625,11 +835,36 @@ func (c *AviController) SetupEventHandlers(k8sinfo K8sinformers) {
 		routeEventHandler := AddRouteEventHandler(numWorkers, c)
 		c.informers.RouteInformer.Informer().AddEventHandler(routeEventHandler)
 	}
+	if os.Getenv(lib.NAMESPACE_SELECTOR) != "" && !lib.GetAdvancedL4() {
+		nsEventHandler := AddNamespaceEventHandler(numWorkers, c)
+		c.informers.NSInformer.Informer().AddEventHandler(nsEventHandler)
+	}
 
 	// Add CRD handlers HostRule/HTTPRule
 	c.SetupAKOCRDEventHandlers(numWorkers)
 }
 
+// enqueueCASecretRules re-evaluates the hostrules which refer to the secret for client
+// certificate authentication, and the httprules which refer to it as destination CA.
+// The rules and the secret share a namespace.
+func (c *AviController) enqueueCASecretRules(secret *corev1.Secret, numWorkers uint32) {
+	bkt := utils.Bkt(secret.Namespace, numWorkers)
+	if found, hostrules := objects.SharedCRDLister().GetClientCASecretToHostrulesMapping(utils.ObjKey(secret)); found {
+		for _, hostrule := range hostrules {
+			key := lib.HostRule + "/" + hostrule
+			c.workqueue[bkt].AddRateLimited(key)
+			utils.AviLog.Debugf("key: %s, msg: client CA secret %s changed", key, utils.ObjKey(secret))
+		}
+	}
+	if found, httprules := objects.SharedCRDLister().GetDestCASecretToHTTPRulesMapping(utils.ObjKey(secret)); found {
+		for _, httprule := range httprules {
+			key := lib.HTTPRule + "/" + httprule
+			c.workqueue[bkt].AddRateLimited(key)
+			utils.AviLog.Debugf("key: %s, msg: destination CA secret %s changed", key, utils.ObjKey(secret))
+		}
+	}
+}
+
 func validateAviConfigMap(obj interface{}) (*corev1.ConfigMap, bool) {
 	configMap, ok := obj.(*corev1.ConfigMap)
 	if ok && lib.GetNamespaceToSync() != "" {
@@ -664,6 +899,12 @@ func (c *AviController) Start(stopCh <-chan struct{}) {
 		go c.dynamicInformers.HostSubnetInformer.Informer().Run(stopCh)
 		informersList = append(informersList, c.dynamicInformers.HostSubnetInformer.Informer().HasSynced)
 	}
+	if c.dynamicInformers != nil && c.dynamicInformers.IngressClassInformer != nil {
+		go c.dynamicInformers.IngressClassInformer.Informer().Run(stopCh)
+		go c.dynamicInformers.NetworkingIngressInformer.Informer().Run(stopCh)
+		informersList = append(informersList, c.dynamicInformers.IngressClassInformer.Informer().HasSynced)
+		informersList = append(informersList, c.dynamicInformers.NetworkingIngressInformer.Informer().HasSynced)
+	}
 
 	// Disable all informers if we are in advancedL4 mode. We expect to only provide L4 load balancing capability for this feature.
 	if lib.GetAdvancedL4() {
@@ -699,9 +940,23 @@ func (c *AviController) Start(stopCh <-chan struct{}) {
 		if !cache.WaitForCacheSync(stopCh, lib.GetCRDInformers().HTTPRuleInformer.Informer().HasSynced) {
 			runtime.HandleError(fmt.Errorf("Timed out waiting for HTTPRule caches to sync"))
 		}
+		// the CRDs added to the chart since the first install are missing from upgraded clusters
+		if lib.IsHostnameClaimEnabled() {
+			go lib.GetCRDInformers().HostnameClaimInformer.Informer().Run(stopCh)
+			if !cache.WaitForCacheSync(stopCh, lib.GetCRDInformers().HostnameClaimInformer.Informer().HasSynced) {
+				runtime.HandleError(fmt.Errorf("Timed out waiting for HostnameClaim caches to sync"))
+			}
+		}
+		if lib.IsAviInfraSettingEnabled() {
+			go lib.GetCRDInformers().AviInfraSettingInformer.Informer().Run(stopCh)
+			if !cache.WaitForCacheSync(stopCh, lib.GetCRDInformers().AviInfraSettingInformer.Informer().HasSynced) {
+				runtime.HandleError(fmt.Errorf("Timed out waiting for AviInfraSetting caches to sync"))
+			}
+		}
 		utils.AviLog.Info("CRD caches synced")
 	}
 
+	c.informersSynced.Store(informersList)
 	if !cache.WaitForCacheSync(stopCh, informersList...) {
 		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
 	} else {
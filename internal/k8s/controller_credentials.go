@@ -0,0 +1,114 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package k8s
+
+import (
+	"errors"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// LoadControllerCredentials reads the controller credentials from the secret named in CTRL_SECRET, they are used
+// instead of the ones set in the environment when the avi clients are built.
+func LoadControllerCredentials(cs kubernetes.Interface) error {
+	secretName := lib.GetControllerSecretName()
+	if secretName == "" {
+		return nil
+	}
+	secret, err := cs.CoreV1().Secrets(lib.AviNS).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		utils.AviLog.Errorf("Unable to read the controller credentials from secret %s/%s, err: %v", lib.AviNS, secretName, err)
+		return err
+	}
+	models.DiagnosticsStatus.SetToken(string(secret.Data["diagnosticsToken"]))
+	credentials, err := controllerCredentialsFromSecret(secret)
+	if err != nil {
+		utils.AviLog.Errorf("Invalid controller credentials in secret %s/%s, err: %v", lib.AviNS, secretName, err)
+		return err
+	}
+	avicache.SetControllerCredentials(credentials)
+	return nil
+}
+
+// WatchControllerCredentials has the avi clients log in again with the credentials in the controller secret
+// every time it changes, so that the password or token can be rotated without restarting AKO.
+func WatchControllerCredentials(cs kubernetes.Interface, stopCh <-chan struct{}) {
+	secretName := lib.GetControllerSecretName()
+	if secretName == "" {
+		return
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(cs, 0, informers.WithNamespace(lib.AviNS),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = "metadata.name=" + secretName
+		}))
+	secretInformer := informerFactory.Core().V1().Secrets().Informer()
+	updateCredentials := func(obj interface{}) {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok || secret.Name != secretName {
+			return
+		}
+		// the token of the diagnostics endpoints is rotated along with the credentials
+		models.DiagnosticsStatus.SetToken(string(secret.Data["diagnosticsToken"]))
+		credentials, err := controllerCredentialsFromSecret(secret)
+		if err != nil {
+			utils.AviLog.Errorf("Invalid controller credentials in secret %s/%s, err: %v", lib.AviNS, secretName, err)
+			return
+		}
+		if avicache.SetControllerCredentials(credentials) {
+			utils.AviLog.Infof("Controller credentials in secret %s/%s changed, logging in again", lib.AviNS, secretName)
+			avicache.SharedAVIClients().UpdateCredentials(credentials)
+		}
+	}
+	secretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: updateCredentials,
+		UpdateFunc: func(old, cur interface{}) {
+			oldSecret, oldok := old.(*corev1.Secret)
+			secret, ok := cur.(*corev1.Secret)
+			if oldok && ok && oldSecret.ResourceVersion == secret.ResourceVersion {
+				return
+			}
+			updateCredentials(cur)
+		},
+		DeleteFunc: func(obj interface{}) {
+			utils.AviLog.Warnf("Controller secret %s/%s deleted, the avi clients stay logged in with the last credentials", lib.AviNS, secretName)
+		},
+	})
+	go secretInformer.Run(stopCh)
+}
+
+func controllerCredentialsFromSecret(secret *corev1.Secret) (utils.AviControllerCredentials, error) {
+	credentials := utils.AviControllerCredentials{
+		Username:  string(secret.Data["username"]),
+		Password:  string(secret.Data["password"]),
+		AuthToken: string(secret.Data["authtoken"]),
+		CACert:    string(secret.Data["certificateAuthorityData"]),
+	}
+	if credentials.Username == "" {
+		return credentials, errors.New("username not set")
+	}
+	if credentials.Password == "" && credentials.AuthToken == "" {
+		return credentials, errors.New("neither password nor authtoken set")
+	}
+	return credentials, nil
+}
@@ -34,10 +34,14 @@ func NewCRDInformers(cs akocrd.Interface) {
 	akoInformerFactory = akoinformers.NewSharedInformerFactoryWithOptions(cs, time.Second*30)
 	hostRuleInformer := akoInformerFactory.Ako().V1alpha1().HostRules()
 	httpRuleInformer := akoInformerFactory.Ako().V1alpha1().HTTPRules()
+	hostnameClaimInformer := akoInformerFactory.Ako().V1alpha1().HostnameClaims()
+	aviInfraSettingInformer := akoInformerFactory.Ako().V1alpha1().AviInfraSettings()
 
 	lib.SetCRDInformers(&lib.AKOCrdInformers{
-		HostRuleInformer: hostRuleInformer,
-		HTTPRuleInformer: httpRuleInformer,
+		HostRuleInformer:        hostRuleInformer,
+		HTTPRuleInformer:        httpRuleInformer,
+		HostnameClaimInformer:   hostnameClaimInformer,
+		AviInfraSettingInformer: aviInfraSettingInformer,
 	})
 }
 
@@ -121,8 +125,80 @@ func (c *AviController) SetupAKOCRDEventHandlers(numWorkers uint32) {
 		},
 	}
 
+	hostnameClaimEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			hostnameclaim := obj.(*akov1alpha1.HostnameClaim)
+			namespace, _, _ := cache.SplitMetaNamespaceKey(utils.ObjKey(hostnameclaim))
+			key := lib.HostnameClaim + "/" + utils.ObjKey(hostnameclaim)
+			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			bkt := utils.Bkt(namespace, numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldObj := old.(*akov1alpha1.HostnameClaim)
+			hostnameclaim := new.(*akov1alpha1.HostnameClaim)
+			if !reflect.DeepEqual(oldObj.Spec, hostnameclaim.Spec) {
+				namespace, _, _ := cache.SplitMetaNamespaceKey(utils.ObjKey(hostnameclaim))
+				key := lib.HostnameClaim + "/" + utils.ObjKey(hostnameclaim)
+				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				bkt := utils.Bkt(namespace, numWorkers)
+				c.workqueue[bkt].AddRateLimited(key)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			hostnameclaim := obj.(*akov1alpha1.HostnameClaim)
+			namespace, _, _ := cache.SplitMetaNamespaceKey(utils.ObjKey(hostnameclaim))
+			key := lib.HostnameClaim + "/" + utils.ObjKey(hostnameclaim)
+			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			bkt := utils.Bkt(namespace, numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+		},
+	}
+
+	// AviInfraSettings are cluster scoped, their keys are bucketed on the tenant
+	aviInfraSettingEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			aviinfrasetting := obj.(*akov1alpha1.AviInfraSetting)
+			key := lib.AviInfraSetting + "/" + utils.ObjKey(aviinfrasetting)
+			utils.AviLog.Debugf("key: %s, msg: ADD", key)
+			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldObj := old.(*akov1alpha1.AviInfraSetting)
+			aviinfrasetting := new.(*akov1alpha1.AviInfraSetting)
+			if !reflect.DeepEqual(oldObj.Spec, aviinfrasetting.Spec) {
+				key := lib.AviInfraSetting + "/" + utils.ObjKey(aviinfrasetting)
+				utils.AviLog.Debugf("key: %s, msg: UPDATE", key)
+				bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+				c.workqueue[bkt].AddRateLimited(key)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if c.DisableSync {
+				return
+			}
+			aviinfrasetting := obj.(*akov1alpha1.AviInfraSetting)
+			key := lib.AviInfraSetting + "/" + utils.ObjKey(aviinfrasetting)
+			utils.AviLog.Debugf("key: %s, msg: DELETE", key)
+			bkt := utils.Bkt(lib.GetTenant(), numWorkers)
+			c.workqueue[bkt].AddRateLimited(key)
+		},
+	}
+
 	informer.HostRuleInformer.Informer().AddEventHandler(hostRuleEventHandler)
 	informer.HTTPRuleInformer.Informer().AddEventHandler(httpRuleEventHandler)
+	informer.HostnameClaimInformer.Informer().AddEventHandler(hostnameClaimEventHandler)
+	informer.AviInfraSettingInformer.Informer().AddEventHandler(aviInfraSettingEventHandler)
 
 	return
 }
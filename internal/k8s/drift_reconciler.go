@@ -0,0 +1,165 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/avinetworks/sdk/go/clients"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	driftDeleted          = "deleted on the controller"
+	driftModified         = "modified on the controller"
+	driftChecksumMismatch = "checksum on the controller does not match the model"
+)
+
+// DriftReconcile compares the AKO created virtualservices and pools on the controller against the models,
+// and republishes the models whose objects were deleted or edited outside of AKO. The drifted objects are
+// reported via the drift API and as events on the AKO configmap. Only the models of the tenant AKO runs in are
+// checked, the ones placed in the tenant of their AviInfraSetting are left out.
+func (c *AviController) DriftReconcile(client *clients.AviClient) {
+	vsCksums := make(map[string]avicache.AviObjCksum)
+	if err := avicache.AviGetAllObjCksums(client, utils.CloudName, "virtualservice", vsCksums); err != nil {
+		utils.AviLog.Warnf("Unable to fetch virtualservices for drift detection: %v", err)
+		return
+	}
+	poolCksums := make(map[string]avicache.AviObjCksum)
+	if err := avicache.AviGetAllObjCksums(client, utils.CloudName, "pool", poolCksums); err != nil {
+		utils.AviLog.Warnf("Unable to fetch pools for drift detection: %v", err)
+		return
+	}
+
+	sharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
+	var driftedObjects []models.DriftedObject
+	for modelName, modelIntf := range objects.SharedAviGraphLister().AviGraphStore.CopyAllObjects() {
+		aviModel, ok := modelIntf.(*nodes.AviObjectGraph)
+		if !ok || aviModel == nil {
+			continue
+		}
+		if tenant, _ := utils.ExtractNamespaceObjectName(modelName); tenant != lib.GetTenant() {
+			continue
+		}
+		modelDrift := detectModelDrift(modelName, aviModel, vsCksums, poolCksums)
+		if len(modelDrift) == 0 {
+			continue
+		}
+		utils.AviLog.Warnf("Drift detected for model %s: %s", modelName, utils.Stringify(modelDrift))
+		driftedObjects = append(driftedObjects, modelDrift...)
+		recordDriftEvent(modelName, len(modelDrift))
+		nodes.PublishKeyToRestLayer(modelName, "driftreconcile", sharedQueue)
+	}
+	models.DriftStatus.UpdateDriftReport(driftedObjects)
+	utils.AviLog.Infof("Drift detection done, %d objects drifted", len(driftedObjects))
+}
+
+// detectModelDrift checks the virtualservices and pools of a model which AKO has already synced to the controller.
+// The cache checksum of a drifted object is invalidated, so that the republished model updates it.
+func detectModelDrift(modelName string, aviModel *nodes.AviObjectGraph, vsCksums, poolCksums map[string]avicache.AviObjCksum) []models.DriftedObject {
+	var driftedObjects []models.DriftedObject
+	aviObjCache := avicache.SharedAviObjCache()
+	var vsNodes []*nodes.AviVsNode
+	for _, vsNode := range aviModel.GetAviVS() {
+		vsNodes = append(vsNodes, vsNode)
+		vsNodes = append(vsNodes, vsNode.SniNodes...)
+	}
+
+	for _, vsNode := range vsNodes {
+		vsKey := avicache.NamespaceName{Namespace: lib.GetTenant(), Name: vsNode.Name}
+		if vsCache, found := aviObjCache.VsCacheMeta.AviCacheGet(vsKey); found {
+			vsCacheObj, ok := vsCache.(*avicache.AviVsCache)
+			if ok {
+				cachedCksum, cachedLastModified := vsCacheObj.GetCksumAndLastModified()
+				reason := driftReason(vsCksums, vsNode.Name, strconv.Itoa(int(vsNode.GetCheckSum())), cachedCksum, cachedLastModified)
+				if reason != "" {
+					vsCacheObj.InvalidateCksum()
+					driftedObjects = append(driftedObjects, models.DriftedObject{
+						Model:      modelName,
+						ObjectType: "virtualservice",
+						Name:       vsNode.Name,
+						Reason:     reason,
+					})
+				}
+			}
+		}
+
+		for _, poolNode := range vsNode.PoolRefs {
+			poolKey := avicache.NamespaceName{Namespace: lib.GetTenant(), Name: poolNode.Name}
+			poolCache, found := aviObjCache.PoolCache.AviCacheGet(poolKey)
+			if !found {
+				continue
+			}
+			poolCacheObj, ok := poolCache.(*avicache.AviPoolCache)
+			if !ok {
+				continue
+			}
+			reason := driftReason(poolCksums, poolNode.Name, strconv.Itoa(int(poolNode.GetCheckSum())), poolCacheObj.CloudConfigCksum, poolCacheObj.LastModified)
+			if reason != "" && aviObjCache.PoolCache.AviCacheInvalidatePool(poolKey, poolCacheObj.CloudConfigCksum) {
+				driftedObjects = append(driftedObjects, models.DriftedObject{
+					Model:      modelName,
+					ObjectType: "pool",
+					Name:       poolNode.Name,
+					Reason:     reason,
+				})
+			}
+		}
+	}
+	return driftedObjects
+}
+
+// driftReason returns why the object on the controller differs from the model, empty if it does not.
+// Objects with a pending update, where the cache does not match the model yet, are left to the rest layer.
+// An edit made outside of AKO leaves the checksum as is, it is caught by the last modified timestamp
+// recorded in the cache when AKO last wrote the object.
+func driftReason(objCksums map[string]avicache.AviObjCksum, name, modelCksum, cachedCksum, cachedLastModified string) string {
+	if cachedCksum != modelCksum {
+		return ""
+	}
+	objCksum, found := objCksums[name]
+	if !found {
+		return driftDeleted
+	}
+	if objCksum.CloudConfigCksum != modelCksum {
+		return driftChecksumMismatch
+	}
+	if cachedLastModified != "" && objCksum.LastModified != "" && objCksum.LastModified != cachedLastModified {
+		return driftModified
+	}
+	return ""
+}
+
+func recordDriftEvent(modelName string, driftedCount int) {
+	recorder := lib.GetEventRecorder()
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      "ConfigMap",
+		Namespace: lib.AviNS,
+		Name:      lib.AviConfigMap,
+	}
+	recorder.Event(ref, corev1.EventTypeWarning, lib.DriftDetected,
+		fmt.Sprintf("%d objects of model %s drifted on the controller, republishing the model", driftedCount, modelName))
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// addHealthChecks adds the checks of the controller to the /healthz and /readyz probes. Only the workers being
+// stuck fails the liveness probe, as restarting AKO does not fix the other checks.
+func (c *AviController) addHealthChecks(aviClientPool *utils.AviRestClientPool) {
+	models.HealthStatus.AddHealthCheck("sync", false, func() error {
+		if lib.DisableSync {
+			return errors.New("sync is disabled")
+		}
+		return nil
+	})
+	models.HealthStatus.AddHealthCheck("informers", false, c.checkInformersSynced)
+	models.HealthStatus.AddHealthCheck("avi_cache", false, func() error {
+		if !models.RestStatus.IsAviCachePopulated() {
+			return errors.New("avi object cache is not populated")
+		}
+		return nil
+	})
+	models.HealthStatus.AddHealthCheck("controller", false, func() error {
+		return checkControllerSession(aviClientPool)
+	})
+	models.HealthStatus.AddHealthCheck("workers", true, checkWorkersProgress)
+}
+
+func (c *AviController) checkInformersSynced() error {
+	informersSynced, ok := c.informersSynced.Load().([]cache.InformerSynced)
+	if !ok {
+		return errors.New("informers are not started")
+	}
+	for _, hasSynced := range informersSynced {
+		if !hasSynced() {
+			return errors.New("informer caches are not synced")
+		}
+	}
+	return nil
+}
+
+// checkControllerSession fails if the last call to the controller timed out, or if none of the controller
+// endpoints passed its last health check.
+func checkControllerSession(aviClientPool *utils.AviRestClientPool) error {
+	if models.RestStatus.GetConnectionStatus() == utils.AVIAPI_DISCONNECTED {
+		return errors.New("connection to the controller timed out")
+	}
+	_, statuses := aviClientPool.Endpoints.GetStatus()
+	var checked bool
+	for _, status := range statuses {
+		if status.LastCheck.IsZero() {
+			continue
+		}
+		if status.Healthy {
+			return nil
+		}
+		checked = true
+	}
+	if checked {
+		return errors.New("none of the controller endpoints is healthy")
+	}
+	return nil
+}
+
+// checkWorkersProgress fails if a worker of the layers is processing the same key for longer than
+// WORKER_STUCK_THRESHOLD.
+func checkWorkersProgress() error {
+	threshold := lib.GetWorkerStuckThreshold()
+	for queueName, queue := range utils.GetSharedWorkQueues() {
+		for _, state := range queue.GetWorkerStates() {
+			if state.Key != "" && time.Since(state.Since) > threshold {
+				return fmt.Errorf("worker %d of %s is processing the key %s since %s", state.WorkerId, queueName,
+					state.Key, state.Since.Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package k8s
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// objectRefs is a set of objects per avi object type, keyed by tenant and name like in the cache.
+type objectRefs map[string]map[avicache.NamespaceName]bool
+
+func (r objectRefs) add(objType, tenant, name string) {
+	if r[objType] == nil {
+		r[objType] = make(map[avicache.NamespaceName]bool)
+	}
+	r[objType][avicache.NamespaceName{Namespace: tenant, Name: name}] = true
+}
+
+func (r objectRefs) addKeys(objType string, keys []avicache.NamespaceName) {
+	for _, key := range keys {
+		r.add(objType, key.Namespace, key.Name)
+	}
+}
+
+// orphanTracker remembers since when an object has been orphaned, across the garbage collection runs.
+var orphanTracker = struct {
+	sync.Mutex
+	firstSeen map[string]time.Time
+}{firstSeen: make(map[string]time.Time)}
+
+// RunOrphanGC runs the orphan garbage collector every interval, until the stop channel is closed.
+func (c *AviController) RunOrphanGC(interval time.Duration, stopCh <-chan struct{}) {
+	utils.AviLog.Infof("Started the orphan garbage collector, running every %v", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			utils.AviLog.Infof("Shutting down the orphan garbage collector")
+			return
+		case <-ticker.C:
+			c.OrphanGC()
+		}
+	}
+}
+
+// OrphanGC deletes the AKO created objects on the controller which are not reachable from any model. The
+// bootup cleanup only covers what is stale at the time AKO starts, objects can also be left behind while
+// AKO runs, for example when it restarts between creating a pool and updating the virtualservice. An object
+// is deleted only once it has been orphaned for the grace period, and never in report only mode. The outcome
+// of the run is reported via the orphan gc API.
+func (c *AviController) OrphanGC() {
+	reportOnly := lib.IsOrphanGCReportOnly() || c.DisableSync
+	gracePeriod := lib.GetOrphanGCGracePeriod()
+	aviObjCache := avicache.SharedAviObjCache()
+	modelRefs := modelReferencedObjects()
+
+	// The objects still referred to by a virtualservice are either in use or removed along with it.
+	vsRefs := make(objectRefs)
+	var orphanVSs []avicache.NamespaceName
+	for _, vsKey := range aviObjCache.VsCacheMeta.AviGetAllKeys() {
+		vsIntf, _ := aviObjCache.VsCacheMeta.AviCacheGet(vsKey)
+		vsCacheObj, ok := vsIntf.(*avicache.AviVsCache)
+		if !ok || vsKey.Name == lib.DummyVSForStaleData || vsKey.Name == lib.DummyVSForOrphanGC {
+			continue
+		}
+		vsRefs.addKeys("poolgroup", vsCacheObj.PGKeyCollection)
+		vsRefs.addKeys("vsvip", vsCacheObj.VSVipKeyCollection)
+		vsRefs.addKeys("pool", vsCacheObj.PoolKeyCollection)
+		vsRefs.addKeys("vsdatascriptset", vsCacheObj.DSKeyCollection)
+		vsRefs.addKeys("httppolicyset", vsCacheObj.HTTPKeyCollection)
+		vsRefs.addKeys("sslkeyandcertificate", vsCacheObj.SSLKeyCertCollection)
+		vsRefs.addKeys("l4policyset", vsCacheObj.L4PolicyCollection)
+		// The child virtualservices are removed by the rest layer when their parent is synced or deleted.
+		if vsCacheObj.ParentVSRef.Name != "" || vsCacheObj.PassthroughParentRef.Name != "" {
+			continue
+		}
+		if !modelRefs["virtualservice"][vsKey] {
+			orphanVSs = append(orphanVSs, vsKey)
+		}
+	}
+
+	orphanCaches := []struct {
+		objType  string
+		objCache *avicache.AviCache
+	}{
+		{"pool", aviObjCache.PoolCache},
+		{"poolgroup", aviObjCache.PgCache},
+		{"vsvip", aviObjCache.VSVIPCache},
+		{"httppolicyset", aviObjCache.HTTPPolicyCache},
+		{"l4policyset", aviObjCache.L4PolicyCache},
+		{"sslkeyandcertificate", aviObjCache.SSLKeyCache},
+		{"vsdatascriptset", aviObjCache.DSCache},
+	}
+	var orphanedObjects []models.OrphanedObject
+	var deletedVSs []avicache.NamespaceName
+	// the stale objects of each tenant are removed through a dummy virtualservice of the tenant
+	staleObjects := make(map[string]map[string][]avicache.NamespaceName)
+
+	now := time.Now()
+	orphanTracker.Lock()
+	firstSeen := make(map[string]time.Time)
+	orphanAction := func(objType string, objKey avicache.NamespaceName, uuid string) string {
+		orphanID := objType + "/" + objKey.Namespace + "/" + objKey.Name
+		since, found := orphanTracker.firstSeen[orphanID]
+		if !found {
+			since = now
+		}
+		firstSeen[orphanID] = since
+		action := models.OrphanDeleted
+		if now.Sub(since) < gracePeriod {
+			action = models.OrphanInGracePeriod
+		} else if reportOnly {
+			action = models.OrphanWouldDelete
+		}
+		orphanedObjects = append(orphanedObjects, models.OrphanedObject{
+			ObjectType:    objType,
+			Tenant:        objKey.Namespace,
+			Name:          objKey.Name,
+			Uuid:          uuid,
+			OrphanedSince: since,
+			Action:        action,
+		})
+		return action
+	}
+	for _, vsKey := range orphanVSs {
+		vsIntf, _ := aviObjCache.VsCacheMeta.AviCacheGet(vsKey)
+		if orphanAction("virtualservice", vsKey, cachedUuid(vsIntf)) == models.OrphanDeleted {
+			deletedVSs = append(deletedVSs, vsKey)
+		}
+	}
+	for _, orphanCache := range orphanCaches {
+		for _, objKey := range orphanCache.objCache.AviGetAllKeys() {
+			if modelRefs[orphanCache.objType][objKey] || vsRefs[orphanCache.objType][objKey] {
+				continue
+			}
+			objIntf, _ := orphanCache.objCache.AviCacheGet(objKey)
+			if orphanAction(orphanCache.objType, objKey, cachedUuid(objIntf)) == models.OrphanDeleted {
+				if staleObjects[objKey.Namespace] == nil {
+					staleObjects[objKey.Namespace] = make(map[string][]avicache.NamespaceName)
+				}
+				tenantObjects := staleObjects[objKey.Namespace]
+				tenantObjects[orphanCache.objType] = append(tenantObjects[orphanCache.objType], objKey)
+			}
+		}
+	}
+	orphanTracker.firstSeen = firstSeen
+	orphanTracker.Unlock()
+
+	sort.Slice(orphanedObjects, func(i, j int) bool {
+		if orphanedObjects[i].ObjectType != orphanedObjects[j].ObjectType {
+			return orphanedObjects[i].ObjectType < orphanedObjects[j].ObjectType
+		}
+		if orphanedObjects[i].Tenant != orphanedObjects[j].Tenant {
+			return orphanedObjects[i].Tenant < orphanedObjects[j].Tenant
+		}
+		return orphanedObjects[i].Name < orphanedObjects[j].Name
+	})
+	models.OrphanGCStatus.UpdateOrphanGCReport(orphanedObjects, reportOnly)
+	staleCount := 0
+	for _, tenantObjects := range staleObjects {
+		staleCount += countKeys(tenantObjects)
+	}
+	utils.AviLog.Infof("Orphan garbage collection done, %d objects orphaned, %d virtualservices and %d objects to delete",
+		len(orphanedObjects), len(deletedVSs), staleCount)
+
+	deleteOrphans(deletedVSs, staleObjects)
+}
+
+// deleteOrphans has the rest layer remove the orphaned virtualservices along with the objects they refer to, and
+// the remaining orphaned objects through a dummy virtualservice per tenant, the same way stale objects are removed
+// at bootup. The keys are published to the rest layer, so that the objects are deleted in their tenant by the
+// worker owning the virtualservice, and a failed delete is retried like any other model.
+func deleteOrphans(deletedVSs []avicache.NamespaceName, staleObjects map[string]map[string][]avicache.NamespaceName) {
+	sharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
+	for _, vsKey := range deletedVSs {
+		utils.AviLog.Infof("Deleting orphaned virtualservice %s in tenant %s", vsKey.Name, vsKey.Namespace)
+		nodes.PublishKeyToRestLayer(lib.GetModelName(vsKey.Namespace, vsKey.Name), "orphangc", sharedQueue)
+	}
+
+	for tenant, tenantObjects := range staleObjects {
+		vsKey := avicache.NamespaceName{Namespace: tenant, Name: lib.DummyVSForOrphanGC}
+		avicache.SharedAviObjCache().VsCacheMeta.AviCacheAdd(vsKey, &avicache.AviVsCache{
+			Name:                 lib.DummyVSForOrphanGC,
+			Tenant:               tenant,
+			VSVipKeyCollection:   tenantObjects["vsvip"],
+			HTTPKeyCollection:    tenantObjects["httppolicyset"],
+			DSKeyCollection:      tenantObjects["vsdatascriptset"],
+			SSLKeyCertCollection: tenantObjects["sslkeyandcertificate"],
+			PGKeyCollection:      tenantObjects["poolgroup"],
+			PoolKeyCollection:    tenantObjects["pool"],
+			L4PolicyCollection:   tenantObjects["l4policyset"],
+		})
+		utils.AviLog.Infof("Deleting orphaned objects of tenant %s %s", tenant, utils.Stringify(tenantObjects))
+		nodes.PublishKeyToRestLayer(lib.GetModelName(vsKey.Namespace, vsKey.Name), "orphangc", sharedQueue)
+	}
+}
+
+// modelReferencedObjects returns all the objects referred to by the current models, in the tenant of their model.
+func modelReferencedObjects() objectRefs {
+	refs := make(objectRefs)
+	for modelName, modelIntf := range objects.SharedAviGraphLister().AviGraphStore.CopyAllObjects() {
+		aviModel, ok := modelIntf.(*nodes.AviObjectGraph)
+		if !ok || aviModel == nil {
+			continue
+		}
+		tenant, _ := utils.ExtractNamespaceObjectName(modelName)
+		if tenant == "" {
+			tenant = lib.GetTenant()
+		}
+		for _, vsNode := range aviModel.GetAviVS() {
+			addVsNodeRefs(vsNode, tenant, refs)
+		}
+	}
+	return refs
+}
+
+// addVsNodeRefs adds the objects of the VS, which are all placed in the tenant of the VS
+func addVsNodeRefs(vsNode *nodes.AviVsNode, tenant string, refs objectRefs) {
+	refs.add("virtualservice", tenant, vsNode.Name)
+	for _, pool := range vsNode.PoolRefs {
+		refs.add("pool", tenant, pool.Name)
+	}
+	for _, pg := range vsNode.PoolGroupRefs {
+		refs.add("poolgroup", tenant, pg.Name)
+	}
+	for _, pg := range vsNode.TCPPoolGroupRefs {
+		refs.add("poolgroup", tenant, pg.Name)
+	}
+	for _, vsvip := range vsNode.VSVIPRefs {
+		refs.add("vsvip", tenant, vsvip.Name)
+	}
+	for _, httpPolicy := range vsNode.HttpPolicyRefs {
+		refs.add("httppolicyset", tenant, httpPolicy.Name)
+	}
+	for _, l4Policy := range vsNode.L4PolicyRefs {
+		refs.add("l4policyset", tenant, l4Policy.Name)
+	}
+	for _, sslKeyCert := range vsNode.SSLKeyCertRefs {
+		refs.add("sslkeyandcertificate", tenant, sslKeyCert.Name)
+	}
+	for _, caCert := range vsNode.CACertRefs {
+		refs.add("sslkeyandcertificate", tenant, caCert.Name)
+	}
+	for _, ds := range vsNode.HTTPDSrefs {
+		refs.add("vsdatascriptset", tenant, ds.Name)
+	}
+	for _, sniNode := range vsNode.SniNodes {
+		addVsNodeRefs(sniNode, tenant, refs)
+	}
+	for _, passthroughChild := range vsNode.PassthroughChildNodes {
+		addVsNodeRefs(passthroughChild, tenant, refs)
+	}
+}
+
+func cachedUuid(objIntf interface{}) string {
+	switch obj := objIntf.(type) {
+	case *avicache.AviVsCache:
+		return obj.Uuid
+	case *avicache.AviPoolCache:
+		return obj.Uuid
+	case *avicache.AviPGCache:
+		return obj.Uuid
+	case *avicache.AviVSVIPCache:
+		return obj.Uuid
+	case *avicache.AviHTTPPolicyCache:
+		return obj.Uuid
+	case *avicache.AviL4PolicyCache:
+		return obj.Uuid
+	case *avicache.AviSSLCache:
+		return obj.Uuid
+	case *avicache.AviDSCache:
+		return obj.Uuid
+	}
+	return ""
+}
+
+func countKeys(staleObjects map[string][]avicache.NamespaceName) int {
+	count := 0
+	for _, keys := range staleObjects {
+		count += len(keys)
+	}
+	return count
+}
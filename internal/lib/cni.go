@@ -49,8 +49,8 @@ var (
 
 // NewDynamicClientSet initializes dynamic client set instance
 func NewDynamicClientSet(config *rest.Config) (dynamic.Interface, error) {
-	// do not instantiate the dynamic client set if the CNI being used is NOT calico
-	if GetCNIPlugin() != CALICO_CNI && GetCNIPlugin() != OPENSHIFT_CNI {
+	// do not instantiate the dynamic client set if the CNI being used is NOT calico, unless the IngressClasses are watched
+	if GetCNIPlugin() != CALICO_CNI && GetCNIPlugin() != OPENSHIFT_CNI && !IsIngressClassEnabled() {
 		return nil, nil
 	}
 
@@ -78,6 +78,9 @@ func GetDynamicClientSet() dynamic.Interface {
 type DynamicInformers struct {
 	CalicoBlockAffinityInformer informers.GenericInformer
 	HostSubnetInformer          informers.GenericInformer
+	IngressClassInformer        informers.GenericInformer
+	// NetworkingIngressInformer holds the ingresses as unstructured objects, to read their ingressClassName
+	NetworkingIngressInformer informers.GenericInformer
 }
 
 // NewDynamicInformers initializes the DynamicInformers struct
@@ -93,6 +96,11 @@ func NewDynamicInformers(client dynamic.Interface) *DynamicInformers {
 	default:
 		utils.AviLog.Infof("Skipped iniializing dynamic informers %s \n", GetCNIPlugin())
 	}
+	if IsIngressClassEnabled() && client != nil {
+		informers.IngressClassInformer = f.ForResource(IngressClassGVR)
+		ingressFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, GetNamespaceToSync(), nil)
+		informers.NetworkingIngressInformer = ingressFactory.ForResource(utils.NetworkingIngress)
+	}
 
 	dynamicInformerInstance = informers
 	return dynamicInformerInstance
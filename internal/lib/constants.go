@@ -33,6 +33,24 @@ const (
 	NODE_NETWORK_MAX_ENTRIES                   = 5
 	L7_SHARD_SCHEME                            = "L7_SHARD_SCHEME"
 	DEFAULT_DOMAIN                             = "DEFAULT_DOMAIN"
+	WILDCARD_ROUTE_NAMESPACES                  = "WILDCARD_ROUTE_NAMESPACES"
+	ROUTE_SELECTOR                             = "ROUTE_SELECTOR"
+	NAMESPACE_SELECTOR                         = "NAMESPACE_SELECTOR"
+	NAMESPACE_LIST                             = "NAMESPACE_LIST"
+	HOSTNAME_OWNERSHIP_POLICY                  = "HOSTNAME_OWNERSHIP_POLICY"
+	DOMAIN_NAMESPACE_LIST                      = "DOMAIN_NAMESPACE_LIST"
+	DRIFT_RECONCILE                            = "DRIFT_RECONCILE"
+	CACHE_SNAPSHOT                             = "CACHE_SNAPSHOT"
+	REST_MACRO_BATCH                           = "REST_MACRO_BATCH"
+	ORPHAN_GC_INTERVAL                         = "ORPHAN_GC_INTERVAL"
+	ORPHAN_GC_GRACE_PERIOD                     = "ORPHAN_GC_GRACE_PERIOD"
+	ORPHAN_GC_REPORT_ONLY                      = "ORPHAN_GC_REPORT_ONLY"
+	CTRL_HEALTH_CHECK_INTERVAL                 = "CTRL_HEALTH_CHECK_INTERVAL"
+	WORKER_STUCK_THRESHOLD                     = "WORKER_STUCK_THRESHOLD"
+	CTRL_SECRET                                = "CTRL_SECRET"
+	RETRY_POLICY                               = "RETRY_POLICY"
+	PERSIST_SHARD_ASSIGNMENT                   = "PERSIST_SHARD_ASSIGNMENT"
+	SHARD_VS_MAX_SNI_CHILDREN                  = "SHARD_VS_MAX_SNI_CHILDREN"
 	ADVANCED_L4                                = "ADVANCED_L4"
 	CLUSTER_NAME                               = "CLUSTER_NAME"
 	CLOUD_VCENTER                              = "CLOUD_VCENTER"
@@ -47,12 +65,20 @@ const (
 	NOT_FOUND                                  = "HTTP code: 404"
 	STATUS_REDIRECT                            = "HTTP_REDIRECT_STATUS_CODE_302"
 	SLOW_SYNC_TIME                             = 120
+	CACHE_POPULATE_PAGE_FANOUT                 = 4
+	DEFAULT_ORPHAN_GC_GRACE_PERIOD             = 600
+	DEFAULT_CTRL_HEALTH_CHECK_INTERVAL         = 10
+	DEFAULT_WORKER_STUCK_THRESHOLD             = 300
 	LOG_LEVEL                                  = "logLevel"
+	SUBSYSTEM_LOG_LEVELS                       = "subsystemLogLevels"
+	ENABLE_DIAGNOSTICS                         = "enableDiagnostics"
 	SERVICE_TYPE                               = "SERVICE_TYPE"
 	NODE_PORT                                  = "NodePort"
 	NODE_KEY                                   = "NODE_KEY"
 	NODE_VALUE                                 = "NODE_VALUE"
 	ShardVSPrefix                              = "Shared-L7"
+	DedicatedVSPrefix                          = "Dedicated-L7-"
+	DedicatedVSAnnotation                      = "ako.vmware.com/dedicated-vs"
 	PassthroughPrefix                          = "Shared-Passthrough-"
 	PolicyAllow                                = "ALLOW"
 	PolicyNone                                 = "NONE"
@@ -67,6 +93,11 @@ const (
 	VSVIPDELCTRLVER                            = "20.1.1"
 	HostRule                                   = "HostRule"
 	HTTPRule                                   = "HTTPRule"
+	HostnameClaim                              = "HostnameClaim"
+	IngressClass                               = "IngressClass"
+	AviInfraSetting                            = "AviInfraSetting"
+	AviIngressController                       = "ako.vmware.com/avi-lb"
+	DefaultIngressClassAnnotation              = "ingressclass.kubernetes.io/is-default-class"
 	DummySecret                                = "@avisslkeycertrefdummy"
 	StatusRejected                             = "Rejected"
 	StatusAccepted                             = "Accepted"
@@ -78,11 +109,40 @@ const (
 	Gateway                                    = "Gateway"
 	GatewayClass                               = "GatewayClass"
 	DuplicateBackends                          = "MultipleBackendsWithSameServiceError"
+	WildcardPolicyNotAllowed                   = "WildcardPolicyNotAllowed"
+	WildcardHostPrefix                         = "*."
 	GatewayNameLabelKey                        = "service.route.lbapi.run.tanzu.vmware.com/gateway-name"
 	GatewayNamespaceLabelKey                   = "service.route.lbapi.run.tanzu.vmware.com/gateway-namespace"
 	GatewayTypeLabelKey                        = "service.route.lbapi.run.tanzu.vmware.com/type"
 	AviGatewayController                       = "lbapi.run.tanzu.vmware.com/avi-lb"
 	DummyVSForStaleData                        = "DummyVSForStaleData"
+	DummyVSForOrphanGC                         = "DummyVSForOrphanGC"
+	ClientCertModeRequest                      = "request"
+	ClientCertModeRequire                      = "require"
+	ClientCACertSecretKey                      = "ca.crt"
+	ClientCRLSecretKey                         = "ca.crl"
+	SSLClientCertificateRequest                = "SSL_CLIENT_CERTIFICATE_REQUEST"
+	SSLClientCertificateRequire                = "SSL_CLIENT_CERTIFICATE_REQUIRE"
+	SSLClientSubjectVar                        = "HTTP_POLICY_VAR_SSL_CLIENT_SUBJECT"
+	DefaultClientSubjectHeader                 = "X-SSL-Client-Subject"
+	DestinationCACertSecretKey                 = "ca.crt"
+	HostnameOwnershipFirstClaim                = "FirstClaim"
+	HostnameOwnershipNamespaceAllowList        = "NamespaceAllowList"
+	HostnameOwnershipHostnameClaim             = "HostnameClaim"
+	HostAlreadyClaimed                         = "HostAlreadyClaimed"
+	AKOEventComponent                          = "avi-kubernetes-operator"
+	DriftDetected                              = "DriftDetected"
+	RetryExhausted                             = "RetryExhausted"
+	ValidationFailed                           = "ValidationFailed"
+	ValidationSucceeded                        = "ValidationSucceeded"
+	CacheSnapshotStorePVC                      = "PVC"
+	CacheSnapshotStoreConfigMap                = "ConfigMap"
+	CacheSnapshotConfigMap                     = "avi-k8s-cache-snapshot"
+	CacheSnapshotKey                           = "snapshot"
+	CacheSnapshotFile                          = "ako-cache-snapshot.json"
+	ShardAssignmentConfigMap                   = "avi-k8s-shard-assignment"
+	ShardAssignmentKey                         = "assignment"
+	ShardMigrated                              = "ShardMigrated"
 )
 
 const (
@@ -109,4 +169,21 @@ const (
 	end
 	avi.l4.ds_done()
 	avi_tls = nil`
+
+	// Used in place of utils.HTTP_DS_SCRIPT on shards that carry insecure wildcard hosts,
+	// an exact host on the shard always takes precedence over the wildcard domain.
+	WildcardHTTPDatascript = `host = avi.http.get_host_tokens(1)
+path = avi.http.get_path_tokens(1)
+domain = avi.http.get_host_tokens(2)
+wildcards = {WILDCARD_DOMAINS}
+exacts = {EXACT_HOSTS}
+if host and domain and wildcards[string.lower(domain)] and not exacts[string.lower(host)] then
+host = "*."..domain
+end
+if host and path then
+lbl = host.."/"..path
+else
+lbl = host.."/"
+end
+avi.poolgroup.select("POOLGROUP", string.lower(lbl) )`
 )
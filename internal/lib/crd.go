@@ -15,8 +15,12 @@
 package lib
 
 import (
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
 	akocrd "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/client/clientset/versioned"
 	akoinformer "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/client/informers/externalversions/ako/v1alpha1"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"k8s.io/client-go/kubernetes"
 )
 
 var CRDClientset akocrd.Interface
@@ -33,8 +37,10 @@ func GetCRDClientset() akocrd.Interface {
 var CRDInformers *AKOCrdInformers
 
 type AKOCrdInformers struct {
-	HostRuleInformer akoinformer.HostRuleInformer
-	HTTPRuleInformer akoinformer.HTTPRuleInformer
+	HostRuleInformer        akoinformer.HostRuleInformer
+	HTTPRuleInformer        akoinformer.HTTPRuleInformer
+	HostnameClaimInformer   akoinformer.HostnameClaimInformer
+	AviInfraSettingInformer akoinformer.AviInfraSettingInformer
 }
 
 func SetCRDInformers(c *AKOCrdInformers) {
@@ -44,3 +50,38 @@ func SetCRDInformers(c *AKOCrdInformers) {
 func GetCRDInformers() *AKOCrdInformers {
 	return CRDInformers
 }
+
+var hostnameClaimEnabled, aviInfraSettingEnabled bool
+
+// SetCRDsEnabled looks up the AKO CRDs in the api server. helm upgrade does not install the CRDs added to the
+// chart since the first install, the informers of the CRDs not found are not run.
+func SetCRDsEnabled(kc kubernetes.Interface) {
+	hostnameClaimEnabled, aviInfraSettingEnabled = false, false
+	resources, err := kc.Discovery().ServerResourcesForGroupVersion(akov1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		utils.AviLog.Warnf("AKO CRDs not found: %v", err)
+		return
+	}
+	for _, resource := range resources.APIResources {
+		switch resource.Name {
+		case "hostnameclaims":
+			hostnameClaimEnabled = true
+		case "aviinfrasettings":
+			aviInfraSettingEnabled = true
+		}
+	}
+	utils.AviLog.Infof("HostnameClaim resource enabled: %v, AviInfraSetting resource enabled: %v", hostnameClaimEnabled, aviInfraSettingEnabled)
+	if !hostnameClaimEnabled && GetHostnameOwnershipPolicy() == HostnameOwnershipHostnameClaim {
+		utils.AviLog.Warnf("The HostnameClaim CRD is not installed, no host can be claimed with the %s policy", HostnameOwnershipHostnameClaim)
+	}
+}
+
+// IsHostnameClaimEnabled returns true if the HostnameClaim CRD is installed
+func IsHostnameClaimEnabled() bool {
+	return hostnameClaimEnabled
+}
+
+// IsAviInfraSettingEnabled returns true if the AviInfraSetting CRD is installed
+func IsAviInfraSettingEnabled() bool {
+	return aviInfraSettingEnabled
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package lib
+
+import (
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+var ingressClassEnabled bool
+
+// IngressClassGVR : networking.k8s.io IngressClass resource identifier
+var IngressClassGVR = schema.GroupVersionResource{
+	Group:    "networking.k8s.io",
+	Version:  "v1beta1",
+	Resource: "ingressclasses",
+}
+
+// SetIngressClassEnabled looks up the IngressClass resource in the api server. The IngressClasses and the
+// ingressClassName of the ingresses are only honoured on the clusters serving them, the other ones keep
+// selecting the ingresses on the ingress class annotation.
+func SetIngressClassEnabled(kc kubernetes.Interface) {
+	ingressClassEnabled = false
+	resources, err := kc.Discovery().ServerResourcesForGroupVersion(IngressClassGVR.GroupVersion().String())
+	if err != nil {
+		utils.AviLog.Infof("IngressClass resource not found, selecting the ingresses on the %s annotation: %v", INGRESS_CLASS_ANNOT, err)
+		return
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == IngressClassGVR.Resource {
+			ingressClassEnabled = true
+			break
+		}
+	}
+	utils.AviLog.Infof("IngressClass resource enabled: %v", ingressClassEnabled)
+}
+
+func IsIngressClassEnabled() bool {
+	return ingressClassEnabled
+}
+
+// GetIngressClassName returns the class of the ingress, from its spec.ingressClassName or else from the ingress
+// class annotation. The typed ingresses of this client version do not carry the ingressClassName, it is read from
+// the dynamic ingress informer.
+func GetIngressClassName(ingress *networking.Ingress) string {
+	if dynamicInformerInstance != nil && dynamicInformerInstance.NetworkingIngressInformer != nil {
+		obj, err := dynamicInformerInstance.NetworkingIngressInformer.Lister().ByNamespace(ingress.Namespace).Get(ingress.Name)
+		if err == nil {
+			if className := GetUnstructuredIngressClassName(obj); className != "" {
+				return className
+			}
+		}
+	}
+	return ingress.GetAnnotations()[INGRESS_CLASS_ANNOT]
+}
+
+// GetUnstructuredIngressClassName returns the spec.ingressClassName of an ingress from the dynamic informer
+func GetUnstructuredIngressClassName(obj interface{}) string {
+	ingress, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return ""
+	}
+	className, _, _ := unstructured.NestedString(ingress.UnstructuredContent(), "spec", "ingressClassName")
+	return className
+}
+
+// GetIngressClass returns the IngressClass of the name, if it exists
+func GetIngressClass(name string) (*unstructured.Unstructured, bool) {
+	if dynamicInformerInstance == nil || dynamicInformerInstance.IngressClassInformer == nil {
+		return nil, false
+	}
+	obj, err := dynamicInformerInstance.IngressClassInformer.Lister().Get(name)
+	if err != nil {
+		return nil, false
+	}
+	ingClass, ok := obj.(*unstructured.Unstructured)
+	return ingClass, ok
+}
+
+// GetDefaultIngressClass returns the IngressClass annotated as the default one, which the ingresses without a
+// class belong to.
+func GetDefaultIngressClass() (*unstructured.Unstructured, bool) {
+	if dynamicInformerInstance == nil || dynamicInformerInstance.IngressClassInformer == nil {
+		return nil, false
+	}
+	objs, err := dynamicInformerInstance.IngressClassInformer.Lister().List(labels.Everything())
+	if err != nil {
+		utils.AviLog.Warnf("Unable to list the IngressClasses: %v", err)
+		return nil, false
+	}
+	for _, obj := range objs {
+		ingClass, ok := obj.(*unstructured.Unstructured)
+		if ok && ingClass.GetAnnotations()[DefaultIngressClassAnnotation] == "true" {
+			return ingClass, true
+		}
+	}
+	return nil, false
+}
+
+// IsAviIngressClass returns true if AKO is the controller of the IngressClass
+func IsAviIngressClass(ingClass *unstructured.Unstructured) bool {
+	controller, _, _ := unstructured.NestedString(ingClass.UnstructuredContent(), "spec", "controller")
+	return controller == AviIngressController
+}
+
+// GetIngressClassInfraSetting returns the name of the AviInfraSetting the parameters of the IngressClass refer to
+func GetIngressClassInfraSetting(ingClass *unstructured.Unstructured) string {
+	params, found, _ := unstructured.NestedStringMap(ingClass.UnstructuredContent(), "spec", "parameters")
+	if !found || params["apiGroup"] != akov1alpha1.SchemeGroupVersion.Group || params["kind"] != AviInfraSetting {
+		return ""
+	}
+	return params["name"]
+}
@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
@@ -29,7 +30,9 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	oshiftclient "github.com/openshift/client-go/route/clientset/versioned"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 )
 
 var IngressApiMap = map[string]string{
@@ -76,6 +79,18 @@ func GetAKOUser() string {
 	return AKOUser
 }
 
+var AKOEventRecorder record.EventRecorder
+
+func SetEventRecorder(recorder record.EventRecorder) {
+	AKOEventRecorder = recorder
+}
+
+// GetEventRecorder returns the recorder for k8s events raised on the objects handled by AKO,
+// nil until the event handlers are set up.
+func GetEventRecorder() record.EventRecorder {
+	return AKOEventRecorder
+}
+
 func GetshardSize() uint32 {
 	if GetAdvancedL4() {
 		// shard to 8 go routines in the REST layer
@@ -90,6 +105,12 @@ func GetshardSize() uint32 {
 	}
 }
 
+// GetShardSizeByName returns the number of shards for a shard size name such as SMALL, MEDIUM or LARGE
+func GetShardSizeByName(name string) (uint32, bool) {
+	shardSize, ok := shardSizeMap[name]
+	return shardSize, ok
+}
+
 func GetModelName(namespace, objectName string) string {
 	return namespace + "/" + objectName
 }
@@ -149,6 +170,16 @@ func GetSniNodeName(ingName, namespace, secret string, sniHostName ...string) st
 	return NamePrefix + ingName + "-" + namespace + "-" + secret
 }
 
+// GetDedicatedVSName returns the name of the parent VS built for a host which opted out of sharding
+func GetDedicatedVSName(host string) string {
+	return NamePrefix + DedicatedVSPrefix + host
+}
+
+// GetInfraSettingShardVSName returns the name of a shard VS of the hosts placed by an AviInfraSetting
+func GetInfraSettingShardVSName(infraSetting string, shard uint32) string {
+	return NamePrefix + infraSetting + "-" + ShardVSPrefix + "-" + fmt.Sprint(shard)
+}
+
 func GetSniPoolName(ingName, namespace, host, path string, args ...string) string {
 	path = strings.Replace(path, "/", "_", 1)
 	poolName := NamePrefix + namespace + "-" + host + path + "-" + ingName
@@ -180,6 +211,18 @@ func GetCACertNodeName(keycertname string) string {
 	return keycertname + "-cacert"
 }
 
+func GetClientAuthPkiProfileName(sniNodeName string) string {
+	return sniNodeName + "-client-pkiprofile"
+}
+
+func GetClientAuthAppProfileName(sniNodeName string) string {
+	return sniNodeName + "-client-appprofile"
+}
+
+func GetPoolPKIProfileName(poolName string) string {
+	return poolName + "-pkiprofile"
+}
+
 func GetPoolTLSKeyCertNodeName(httprule, pathPrefix string) string {
 	if pathPrefix == "/" {
 		pathPrefix = ""
@@ -346,6 +389,194 @@ func GetAdvancedL4() bool {
 	return false
 }
 
+// IsDriftReconcileEnabled returns true if the AKO created objects on the controller should be
+// checked for out of band changes during the periodic full sync.
+func IsDriftReconcileEnabled() bool {
+	if os.Getenv(DRIFT_RECONCILE) == "true" {
+		return true
+	}
+	return false
+}
+
+// GetCacheSnapshotStore returns where the snapshot of the Avi object cache is persisted, either on the
+// persistent volume claim used for the logs or in a ConfigMap. Snapshots are disabled when empty.
+func GetCacheSnapshotStore() string {
+	store := os.Getenv(CACHE_SNAPSHOT)
+	switch store {
+	case CacheSnapshotStoreConfigMap:
+		return store
+	case CacheSnapshotStorePVC:
+		if os.Getenv("USE_PVC") != "true" {
+			utils.AviLog.Warnf("Cache snapshot store is set to PVC, but no persistent volume claim is used, disabling cache snapshots")
+			return ""
+		}
+		return store
+	case "":
+	default:
+		utils.AviLog.Warnf("Invalid cache snapshot store %s, disabling cache snapshots", store)
+	}
+	return ""
+}
+
+// IsRestMacroBatchEnabled returns true if the create and update calls for a virtualservice and the objects
+// it refers to should be sent to the controller as a single macro.
+func IsRestMacroBatchEnabled() bool {
+	if os.Getenv(REST_MACRO_BATCH) == "true" {
+		return true
+	}
+	return false
+}
+
+// GetOrphanGCInterval returns how often the controller is scanned for AKO created objects which are no
+// longer referenced from any model. The orphan garbage collector is disabled when not set.
+func GetOrphanGCInterval() time.Duration {
+	interval := os.Getenv(ORPHAN_GC_INTERVAL)
+	if interval == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(interval)
+	if err != nil || seconds < 0 {
+		utils.AviLog.Warnf("Invalid orphan gc interval %s, disabling the orphan garbage collector", interval)
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetOrphanGCGracePeriod returns how long an object has to stay orphaned before it is deleted, this leaves
+// room for the objects created ahead of the virtualservice referring to them.
+func GetOrphanGCGracePeriod() time.Duration {
+	gracePeriod := os.Getenv(ORPHAN_GC_GRACE_PERIOD)
+	if gracePeriod == "" {
+		return DEFAULT_ORPHAN_GC_GRACE_PERIOD * time.Second
+	}
+	seconds, err := strconv.Atoi(gracePeriod)
+	if err != nil || seconds < 0 {
+		utils.AviLog.Warnf("Invalid orphan gc grace period %s, using %d seconds", gracePeriod, DEFAULT_ORPHAN_GC_GRACE_PERIOD)
+		return DEFAULT_ORPHAN_GC_GRACE_PERIOD * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetControllerHealthCheckInterval returns how often the controller endpoints are health checked, when more
+// than one is set in CTRL_IPADDRESS.
+func GetControllerHealthCheckInterval() time.Duration {
+	interval := os.Getenv(CTRL_HEALTH_CHECK_INTERVAL)
+	if interval == "" {
+		return DEFAULT_CTRL_HEALTH_CHECK_INTERVAL * time.Second
+	}
+	seconds, err := strconv.Atoi(interval)
+	if err != nil || seconds <= 0 {
+		utils.AviLog.Warnf("Invalid controller health check interval %s, using %d seconds", interval, DEFAULT_CTRL_HEALTH_CHECK_INTERVAL)
+		return DEFAULT_CTRL_HEALTH_CHECK_INTERVAL * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetWorkerStuckThreshold returns how long a worker of the layers can process a key before it is considered
+// stuck, and AKO is reported as not live.
+func GetWorkerStuckThreshold() time.Duration {
+	threshold := os.Getenv(WORKER_STUCK_THRESHOLD)
+	if threshold == "" {
+		return DEFAULT_WORKER_STUCK_THRESHOLD * time.Second
+	}
+	seconds, err := strconv.Atoi(threshold)
+	if err != nil || seconds <= 0 {
+		utils.AviLog.Warnf("Invalid worker stuck threshold %s, using %d seconds", threshold, DEFAULT_WORKER_STUCK_THRESHOLD)
+		return DEFAULT_WORKER_STUCK_THRESHOLD * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// IsShardAssignmentPersisted returns true if the shard virtualservice of each host is kept in a configmap,
+// instead of being derived from the hash of the host every time. It applies to the hostname shard scheme only.
+func IsShardAssignmentPersisted() bool {
+	if os.Getenv(PERSIST_SHARD_ASSIGNMENT) != "true" || GetAdvancedL4() {
+		return false
+	}
+	return GetShardScheme() == HOSTNAME_SHARD_SCHEME
+}
+
+// GetShardVSMaxSNIChildren returns how many SNI children a shard virtualservice takes before new hosts are
+// placed on the least loaded shard instead, 0 leaves the shards unbounded.
+func GetShardVSMaxSNIChildren() int {
+	maxChildren := os.Getenv(SHARD_VS_MAX_SNI_CHILDREN)
+	if maxChildren == "" {
+		return 0
+	}
+	num, err := strconv.Atoi(maxChildren)
+	if err != nil || num < 0 {
+		utils.AviLog.Warnf("Invalid shard vs max sni children %s, leaving the shards unbounded", maxChildren)
+		return 0
+	}
+	return num
+}
+
+// GetControllerSecretName returns the secret in the avi-system namespace holding the controller credentials
+func GetControllerSecretName() string {
+	return os.Getenv(CTRL_SECRET)
+}
+
+func IsOrphanGCReportOnly() bool {
+	if os.Getenv(ORPHAN_GC_REPORT_ONLY) == "true" {
+		return true
+	}
+	return false
+}
+
+// GetHostnameOwnershipPolicy returns the policy deciding which namespaces may publish a host,
+// an empty value lets any namespace publish any host.
+func GetHostnameOwnershipPolicy() string {
+	policy := os.Getenv(HOSTNAME_OWNERSHIP_POLICY)
+	switch policy {
+	case HostnameOwnershipFirstClaim, HostnameOwnershipNamespaceAllowList, HostnameOwnershipHostnameClaim:
+		return policy
+	case "":
+	default:
+		utils.AviLog.Warnf("Invalid hostname ownership policy %s, hosts are not restricted to namespaces", policy)
+	}
+	return ""
+}
+
+// GetDomainNamespaceMap returns the namespaces allowed to publish hosts under a domain,
+// for the NamespaceAllowList hostname ownership policy.
+func GetDomainNamespaceMap() (map[string][]string, error) {
+	domainNamespaceMap := make(map[string][]string)
+	type Row struct {
+		Domain     string   `json:"domain"`
+		Namespaces []string `json:"namespaces"`
+	}
+	type domainNamespaceList []Row
+
+	domainNamespaceListStr := os.Getenv(DOMAIN_NAMESPACE_LIST)
+	if domainNamespaceListStr == "" || domainNamespaceListStr == "null" {
+		return domainNamespaceMap, nil
+	}
+	var domainNamespaceListObj domainNamespaceList
+	err := json.Unmarshal([]byte(domainNamespaceListStr), &domainNamespaceListObj)
+	if err != nil {
+		return domainNamespaceMap, fmt.Errorf("Unable to unmarshall json for domainNamespaceList")
+	}
+
+	for _, row := range domainNamespaceListObj {
+		domain := strings.TrimPrefix(strings.ToLower(row.Domain), ".")
+		domainNamespaceMap[domain] = append(domainNamespaceMap[domain], row.Namespaces...)
+	}
+	return domainNamespaceMap, nil
+}
+
+// IsWildcardRouteAllowed returns true if routes with wildcardPolicy Subdomain are admitted
+// in the namespace, WILDCARD_ROUTE_NAMESPACES takes a comma separated list or * for all.
+func IsWildcardRouteAllowed(namespace string) bool {
+	allowedNamespaces := os.Getenv(WILDCARD_ROUTE_NAMESPACES)
+	for _, ns := range strings.Split(allowedNamespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func GetClusterName() string {
 	clusterName := os.Getenv(CLUSTER_NAME)
 	if clusterName != "" {
@@ -381,9 +612,9 @@ func VrfChecksum(vrfName string, staticRoutes []*models.StaticRoute) uint32 {
 	return utils.Hash(utils.Stringify(filteredStaticRoutes))
 }
 
-func DSChecksum(pgrefs []string) uint32 {
+func DSChecksum(pgrefs []string, script string) uint32 {
 	sort.Strings(pgrefs)
-	checksum := utils.Hash(utils.Stringify(pgrefs))
+	checksum := utils.Hash(utils.Stringify(pgrefs) + script)
 	return checksum
 }
 
@@ -493,6 +724,95 @@ func GetLabels() []*models.KeyValue {
 	return labels
 }
 
+// GetRouteHostName returns the hostname with which a route is programmed, for wildcardPolicy
+// Subdomain it is the wildcard domain of spec.host, e.g. *.example.com for foo.example.com.
+func GetRouteHostName(routeSpec routev1.RouteSpec) string {
+	if routeSpec.WildcardPolicy != routev1.WildcardPolicySubdomain {
+		return routeSpec.Host
+	}
+	hostTokens := strings.SplitN(routeSpec.Host, ".", 2)
+	if len(hostTokens) != 2 || hostTokens[1] == "" {
+		return ""
+	}
+	return WildcardHostPrefix + hostTokens[1]
+}
+
+func HasValidWildcardPolicy(routeSpec routev1.RouteSpec, routeName, namespace, key string) bool {
+	if routeSpec.WildcardPolicy != routev1.WildcardPolicySubdomain {
+		return true
+	}
+	if !IsWildcardRouteAllowed(namespace) {
+		utils.AviLog.Warnf("key: %s, msg: wildcard routes are not allowed in namespace %s, route: %s", key, namespace, routeName)
+		return false
+	}
+	if GetRouteHostName(routeSpec) == "" {
+		utils.AviLog.Warnf("key: %s, msg: no subdomain found in host %s for wildcard route: %s", key, routeSpec.Host, routeName)
+		return false
+	}
+	if routeSpec.TLS != nil && routeSpec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		utils.AviLog.Warnf("key: %s, msg: wildcard policy is not supported for passthrough route: %s", key, routeName)
+		return false
+	}
+	return true
+}
+
+// getLabelSelector parses the label selector set in the environment variable, an unset
+// variable selects everything while an invalid one selects nothing.
+func getLabelSelector(envName string) labels.Selector {
+	selectorStr := os.Getenv(envName)
+	if selectorStr == "" {
+		return labels.Everything()
+	}
+	selector, err := labels.Parse(selectorStr)
+	if err != nil {
+		utils.AviLog.Errorf("Invalid label selector %s set in %s: %v", selectorStr, envName, err)
+		return labels.Nothing()
+	}
+	return selector
+}
+
+// IsNamespaceLabelSelected returns true if the namespace labels match NAMESPACE_SELECTOR.
+func IsNamespaceLabelSelected(nsLabels map[string]string) bool {
+	return getLabelSelector(NAMESPACE_SELECTOR).Matches(labels.Set(nsLabels))
+}
+
+// GetNamespaceList returns the namespaces set in NAMESPACE_LIST, an empty list does not restrict the namespaces.
+func GetNamespaceList() []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(os.Getenv(NAMESPACE_LIST), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// IsNamespaceSelected returns true if the ingresses, routes and services of the namespace are served by this AKO,
+// that is the namespace is in NAMESPACE_LIST and its labels match NAMESPACE_SELECTOR, when they are set.
+func IsNamespaceSelected(namespace string) bool {
+	if namespaces := GetNamespaceList(); len(namespaces) > 0 && !utils.HasElem(namespaces, namespace) {
+		return false
+	}
+	if os.Getenv(NAMESPACE_SELECTOR) == "" {
+		return true
+	}
+	nsObj, err := utils.GetInformers().NSInformer.Lister().Get(namespace)
+	if err != nil {
+		utils.AviLog.Warnf("Unable to fetch namespace %s: %v", namespace, err)
+		return false
+	}
+	return IsNamespaceLabelSelected(nsObj.Labels)
+}
+
+// IsRouteSelected returns true if the route is served by this AKO, that is the route labels
+// match ROUTE_SELECTOR and the route namespace is selected.
+func IsRouteSelected(route *routev1.Route) bool {
+	if !getLabelSelector(ROUTE_SELECTOR).Matches(labels.Set(route.Labels)) {
+		return false
+	}
+	return IsNamespaceSelected(route.Namespace)
+}
+
 func HasValidBackends(routeSpec routev1.RouteSpec, routeName, namespace, key string) bool {
 	svcList := make(map[string]bool)
 	toSvc := routeSpec.To.Name
@@ -17,6 +17,7 @@ package nodes
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
@@ -124,6 +125,7 @@ func (o *AviObjectGraph) BuildL7VSGraphHostNameShard(vsName, hostname string, ro
 		pgNode.Members = append(pgNode.Members, &avimodels.PoolGroupMember{PoolRef: &pool_ref, PriorityLabel: &poolNode.PriorityLabel, Ratio: &ratio})
 
 	}
+	updateWildcardHTTPDataScript(vsNode[0], key)
 }
 
 func (o *AviObjectGraph) DeletePoolForHostname(vsName, hostname string, routeIgrObj RouteIngressModel, pathSvc map[string][]string, key string, removeFqdn, removeRedir, secure bool) {
@@ -169,6 +171,7 @@ func (o *AviObjectGraph) DeletePoolForHostname(vsName, hostname string, routeIgr
 			pool_ref := fmt.Sprintf("/api/pool?name=%s", poolNode.Name)
 			pgNode.Members = append(pgNode.Members, &avimodels.PoolGroupMember{PoolRef: &pool_ref, PriorityLabel: &poolNode.PriorityLabel, Ratio: &ratio})
 		}
+		updateWildcardHTTPDataScript(vsNode[0], key)
 	} else {
 		// Remove the ingress from the hostmap
 		hostMapOk, ingressHostMap := SharedHostNameLister().Get(hostname)
@@ -197,6 +200,47 @@ func (o *AviObjectGraph) DeletePoolForHostname(vsName, hostname string, routeIgr
 
 }
 
+// updateWildcardHTTPDataScript rebuilds the insecure datascript of the shard VS from the pool
+// priority labels. Requests for a host under a wildcard domain are sent to the wildcard pools
+// only if the host has no exact match among the insecure hosts of this shard.
+func updateWildcardHTTPDataScript(vsNode *AviVsNode, key string) {
+	if len(vsNode.HTTPDSrefs) == 0 || vsNode.HTTPDSrefs[0].DataScript == nil || len(vsNode.HTTPDSrefs[0].PoolGroupRefs) == 0 {
+		return
+	}
+	wildcards := make(map[string]bool)
+	hosts := make(map[string]bool)
+	for _, poolNode := range vsNode.PoolRefs {
+		host := strings.SplitN(poolNode.PriorityLabel, "/", 2)[0]
+		if strings.HasPrefix(host, lib.WildcardHostPrefix) {
+			wildcards[strings.TrimPrefix(host, lib.WildcardHostPrefix)] = true
+		} else {
+			hosts[host] = true
+		}
+	}
+
+	script := utils.HTTP_DS_SCRIPT
+	if len(wildcards) > 0 {
+		var wildcardEntries, exactEntries []string
+		for domain := range wildcards {
+			wildcardEntries = append(wildcardEntries, fmt.Sprintf("[\"%s\"]=true", strings.ToLower(domain)))
+		}
+		for host := range hosts {
+			// only the hosts that could be shadowed by a wildcard domain need to be listed.
+			hostTokens := strings.SplitN(host, ".", 2)
+			if len(hostTokens) == 2 && wildcards[hostTokens[1]] {
+				exactEntries = append(exactEntries, fmt.Sprintf("[\"%s\"]=true", strings.ToLower(host)))
+			}
+		}
+		sort.Strings(wildcardEntries)
+		sort.Strings(exactEntries)
+		script = strings.Replace(lib.WildcardHTTPDatascript, "WILDCARD_DOMAINS", strings.Join(wildcardEntries, ","), 1)
+		script = strings.Replace(script, "EXACT_HOSTS", strings.Join(exactEntries, ","), 1)
+		utils.AviLog.Debugf("key: %s, msg: wildcard domains on vs %s: %v", key, vsNode.Name, wildcardEntries)
+	}
+	dsNode := vsNode.HTTPDSrefs[0]
+	dsNode.Script = strings.Replace(script, "POOLGROUP", dsNode.PoolGroupRefs[0], 1)
+}
+
 func (o *AviObjectGraph) ManipulateSniNode(currentSniNodeName, ingName, namespace, hostname string, pathSvc map[string][]string, vsNode []*AviVsNode, key string, isIngr bool) bool {
 	for _, modelSniNode := range vsNode[0].SniNodes {
 		if currentSniNodeName != modelSniNode.Name {
@@ -312,7 +356,7 @@ func sniNodeHostName(routeIgrObj RouteIngressModel, tlssetting TlsSettings, ingN
 			//return hostPathMap
 			return hostPathSvcMap
 		}
-		model_name := lib.GetModelName(lib.GetTenant(), shardVsName)
+		model_name := getVSModelName(shardVsName)
 		found, aviModel := objects.SharedAviGraphLister().Get(model_name)
 		if !found || aviModel == nil {
 			utils.AviLog.Infof("key: %s, msg: model not found, generating new model with name: %s", key, model_name)
@@ -355,9 +399,8 @@ func sniNodeHostName(routeIgrObj RouteIngressModel, tlssetting TlsSettings, ingN
 				certsBuilt = true
 			}
 		}
-		if lib.GetSEGName() != lib.DEFAULT_GROUP {
-			sniNode.ServiceEngineGroup = lib.GetSEGName()
-		}
+		// the SNI child is placed on the SEG of its parent, which might come from an AviInfraSetting
+		sniNode.ServiceEngineGroup = vsNode[0].ServiceEngineGroup
 		sniNode.VrfContext = lib.GetVrf()
 		if !certsBuilt {
 			certsBuilt = aviModel.(*AviObjectGraph).BuildTlsCertNode(routeIgrObj.GetSvcLister(), sniNode, namespace, tlssetting, key, sniHost)
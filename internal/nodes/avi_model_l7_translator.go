@@ -57,7 +57,7 @@ func (o *AviObjectGraph) BuildL7VSGraph(vsName string, namespace string, ingName
 		var parsedIng IngressConfig
 		processIng := true
 
-		processIng = filterIngressOnClass(ingObj)
+		processIng = FilterIngressOnClass(ingObj) && lib.IsNamespaceSelected(namespace)
 		if !processIng {
 			// If the ingress class is not right, let's delete it.
 			o.DeletePoolForIngress(namespace, ingName, key, vsNode)
@@ -334,6 +334,7 @@ func (o *AviObjectGraph) ConstructAviL7VsNode(vsName string, key string) *AviVsN
 	vsVipNode := &AviVSVIPNode{Name: lib.GetVsVipName(vsName), Tenant: lib.GetTenant(), FQDNs: fqdns,
 		EastWest: false, VrfContext: vrfcontext}
 	avi_vs_meta.VSVIPRefs = append(avi_vs_meta.VSVIPRefs, vsVipNode)
+	setVSNodeInfraSetting(avi_vs_meta, key)
 	return avi_vs_meta
 }
 
@@ -559,7 +560,7 @@ func (o *AviObjectGraph) BuildPoolSecurity(poolNode *AviPoolNode, tlsData TlsSet
 		return
 	}
 	pkiProfile := AviPkiProfileNode{
-		Name:   poolNode.Name + "-" + "pkiprofile",
+		Name:   lib.GetPoolPKIProfileName(poolNode.Name),
 		Tenant: lib.GetTenant(),
 		CACert: tlsData.destCA,
 	}
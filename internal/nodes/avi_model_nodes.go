@@ -300,6 +300,7 @@ type AviVsNode struct {
 	AppProfileRef         string
 	HttpPolicySetRefs     []string
 	SSLKeyCertAviRef      string
+	ClientAuthAppProfile  *AviAppProfileNode
 }
 
 func (o *AviObjectGraph) GetAviVS() []*AviVsNode {
@@ -529,6 +530,14 @@ func (v *AviVsNode) CalculateCheckSum() {
 	})
 	vsRefs := v.WafPolicyRef + v.AppProfileRef + utils.Stringify(policies)
 
+	var clientAuthChecksum uint32
+	if v.ClientAuthAppProfile != nil {
+		clientAuthChecksum = v.ClientAuthAppProfile.GetCheckSum()
+		if v.ClientAuthAppProfile.PkiProfile != nil {
+			clientAuthChecksum += v.ClientAuthAppProfile.PkiProfile.GetCheckSum()
+		}
+	}
+
 	checksum := dsChecksum +
 		httppolChecksum +
 		sniChecksum +
@@ -538,7 +547,12 @@ func (v *AviVsNode) CalculateCheckSum() {
 		sslkeyChecksum +
 		utils.Hash(vsRefs) +
 		l4policyChecksum +
-		passthoughChecksum
+		passthoughChecksum +
+		clientAuthChecksum
+
+	if v.ServiceEngineGroup != "" && v.ServiceEngineGroup != lib.GetSEGName() {
+		checksum += utils.Hash(v.ServiceEngineGroup)
+	}
 
 	v.CloudConfigCksum = checksum
 }
@@ -724,6 +738,8 @@ type AviVSVIPNode struct {
 	VrfContext              string
 	SecurePassthoughNode    *AviVsNode
 	InsecurePassthroughNode *AviVsNode
+	// NetworkName is the network the VIP is allocated from, when it is not the one of the AKO configuration
+	NetworkName string
 }
 
 func (v *AviVSVIPNode) GetCheckSum() uint32 {
@@ -828,7 +844,11 @@ func (v *AviHTTPDataScriptNode) GetCheckSum() uint32 {
 
 func (v *AviHTTPDataScriptNode) CalculateCheckSum() {
 	// A sum of fields for this VS.
-	v.CloudConfigCksum = lib.DSChecksum(v.PoolGroupRefs)
+	var script string
+	if v.DataScript != nil {
+		script = v.Script
+	}
+	v.CloudConfigCksum = lib.DSChecksum(v.PoolGroupRefs, script)
 }
 
 func (v *AviHTTPDataScriptNode) GetNodeType() string {
@@ -869,6 +889,7 @@ type AviPkiProfileNode struct {
 	Tenant           string
 	CloudConfigCksum uint32
 	CACert           string
+	CRL              string
 }
 
 func (v *AviPkiProfileNode) GetCheckSum() uint32 {
@@ -878,7 +899,32 @@ func (v *AviPkiProfileNode) GetCheckSum() uint32 {
 }
 
 func (v *AviPkiProfileNode) CalculateCheckSum() {
-	v.CloudConfigCksum = lib.SSLKeyCertChecksum(v.Name, "", v.CACert)
+	v.CloudConfigCksum = lib.SSLKeyCertChecksum(v.Name, "", v.CACert+v.CRL)
+}
+
+// AviAppProfileNode is the HTTP application profile created for a SNI child
+// that requires client certificate validation
+type AviAppProfileNode struct {
+	Name             string
+	Tenant           string
+	CloudConfigCksum uint32
+	PkiProfile       *AviPkiProfileNode
+	ClientCertMode   string
+	SubjectHeader    string
+}
+
+func (v *AviAppProfileNode) GetCheckSum() uint32 {
+	// Calculate checksum and return
+	v.CalculateCheckSum()
+	return v.CloudConfigCksum
+}
+
+func (v *AviAppProfileNode) CalculateCheckSum() {
+	var pkiName string
+	if v.PkiProfile != nil {
+		pkiName = v.PkiProfile.Name
+	}
+	v.CloudConfigCksum = utils.Hash(v.Name + pkiName + v.ClientCertMode + v.SubjectHeader)
 }
 
 type AviPoolNode struct {
@@ -899,7 +945,10 @@ type AviPoolNode struct {
 	SniEnabled       bool
 	SslProfileRef    string
 	PkiProfile       *AviPkiProfileNode
+	ServerName       string
 	VrfContext       string
+
+	SslKeyAndCertificateRef string
 }
 
 func (v *AviPoolNode) GetCheckSum() uint32 {
@@ -926,6 +975,8 @@ func (v *AviPoolNode) CalculateCheckSum() {
 		v.LbAlgoHostHeader,
 		utils.Stringify(v.SniEnabled),
 		v.SslProfileRef,
+		v.ServerName,
+		v.SslKeyAndCertificateRef,
 		v.PriorityLabel,
 		utils.Stringify(nodeNetworkMap),
 	}[:], delim))
@@ -16,6 +16,7 @@ package nodes
 
 import (
 	"errors"
+	"time"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
@@ -35,6 +36,10 @@ type RouteIngressModel interface {
 	GetType() string
 	GetSvcLister() *objects.SvcLister
 	GetSpec() interface{}
+	GetAnnotations() map[string]string
+	GetCreationTimestamp() time.Time
+	// GetInfraSetting returns the AviInfraSetting referred by the IngressClass of the object, if any
+	GetInfraSetting() string
 	ParseHostPath() IngressConfig
 	// this is required due to different naming convention used in ingress where we dont use service name
 	// later if we decide to have common naming for ingress and route, then we can hav a common method
@@ -43,18 +48,23 @@ type RouteIngressModel interface {
 
 // OshiftRouteModel : Model for openshift routes with it's own service lister
 type OshiftRouteModel struct {
-	key       string
-	name      string
-	namespace string
-	spec      routev1.RouteSpec
+	key         string
+	name        string
+	namespace   string
+	spec        routev1.RouteSpec
+	annotations map[string]string
+	created     time.Time
 }
 
 // K8sIngressModel : Model for openshift routes with default service lister
 type K8sIngressModel struct {
-	key       string
-	name      string
-	namespace string
-	spec      networking.IngressSpec
+	key          string
+	name         string
+	namespace    string
+	spec         networking.IngressSpec
+	annotations  map[string]string
+	infraSetting string
+	created      time.Time
 }
 
 func GetOshiftRouteModel(name, namespace, key string) (*OshiftRouteModel, error, bool) {
@@ -69,10 +79,20 @@ func GetOshiftRouteModel(name, namespace, key string) (*OshiftRouteModel, error,
 		return &routeModel, err, processObj
 	}
 	routeModel.spec = routeObj.Spec
+	routeModel.annotations = routeObj.Annotations
+	routeModel.created = routeObj.CreationTimestamp.Time
+	if !lib.IsRouteSelected(routeObj) {
+		err := errors.New("route " + name + " does not match the route and namespace selectors")
+		return &routeModel, err, false
+	}
 	if !lib.HasValidBackends(routeObj.Spec, name, namespace, key) {
 		err := errors.New("validation failed for alternate backends for route: " + name)
 		return &routeModel, err, false
 	}
+	if !lib.HasValidWildcardPolicy(routeObj.Spec, name, namespace, key) {
+		err := errors.New("validation failed for wildcard policy for route: " + name)
+		return &routeModel, err, false
+	}
 
 	return &routeModel, nil, processObj
 }
@@ -97,6 +117,18 @@ func (m *OshiftRouteModel) GetSpec() interface{} {
 	return m.spec
 }
 
+func (m *OshiftRouteModel) GetAnnotations() map[string]string {
+	return m.annotations
+}
+
+func (m *OshiftRouteModel) GetCreationTimestamp() time.Time {
+	return m.created
+}
+
+func (m *OshiftRouteModel) GetInfraSetting() string {
+	return ""
+}
+
 func (or *OshiftRouteModel) ParseHostPath() IngressConfig {
 	o := NewNodesValidator()
 	return o.ParseHostPathForRoute(or.namespace, or.name, or.spec, or.key)
@@ -138,8 +170,19 @@ func GetK8sIngressModel(name, namespace, key string) (*K8sIngressModel, error, b
 	if !ok {
 		return &ingrModel, errors.New("Could not convert ingress to net v1beta"), processObj
 	}
-	processObj = filterIngressOnClass(ingObj)
+	processObj = FilterIngressOnClass(ingObj)
+	if processObj && !lib.IsNamespaceSelected(namespace) {
+		utils.AviLog.Infof("key: %s, msg: namespace %s is not selected, not processing the ingress: %s", key, namespace, name)
+		processObj = false
+	}
 	ingrModel.spec = ingObj.Spec
+	ingrModel.annotations = ingObj.Annotations
+	ingrModel.created = ingObj.CreationTimestamp.Time
+	if lib.IsIngressClassEnabled() {
+		if _, ingClass := getIngressClassObj(ingObj); ingClass != nil {
+			ingrModel.infraSetting = lib.GetIngressClassInfraSetting(ingClass)
+		}
+	}
 	return &ingrModel, nil, processObj
 }
 
@@ -163,6 +206,18 @@ func (m *K8sIngressModel) GetSpec() interface{} {
 	return m.spec
 }
 
+func (m *K8sIngressModel) GetAnnotations() map[string]string {
+	return m.annotations
+}
+
+func (m *K8sIngressModel) GetCreationTimestamp() time.Time {
+	return m.created
+}
+
+func (m *K8sIngressModel) GetInfraSetting() string {
+	return m.infraSetting
+}
+
 func (m *K8sIngressModel) ParseHostPath() IngressConfig {
 	o := NewNodesValidator()
 	return o.ParseHostPathForIngress(m.namespace, m.name, m.spec, m.key)
@@ -217,6 +272,9 @@ func HostNameShardAndPublishV2(objType, objname, namespace, key string, fullsync
 		if k8serrors.IsNotFound(err) || !processObj {
 			utils.AviLog.Infof("key: %s, Deleting Pool for ingress delete", key)
 			RouteIngrDeletePoolsByHostname(routeIgrObj, namespace, objname, key, fullsync, sharedQueue)
+			releaseHostnameClaims(objType, namespace, objname, key)
+			releaseDedicatedVS(objType, namespace, objname, key)
+			releaseInfraSetting(objType, namespace, objname, key)
 		}
 		return
 	}
@@ -227,6 +285,9 @@ func HostNameShardAndPublishV2(objType, objname, namespace, key string, fullsync
 	var modelList []string
 
 	parsedIng = routeIgrObj.ParseHostPath()
+	applyHostnameOwnership(routeIgrObj, &parsedIng, key)
+	applyDedicatedVS(routeIgrObj, parsedIng, fullsync, key)
+	applyInfraSetting(routeIgrObj, parsedIng, fullsync, key)
 
 	// Check if this ingress and had any previous mappings, if so - delete them first.
 	_, Storedhosts := routeIgrObj.GetSvcLister().IngressMappings(namespace).GetRouteIngToHost(objname)
@@ -301,7 +362,7 @@ func ProcessInsecureHosts(routeIgrObj RouteIngressModel, key string, parsedIng I
 			// If we aren't able to derive the ShardVS name, we should return
 			return
 		}
-		modelName := lib.GetModelName(lib.GetTenant(), shardVsName)
+		modelName := getVSModelName(shardVsName)
 		found, aviModel := objects.SharedAviGraphLister().Get(modelName)
 		if !found || aviModel == nil {
 			utils.AviLog.Infof("key: %s, msg: model not found, generating new model with name: %s", key, modelName)
@@ -382,7 +443,7 @@ func ProcessPassthroughHosts(routeIgrObj RouteIngressModel, key string, parsedIn
 		}
 
 		shardVsName := lib.GetPassthroughShardVSName(host, key)
-		modelName := lib.GetModelName(lib.GetTenant(), shardVsName)
+		modelName := getVSModelName(shardVsName)
 		found, aviModel := objects.SharedAviGraphLister().Get(modelName)
 		if !found || aviModel == nil {
 			aviModel = NewAviObjectGraph()
@@ -410,7 +471,7 @@ func DeleteStaleData(routeIgrObj RouteIngressModel, key string, modelList *[]str
 			// If we aren't able to derive the ShardVS name, we should return
 			return
 		}
-		modelName := lib.GetModelName(lib.GetTenant(), shardVsName)
+		modelName := getVSModelName(shardVsName)
 		found, aviModel := objects.SharedAviGraphLister().Get(modelName)
 		if !found || aviModel == nil {
 			utils.AviLog.Warnf("key: %s, msg: model not found during delete: %s", key, modelName)
@@ -464,7 +525,7 @@ func RouteIngrDeletePoolsByHostname(routeIgrObj RouteIngressModel, namespace, ob
 			utils.AviLog.Infof("key: %s, shard vs ndoe not found for host: %s", host)
 			return
 		}
-		modelName := lib.GetModelName(lib.GetTenant(), shardVsName)
+		modelName := getVSModelName(shardVsName)
 		found, aviModel := objects.SharedAviGraphLister().Get(modelName)
 		if !found || aviModel == nil {
 			utils.AviLog.Warnf("key: %s, msg: model not found during delete: %s", key, modelName)
@@ -60,6 +60,7 @@ func BuildL7HostRule(host, namespace, ingName, key string, vsNode *AviVsNode) {
 		vsNode.WafPolicyRef = ""
 		vsNode.HttpPolicySetRefs = []string{}
 		vsNode.AppProfileRef = ""
+		vsNode.ClientAuthAppProfile = nil
 		if vsNode.ServiceMetadata.CRDStatus.Value != "" {
 			vsNode.ServiceMetadata.CRDStatus.Status = "INACTIVE"
 		}
@@ -101,6 +102,7 @@ func BuildL7HostRule(host, namespace, ingName, key string, vsNode *AviVsNode) {
 	vsNode.WafPolicyRef = vsWafPolicy
 	vsNode.HttpPolicySetRefs = vsHTTPPolicySets
 	vsNode.AppProfileRef = vsAppProfile
+	vsNode.ClientAuthAppProfile = buildClientAuthAppProfile(hostrule, vsNode, key)
 	vsNode.ServiceMetadata.CRDStatus = cache.CRDMetadata{
 		Type:   "HostRule",
 		Value:  hostrule.Namespace + "/" + hostrule.Name,
@@ -110,6 +112,46 @@ func BuildL7HostRule(host, namespace, ingName, key string, vsNode *AviVsNode) {
 	utils.AviLog.Infof("key: %s, Attached hostrule %s on vsNode %s", key, host, vsNode.Name)
 }
 
+// buildClientAuthAppProfile builds the PKI profile out of the client CA secret referred in
+// the hostrule and wraps it in an application profile, which is attached to the SNI child
+func buildClientAuthAppProfile(hostrule *akov1alpha1.HostRule, vsNode *AviVsNode, key string) *AviAppProfileNode {
+	clientCert := hostrule.Spec.VirtualHost.TLS.ClientCertificate
+	if clientCert.CASecret == "" {
+		return nil
+	}
+
+	secret, err := utils.GetInformers().SecretInformer.Lister().Secrets(hostrule.Namespace).Get(clientCert.CASecret)
+	if err != nil {
+		utils.AviLog.Warnf("key: %s, msg: client CA secret %s/%s not found, err: %v", key, hostrule.Namespace, clientCert.CASecret, err)
+		return nil
+	}
+
+	pkiProfile := &AviPkiProfileNode{
+		Name:   lib.GetClientAuthPkiProfileName(vsNode.Name),
+		Tenant: lib.GetTenant(),
+		CACert: string(secret.Data[lib.ClientCACertSecretKey]),
+		CRL:    string(secret.Data[lib.ClientCRLSecretKey]),
+	}
+
+	clientCertMode := lib.SSLClientCertificateRequire
+	if clientCert.Mode == lib.ClientCertModeRequest {
+		clientCertMode = lib.SSLClientCertificateRequest
+	}
+
+	subjectHeader := clientCert.SubjectHeader
+	if subjectHeader == "" {
+		subjectHeader = lib.DefaultClientSubjectHeader
+	}
+
+	return &AviAppProfileNode{
+		Name:           lib.GetClientAuthAppProfileName(vsNode.Name),
+		Tenant:         lib.GetTenant(),
+		PkiProfile:     pkiProfile,
+		ClientCertMode: clientCertMode,
+		SubjectHeader:  subjectHeader,
+	}
+}
+
 // BuildPoolHTTPRule notes
 // when we get an ingress update and we are building the corresponding pools of that ingress
 // we need to get all httprules which match ingress's host/path
@@ -126,6 +168,8 @@ func BuildPoolHTTPRule(host, path, ingName, namespace, key string, vsNode *AviVs
 			pool.LbAlgorithm = ""
 			pool.LbAlgorithmHash = ""
 			pool.LbAlgoHostHeader = ""
+			pool.ServerName = ""
+			pool.SslKeyAndCertificateRef = ""
 			if pool.ServiceMetadata.CRDStatus.Value != "" {
 				pool.ServiceMetadata.CRDStatus.Status = "INACTIVE"
 			}
@@ -194,6 +238,20 @@ func BuildPoolHTTPRule(host, path, ingName, namespace, key string, vsNode *AviVs
 					} else {
 						pathSslProfile = fmt.Sprintf("/api/sslprofile?name=%s", lib.DefaultPoolSSLProfile)
 					}
+
+					pool.ServerName = httpRulePath.TLS.ServerName
+					if sslKeyCertRef := httpRulePath.TLS.SSLKeyCertificate; sslKeyCertRef != "" {
+						pool.SslKeyAndCertificateRef = fmt.Sprintf("/api/sslkeyandcertificate?name=%s", sslKeyCertRef)
+					}
+
+					// destination CA of the httprule takes precedence over the one in a reencrypt route
+					if destCA := getHTTPRuleDestinationCA(rrNamespace, httpRulePath.TLS, key); destCA != "" {
+						pool.PkiProfile = &AviPkiProfileNode{
+							Name:   lib.GetPoolPKIProfileName(pool.Name),
+							Tenant: lib.GetTenant(),
+							CACert: destCA,
+						}
+					}
 				}
 
 				pool.SniEnabled = isPathSniEnabled
@@ -226,6 +284,21 @@ func BuildPoolHTTPRule(host, path, ingName, namespace, key string, vsNode *AviVs
 	return
 }
 
+// getHTTPRuleDestinationCA returns the CA bundle used for validating the backend server
+// certificates, provided either inline or via a secret in the httprule namespace
+func getHTTPRuleDestinationCA(namespace string, pathTLS akov1alpha1.HTTPRuleTLS, key string) string {
+	if pathTLS.DestinationCASecret == "" {
+		return pathTLS.DestinationCA
+	}
+
+	secret, err := utils.GetInformers().SecretInformer.Lister().Secrets(namespace).Get(pathTLS.DestinationCASecret)
+	if err != nil {
+		utils.AviLog.Warnf("key: %s, msg: destination CA secret %s/%s not found, err: %v", key, namespace, pathTLS.DestinationCASecret, err)
+		return ""
+	}
+	return string(secret.Data[lib.DestinationCACertSecretKey])
+}
+
 // validateHostRuleObj would do validation checks
 // update internal CRD caches, and push relevant ingresses to ingestion
 func validateHostRuleObj(key string, hostrule *akov1alpha1.HostRule) error {
@@ -251,6 +324,15 @@ func validateHostRuleObj(key string, hostrule *akov1alpha1.HostRule) error {
 		refData[policy] = "HttpPolicySet"
 	}
 
+	if err = validateHostRuleClientCert(key, hostrule); err != nil {
+		status.UpdateHostRuleStatus(hostrule, status.UpdateCRDStatusOptions{
+			Status: lib.StatusRejected,
+			Error:  err.Error(),
+		})
+		utils.AviLog.Warnf("key: %s, msg: %v", key, err)
+		return err
+	}
+
 	for k, value := range refData {
 		if k == "" {
 			continue
@@ -271,12 +353,53 @@ func validateHostRuleObj(key string, hostrule *akov1alpha1.HostRule) error {
 	return nil
 }
 
+// validateHostRuleClientCert checks the client certificate settings of the hostrule
+// and verifies that the CA secret carries a CA bundle
+func validateHostRuleClientCert(key string, hostrule *akov1alpha1.HostRule) error {
+	clientCert := hostrule.Spec.VirtualHost.TLS.ClientCertificate
+	if clientCert.CASecret == "" {
+		return nil
+	}
+
+	if clientCert.Mode != "" && clientCert.Mode != lib.ClientCertModeRequest && clientCert.Mode != lib.ClientCertModeRequire {
+		return fmt.Errorf("clientCertificate mode %s is invalid, must be one of %s, %s",
+			clientCert.Mode, lib.ClientCertModeRequest, lib.ClientCertModeRequire)
+	}
+
+	if hostrule.Spec.VirtualHost.ApplicationProfile != "" {
+		return fmt.Errorf("applicationProfile cannot be used along with tls.clientCertificate")
+	}
+
+	secret, err := utils.GetInformers().SecretInformer.Lister().Secrets(hostrule.Namespace).Get(clientCert.CASecret)
+	if err != nil {
+		return fmt.Errorf("client CA secret %s not found", clientCert.CASecret)
+	}
+
+	if len(secret.Data[lib.ClientCACertSecretKey]) == 0 {
+		return fmt.Errorf("client CA secret %s does not contain %s", clientCert.CASecret, lib.ClientCACertSecretKey)
+	}
+
+	return nil
+}
+
 // validateHTTPRuleObj would do validation checks
 // update internal CRD caches, and push relevant ingresses to ingestion
 func validateHTTPRuleObj(key string, httprule *akov1alpha1.HTTPRule) error {
 	refData := make(map[string]string)
 	for _, path := range httprule.Spec.Paths {
 		refData[path.TLS.SSLProfile] = "SslProfile"
+		refData[path.TLS.SSLKeyCertificate] = "SslKeyCert"
+	}
+
+	for _, path := range httprule.Spec.Paths {
+		if err := validateHTTPRulePathTLS(httprule.Namespace, path); err != nil {
+			status.UpdateHTTPRuleStatus(httprule, status.UpdateCRDStatusOptions{
+				Status: lib.StatusRejected,
+				Error:  err.Error(),
+			})
+			utils.AviLog.Warnf("key: %s, msg: %v", key, err)
+			return err
+		}
 	}
 
 	for k, value := range refData {
@@ -300,12 +423,147 @@ func validateHTTPRuleObj(key string, httprule *akov1alpha1.HTTPRule) error {
 	return nil
 }
 
+// validateHTTPRulePathTLS checks the pool side TLS settings of a httprule path,
+// and verifies that the destination CA secret carries a CA bundle
+func validateHTTPRulePathTLS(namespace string, path akov1alpha1.HTTPRulePaths) error {
+	pathTLS := path.TLS
+	if pathTLS.Type == "" {
+		if pathTLS.DestinationCA != "" || pathTLS.DestinationCASecret != "" ||
+			pathTLS.ServerName != "" || pathTLS.SSLKeyCertificate != "" {
+			return fmt.Errorf("tls.type must be set to %s for target %s", lib.TypeTLSReencrypt, path.Target)
+		}
+		return nil
+	}
+
+	if pathTLS.DestinationCA != "" && pathTLS.DestinationCASecret != "" {
+		return fmt.Errorf("destinationCA and destinationCASecret cannot be used together for target %s", path.Target)
+	}
+
+	if pathTLS.DestinationCASecret == "" {
+		return nil
+	}
+
+	secret, err := utils.GetInformers().SecretInformer.Lister().Secrets(namespace).Get(pathTLS.DestinationCASecret)
+	if err != nil {
+		return fmt.Errorf("destination CA secret %s not found", pathTLS.DestinationCASecret)
+	}
+
+	if len(secret.Data[lib.DestinationCACertSecretKey]) == 0 {
+		return fmt.Errorf("destination CA secret %s does not contain %s", pathTLS.DestinationCASecret, lib.DestinationCACertSecretKey)
+	}
+
+	return nil
+}
+
+// validateHostnameClaimObj rejects a hostnameclaim for an fqdn which is already claimed by an older hostnameclaim.
+// A hostnameclaim older than the one accepted for its fqdn takes the fqdn over, so that the accepted claim does
+// not depend on the order in which they are processed.
+func validateHostnameClaimObj(key string, hostnameclaim *akov1alpha1.HostnameClaim) error {
+	fqdn := hostnameclaim.Spec.Fqdn
+	claimKey := hostnameclaim.Namespace + "/" + hostnameclaim.Name
+	foundHost, foundClaim := objects.SharedCRDLister().GetFQDNToHostnameClaimMapping(fqdn)
+	if foundHost && foundClaim != claimKey {
+		if !hostnameClaimPrecedes(hostnameclaim, foundClaim) {
+			err := fmt.Errorf("fqdn %s is already claimed by %s", fqdn, foundClaim)
+			status.UpdateHostnameClaimStatus(hostnameclaim, status.UpdateCRDStatusOptions{
+				Status: lib.StatusRejected,
+				Error:  err.Error(),
+			})
+			utils.AviLog.Warnf("key: %s, msg: %v", key, err)
+			return err
+		}
+		utils.AviLog.Infof("key: %s, msg: fqdn %s is taken over from the newer hostnameclaim %s", key, fqdn, foundClaim)
+		objects.SharedCRDLister().DeleteHostnameClaimFQDNMapping(foundClaim)
+		ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+		claimNamespace := strings.Split(foundClaim, "/")[0]
+		bkt := utils.Bkt(claimNamespace, ingestionQueue.NumWorkers)
+		ingestionQueue.Workqueue[bkt].AddRateLimited(lib.HostnameClaim + "/" + foundClaim)
+	}
+
+	status.UpdateHostnameClaimStatus(hostnameclaim, status.UpdateCRDStatusOptions{
+		Status: lib.StatusAccepted,
+		Error:  "",
+	})
+	return nil
+}
+
+// hostnameClaimPrecedes returns true if the hostnameclaim was created before the other one, the ones created
+// in the same second go by namespace and name. A deleted hostnameclaim is always preceded.
+func hostnameClaimPrecedes(hostnameclaim *akov1alpha1.HostnameClaim, otherKey string) bool {
+	otherNamespace, otherName := strings.Split(otherKey, "/")[0], strings.Split(otherKey, "/")[1]
+	other, err := lib.GetCRDInformers().HostnameClaimInformer.Lister().HostnameClaims(otherNamespace).Get(otherName)
+	if err != nil {
+		return true
+	}
+	if !hostnameclaim.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return hostnameclaim.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	if hostnameclaim.Namespace != other.Namespace {
+		return hostnameclaim.Namespace < other.Namespace
+	}
+	return hostnameclaim.Name < other.Name
+}
+
+// validateAviInfraSettingSpec checks that the shard size is known
+func validateAviInfraSettingSpec(spec akov1alpha1.AviInfraSettingSpec) error {
+	if spec.L7Settings.ShardSize != "" {
+		if _, ok := lib.GetShardSizeByName(spec.L7Settings.ShardSize); !ok {
+			return fmt.Errorf("shardSize %s is not one of SMALL, MEDIUM or LARGE", spec.L7Settings.ShardSize)
+		}
+	}
+	return nil
+}
+
+// validateAviInfraSetting checks the spec of the aviinfrasetting, along with its tenant, SEG and VIP network which
+// must exist on the controller. The result is recorded for the VSes the aviinfrasetting is applied to.
+func validateAviInfraSetting(key string, infraSetting *akov1alpha1.AviInfraSetting) error {
+	err := validateAviInfraSettingSpec(infraSetting.Spec)
+	if err == nil {
+		refData := map[string]string{
+			infraSetting.Spec.Tenant:       "Tenant",
+			infraSetting.Spec.SeGroup.Name: "ServiceEngineGroup",
+			infraSetting.Spec.Network.Name: "Network",
+		}
+		for k, value := range refData {
+			if k == "" {
+				continue
+			}
+			if err = checkRefOnController(key, value, k); err != nil {
+				break
+			}
+		}
+	}
+	SharedInfraSettingStore().setValidation(infraSetting.Name, err)
+	return err
+}
+
+// validateAviInfraSettingObj updates the status of the aviinfrasetting with the result of its validation
+func validateAviInfraSettingObj(key string, infraSetting *akov1alpha1.AviInfraSetting) error {
+	if err := validateAviInfraSetting(key, infraSetting); err != nil {
+		status.UpdateAviInfraSettingStatus(infraSetting, status.UpdateCRDStatusOptions{
+			Status: lib.StatusRejected,
+			Error:  err.Error(),
+		})
+		utils.AviLog.Warnf("key: %s, msg: %v", key, err)
+		return err
+	}
+
+	status.UpdateAviInfraSettingStatus(infraSetting, status.UpdateCRDStatusOptions{
+		Status: lib.StatusAccepted,
+		Error:  "",
+	})
+	return nil
+}
+
 var refModelMap = map[string]string{
-	"SslKeyCert":    "sslkeyandcertificate",
-	"WafPolicy":     "wafpolicy",
-	"HttpPolicySet": "httppolicyset",
-	"SslProfile":    "sslprofile",
-	"AppProfile":    "applicationprofile",
+	"SslKeyCert":         "sslkeyandcertificate",
+	"WafPolicy":          "wafpolicy",
+	"HttpPolicySet":      "httppolicyset",
+	"SslProfile":         "sslprofile",
+	"AppProfile":         "applicationprofile",
+	"Tenant":             "tenant",
+	"ServiceEngineGroup": "serviceenginegroup",
+	"Network":            "network",
 }
 
 // checkRefOnController checks whether a provided ref on the controller
@@ -315,7 +573,7 @@ func checkRefOnController(key, refKey, refValue string) error {
 
 	// assign the last avi client for ref checks
 	aviClientLen := lib.GetshardSize()
-	result, err := cache.AviGetCollectionRaw(clients.AviClient[aviClientLen], uri)
+	result, err := cache.AviGetCollectionRaw(clients.GetClient(int(aviClientLen)), uri)
 	if err != nil {
 		utils.AviLog.Warnf("key: %s, msg: Get uri %v returned err %v", key, uri, err)
 		return fmt.Errorf("%s \"%s\" not found on controller", refModelMap[refKey], refValue)
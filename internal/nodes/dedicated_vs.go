@@ -0,0 +1,244 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package nodes
+
+import (
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+var dedicatedVSStoreInstance *DedicatedVSStore
+var dvsonce sync.Once
+
+func SharedDedicatedVSStore() *DedicatedVSStore {
+	dvsonce.Do(func() {
+		dedicatedVSStoreInstance = &DedicatedVSStore{
+			requests:  newHostRequests(),
+			dedicated: make(map[string]bool),
+		}
+	})
+	return dedicatedVSStoreInstance
+}
+
+// hostRequests keeps the ingresses/routes asking something for each host, objects are keyed the same way as in the
+// ingestion queue.
+type hostRequests struct {
+	hostRequests map[string][]string
+	requestHosts map[string][]string
+}
+
+func newHostRequests() hostRequests {
+	return hostRequests{
+		hostRequests: make(map[string][]string),
+		requestHosts: make(map[string][]string),
+	}
+}
+
+// update records the hosts the ingress/route asks for and returns the hosts which got their first request or
+// lost their last one as a result.
+func (r hostRequests) update(objKey string, hosts []string) []string {
+	var changedHosts []string
+	for _, host := range r.requestHosts[objKey] {
+		if utils.HasElem(hosts, host) {
+			continue
+		}
+		requests := r.hostRequests[host]
+		for i, request := range requests {
+			if request == objKey {
+				requests = append(requests[:i:i], requests[i+1:]...)
+				break
+			}
+		}
+		if len(requests) == 0 {
+			delete(r.hostRequests, host)
+			changedHosts = append(changedHosts, host)
+			continue
+		}
+		r.hostRequests[host] = requests
+	}
+
+	for _, host := range hosts {
+		if utils.HasElem(r.hostRequests[host], objKey) {
+			continue
+		}
+		if len(r.hostRequests[host]) == 0 {
+			changedHosts = append(changedHosts, host)
+		}
+		r.hostRequests[host] = append(r.hostRequests[host], objKey)
+	}
+
+	if len(hosts) == 0 {
+		delete(r.requestHosts, objKey)
+	} else {
+		r.requestHosts[objKey] = append([]string{}, hosts...)
+	}
+	return changedHosts
+}
+
+// DedicatedVSStore keeps track of the hosts which opted out of sharding. A host asks for its own parent VS as
+// long as one of the ingresses/routes publishing it has the dedicated VS annotation. The host is built on the
+// dedicated VS only once it left its shard VS.
+// cache sample: foo.com -> [Ingress/ns1/ingress1]
+type DedicatedVSStore struct {
+	sync.RWMutex
+	requests hostRequests
+	// dedicated holds the hosts built on their dedicated VS
+	dedicated map[string]bool
+}
+
+// UpdateRequests records the hosts an ingress/route asks a dedicated VS for and returns the hosts for which
+// the request was turned on or off as a result.
+func (d *DedicatedVSStore) UpdateRequests(objKey string, hosts []string) []string {
+	d.Lock()
+	defer d.Unlock()
+	return d.requests.update(objKey, hosts)
+}
+
+func (d *DedicatedVSStore) IsDedicated(host string) bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.dedicated[host]
+}
+
+func (d *DedicatedVSStore) isRequested(host string) bool {
+	d.RLock()
+	defer d.RUnlock()
+	return len(d.requests.hostRequests[host]) > 0
+}
+
+func (d *DedicatedVSStore) setDedicated(host string, dedicated bool) {
+	d.Lock()
+	defer d.Unlock()
+	if dedicated {
+		d.dedicated[host] = true
+	} else {
+		delete(d.dedicated, host)
+	}
+}
+
+// getPendingHosts returns the hosts for which the request changed while they were being moved
+func (d *DedicatedVSStore) getPendingHosts() []string {
+	d.RLock()
+	defer d.RUnlock()
+	var hosts []string
+	for host := range d.requests.hostRequests {
+		if !d.dedicated[host] {
+			hosts = append(hosts, host)
+		}
+	}
+	for host := range d.dedicated {
+		if len(d.requests.hostRequests[host]) == 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// applyDedicatedVS records the hosts of the ingress/route asking for a dedicated VS, before the hosts are built
+// on the VS returned by DeriveHostNameShardVS.
+func applyDedicatedVS(routeIgrObj RouteIngressModel, parsedIng IngressConfig, fullsync bool, key string) {
+	objKey := routeIgrObj.GetType() + "/" + routeIgrObj.GetNamespace() + "/" + routeIgrObj.GetName()
+	var hosts []string
+	if routeIgrObj.GetAnnotations()[lib.DedicatedVSAnnotation] == "true" {
+		hosts = parsedIngHosts(parsedIng)
+	}
+	for _, host := range SharedDedicatedVSStore().UpdateRequests(objKey, hosts) {
+		switchHostVS(host, fullsync, key)
+	}
+}
+
+// releaseDedicatedVS drops the requests of a deleted ingress/route, after its pools were deleted.
+func releaseDedicatedVS(objType, namespace, name, key string) {
+	objKey := objType + "/" + namespace + "/" + name
+	for _, host := range SharedDedicatedVSStore().UpdateRequests(objKey, nil) {
+		switchHostVS(host, false, key)
+	}
+}
+
+// switchHostVS moves the host to the VS it is asked for, its dedicated VS, a shard VS of its AviInfraSetting or
+// its shard VS, when the request changed. A host not served yet is switched right away, otherwise it is migrated
+// like between two shards, so that it is served by the old VS until the new one is synced. The dedicated VS is
+// deleted once the host left it.
+func switchHostVS(host string, fullsync bool, key string) {
+	dedicatedStore := SharedDedicatedVSStore()
+	infraSettingStore := SharedInfraSettingStore()
+	from := DeriveHostNameShardVS(host, key)
+	to, infraSetting := deriveRequestedHostVS(host, key)
+	if from == to {
+		// the host stays, its dedicated VS might still have to take the new AviInfraSetting
+		if infraSettingStore.setVSInfraSetting(to, infraSetting) {
+			applyVSInfraSetting(to, !fullsync, key)
+		}
+		return
+	}
+	dedicated := dedicatedStore.isRequested(host)
+	deleteFrom := from == lib.GetDedicatedVSName(host)
+	switchHost := func() {
+		dedicatedStore.setDedicated(host, dedicated)
+		hostVSName := ""
+		if !dedicated && infraSetting != "" {
+			hostVSName = to
+		}
+		infraSettingStore.setHostVSName(host, hostVSName)
+		infraSettingStore.setVSInfraSetting(to, infraSetting)
+		infraSettingStore.setVSTenant(to, getInfraSettingTenant(infraSetting))
+		if deleteFrom {
+			infraSettingStore.setVSInfraSetting(from, "")
+		}
+	}
+
+	fromModel := getL7ModelByVSName(from)
+	if fromModel == nil || !getShardModelHosts(fromModel)[host] {
+		switchHost()
+		if deleteFrom && fromModel != nil {
+			deleteL7VSModel(from, !fullsync, key)
+		}
+		utils.AviLog.Infof("key: %s, msg: host %s is built on %s", key, host, to)
+		return
+	}
+
+	if fullsync {
+		// the models are only published once the full sync is done, the host can leave the old VS right away
+		removeHostFromShardModel(fromModel, host, false)
+		removeHostFromShardModel(fromModel, host, true)
+		saveAviModel(getVSModelName(from), fromModel, key)
+		switchHost()
+		if deleteFrom {
+			deleteL7VSModel(from, false, key)
+		}
+		requeueHost(host)
+		utils.AviLog.Infof("key: %s, msg: host %s is built on %s", key, host, to)
+		return
+	}
+
+	if err := SharedShardAssignment().moveHost(host, from, to, deleteFrom, switchHost); err != nil {
+		// the host is moved by the next shard assignment sync, once the migration in progress is done
+		utils.AviLog.Infof("key: %s, msg: unable to move host %s to %s yet: %v", key, host, to, err)
+	}
+}
+
+// deleteL7VSModel deletes the model of a dedicated VS or of a shard VS no longer used, with publish set the rest
+// layer deletes the VS along with its VSVIP.
+func deleteL7VSModel(vsName string, publish bool, key string) {
+	modelName := getVSModelName(vsName)
+	objects.SharedAviGraphLister().Save(modelName, nil)
+	if publish {
+		PublishKeyToRestLayer(modelName, key, utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer))
+	}
+	utils.AviLog.Infof("key: %s, msg: deleted the VS model %s", key, modelName)
+}
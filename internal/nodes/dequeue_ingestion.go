@@ -40,8 +40,10 @@ func DequeueIngestion(key string, fullsync bool) {
 		// If it's an ingress related change, let's process that.
 		if utils.GetInformers().IngressInformer != nil && schema.GetParentIngresses != nil {
 			ingressNames, ingressFound = schema.GetParentIngresses(name, namespace, key)
+			traceFanOut(key, utils.Ingress, ingressNames)
 		} else if utils.GetInformers().RouteInformer != nil && schema.GetParentRoutes != nil {
 			routeNames, routeFound = schema.GetParentRoutes(name, namespace, key)
+			traceFanOut(key, utils.OshiftRoute, routeNames)
 		}
 	}
 	// if we get update for object of type k8s node, create vrf graph
@@ -96,7 +98,7 @@ func DequeueIngestion(key string, fullsync bool) {
 				return
 			}
 
-			if svcObj.Spec.Type == utils.LoadBalancer {
+			if svcObj.Spec.Type == utils.LoadBalancer && lib.IsNamespaceSelected(namespace) {
 				// This endpoint update affects a LB service.
 				aviModelGraph := NewAviObjectGraph()
 				aviModelGraph.BuildL4LBGraph(namespace, name, key)
@@ -180,7 +182,8 @@ func handleL4Service(key string, fullsync bool) {
 	_, namespace, name := extractTypeNameNamespace(key)
 	sharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
 	// L4 type of services need special handling. We create a dedicated VS in Avi for these.
-	if !isServiceDelete(name, namespace, key) {
+	// The VS of a service whose namespace is no longer selected is deleted like for a deleted service.
+	if !isServiceDelete(name, namespace, key) && lib.IsNamespaceSelected(namespace) {
 		utils.AviLog.Infof("key: %s, msg: service is of type loadbalancer. Will create dedicated VS nodes", key)
 		aviModelGraph := NewAviObjectGraph()
 		aviModelGraph.BuildL4LBGraph(namespace, name, key)
@@ -254,8 +257,20 @@ func handleIngress(key string, fullsync bool, ingressNames []string) {
 	}
 }
 
+// traceFanOut records the change of the key on the traces of the ingresses or routes it affects
+func traceFanOut(key, parentType string, parentNames []string) {
+	objType, namespace, _ := extractTypeNameNamespace(key)
+	var parentKeys []string
+	for _, parentName := range parentNames {
+		nsParent, nameParent := getIngressNSNameForIngestion(objType, namespace, parentName)
+		parentKeys = append(parentKeys, parentType+"/"+nsParent+"/"+nameParent)
+	}
+	utils.TraceFanOut(key, parentKeys)
+}
+
 func getIngressNSNameForIngestion(objType, namespace, nsname string) (string, string) {
-	if objType == lib.HostRule || objType == lib.HTTPRule {
+	if objType == lib.HostRule || objType == lib.HTTPRule || objType == lib.HostnameClaim ||
+		objType == lib.IngressClass || objType == lib.AviInfraSetting {
 		arr := strings.Split(nsname, "/")
 		return arr[0], arr[1]
 	}
@@ -270,6 +285,12 @@ func saveAviModel(model_name string, aviGraph *AviObjectGraph, key string) bool
 		utils.AviLog.Infof("key: %s, msg: Disable Sync is True, model %s can not be saved", key, model_name)
 		return false
 	}
+	// the nodes are built in the tenant AKO runs in, the ones of a VS placed in another tenant are moved to it
+	if tenant, _ := utils.ExtractNamespaceObjectName(model_name); tenant != "" && tenant != lib.GetTenant() {
+		for _, vsNode := range aviGraph.GetAviVS() {
+			vsNode.setTenant(tenant)
+		}
+	}
 	found, aviModel := objects.SharedAviGraphLister().Get(model_name)
 	if found && aviModel != nil {
 		prevChecksum := aviModel.(*AviObjectGraph).GraphChecksum
@@ -322,6 +343,9 @@ func processNodeObj(key, nodename string, sharedQueue *utils.WorkerQueue, fullsy
 
 func PublishKeyToRestLayer(model_name string, key string, sharedQueue *utils.WorkerQueue) {
 	bkt := utils.Bkt(model_name, sharedQueue.NumWorkers)
+	// the rest layer logs of the model carry the correlation id of the key
+	utils.SetCorrelationID(model_name, utils.GetCorrelationID(key))
+	utils.TraceModelPublish(key, model_name)
 	sharedQueue.Workqueue[bkt].AddRateLimited(model_name)
 	utils.AviLog.Infof("key: %s, msg: Published key with model_name: %s", key, model_name)
 
@@ -390,6 +414,24 @@ func GetShardVSName(s string, key string) string {
 func DeriveHostNameShardVS(hostname string, key string) string {
 	// Read the value of the num_shards from the environment variable.
 	utils.AviLog.Debugf("key: %s, msg: hostname for sharding: %s", key, hostname)
+	if SharedDedicatedVSStore().IsDedicated(hostname) {
+		vsName := lib.GetDedicatedVSName(hostname)
+		utils.AviLog.Infof("key: %s, msg: DedicatedVSName: %s", key, vsName)
+		return vsName
+	}
+	if vsName := SharedInfraSettingStore().GetHostVSName(hostname); vsName != "" {
+		utils.AviLog.Infof("key: %s, msg: ShardVSName: %s", key, vsName)
+		return vsName
+	}
+	return deriveSharedHostNameShardVS(hostname, key)
+}
+
+// deriveSharedHostNameShardVS returns the shard VS of the host, whether or not it is served on a dedicated VS or on
+// a VS of its AviInfraSetting
+func deriveSharedHostNameShardVS(hostname string, key string) string {
+	if lib.IsShardAssignmentPersisted() {
+		return SharedShardAssignment().GetShardVSName(hostname, key)
+	}
 	vsName := GetShardVSName(hostname, key)
 	return vsName
 }
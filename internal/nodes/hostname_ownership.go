@@ -0,0 +1,349 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package nodes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/status"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var hostnameClaimStoreInstance *HostnameClaimStore
+var hcsonce sync.Once
+
+func SharedHostnameClaimStore() *HostnameClaimStore {
+	hcsonce.Do(func() {
+		hostnameClaimStoreInstance = NewHostnameClaimStore()
+	})
+	return hostnameClaimStoreInstance
+}
+
+func NewHostnameClaimStore() *HostnameClaimStore {
+	return &HostnameClaimStore{
+		hostClaimants: make(map[string][]hostnameClaimant),
+		claimantHosts: make(map[string][]string),
+	}
+}
+
+// HostnameClaimStore keeps track of the ingresses/routes publishing a host, the oldest first. The order
+// only depends on the objects, so that the owner of a host is the same whichever order they are
+// processed in, e.g. after a restart. Objects are keyed the same way as in the ingestion queue.
+// cache sample: foo.com -> [Ingress/ns1/ingress1, Ingress/ns2/ingress2]
+type HostnameClaimStore struct {
+	sync.RWMutex
+	hostClaimants map[string][]hostnameClaimant
+	claimantHosts map[string][]string
+}
+
+type hostnameClaimant struct {
+	key     string
+	created time.Time
+}
+
+// before orders the claimants by creation timestamp, the ones created in the same second by namespace
+// and name.
+func (c hostnameClaimant) before(other hostnameClaimant) bool {
+	if !c.created.Equal(other.created) {
+		return c.created.Before(other.created)
+	}
+	objType, namespace, name := extractTypeNameNamespace(c.key)
+	otherType, otherNamespace, otherName := extractTypeNameNamespace(other.key)
+	if namespace != otherNamespace {
+		return namespace < otherNamespace
+	}
+	if name != otherName {
+		return name < otherName
+	}
+	return objType < otherType
+}
+
+// UpdateClaims records the hosts published by an ingress/route created at the given time, and returns the
+// hosts for which the namespace of the first claimant changed as a result.
+func (h *HostnameClaimStore) UpdateClaims(objKey string, created time.Time, hosts []string) []string {
+	h.Lock()
+	defer h.Unlock()
+	oldOwners := make(map[string]string)
+	for _, host := range append(append([]string{}, h.claimantHosts[objKey]...), hosts...) {
+		oldOwners[host] = h.firstClaimNamespace(host)
+	}
+
+	for _, host := range h.claimantHosts[objKey] {
+		if !utils.HasElem(hosts, host) {
+			h.removeClaimant(host, objKey)
+		}
+	}
+	for _, host := range hosts {
+		h.removeClaimant(host, objKey)
+		claimant := hostnameClaimant{key: objKey, created: created}
+		claimants := h.hostClaimants[host]
+		i := sort.Search(len(claimants), func(i int) bool {
+			return claimant.before(claimants[i])
+		})
+		claimants = append(claimants, hostnameClaimant{})
+		copy(claimants[i+1:], claimants[i:])
+		claimants[i] = claimant
+		h.hostClaimants[host] = claimants
+	}
+
+	if len(hosts) == 0 {
+		delete(h.claimantHosts, objKey)
+	} else {
+		h.claimantHosts[objKey] = append([]string{}, hosts...)
+	}
+
+	var changedHosts []string
+	for host, oldOwner := range oldOwners {
+		newOwner := h.firstClaimNamespace(host)
+		if oldOwner != "" && newOwner != "" && oldOwner != newOwner {
+			changedHosts = append(changedHosts, host)
+		}
+	}
+	sort.Strings(changedHosts)
+	return changedHosts
+}
+
+// removeClaimant is called with the store lock held
+func (h *HostnameClaimStore) removeClaimant(host, objKey string) {
+	claimants := h.hostClaimants[host]
+	for i, claimant := range claimants {
+		if claimant.key == objKey {
+			claimants = append(claimants[:i:i], claimants[i+1:]...)
+			break
+		}
+	}
+	if len(claimants) == 0 {
+		delete(h.hostClaimants, host)
+		return
+	}
+	h.hostClaimants[host] = claimants
+}
+
+func (h *HostnameClaimStore) DeleteClaims(objKey string) []string {
+	return h.UpdateClaims(objKey, time.Time{}, nil)
+}
+
+func (h *HostnameClaimStore) GetClaimants(host string) []string {
+	h.RLock()
+	defer h.RUnlock()
+	var claimants []string
+	for _, claimant := range h.hostClaimants[host] {
+		claimants = append(claimants, claimant.key)
+	}
+	return claimants
+}
+
+func (h *HostnameClaimStore) FirstClaimNamespace(host string) string {
+	h.RLock()
+	defer h.RUnlock()
+	return h.firstClaimNamespace(host)
+}
+
+func (h *HostnameClaimStore) firstClaimNamespace(host string) string {
+	claimants := h.hostClaimants[host]
+	if len(claimants) == 0 {
+		return ""
+	}
+	_, namespace, _ := extractTypeNameNamespace(claimants[0].key)
+	return namespace
+}
+
+// HostnameOwnershipPolicy decides whether ingresses/routes of a namespace are allowed to publish a host.
+// When the host is owned elsewhere, the reason is returned along with the decision.
+type HostnameOwnershipPolicy interface {
+	IsHostOwner(host, namespace string) (bool, string)
+}
+
+// firstClaimPolicy hands over the host to the namespace of the oldest ingress/route publishing it.
+type firstClaimPolicy struct{}
+
+func (p firstClaimPolicy) IsHostOwner(host, namespace string) (bool, string) {
+	owner := SharedHostnameClaimStore().FirstClaimNamespace(host)
+	if owner == "" || owner == namespace {
+		return true, ""
+	}
+	return false, fmt.Sprintf("host %s is already claimed by namespace %s", host, owner)
+}
+
+// namespaceAllowListPolicy restricts the hosts under a domain to a list of namespaces, the longest
+// matching domain suffix decides. Hosts which do not fall under any of the domains are not restricted.
+type namespaceAllowListPolicy struct {
+	domainNamespaces map[string][]string
+}
+
+func (p namespaceAllowListPolicy) IsHostOwner(host, namespace string) (bool, string) {
+	var matchedDomain string
+	for domain := range p.domainNamespaces {
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(matchedDomain) {
+			matchedDomain = domain
+		}
+	}
+	if matchedDomain == "" || utils.HasElem(p.domainNamespaces[matchedDomain], namespace) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("namespace %s is not allowed to publish hosts under %s", namespace, matchedDomain)
+}
+
+// hostnameClaimPolicy only allows the namespace of the accepted HostnameClaim for the host to publish it.
+type hostnameClaimPolicy struct{}
+
+func (p hostnameClaimPolicy) IsHostOwner(host, namespace string) (bool, string) {
+	found, claim := objects.SharedCRDLister().GetFQDNToHostnameClaimMapping(host)
+	if !found {
+		return false, fmt.Sprintf("host %s is not claimed by any HostnameClaim", host)
+	}
+	claimNamespace := strings.Split(claim, "/")[0]
+	if claimNamespace != namespace {
+		return false, fmt.Sprintf("host %s is claimed by HostnameClaim %s", host, claim)
+	}
+	return true, ""
+}
+
+// GetHostnameOwnershipPolicy returns nil when no ownership policy is configured,
+// in which case hosts can be shared across namespaces.
+func GetHostnameOwnershipPolicy() HostnameOwnershipPolicy {
+	switch lib.GetHostnameOwnershipPolicy() {
+	case lib.HostnameOwnershipFirstClaim:
+		return firstClaimPolicy{}
+	case lib.HostnameOwnershipNamespaceAllowList:
+		domainNamespaces, err := lib.GetDomainNamespaceMap()
+		if err != nil {
+			utils.AviLog.Warnf("Unable to parse the domain namespace list: %v", err)
+		}
+		return namespaceAllowListPolicy{domainNamespaces: domainNamespaces}
+	case lib.HostnameOwnershipHostnameClaim:
+		return hostnameClaimPolicy{}
+	}
+	return nil
+}
+
+func parsedIngHosts(parsedIng IngressConfig) []string {
+	var hosts []string
+	for host := range parsedIng.IngressHostMap {
+		hosts = append(hosts, host)
+	}
+	for _, tlsSetting := range parsedIng.TlsCollection {
+		for host := range tlsSetting.Hosts {
+			if !utils.HasElem(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	for host := range parsedIng.PassthroughCollection {
+		if !utils.HasElem(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// applyHostnameOwnership removes the hosts owned by other namespaces from the parsed ingress/route,
+// so that they never make it to the models. The ingress/route is notified through an event,
+// routes additionally get a rejected status.
+func applyHostnameOwnership(routeIgrObj RouteIngressModel, parsedIng *IngressConfig, key string) {
+	policy := GetHostnameOwnershipPolicy()
+	if policy == nil {
+		return
+	}
+	objType, namespace, name := routeIgrObj.GetType(), routeIgrObj.GetNamespace(), routeIgrObj.GetName()
+	objKey := objType + "/" + namespace + "/" + name
+	hosts := parsedIngHosts(*parsedIng)
+	changedHosts := SharedHostnameClaimStore().UpdateClaims(objKey, routeIgrObj.GetCreationTimestamp(), hosts)
+	requeueHostnameClaimants(changedHosts, objKey, key)
+
+	var rejected bool
+	for _, host := range hosts {
+		isOwner, reason := policy.IsHostOwner(host, namespace)
+		if isOwner {
+			continue
+		}
+		utils.AviLog.Warnf("key: %s, msg: skipping host %s for %s %s/%s: %s", key, host, objType, namespace, name, reason)
+		delete(parsedIng.IngressHostMap, host)
+		delete(parsedIng.PassthroughCollection, host)
+		for _, tlsSetting := range parsedIng.TlsCollection {
+			delete(tlsSetting.Hosts, host)
+		}
+		recordHostConflictEvent(objType, namespace, name, reason)
+		rejected = true
+	}
+	if !rejected {
+		return
+	}
+
+	var tlsCollection []TlsSettings
+	for _, tlsSetting := range parsedIng.TlsCollection {
+		if len(tlsSetting.Hosts) > 0 {
+			tlsCollection = append(tlsCollection, tlsSetting)
+		}
+	}
+	parsedIng.TlsCollection = tlsCollection
+	if objType == utils.OshiftRoute {
+		status.UpdateRouteStatusWithErrMsg(name, namespace, lib.HostAlreadyClaimed)
+	}
+}
+
+// releaseHostnameClaims drops the hosts claimed by a deleted ingress/route.
+func releaseHostnameClaims(objType, namespace, name, key string) {
+	objKey := objType + "/" + namespace + "/" + name
+	changedHosts := SharedHostnameClaimStore().DeleteClaims(objKey)
+	requeueHostnameClaimants(changedHosts, objKey, key)
+}
+
+// requeueHostnameClaimants sends the remaining claimants of hosts, which changed hands under the
+// FirstClaim policy, back to the ingestion layer so that the new owner gets to publish the host.
+func requeueHostnameClaimants(hosts []string, objKey, key string) {
+	if len(hosts) == 0 || lib.GetHostnameOwnershipPolicy() != lib.HostnameOwnershipFirstClaim {
+		return
+	}
+	ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	var requeued []string
+	for _, host := range hosts {
+		for _, claimant := range SharedHostnameClaimStore().GetClaimants(host) {
+			if claimant == objKey || utils.HasElem(requeued, claimant) {
+				continue
+			}
+			_, namespace, _ := extractTypeNameNamespace(claimant)
+			bkt := utils.Bkt(namespace, ingestionQueue.NumWorkers)
+			ingestionQueue.Workqueue[bkt].AddRateLimited(claimant)
+			requeued = append(requeued, claimant)
+		}
+	}
+	utils.AviLog.Infof("key: %s, msg: requeued claimants of hosts %v: %v", key, hosts, requeued)
+}
+
+func recordHostConflictEvent(objType, namespace, name, reason string) {
+	recorder := lib.GetEventRecorder()
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      "Ingress",
+		Namespace: namespace,
+		Name:      name,
+	}
+	if objType == utils.OshiftRoute {
+		ref.Kind = "Route"
+	}
+	recorder.Event(ref, corev1.EventTypeWarning, lib.HostAlreadyClaimed, reason)
+}
@@ -0,0 +1,425 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package nodes
+
+import (
+	"strings"
+	"sync"
+
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+var infraSettingStoreInstance *InfraSettingStore
+var infrasettingonce sync.Once
+
+func SharedInfraSettingStore() *InfraSettingStore {
+	infrasettingonce.Do(func() {
+		infraSettingStoreInstance = &InfraSettingStore{
+			requests:        newHostRequests(),
+			requestSettings: make(map[string]string),
+			hostVSNames:     make(map[string]string),
+			vsSettings:      make(map[string]string),
+			vsTenants:       make(map[string]string),
+			validations:     make(map[string]error),
+		}
+	})
+	return infraSettingStoreInstance
+}
+
+// InfraSettingStore keeps track of the AviInfraSetting referred by the IngressClass of each ingress. The hosts
+// of these ingresses are placed on shard VSes of the AviInfraSetting, which get its SEG and VIP network and are
+// created in its tenant. A host published by several ingresses follows the AviInfraSetting of the first one.
+// cache sample: foo.com -> [Ingress/ns1/ingress1], Ingress/ns1/ingress1 -> setting1
+type InfraSettingStore struct {
+	sync.RWMutex
+	requests        hostRequests
+	requestSettings map[string]string
+	// hostVSNames holds the VS of the hosts built on a shard VS of their AviInfraSetting
+	hostVSNames map[string]string
+	// vsSettings holds the AviInfraSetting applied to each VS
+	vsSettings map[string]string
+	// vsTenants holds the tenant of the VSes placed in the tenant of their AviInfraSetting. It is kept once the
+	// VS is deleted, so that the deletion goes to the tenant the VS was in.
+	vsTenants map[string]string
+	// validations holds the result of the last validation of each AviInfraSetting
+	validations map[string]error
+}
+
+// UpdateRequests records the AviInfraSetting of an ingress/route along with its hosts, and returns the hosts of
+// the ingress/route before and after the update.
+func (s *InfraSettingStore) UpdateRequests(objKey, infraSetting string, hosts []string) []string {
+	s.Lock()
+	defer s.Unlock()
+	if infraSetting == "" {
+		hosts = nil
+	}
+	changedHosts := append([]string{}, s.requests.requestHosts[objKey]...)
+	s.requests.update(objKey, hosts)
+	if infraSetting == "" {
+		delete(s.requestSettings, objKey)
+	} else {
+		s.requestSettings[objKey] = infraSetting
+	}
+	for _, host := range hosts {
+		if !utils.HasElem(changedHosts, host) {
+			changedHosts = append(changedHosts, host)
+		}
+	}
+	return changedHosts
+}
+
+func (s *InfraSettingStore) getRequestedSetting(host string) string {
+	s.RLock()
+	defer s.RUnlock()
+	requests := s.requests.hostRequests[host]
+	if len(requests) == 0 {
+		return ""
+	}
+	return s.requestSettings[requests[0]]
+}
+
+// GetHostVSName returns the shard VS of the AviInfraSetting the host is built on, if any
+func (s *InfraSettingStore) GetHostVSName(host string) string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.hostVSNames[host]
+}
+
+func (s *InfraSettingStore) setHostVSName(host, vsName string) {
+	s.Lock()
+	defer s.Unlock()
+	if vsName == "" {
+		delete(s.hostVSNames, host)
+	} else {
+		s.hostVSNames[host] = vsName
+	}
+}
+
+// GetVSInfraSetting returns the AviInfraSetting applied to the VS, if any
+func (s *InfraSettingStore) GetVSInfraSetting(vsName string) string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.vsSettings[vsName]
+}
+
+// setVSInfraSetting records the AviInfraSetting applied to the VS and returns true if it changed
+func (s *InfraSettingStore) setVSInfraSetting(vsName, infraSetting string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.vsSettings[vsName] == infraSetting {
+		return false
+	}
+	if infraSetting == "" {
+		delete(s.vsSettings, vsName)
+	} else {
+		s.vsSettings[vsName] = infraSetting
+	}
+	return true
+}
+
+// GetVSTenant returns the tenant the VS is placed in, the one of its AviInfraSetting or the tenant AKO runs in
+func (s *InfraSettingStore) GetVSTenant(vsName string) string {
+	s.RLock()
+	defer s.RUnlock()
+	if tenant, ok := s.vsTenants[vsName]; ok {
+		return tenant
+	}
+	return lib.GetTenant()
+}
+
+// setVSTenant records the tenant the VS is placed in and returns the previous one
+func (s *InfraSettingStore) setVSTenant(vsName, tenant string) string {
+	s.Lock()
+	defer s.Unlock()
+	prevTenant, ok := s.vsTenants[vsName]
+	if !ok {
+		prevTenant = lib.GetTenant()
+	}
+	if tenant == lib.GetTenant() {
+		delete(s.vsTenants, vsName)
+	} else {
+		s.vsTenants[vsName] = tenant
+	}
+	return prevTenant
+}
+
+func (s *InfraSettingStore) setValidation(infraSetting string, err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.validations[infraSetting] = err
+}
+
+func (s *InfraSettingStore) deleteValidation(infraSetting string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.validations, infraSetting)
+}
+
+func (s *InfraSettingStore) getValidation(infraSetting string) (bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+	err, ok := s.validations[infraSetting]
+	return ok, err
+}
+
+// getHosts returns the hosts asking for an AviInfraSetting along with the hosts still built on one
+func (s *InfraSettingStore) getHosts() []string {
+	s.RLock()
+	defer s.RUnlock()
+	var hosts []string
+	for host := range s.requests.hostRequests {
+		hosts = append(hosts, host)
+	}
+	for host := range s.hostVSNames {
+		if len(s.requests.hostRequests[host]) == 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func (s *InfraSettingStore) getSettingVSNames(infraSetting string) []string {
+	s.RLock()
+	defer s.RUnlock()
+	var vsNames []string
+	for vsName, setting := range s.vsSettings {
+		if setting == infraSetting {
+			vsNames = append(vsNames, vsName)
+		}
+	}
+	return vsNames
+}
+
+// deleteUnusedVS deletes the shard VSes of the AviInfraSettings which no host is built on or being moved to or
+// from anymore. The dedicated VSes are deleted by the migrations moving their host away.
+func (s *InfraSettingStore) deleteUnusedVS(migrations []*shardMigration) {
+	used := make(map[string]bool)
+	for _, migration := range migrations {
+		used[migration.From] = true
+		used[migration.To] = true
+	}
+	var unused []string
+	s.Lock()
+	for _, vsName := range s.hostVSNames {
+		used[vsName] = true
+	}
+	for vsName := range s.vsSettings {
+		if used[vsName] || strings.HasPrefix(vsName, lib.GetNamePrefix()+lib.DedicatedVSPrefix) {
+			continue
+		}
+		delete(s.vsSettings, vsName)
+		unused = append(unused, vsName)
+	}
+	s.Unlock()
+
+	for _, vsName := range unused {
+		if getL7ModelByVSName(vsName) != nil {
+			deleteL7VSModel(vsName, true, shardMigrationKey)
+		}
+	}
+}
+
+// getAcceptedInfraSetting returns the AviInfraSetting if it exists and is valid. The refs on the controller are
+// checked once for each update of the AviInfraSetting, the result is recorded by its validation.
+func getAcceptedInfraSetting(name string) *akov1alpha1.AviInfraSetting {
+	if name == "" {
+		return nil
+	}
+	infraSetting, err := lib.GetCRDInformers().AviInfraSettingInformer.Lister().Get(name)
+	if err != nil {
+		return nil
+	}
+	if err := validateAviInfraSettingSpec(infraSetting.Spec); err != nil {
+		return nil
+	}
+	validated, err := SharedInfraSettingStore().getValidation(name)
+	if !validated {
+		err = validateAviInfraSetting(lib.AviInfraSetting+"/"+name, infraSetting)
+	}
+	if err != nil {
+		return nil
+	}
+	return infraSetting
+}
+
+// getInfraSettingTenant returns the tenant the VSes of the AviInfraSetting are placed in
+func getInfraSettingTenant(name string) string {
+	if infraSetting := getAcceptedInfraSetting(name); infraSetting != nil && infraSetting.Spec.Tenant != "" {
+		return infraSetting.Spec.Tenant
+	}
+	return lib.GetTenant()
+}
+
+// getVSModelName returns the name of the model of the L7 VS, keyed by the tenant it is placed in
+func getVSModelName(vsName string) string {
+	return lib.GetModelName(SharedInfraSettingStore().GetVSTenant(vsName), vsName)
+}
+
+// deriveRequestedHostVS returns the VS the host is asked for along with the AviInfraSetting to apply to it
+func deriveRequestedHostVS(host, key string) (string, string) {
+	infraSettingName := SharedInfraSettingStore().getRequestedSetting(host)
+	infraSetting := getAcceptedInfraSetting(infraSettingName)
+	if infraSetting == nil {
+		infraSettingName = ""
+	}
+	if SharedDedicatedVSStore().isRequested(host) {
+		return lib.GetDedicatedVSName(host), infraSettingName
+	}
+	if infraSetting == nil {
+		return deriveSharedHostNameShardVS(host, key), ""
+	}
+	shardSize, ok := lib.GetShardSizeByName(infraSetting.Spec.L7Settings.ShardSize)
+	if !ok {
+		shardSize = lib.GetshardSize()
+	}
+	return lib.GetInfraSettingShardVSName(infraSettingName, utils.Bkt(host, shardSize)), infraSettingName
+}
+
+// applyInfraSetting records the AviInfraSetting of the ingress, before its hosts are built on the VS returned by
+// DeriveHostNameShardVS.
+func applyInfraSetting(routeIgrObj RouteIngressModel, parsedIng IngressConfig, fullsync bool, key string) {
+	objKey := routeIgrObj.GetType() + "/" + routeIgrObj.GetNamespace() + "/" + routeIgrObj.GetName()
+	infraSetting := routeIgrObj.GetInfraSetting()
+	var hosts []string
+	if infraSetting != "" {
+		hosts = parsedIngHosts(parsedIng)
+	}
+	for _, host := range SharedInfraSettingStore().UpdateRequests(objKey, infraSetting, hosts) {
+		switchHostVS(host, fullsync, key)
+	}
+}
+
+// releaseInfraSetting drops the AviInfraSetting of a deleted ingress/route, after its pools were deleted.
+func releaseInfraSetting(objType, namespace, name, key string) {
+	objKey := objType + "/" + namespace + "/" + name
+	for _, host := range SharedInfraSettingStore().UpdateRequests(objKey, "", nil) {
+		switchHostVS(host, false, key)
+	}
+}
+
+// setVSNodeInfraSetting sets the SEG and the VIP network of the AviInfraSetting applied to the VS, or the ones
+// of the AKO configuration.
+func setVSNodeInfraSetting(vsNode *AviVsNode, key string) {
+	var segName, networkName string
+	if lib.GetSEGName() != lib.DEFAULT_GROUP {
+		segName = lib.GetSEGName()
+	}
+	if infraSetting := getAcceptedInfraSetting(SharedInfraSettingStore().GetVSInfraSetting(vsNode.Name)); infraSetting != nil {
+		if infraSetting.Spec.SeGroup.Name != "" {
+			segName = infraSetting.Spec.SeGroup.Name
+		}
+		networkName = infraSetting.Spec.Network.Name
+		utils.AviLog.Debugf("key: %s, msg: applying aviinfrasetting %s to VS %s", key, infraSetting.Name, vsNode.Name)
+	}
+	vsNode.ServiceEngineGroup = segName
+	for _, sniNode := range vsNode.SniNodes {
+		sniNode.ServiceEngineGroup = segName
+	}
+	for _, vsvipNode := range vsNode.VSVIPRefs {
+		vsvipNode.NetworkName = networkName
+	}
+}
+
+// applyVSInfraSetting updates the VS model after the AviInfraSetting applied to it changed. A VS whose tenant
+// changed is deleted from its old tenant and built again in the new one.
+func applyVSInfraSetting(vsName string, publish bool, key string) {
+	tenant := getInfraSettingTenant(SharedInfraSettingStore().GetVSInfraSetting(vsName))
+	if oldTenant := SharedInfraSettingStore().setVSTenant(vsName, tenant); oldTenant != tenant {
+		moveVSTenant(vsName, oldTenant, publish, key)
+		return
+	}
+	aviModel := getL7ModelByVSName(vsName)
+	if aviModel == nil {
+		return
+	}
+	aviModel.Lock.Lock()
+	if vsNode := aviModel.GetAviVS(); len(vsNode) > 0 {
+		setVSNodeInfraSetting(vsNode[0], key)
+	}
+	aviModel.Lock.Unlock()
+	modelName := getVSModelName(vsName)
+	if saveAviModel(modelName, aviModel, key) && publish {
+		PublishKeyToRestLayer(modelName, key, utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer))
+	}
+}
+
+// moveVSTenant deletes the model of the VS from its old tenant, the hosts it served are requeued to be built on
+// the VS in its new tenant.
+func moveVSTenant(vsName, oldTenant string, publish bool, key string) {
+	modelName := lib.GetModelName(oldTenant, vsName)
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return
+	}
+	hosts := getShardModelHosts(aviModel.(*AviObjectGraph))
+	objects.SharedAviGraphLister().Save(modelName, nil)
+	if publish {
+		PublishKeyToRestLayer(modelName, key, utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer))
+	}
+	utils.AviLog.Infof("key: %s, msg: VS %s moves from tenant %s to %s", key, vsName, oldTenant, SharedInfraSettingStore().GetVSTenant(vsName))
+	for host := range hosts {
+		requeueHost(host)
+	}
+}
+
+// setTenant places the VS along with its children in the tenant
+func (v *AviVsNode) setTenant(tenant string) {
+	v.Tenant = tenant
+	for _, pgNode := range v.PoolGroupRefs {
+		pgNode.Tenant = tenant
+	}
+	for _, pgNode := range v.TCPPoolGroupRefs {
+		pgNode.Tenant = tenant
+	}
+	for _, poolNode := range v.PoolRefs {
+		poolNode.Tenant = tenant
+		if poolNode.PkiProfile != nil {
+			poolNode.PkiProfile.Tenant = tenant
+		}
+	}
+	for _, dsNode := range v.HTTPDSrefs {
+		dsNode.Tenant = tenant
+	}
+	for _, certNode := range v.CACertRefs {
+		certNode.Tenant = tenant
+	}
+	for _, certNode := range v.SSLKeyCertRefs {
+		certNode.Tenant = tenant
+	}
+	for _, policyNode := range v.HttpPolicyRefs {
+		policyNode.Tenant = tenant
+	}
+	for _, vsvipNode := range v.VSVIPRefs {
+		vsvipNode.Tenant = tenant
+	}
+	for _, policyNode := range v.L4PolicyRefs {
+		policyNode.Tenant = tenant
+	}
+	if v.ClientAuthAppProfile != nil {
+		v.ClientAuthAppProfile.Tenant = tenant
+		if v.ClientAuthAppProfile.PkiProfile != nil {
+			v.ClientAuthAppProfile.PkiProfile.Tenant = tenant
+		}
+	}
+	for _, childNode := range v.SniNodes {
+		childNode.setTenant(tenant)
+	}
+	for _, childNode := range v.PassthroughChildNodes {
+		childNode.setTenant(tenant)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -73,6 +74,11 @@ var (
 		GetParentIngresses: HTTPRuleToIng,
 		GetParentRoutes:    HTTPRuleToIng,
 	}
+	HostnameClaim = GraphSchema{
+		Type:               "HostnameClaim",
+		GetParentIngresses: HostnameClaimToIng,
+		GetParentRoutes:    HostnameClaimToRoute,
+	}
 	Gateway = GraphSchema{
 		Type:              "Gateway",
 		GetParentGateways: GatewayChanges,
@@ -81,6 +87,14 @@ var (
 		Type:              "GatewayClass",
 		GetParentGateways: GatewayClassChanges,
 	}
+	IngressClass = GraphSchema{
+		Type:               "IngressClass",
+		GetParentIngresses: IngressClassToIng,
+	}
+	AviInfraSetting = GraphSchema{
+		Type:               "AviInfraSetting",
+		GetParentIngresses: AviInfraSettingToIng,
+	}
 	SupportedGraphTypes = GraphDescriptor{
 		Ingress,
 		Service,
@@ -90,8 +104,11 @@ var (
 		Node,
 		HostRule,
 		HTTPRule,
+		HostnameClaim,
 		Gateway,
 		GatewayClass,
+		IngressClass,
+		AviInfraSetting,
 	}
 )
 
@@ -368,10 +385,19 @@ func HostRuleToIng(hrname string, namespace string, key string) ([]string, bool)
 		utils.AviLog.Debugf("key: %s, msg: HostRule Deleted\n", key)
 		_, fqdn = objects.SharedCRDLister().GetHostruleToFQDNMapping(namespace + "/" + hrname)
 		objects.SharedCRDLister().DeleteHostruleFQDNMapping(namespace + "/" + hrname)
+		objects.SharedCRDLister().DeleteHostruleClientCASecretMapping(namespace + "/" + hrname)
 	} else if err != nil {
 		utils.AviLog.Errorf("key: %s, msg: Error getting hostrule: %v\n", key, err)
 		return nil, false
 	} else {
+		// the client CA secret mapping is kept even if validation fails, so that
+		// the hostrule is re-evaluated once the secret is created or fixed
+		if caSecret := hostrule.Spec.VirtualHost.TLS.ClientCertificate.CASecret; caSecret != "" {
+			objects.SharedCRDLister().UpdateClientCASecretHostruleMapping(namespace+"/"+caSecret, namespace+"/"+hrname)
+		} else {
+			objects.SharedCRDLister().DeleteHostruleClientCASecretMapping(namespace + "/" + hrname)
+		}
+
 		if err = validateHostRuleObj(key, hostrule); err != nil {
 			return allIngresses, false
 		}
@@ -438,11 +464,22 @@ func HTTPRuleToIng(rrname string, namespace string, key string) ([]string, bool)
 			oldPathRules[i] = elem
 		}
 		objects.SharedCRDLister().RemoveFqdnHTTPRulesMappings(namespace + "/" + rrname)
+		objects.SharedCRDLister().DeleteHTTPRuleDestCASecretsMapping(namespace + "/" + rrname)
 	} else if err != nil {
 		utils.AviLog.Errorf("key: %s, msg: Error getting httprule: %v\n", key, err)
 		return nil, false
 	} else {
 		utils.AviLog.Debugf("key: %s, HTTPRule %v\n", key, httprule)
+		// as with hostrules, the destination CA secret mappings are kept for rejected
+		// httprules, so that they are re-evaluated once the secrets show up
+		var destCASecrets []string
+		for _, path := range httprule.Spec.Paths {
+			if caSecret := path.TLS.DestinationCASecret; caSecret != "" && !utils.HasElem(destCASecrets, namespace+"/"+caSecret) {
+				destCASecrets = append(destCASecrets, namespace+"/"+caSecret)
+			}
+		}
+		objects.SharedCRDLister().UpdateHTTPRuleDestCASecretsMapping(namespace+"/"+rrname, destCASecrets)
+
 		if err = validateHTTPRuleObj(key, httprule); err != nil {
 			return allIngresses, false
 		}
@@ -513,6 +550,133 @@ func HTTPRuleToIng(rrname string, namespace string, key string) ([]string, bool)
 	return allIngresses, true
 }
 
+func HostnameClaimToIng(hcname string, namespace string, key string) ([]string, bool) {
+	return hostnameClaimToIngRoute(utils.Ingress, hcname, namespace, key)
+}
+
+func HostnameClaimToRoute(hcname string, namespace string, key string) ([]string, bool) {
+	return hostnameClaimToIngRoute(utils.OshiftRoute, hcname, namespace, key)
+}
+
+// hostnameClaimToIngRoute returns the ingresses/routes publishing the fqdn of the hostnameclaim,
+// across all namespaces, so that their hosts are re-evaluated against the claim.
+func hostnameClaimToIngRoute(objType, hcname, namespace, key string) ([]string, bool) {
+	var fqdns []string
+	allIngresses := make([]string, 0)
+	hostnameclaim, err := lib.GetCRDInformers().HostnameClaimInformer.Lister().HostnameClaims(namespace).Get(hcname)
+	if errors.IsNotFound(err) {
+		utils.AviLog.Debugf("key: %s, msg: HostnameClaim Deleted\n", key)
+		found, fqdn := objects.SharedCRDLister().GetHostnameClaimToFQDNMapping(namespace + "/" + hcname)
+		if found {
+			objects.SharedCRDLister().DeleteHostnameClaimFQDNMapping(namespace + "/" + hcname)
+			fqdns = append(fqdns, fqdn)
+			requeueRejectedHostnameClaims(fqdn, namespace+"/"+hcname, key)
+		}
+	} else if err != nil {
+		utils.AviLog.Errorf("key: %s, msg: Error getting hostnameclaim: %v\n", key, err)
+		return nil, false
+	} else {
+		if err = validateHostnameClaimObj(key, hostnameclaim); err != nil {
+			return allIngresses, false
+		}
+
+		fqdns = append(fqdns, hostnameclaim.Spec.Fqdn)
+		oldFound, oldFqdn := objects.SharedCRDLister().GetHostnameClaimToFQDNMapping(namespace + "/" + hcname)
+		if oldFound && oldFqdn != hostnameclaim.Spec.Fqdn {
+			objects.SharedCRDLister().DeleteHostnameClaimFQDNMapping(namespace + "/" + hcname)
+			fqdns = append(fqdns, oldFqdn)
+			requeueRejectedHostnameClaims(oldFqdn, namespace+"/"+hcname, key)
+		}
+		objects.SharedCRDLister().UpdateFQDNHostnameClaimMapping(hostnameclaim.Spec.Fqdn, namespace+"/"+hcname)
+	}
+
+	for _, fqdn := range fqdns {
+		for _, claimant := range SharedHostnameClaimStore().GetClaimants(fqdn) {
+			claimantType, claimantNS, claimantName := extractTypeNameNamespace(claimant)
+			if claimantType != objType || utils.HasElem(allIngresses, claimantNS+"/"+claimantName) {
+				continue
+			}
+			allIngresses = append(allIngresses, claimantNS+"/"+claimantName)
+		}
+	}
+
+	utils.AviLog.Infof("key: %s, msg: ingresses to compute: %v via hostnameclaim %s",
+		key, allIngresses, namespace+"/"+hcname)
+	return allIngresses, true
+}
+
+// requeueRejectedHostnameClaims re-evaluates the other hostnameclaims for an fqdn released by a hostnameclaim.
+func requeueRejectedHostnameClaims(fqdn, releasedBy, key string) {
+	hostnameClaims, err := lib.GetCRDInformers().HostnameClaimInformer.Lister().List(labels.Set(nil).AsSelector())
+	if err != nil {
+		utils.AviLog.Warnf("key: %s, msg: Unable to list hostnameclaims: %v", key, err)
+		return
+	}
+	ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	for _, hostnameclaim := range hostnameClaims {
+		if hostnameclaim.Spec.Fqdn != fqdn || hostnameclaim.Namespace+"/"+hostnameclaim.Name == releasedBy {
+			continue
+		}
+		bkt := utils.Bkt(hostnameclaim.Namespace, ingestionQueue.NumWorkers)
+		ingestionQueue.Workqueue[bkt].AddRateLimited(lib.HostnameClaim + "/" + utils.ObjKey(hostnameclaim))
+	}
+}
+
+// IngressClassToIng returns the ingresses of the IngressClass, along with the ingresses without a class which
+// might belong to it as the default IngressClass.
+func IngressClassToIng(ingClassName string, namespace string, key string) ([]string, bool) {
+	allIngresses := filterIngresses(key, func(ingress *v1beta1.Ingress) bool {
+		className := lib.GetIngressClassName(ingress)
+		return className == "" || className == ingClassName
+	})
+	utils.AviLog.Infof("key: %s, msg: ingresses to compute: %v via ingressclass %s", key, allIngresses, ingClassName)
+	return allIngresses, true
+}
+
+// AviInfraSettingToIng validates the AviInfraSetting and returns the ingresses of the IngressClasses referring
+// to it, the VSes the AviInfraSetting is applied to are updated right away.
+func AviInfraSettingToIng(infraSettingName string, namespace string, key string) ([]string, bool) {
+	infraSetting, err := lib.GetCRDInformers().AviInfraSettingInformer.Lister().Get(infraSettingName)
+	if errors.IsNotFound(err) {
+		utils.AviLog.Debugf("key: %s, msg: AviInfraSetting Deleted\n", key)
+		SharedInfraSettingStore().deleteValidation(infraSettingName)
+	} else if err != nil {
+		utils.AviLog.Errorf("key: %s, msg: Error getting aviinfrasetting: %v\n", key, err)
+		return nil, false
+	} else if err = validateAviInfraSettingObj(key, infraSetting); err == nil {
+		for _, vsName := range SharedInfraSettingStore().getSettingVSNames(infraSettingName) {
+			applyVSInfraSetting(vsName, true, key)
+		}
+	}
+
+	allIngresses := filterIngresses(key, func(ingress *v1beta1.Ingress) bool {
+		_, ingClass := getIngressClassObj(ingress)
+		return ingClass != nil && lib.GetIngressClassInfraSetting(ingClass) == infraSettingName
+	})
+	utils.AviLog.Infof("key: %s, msg: ingresses to compute: %v via aviinfrasetting %s", key, allIngresses, infraSettingName)
+	return allIngresses, true
+}
+
+// filterIngresses returns the ingresses across all namespaces matching the filter, keyed as namespace/name
+func filterIngresses(key string, filter func(*v1beta1.Ingress) bool) []string {
+	allIngresses := make([]string, 0)
+	if utils.GetInformers().IngressInformer == nil {
+		return allIngresses
+	}
+	ingObjs, err := utils.GetInformers().IngressInformer.Lister().ByNamespace("").List(labels.Set(nil).AsSelector())
+	if err != nil {
+		utils.AviLog.Warnf("key: %s, msg: Unable to list ingresses: %v", key, err)
+		return allIngresses
+	}
+	for _, ingObj := range ingObjs {
+		ingress, ok := utils.ToNetworkingIngress(ingObj)
+		if ok && filter(ingress) {
+			allIngresses = append(allIngresses, ingress.Namespace+"/"+ingress.Name)
+		}
+	}
+	return allIngresses
+}
+
 func parseServicesForIngress(ingSpec v1beta1.IngressSpec, key string) []string {
 	// Figure out the service names that are part of this ingress
 	var services []string
@@ -568,7 +732,11 @@ func parseGatewayForListeners(gateway *advl4v1alpha1pre1.Gateway, key string) []
 	return listeners
 }
 
-func filterIngressOnClass(ingress *v1beta1.Ingress) bool {
+// FilterIngressOnClass returns true if the ingress is served by AKO, going by its ingress class
+func FilterIngressOnClass(ingress *v1beta1.Ingress) bool {
+	if lib.IsIngressClassEnabled() {
+		return filterIngressOnIngressClass(ingress)
+	}
 	// If Avi is not the default ingress, then filter on ingress class.
 	if !lib.GetDefaultIngController() {
 		annotations := ingress.GetAnnotations()
@@ -591,3 +759,39 @@ func filterIngressOnClass(ingress *v1beta1.Ingress) bool {
 		}
 	}
 }
+
+// getIngressClassObj returns the ingress class name of the ingress along with its IngressClass object, the
+// default IngressClass is returned for an ingress without an ingress class.
+func getIngressClassObj(ingress *v1beta1.Ingress) (string, *unstructured.Unstructured) {
+	className := lib.GetIngressClassName(ingress)
+	if className == "" {
+		ingClass, _ := lib.GetDefaultIngressClass()
+		return className, ingClass
+	}
+	ingClass, _ := lib.GetIngressClass(className)
+	return className, ingClass
+}
+
+// filterIngressOnIngressClass accepts the ingresses of the IngressClasses with the AKO controller. An ingress
+// class without an IngressClass object falls back to the legacy handling of the ingress class annotation.
+func filterIngressOnIngressClass(ingress *v1beta1.Ingress) bool {
+	className, ingClass := getIngressClassObj(ingress)
+	if ingClass != nil {
+		if !lib.IsAviIngressClass(ingClass) {
+			utils.AviLog.Infof("Not processing the ingress: %s/%s since its IngressClass %s has the controller of another ingress controller", ingress.Namespace, ingress.Name, ingClass.GetName())
+			return false
+		}
+		return true
+	}
+	if className == "" {
+		if !lib.GetDefaultIngController() {
+			utils.AviLog.Infof("AKO is not running as the default ingress controller. Not processing the ingress: %s/%s without an ingress class", ingress.Namespace, ingress.Name)
+		}
+		return lib.GetDefaultIngController()
+	}
+	if className != lib.AVI_INGRESS_CLASS {
+		utils.AviLog.Infof("Not processing the ingress: %s/%s since its ingress class is set to : %s", ingress.Namespace, ingress.Name, className)
+		return false
+	}
+	return true
+}
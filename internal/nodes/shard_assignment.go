@@ -0,0 +1,741 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	ShardMigrationBuilding = "building"
+	ShardMigrationRemoving = "removing"
+
+	shardAssignmentSyncInterval = 5 * time.Second
+	// shardMigrationTimeout is how long a step of a migration waits for its VS to be synced before it is reported
+	// as stuck, the migration keeps waiting as the host is still served by the old VS.
+	shardMigrationTimeout = 5 * time.Minute
+	shardMigrationKey     = "ShardMigration"
+)
+
+type shardMigration struct {
+	apimodels.ShardMigration
+	// switchHost has the host built on the new VS the next time its ingresses and routes are processed
+	switchHost func()
+	// deleteFrom is set when the old VS served the host alone, it is deleted instead of being published
+	deleteFrom  bool
+	switched    bool
+	stepStarted time.Time
+	stuck       bool
+}
+
+// ShardAssignment keeps the shard VS each host is placed on, so that a host stays on its shard when the shard
+// size changes or when the other hosts come and go. A host seen for the first time is placed by the hash of its
+// name like without the assignment, unless that shard already has as many SNI children as allowed, in which case
+// it goes to the least loaded shard. Hosts are moved between shards by migrations, which build the host on the
+// new shard before removing it from the old one. The hosts moved to or off their dedicated VS or the VSes of their
+// AviInfraSetting go through the same migrations.
+type ShardAssignment struct {
+	lock sync.Mutex
+	// syncLock keeps the syncs from moving the same migration forward twice
+	syncLock   sync.Mutex
+	hosts      map[string]uint32
+	migrations map[string]*shardMigration
+	// stale holds the assigned hosts not found in any shard model at the last sync, they are forgotten if they
+	// are still missing at the next one
+	stale map[string]bool
+	dirty bool
+}
+
+type persistedShardAssignment struct {
+	ShardSize uint32            `json:"shard_size"`
+	Hosts     map[string]uint32 `json:"hosts"`
+}
+
+var shardAssignmentInstance *ShardAssignment
+var shardassignmentonce sync.Once
+
+func SharedShardAssignment() *ShardAssignment {
+	shardassignmentonce.Do(func() {
+		shardAssignmentInstance = &ShardAssignment{
+			hosts:      make(map[string]uint32),
+			migrations: make(map[string]*shardMigration),
+			stale:      make(map[string]bool),
+		}
+	})
+	return shardAssignmentInstance
+}
+
+func getShardVSNameByNumber(shard uint32) string {
+	return lib.GetNamePrefix() + lib.ShardVSPrefix + "-" + fmt.Sprint(shard)
+}
+
+func getShardModelByNumber(shard uint32) *AviObjectGraph {
+	return getL7ModelByVSName(getShardVSNameByNumber(shard))
+}
+
+func getL7ModelByVSName(vsName string) *AviObjectGraph {
+	modelName := getVSModelName(vsName)
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return nil
+	}
+	return aviModel.(*AviObjectGraph)
+}
+
+// GetShardVSName returns the shard VS of the host, placing the host first if it is not assigned yet or if its
+// shard is gone after the shard size was reduced.
+func (s *ShardAssignment) GetShardVSName(hostname, key string) string {
+	shardSize := lib.GetshardSize()
+	s.lock.Lock()
+	shard, ok := s.hosts[hostname]
+	s.lock.Unlock()
+	if !ok || shard >= shardSize {
+		// the loads are read from the models outside the lock, as the models are locked by the graph layer
+		loads := getShardLoads(shardSize)
+		s.lock.Lock()
+		shard, ok = s.hosts[hostname]
+		if !ok || shard >= shardSize {
+			shard = placeHost(hostname, loads, key)
+			s.hosts[hostname] = shard
+			s.dirty = true
+		}
+		s.lock.Unlock()
+	}
+	vsName := getShardVSNameByNumber(shard)
+	utils.AviLog.Infof("key: %s, msg: ShardVSName: %s", key, vsName)
+	return vsName
+}
+
+// placeHost returns the shard the host hashes to, or the least loaded one if that shard is full. The host is
+// hashed by rendezvous hashing, so that a change of the shard size moves as few of the new hosts as possible.
+func placeHost(hostname string, loads []int, key string) uint32 {
+	shard := utils.RendezvousBkt(hostname, uint32(len(loads)))
+	maxChildren := lib.GetShardVSMaxSNIChildren()
+	if maxChildren == 0 || loads[shard] < maxChildren {
+		return shard
+	}
+	leastLoaded := shard
+	for i := range loads {
+		if loads[i] < loads[leastLoaded] {
+			leastLoaded = uint32(i)
+		}
+	}
+	utils.AviLog.Infof("key: %s, msg: shard %d of host %s has %d SNI children, placing it on shard %d", key, shard, hostname, loads[shard], leastLoaded)
+	return leastLoaded
+}
+
+// getShardLoads returns the number of SNI children of each shard VS
+func getShardLoads(shardSize uint32) []int {
+	loads := make([]int, shardSize)
+	for i := uint32(0); i < shardSize; i++ {
+		aviModel := getShardModelByNumber(i)
+		if aviModel == nil {
+			continue
+		}
+		aviModel.Lock.RLock()
+		for _, vsNode := range aviModel.GetAviVS() {
+			loads[i] += len(vsNode.SniNodes)
+		}
+		aviModel.Lock.RUnlock()
+	}
+	return loads
+}
+
+// getShardModelHosts returns the hosts a shard model serves, from its FQDNs, SNI children and pools
+func getShardModelHosts(aviModel *AviObjectGraph) map[string]bool {
+	hosts := make(map[string]bool)
+	aviModel.Lock.RLock()
+	defer aviModel.Lock.RUnlock()
+	for _, vsNode := range aviModel.GetAviVS() {
+		if len(vsNode.VSVIPRefs) > 0 {
+			for _, fqdn := range vsNode.VSVIPRefs[0].FQDNs {
+				hosts[fqdn] = true
+			}
+		}
+		for _, sniNode := range vsNode.SniNodes {
+			for _, host := range sniNode.VHDomainNames {
+				hosts[host] = true
+			}
+		}
+		for _, pool := range vsNode.PoolRefs {
+			hosts[strings.SplitN(pool.PriorityLabel, "/", 2)[0]] = true
+		}
+	}
+	return hosts
+}
+
+// Load reads the assignment persisted in the shard assignment configmap, the hosts assigned to the shards
+// beyond the current shard size are placed again when they are next processed.
+func (s *ShardAssignment) Load() {
+	cm, err := utils.GetInformers().ClientSet.CoreV1().ConfigMaps(lib.AviNS).Get(lib.ShardAssignmentConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		utils.AviLog.Infof("Shard assignment configmap not found, the hosts are placed by their hash")
+		return
+	} else if err != nil {
+		utils.AviLog.Warnf("Unable to read the shard assignment configmap, the hosts are placed by their hash: %v", err)
+		return
+	}
+	var persisted persistedShardAssignment
+	if err := json.Unmarshal([]byte(cm.Data[lib.ShardAssignmentKey]), &persisted); err != nil {
+		utils.AviLog.Warnf("Unable to parse the shard assignment configmap, the hosts are placed by their hash: %v", err)
+		return
+	}
+	shardSize := lib.GetshardSize()
+	if persisted.ShardSize != shardSize {
+		utils.AviLog.Infof("Shard size changed from %d to %d, only the hosts of the removed shards are moved", persisted.ShardSize, shardSize)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for host, shard := range persisted.Hosts {
+		s.hosts[host] = shard
+	}
+	s.dirty = persisted.ShardSize != shardSize
+	utils.AviLog.Infof("Loaded the shard assignment of %d hosts", len(persisted.Hosts))
+}
+
+func (s *ShardAssignment) save() {
+	s.lock.Lock()
+	if !s.dirty {
+		s.lock.Unlock()
+		return
+	}
+	persisted := persistedShardAssignment{ShardSize: lib.GetshardSize(), Hosts: make(map[string]uint32, len(s.hosts))}
+	for host, shard := range s.hosts {
+		persisted.Hosts[host] = shard
+	}
+	s.dirty = false
+	s.lock.Unlock()
+
+	data, _ := json.Marshal(persisted)
+	cmClient := utils.GetInformers().ClientSet.CoreV1().ConfigMaps(lib.AviNS)
+	cm, err := cmClient.Get(lib.ShardAssignmentConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: lib.AviNS, Name: lib.ShardAssignmentConfigMap},
+			Data:       map[string]string{lib.ShardAssignmentKey: string(data)},
+		}
+		_, err = cmClient.Create(cm)
+	} else if err == nil {
+		cm.Data = map[string]string{lib.ShardAssignmentKey: string(data)}
+		_, err = cmClient.Update(cm)
+	}
+	if err != nil {
+		utils.AviLog.Warnf("Unable to save the shard assignment configmap: %v", err)
+		s.lock.Lock()
+		s.dirty = true
+		s.lock.Unlock()
+	}
+}
+
+// Run syncs the migrations, the shard report and the persisted assignment every few seconds until stopped.
+func (s *ShardAssignment) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(shardAssignmentSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Sync()
+		case <-stopCh:
+			s.save()
+			return
+		}
+	}
+}
+
+// Migrate starts moving the host to the given shard. One host is moved at a time, so that a shard is never
+// drained of several hosts at once.
+func (s *ShardAssignment) Migrate(hostname string, shard uint32) error {
+	shardSize := lib.GetshardSize()
+	if shard >= shardSize {
+		return fmt.Errorf("shard %d is beyond the shard size %d", shard, shardSize)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	from, ok := s.hosts[hostname]
+	if !ok {
+		return fmt.Errorf("host %s is not assigned to a shard", hostname)
+	}
+	if from == shard {
+		return fmt.Errorf("host %s is already on shard %d", hostname, shard)
+	}
+	if SharedDedicatedVSStore().IsDedicated(hostname) {
+		return fmt.Errorf("host %s is served on its dedicated virtualservice", hostname)
+	}
+	if SharedInfraSettingStore().GetHostVSName(hostname) != "" {
+		return fmt.Errorf("host %s is served on a virtualservice of its AviInfraSetting", hostname)
+	}
+	if len(s.migrations) > 0 {
+		return fmt.Errorf("another host is being migrated")
+	}
+	s.startMigration(hostname, getShardVSNameByNumber(from), getShardVSNameByNumber(shard), false, func() {
+		s.lock.Lock()
+		s.hosts[hostname] = shard
+		s.dirty = true
+		s.lock.Unlock()
+	})
+	return nil
+}
+
+// moveHost starts moving the host between two virtualservices, switchHost is called once the host is drained from
+// the old one.
+func (s *ShardAssignment) moveHost(hostname, from, to string, deleteFrom bool, switchHost func()) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.migrations[hostname]; ok {
+		return fmt.Errorf("host %s is being migrated", hostname)
+	}
+	s.startMigration(hostname, from, to, deleteFrom, switchHost)
+	return nil
+}
+
+func (s *ShardAssignment) startMigration(hostname, from, to string, deleteFrom bool, switchHost func()) {
+	s.migrations[hostname] = &shardMigration{
+		ShardMigration: apimodels.ShardMigration{
+			Host:      hostname,
+			From:      from,
+			To:        to,
+			State:     ShardMigrationBuilding,
+			StartedAt: time.Now(),
+		},
+		switchHost: switchHost,
+		deleteFrom: deleteFrom,
+	}
+	utils.AviLog.Infof("key: %s, msg: migrating host %s from %s to %s", shardMigrationKey, hostname, from, to)
+}
+
+func (s *ShardAssignment) isMigrating(hostname string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.migrations[hostname]
+	return ok
+}
+
+// Sync moves the migrations forward, moves the hosts whose dedicated VS annotation or AviInfraSetting changed
+// while they were being migrated, deletes the VSes of the AviInfraSettings left by their hosts, starts a migration
+// off a shard above its capacity, forgets the hosts no longer served and persists the assignment if it changed.
+func (s *ShardAssignment) Sync() {
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+	completed := s.syncMigrations()
+	pendingHosts := append(SharedDedicatedVSStore().getPendingHosts(), SharedInfraSettingStore().getHosts()...)
+	for _, hostname := range pendingHosts {
+		if !s.isMigrating(hostname) {
+			switchHostVS(hostname, false, shardMigrationKey)
+		}
+	}
+	SharedInfraSettingStore().deleteUnusedVS(s.getMigrations())
+	s.rebalance()
+	s.forgetStaleHosts()
+	s.updateShardReport(completed)
+	s.save()
+}
+
+func (s *ShardAssignment) getMigrations() []*shardMigration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var migrations []*shardMigration
+	for _, migration := range s.migrations {
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+// syncMigrations moves each migration to its next state once the model it waits for is synced, and returns
+// the number of migrations completed.
+//
+// building: the host is switched to the new VS and its ingresses and routes are processed again to build it there,
+// the SNI child of the host is moved under the new parent with a PUT. The old VS keeps serving the host meanwhile.
+// removing: once the rest layer synced the new VS, the pools the host keeps on the new VS are handed over to it
+// and the host is removed from the old VS, or the old VS is deleted if it only served the host. The migration is
+// completed once the rest layer synced the old VS.
+func (s *ShardAssignment) syncMigrations() int {
+	completed := 0
+	for _, migration := range s.getMigrations() {
+		oldModel := getL7ModelByVSName(migration.From)
+		switch migration.State {
+		case ShardMigrationBuilding:
+			if !migration.switched {
+				migration.switchHost()
+				requeueHost(migration.Host)
+				s.lock.Lock()
+				migration.switched = true
+				migration.stepStarted = time.Now()
+				s.lock.Unlock()
+				continue
+			}
+			newModel := getL7ModelByVSName(migration.To)
+			built := newModel != nil && getShardModelHosts(newModel)[migration.Host] && isShardModelSynced(newModel)
+			// nothing gets built on the new VS if no ingress or route publishes the host anymore
+			if !built && len(getHostObjectKeys(migration.Host)) > 0 {
+				s.reportStuckMigration(migration, migration.To)
+				continue
+			}
+			if oldModel != nil {
+				if newModel != nil {
+					handOverHostPools(oldModel, newModel, migration.Host)
+				}
+				if migration.deleteFrom {
+					deleteL7VSModel(migration.From, true, shardMigrationKey)
+				} else {
+					removeHostFromShardModel(oldModel, migration.Host, false)
+					removeHostFromShardModel(oldModel, migration.Host, true)
+					publishShardModel(oldModel)
+				}
+			}
+			s.lock.Lock()
+			migration.State = ShardMigrationRemoving
+			migration.stepStarted = time.Now()
+			migration.stuck = false
+			s.lock.Unlock()
+		case ShardMigrationRemoving:
+			removed := !isVSCached(migration.From)
+			if !migration.deleteFrom {
+				removed = oldModel == nil || isShardModelSynced(oldModel)
+			}
+			if !removed {
+				s.reportStuckMigration(migration, migration.From)
+				continue
+			}
+			s.lock.Lock()
+			delete(s.migrations, migration.Host)
+			s.lock.Unlock()
+			completed++
+			utils.AviLog.Infof("key: %s, msg: migrated host %s from %s to %s", shardMigrationKey, migration.Host, migration.From, migration.To)
+			recordShardMigratedEvent(migration.ShardMigration)
+		}
+	}
+	return completed
+}
+
+// reportStuckMigration warns once about a migration waiting for the VS to be synced for longer than expected
+func (s *ShardAssignment) reportStuckMigration(migration *shardMigration, vsName string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if migration.stuck || time.Since(migration.stepStarted) < shardMigrationTimeout {
+		return
+	}
+	migration.stuck = true
+	utils.AviLog.Warnf("key: %s, msg: migration of host %s in state %s waits for %s to be synced for more than %v",
+		shardMigrationKey, migration.Host, migration.State, vsName, shardMigrationTimeout)
+}
+
+// rebalance starts moving a host off a shard with more SNI children than allowed, to the least loaded shard
+func (s *ShardAssignment) rebalance() {
+	maxChildren := lib.GetShardVSMaxSNIChildren()
+	if maxChildren == 0 || !lib.IsShardAssignmentPersisted() || len(s.getMigrations()) > 0 {
+		return
+	}
+	shardSize := lib.GetshardSize()
+	loads := getShardLoads(shardSize)
+	leastLoaded := uint32(0)
+	for i := range loads {
+		if loads[i] < loads[leastLoaded] {
+			leastLoaded = uint32(i)
+		}
+	}
+	if loads[leastLoaded] >= maxChildren {
+		return
+	}
+	for i := range loads {
+		if loads[i] <= maxChildren {
+			continue
+		}
+		aviModel := getShardModelByNumber(uint32(i))
+		if aviModel == nil {
+			continue
+		}
+		var hostname string
+		aviModel.Lock.RLock()
+		for _, vsNode := range aviModel.GetAviVS() {
+			if len(vsNode.SniNodes) > 0 && len(vsNode.SniNodes[len(vsNode.SniNodes)-1].VHDomainNames) > 0 {
+				hostname = vsNode.SniNodes[len(vsNode.SniNodes)-1].VHDomainNames[0]
+			}
+		}
+		aviModel.Lock.RUnlock()
+		if hostname == "" {
+			continue
+		}
+		utils.AviLog.Infof("key: %s, msg: shard %d has %d SNI children, above the %d allowed", shardMigrationKey, i, loads[i], maxChildren)
+		if err := s.Migrate(hostname, leastLoaded); err != nil {
+			utils.AviLog.Warnf("key: %s, msg: unable to migrate host %s: %v", shardMigrationKey, hostname, err)
+		}
+		return
+	}
+}
+
+// forgetStaleHosts forgets the hosts not served by any shard, once the rest layer synced all the shard models so
+// that the hosts being placed or moved are not taken for stale ones.
+func (s *ShardAssignment) forgetStaleHosts() {
+	served := make(map[string]bool)
+	for i := uint32(0); i < lib.GetshardSize(); i++ {
+		if aviModel := getShardModelByNumber(i); aviModel != nil {
+			if !isShardModelSynced(aviModel) {
+				return
+			}
+			for host := range getShardModelHosts(aviModel) {
+				served[host] = true
+			}
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for host := range s.hosts {
+		if _, migrating := s.migrations[host]; served[host] || migrating {
+			delete(s.stale, host)
+			continue
+		}
+		if s.stale[host] {
+			delete(s.hosts, host)
+			delete(s.stale, host)
+			s.dirty = true
+			continue
+		}
+		s.stale[host] = true
+	}
+}
+
+func (s *ShardAssignment) updateShardReport(completed int) {
+	shardSize := lib.GetshardSize()
+	loads := getShardLoads(shardSize)
+	shards := make([]apimodels.ShardLoad, shardSize)
+	for i := range shards {
+		shards[i] = apimodels.ShardLoad{Name: getShardVSNameByNumber(uint32(i)), SNIChildren: loads[i]}
+	}
+	s.lock.Lock()
+	for _, shard := range s.hosts {
+		if shard < shardSize {
+			shards[shard].Hosts++
+		}
+	}
+	migrations := []apimodels.ShardMigration{}
+	for _, migration := range s.migrations {
+		migrations = append(migrations, migration.ShardMigration)
+	}
+	s.lock.Unlock()
+	apimodels.ShardStatus.UpdateShardReport(shardSize, shards, migrations, completed)
+}
+
+// isShardModelSynced returns true once the rest layer applied the shard model, that is the checksums of its
+// virtualservices match the ones in the cache and its SNI children are cached under its parent.
+func isShardModelSynced(aviModel *AviObjectGraph) bool {
+	aviModel.Lock.RLock()
+	defer aviModel.Lock.RUnlock()
+	vsNodes := aviModel.GetAviVS()
+	if len(vsNodes) == 0 {
+		return false
+	}
+	aviObjCache := avicache.SharedAviObjCache()
+	isVsSynced := func(vsNode *AviVsNode, parentName string) bool {
+		vsCache, found := aviObjCache.VsCacheMeta.AviCacheGet(avicache.NamespaceName{Namespace: vsNode.Tenant, Name: vsNode.Name})
+		if !found {
+			return false
+		}
+		vsCacheObj, ok := vsCache.(*avicache.AviVsCache)
+		return ok && vsCacheObj.CloudConfigCksum == strconv.Itoa(int(vsNode.GetCheckSum())) && vsCacheObj.ParentVSRef.Name == parentName
+	}
+	for _, sniNode := range vsNodes[0].SniNodes {
+		if !isVsSynced(sniNode, vsNodes[0].Name) {
+			return false
+		}
+	}
+	return isVsSynced(vsNodes[0], "")
+}
+
+// isVSCached returns true as long as the virtualservice is in the cache, that is not deleted by the rest layer
+func isVSCached(vsName string) bool {
+	vsKey := avicache.NamespaceName{Namespace: SharedInfraSettingStore().GetVSTenant(vsName), Name: vsName}
+	_, found := avicache.SharedAviObjCache().VsCacheMeta.AviCacheGet(vsKey)
+	return found
+}
+
+// handOverHostPools moves the pools the host keeps on the new VS from the cache of the old VS to the one of the new
+// VS, as they keep their names, so that removing the host from the old VS does not delete them. The pools of VSes
+// in different tenants are different objects, they are not handed over.
+func handOverHostPools(oldModel, newModel *AviObjectGraph, hostname string) {
+	newPools := make(map[string]bool)
+	newModel.Lock.RLock()
+	newVsNode := newModel.GetAviVS()
+	for _, vsNode := range newVsNode {
+		for _, pool := range vsNode.PoolRefs {
+			newPools[pool.Name] = true
+		}
+	}
+	newModel.Lock.RUnlock()
+	var poolNames []string
+	oldModel.Lock.RLock()
+	oldVsNode := oldModel.GetAviVS()
+	for _, vsNode := range oldVsNode {
+		for _, pool := range vsNode.PoolRefs {
+			if strings.SplitN(pool.PriorityLabel, "/", 2)[0] == hostname && newPools[pool.Name] {
+				poolNames = append(poolNames, pool.Name)
+			}
+		}
+	}
+	oldModel.Lock.RUnlock()
+	if len(poolNames) == 0 || len(oldVsNode) == 0 || len(newVsNode) == 0 || oldVsNode[0].Tenant != newVsNode[0].Tenant {
+		return
+	}
+
+	tenant := oldVsNode[0].Tenant
+	vsCacheMeta := avicache.SharedAviObjCache().VsCacheMeta
+	oldCache, oldFound := vsCacheMeta.AviCacheGet(avicache.NamespaceName{Namespace: tenant, Name: oldVsNode[0].Name})
+	newCache, newFound := vsCacheMeta.AviCacheGet(avicache.NamespaceName{Namespace: tenant, Name: newVsNode[0].Name})
+	if !oldFound || !newFound {
+		return
+	}
+	oldVsCache, oldOk := oldCache.(*avicache.AviVsCache)
+	newVsCache, newOk := newCache.(*avicache.AviVsCache)
+	if !oldOk || !newOk {
+		return
+	}
+	for _, poolName := range poolNames {
+		poolKey := avicache.NamespaceName{Namespace: tenant, Name: poolName}
+		oldVsCache.RemoveFromPoolKeyCollection(poolKey)
+		newVsCache.AddToPoolKeyCollection(poolKey)
+		utils.AviLog.Infof("key: %s, msg: pool %s of host %s handed over from %s to %s", shardMigrationKey, poolName, hostname, oldVsNode[0].Name, newVsNode[0].Name)
+	}
+}
+
+// removeHostFromShardModel removes the insecure pools of the host from the shard model, or with secure set its
+// SNI child, FQDN and redirect, and returns true if the model changed.
+func removeHostFromShardModel(aviModel *AviObjectGraph, hostname string, secure bool) bool {
+	aviModel.Lock.Lock()
+	defer aviModel.Lock.Unlock()
+	vsNode := aviModel.GetAviVS()
+	if len(vsNode) == 0 {
+		return false
+	}
+	changed := false
+	if !secure {
+		var poolNames []string
+		for _, pool := range vsNode[0].PoolRefs {
+			if strings.SplitN(pool.PriorityLabel, "/", 2)[0] == hostname {
+				poolNames = append(poolNames, pool.Name)
+			}
+		}
+		pgNode := aviModel.GetPoolGroupByName(lib.GetL7SharedPGName(vsNode[0].Name))
+		for _, poolName := range poolNames {
+			aviModel.RemovePoolNodeRefs(poolName)
+			if pgNode != nil {
+				aviModel.RemovePoolRefsFromPG(poolName, pgNode)
+			}
+			changed = true
+		}
+		if changed {
+			updateWildcardHTTPDataScript(vsNode[0], shardMigrationKey)
+		}
+		return changed
+	}
+	for i := 0; i < len(vsNode[0].SniNodes); {
+		if utils.HasElem(vsNode[0].SniNodes[i].VHDomainNames, hostname) {
+			vsNode[0].SniNodes = append(vsNode[0].SniNodes[:i], vsNode[0].SniNodes[i+1:]...)
+			changed = true
+			continue
+		}
+		i++
+	}
+	if len(vsNode[0].VSVIPRefs) > 0 && utils.HasElem(vsNode[0].VSVIPRefs[0].FQDNs, hostname) {
+		RemoveFQDNsFromModel(vsNode[0], []string{hostname}, shardMigrationKey)
+		changed = true
+	}
+	RemoveRedirectHTTPPolicyInModel(vsNode[0], hostname, shardMigrationKey)
+	return changed
+}
+
+func publishShardModel(aviModel *AviObjectGraph) {
+	var modelName string
+	if vsNode := aviModel.GetAviVS(); len(vsNode) > 0 {
+		modelName = getVSModelName(vsNode[0].Name)
+	}
+	if modelName != "" && saveAviModel(modelName, aviModel, shardMigrationKey) {
+		PublishKeyToRestLayer(modelName, shardMigrationKey, utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer))
+	}
+}
+
+// requeueHost adds the ingresses and routes of the host to the ingestion queue, to build it on its new VS
+func requeueHost(hostname string) {
+	ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	for _, key := range getHostObjectKeys(hostname) {
+		namespace := strings.SplitN(strings.SplitN(key, "/", 2)[1], "/", 2)[0]
+		bkt := utils.Bkt(namespace, ingestionQueue.NumWorkers)
+		ingestionQueue.Workqueue[bkt].AddRateLimited(key)
+		utils.AviLog.Infof("key: %s, msg: requeued %s for host %s", shardMigrationKey, key, hostname)
+	}
+}
+
+// getHostObjectKeys returns the ingresses and routes publishing the host, keyed like in the ingestion queue
+func getHostObjectKeys(hostname string) []string {
+	var keys []string
+	if utils.GetInformers().IngressInformer != nil {
+		ingObjs, err := utils.GetInformers().IngressInformer.Lister().ByNamespace("").List(labels.Set(nil).AsSelector())
+		if err != nil {
+			utils.AviLog.Warnf("key: %s, msg: unable to list the ingresses of host %s: %v", shardMigrationKey, hostname, err)
+		}
+		for _, ingObj := range ingObjs {
+			ing, ok := utils.ToNetworkingIngress(ingObj)
+			if !ok {
+				continue
+			}
+			for _, rule := range ing.Spec.Rules {
+				if rule.Host == hostname {
+					keys = append(keys, utils.Ingress+"/"+utils.ObjKey(ing))
+					break
+				}
+			}
+		}
+	}
+	if utils.GetInformers().RouteInformer != nil {
+		routeObjs, err := utils.GetInformers().RouteInformer.Lister().List(labels.Set(nil).AsSelector())
+		if err != nil {
+			utils.AviLog.Warnf("key: %s, msg: unable to list the routes of host %s: %v", shardMigrationKey, hostname, err)
+		}
+		for _, route := range routeObjs {
+			if route.Spec.Host == hostname {
+				keys = append(keys, utils.OshiftRoute+"/"+utils.ObjKey(route))
+			}
+		}
+	}
+	return keys
+}
+
+func recordShardMigratedEvent(migration apimodels.ShardMigration) {
+	recorder := lib.GetEventRecorder()
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      "ConfigMap",
+		Namespace: lib.AviNS,
+		Name:      lib.AviConfigMap,
+	}
+	recorder.Event(ref, corev1.EventTypeNormal, lib.ShardMigrated, fmt.Sprintf("Host %s moved from %s to %s", migration.Host, migration.From, migration.To))
+}
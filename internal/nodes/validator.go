@@ -44,6 +44,8 @@ func (v *Validator) IsValiddHostName(hostname string) bool {
 		// No IPAM DNS configured, we simply pass the hostname
 		return true
 	} else {
+		// wildcard hosts are matched against the sub-domains by their domain part.
+		hostname = strings.TrimPrefix(hostname, lib.WildcardHostPrefix)
 		for _, subd := range v.subDomains {
 			if strings.HasSuffix(hostname, subd) {
 				return true
@@ -70,9 +72,10 @@ func validateSpecFromHostnameCache(key, ns, ingName string, ingSpec v1beta1.Ingr
 
 func validateRouteSpecFromHostnameCache(key, ns, routeName string, routeSpec routev1.RouteSpec) {
 	nsRoute := ns + "/" + routeName
-	found, val := SharedHostNameLister().GetHostPathStoreIngresses(routeSpec.Host, routeSpec.Path)
+	hostName := lib.GetRouteHostName(routeSpec)
+	found, val := SharedHostNameLister().GetHostPathStoreIngresses(hostName, routeSpec.Path)
 	if found && len(val) > 0 && utils.HasElem(val, nsRoute) && len(val) > 1 {
-		utils.AviLog.Warnf("key: %s, msg: Duplicate entries found for hostpath %s%s: %s in routes: %+v", key, nsRoute, routeSpec.Host, routeSpec.Path, utils.Stringify(val))
+		utils.AviLog.Warnf("key: %s, msg: Duplicate entries found for hostpath %s%s: %s in routes: %+v", key, nsRoute, hostName, routeSpec.Path, utils.Stringify(val))
 	}
 }
 
@@ -235,8 +238,8 @@ func (v *Validator) ParseHostPathForIngress(ns string, ingName string, ingSpec v
 func (v *Validator) ParseHostPathForRoute(ns string, routeName string, routeSpec routev1.RouteSpec, key string) IngressConfig {
 	ingressConfig := IngressConfig{}
 	hostMap := make(IngressHostMap)
-	hostName := routeSpec.Host
-	if !v.IsValiddHostName(hostName) {
+	hostName := lib.GetRouteHostName(routeSpec)
+	if hostName == "" || !v.IsValiddHostName(hostName) {
 		return ingressConfig
 	}
 	defaultWeight := int32(100)
@@ -297,8 +300,6 @@ func (v *Validator) ParseHostPathForRoute(ns string, routeName string, routeSpec
 	} else if secretName != "" {
 		tls := TlsSettings{Hosts: hostMap, SecretName: secretName}
 
-		// TODO: add httprule destinationCA here, for reencrypt: true
-
 		if routeSpec.TLS != nil {
 			// build edge cert data for termination: edge and reencrypt
 			if routeSpec.TLS.Termination == routev1.TLSTerminationEdge ||
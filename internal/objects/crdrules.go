@@ -29,6 +29,15 @@ func SharedCRDLister() *CRDLister {
 			HostRuleFQDNCache:  NewObjectMapStore(),
 			FqdnHTTPRulesCache: NewObjectMapStore(),
 			HTTPRuleFqdnCache:  NewObjectMapStore(),
+
+			ClientCASecretHostRulesCache: NewObjectMapStore(),
+			HostRuleClientCASecretCache:  NewObjectMapStore(),
+
+			DestCASecretHTTPRulesCache: NewObjectMapStore(),
+			HTTPRuleDestCASecretsCache: NewObjectMapStore(),
+
+			FqdnHostnameClaimCache: NewObjectMapStore(),
+			HostnameClaimFQDNCache: NewObjectMapStore(),
 		}
 	})
 	return CRDinstance
@@ -50,6 +59,24 @@ type CRDLister struct {
 
 	// rr1: fqdn1.com, rr2: fqdn2.com
 	HTTPRuleFqdnCache *ObjectMapStore
+
+	// ns/secret1: [hr1, hr2] - client certificate CA secrets referred by hostrules
+	ClientCASecretHostRulesCache *ObjectMapStore
+
+	// hr1: ns/secret1
+	HostRuleClientCASecretCache *ObjectMapStore
+
+	// ns/secret1: [rr1, rr2] - destination CA secrets referred by httprule paths
+	DestCASecretHTTPRulesCache *ObjectMapStore
+
+	// rr1: [ns/secret1, ns/secret2]
+	HTTPRuleDestCASecretsCache *ObjectMapStore
+
+	// fqdn.com: ns/claim1 - accepted hostnameclaims
+	FqdnHostnameClaimCache *ObjectMapStore
+
+	// ns/claim1: fqdn.com
+	HostnameClaimFQDNCache *ObjectMapStore
 }
 
 // FqdnHostRuleCache
@@ -139,3 +166,149 @@ func (c *CRDLister) UpdateFqdnHTTPRulesMappings(fqdn, path, httprule string) {
 	pathRules[path] = httprule
 	c.FqdnHTTPRulesCache.AddOrUpdate(fqdn, pathRules)
 }
+
+// ClientCASecretHostRulesCache
+
+func (c *CRDLister) GetClientCASecretToHostrulesMapping(secret string) (bool, []string) {
+	c.NSLock.RLock()
+	defer c.NSLock.RUnlock()
+	found, hostrules := c.ClientCASecretHostRulesCache.Get(secret)
+	if !found {
+		return false, []string{}
+	}
+	hostrulesCopy := make([]string, len(hostrules.([]string)))
+	copy(hostrulesCopy, hostrules.([]string))
+	return true, hostrulesCopy
+}
+
+func (c *CRDLister) DeleteHostruleClientCASecretMapping(hostrule string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	c.deleteHostruleClientCASecretMapping(hostrule)
+}
+
+func (c *CRDLister) deleteHostruleClientCASecretMapping(hostrule string) {
+	found, secret := c.HostRuleClientCASecretCache.Get(hostrule)
+	if !found {
+		return
+	}
+	c.HostRuleClientCASecretCache.Delete(hostrule)
+	_, hostrules := c.ClientCASecretHostRulesCache.Get(secret.(string))
+	var remaining []string
+	for _, hr := range hostrules.([]string) {
+		if hr != hostrule {
+			remaining = append(remaining, hr)
+		}
+	}
+	if len(remaining) == 0 {
+		c.ClientCASecretHostRulesCache.Delete(secret.(string))
+		return
+	}
+	c.ClientCASecretHostRulesCache.AddOrUpdate(secret.(string), remaining)
+}
+
+func (c *CRDLister) UpdateClientCASecretHostruleMapping(secret, hostrule string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	c.deleteHostruleClientCASecretMapping(hostrule)
+	var hostrules []string
+	if found, hrs := c.ClientCASecretHostRulesCache.Get(secret); found {
+		hostrules = hrs.([]string)
+	}
+	c.HostRuleClientCASecretCache.AddOrUpdate(hostrule, secret)
+	c.ClientCASecretHostRulesCache.AddOrUpdate(secret, append(hostrules, hostrule))
+}
+
+// DestCASecretHTTPRulesCache
+
+func (c *CRDLister) GetDestCASecretToHTTPRulesMapping(secret string) (bool, []string) {
+	c.NSLock.RLock()
+	defer c.NSLock.RUnlock()
+	found, httprules := c.DestCASecretHTTPRulesCache.Get(secret)
+	if !found {
+		return false, []string{}
+	}
+	httprulesCopy := make([]string, len(httprules.([]string)))
+	copy(httprulesCopy, httprules.([]string))
+	return true, httprulesCopy
+}
+
+func (c *CRDLister) DeleteHTTPRuleDestCASecretsMapping(httprule string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	c.deleteHTTPRuleDestCASecretsMapping(httprule)
+}
+
+func (c *CRDLister) deleteHTTPRuleDestCASecretsMapping(httprule string) {
+	found, secrets := c.HTTPRuleDestCASecretsCache.Get(httprule)
+	if !found {
+		return
+	}
+	c.HTTPRuleDestCASecretsCache.Delete(httprule)
+	for _, secret := range secrets.([]string) {
+		_, httprules := c.DestCASecretHTTPRulesCache.Get(secret)
+		var remaining []string
+		for _, rr := range httprules.([]string) {
+			if rr != httprule {
+				remaining = append(remaining, rr)
+			}
+		}
+		if len(remaining) == 0 {
+			c.DestCASecretHTTPRulesCache.Delete(secret)
+			continue
+		}
+		c.DestCASecretHTTPRulesCache.AddOrUpdate(secret, remaining)
+	}
+}
+
+func (c *CRDLister) UpdateHTTPRuleDestCASecretsMapping(httprule string, secrets []string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	c.deleteHTTPRuleDestCASecretsMapping(httprule)
+	if len(secrets) == 0 {
+		return
+	}
+	for _, secret := range secrets {
+		var httprules []string
+		if found, rrs := c.DestCASecretHTTPRulesCache.Get(secret); found {
+			httprules = rrs.([]string)
+		}
+		c.DestCASecretHTTPRulesCache.AddOrUpdate(secret, append(httprules, httprule))
+	}
+	c.HTTPRuleDestCASecretsCache.AddOrUpdate(httprule, secrets)
+}
+
+// FqdnHostnameClaimCache
+
+func (c *CRDLister) GetFQDNToHostnameClaimMapping(fqdn string) (bool, string) {
+	found, claim := c.FqdnHostnameClaimCache.Get(fqdn)
+	if !found {
+		return false, ""
+	}
+	return true, claim.(string)
+}
+
+func (c *CRDLister) GetHostnameClaimToFQDNMapping(claim string) (bool, string) {
+	found, fqdn := c.HostnameClaimFQDNCache.Get(claim)
+	if !found {
+		return false, ""
+	}
+	return true, fqdn.(string)
+}
+
+func (c *CRDLister) DeleteHostnameClaimFQDNMapping(claim string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	found, fqdn := c.HostnameClaimFQDNCache.Get(claim)
+	if found {
+		c.HostnameClaimFQDNCache.Delete(claim)
+		c.FqdnHostnameClaimCache.Delete(fqdn.(string))
+	}
+}
+
+func (c *CRDLister) UpdateFQDNHostnameClaimMapping(fqdn string, claim string) {
+	c.NSLock.Lock()
+	defer c.NSLock.Unlock()
+	c.FqdnHostnameClaimCache.AddOrUpdate(fqdn, claim)
+	c.HostnameClaimFQDNCache.AddOrUpdate(claim, fqdn)
+}
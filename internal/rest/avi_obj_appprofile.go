@@ -0,0 +1,145 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	avimodels "github.com/avinetworks/sdk/go/models"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func (rest *RestOperations) AviAppProfileBuild(appProfNode *nodes.AviAppProfileNode, cache_obj *avicache.AviAppProfileCache) *utils.RestOp {
+	name := appProfNode.Name
+	tenant := fmt.Sprintf("/api/tenant/?name=%s", appProfNode.Tenant)
+	cksumString := strconv.Itoa(int(appProfNode.GetCheckSum()))
+	cr := lib.AKOUser
+	profileType := lib.AllowedApplicationProfile
+
+	// only X-Forwarded-Proto and the client validation are set, the rest of the HTTP profile is left to the
+	// controller defaults rather than copied from System-Secure-HTTP
+	xffProto := true
+	clientCertMode := appProfNode.ClientCertMode
+	httpProfile := &avimodels.HTTPApplicationProfile{
+		XForwardedProtoEnabled:   &xffProto,
+		SslClientCertificateMode: &clientCertMode,
+	}
+	if appProfNode.PkiProfile != nil {
+		pkiProfileRef := "/api/pkiprofile/?name=" + appProfNode.PkiProfile.Name
+		httpProfile.PkiProfileRef = &pkiProfileRef
+	}
+	if appProfNode.SubjectHeader != "" {
+		header := appProfNode.SubjectHeader
+		headerValue := lib.SSLClientSubjectVar
+		httpProfile.SslClientCertificateAction = &avimodels.SSLClientCertificateAction{
+			Headers: []*avimodels.SSLClientRequestHeader{
+				&avimodels.SSLClientRequestHeader{
+					RequestHeader:      &header,
+					RequestHeaderValue: &headerValue,
+				},
+			},
+		}
+	}
+
+	appProfile := avimodels.ApplicationProfile{
+		Name:             &name,
+		CreatedBy:        &cr,
+		TenantRef:        &tenant,
+		CloudConfigCksum: &cksumString,
+		Type:             &profileType,
+		HTTPProfile:      httpProfile,
+	}
+
+	macro := utils.AviRestObjMacro{ModelName: "ApplicationProfile", Data: appProfile}
+
+	var path string
+	var rest_op utils.RestOp
+	if cache_obj != nil {
+		path = "/api/applicationprofile/" + cache_obj.Uuid
+		rest_op = utils.RestOp{Path: path, Method: utils.RestPut, Obj: appProfile,
+			Tenant: appProfNode.Tenant, Model: "ApplicationProfile", Version: utils.CtrlVersion}
+	} else {
+		path = "/api/macro"
+		rest_op = utils.RestOp{Path: path, Method: utils.RestPost, Obj: macro,
+			Tenant: appProfNode.Tenant, Model: "ApplicationProfile", Version: utils.CtrlVersion}
+	}
+	return &rest_op
+}
+
+func (rest *RestOperations) AviAppProfileDel(uuid string, tenant string) *utils.RestOp {
+	path := "/api/applicationprofile/" + uuid
+	rest_op := utils.RestOp{Path: path, Method: "DELETE",
+		Tenant: tenant, Model: "ApplicationProfile", Version: utils.CtrlVersion}
+	utils.AviLog.Info(spew.Sprintf("ApplicationProfile DELETE Restop %v \n",
+		utils.Stringify(rest_op)))
+	return &rest_op
+}
+
+func (rest *RestOperations) AviAppProfileCacheAdd(rest_op *utils.RestOp, key string) error {
+	if (rest_op.Err != nil) || (rest_op.Response == nil) {
+		utils.AviLog.Warnf("key: %s, rest_op has err or no reponse for applicationprofile, err: %s, response: %s", key, rest_op.Err, rest_op.Response)
+		return errors.New("Errored rest_op")
+	}
+
+	resp_elems, ok := RestRespArrToObjByType(rest_op, "applicationprofile", key)
+	if ok != nil || resp_elems == nil {
+		utils.AviLog.Warnf("key: %s, Unable to find ApplicationProfile obj in resp %v", key, rest_op.Response)
+		return errors.New("ApplicationProfile not found")
+	}
+
+	for _, resp := range resp_elems {
+		name, ok := resp["name"].(string)
+		if !ok {
+			utils.AviLog.Warnf("key: %s, Name not present in response %v", key, resp)
+			continue
+		}
+
+		uuid, ok := resp["uuid"].(string)
+		if !ok {
+			utils.AviLog.Warnf("key: %s, Uuid not present in response %v", key, resp)
+			continue
+		}
+
+		cksum, _ := resp["cloud_config_cksum"].(string)
+		appProfCacheObj := avicache.AviAppProfileCache{
+			Name:             name,
+			Tenant:           rest_op.Tenant,
+			Uuid:             uuid,
+			CloudConfigCksum: cksum,
+		}
+
+		k := avicache.NamespaceName{Namespace: rest_op.Tenant, Name: name}
+		rest.cache.AppProfileCache.AviCacheAdd(k, &appProfCacheObj)
+		utils.AviLog.Info(spew.Sprintf("key: %s, Added ApplicationProfile cache k %v val %v\n", key, k,
+			appProfCacheObj))
+	}
+
+	return nil
+}
+
+func (rest *RestOperations) AviAppProfileCacheDel(rest_op *utils.RestOp, key string) error {
+	appProfKey := avicache.NamespaceName{Namespace: rest_op.Tenant, Name: rest_op.ObjName}
+	utils.AviLog.Debugf("key: %s, msg: deleting ApplicationProfile cache %v", key, appProfKey)
+	rest.cache.AppProfileCache.AviCacheDelete(appProfKey)
+	return nil
+}
@@ -30,7 +30,6 @@ import (
 
 	avimodels "github.com/avinetworks/sdk/go/models"
 	"github.com/davecgh/go-spew/spew"
-	k8serror "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func (rest *RestOperations) AviPoolBuild(pool_meta *nodes.AviPoolNode, cache_obj *avicache.AviPoolCache, key string) *utils.RestOp {
@@ -100,6 +99,14 @@ func (rest *RestOperations) AviPoolBuild(pool_meta *nodes.AviPoolNode, cache_obj
 		pool.PkiProfileRef = &pkiProfileName
 	}
 
+	if pool_meta.ServerName != "" {
+		pool.ServerName = &pool_meta.ServerName
+	}
+
+	if pool_meta.SslKeyAndCertificateRef != "" {
+		pool.SslKeyAndCertificateRef = &pool_meta.SslKeyAndCertificateRef
+	}
+
 	// there are defaults set by the Avi controller internally
 	if pool_meta.LbAlgorithm != "" {
 		pool.LbAlgorithm = &pool_meta.LbAlgorithm
@@ -220,7 +227,7 @@ func (rest *RestOperations) AviPoolCacheAdd(rest_op *utils.RestOp, vsKey avicach
 			pkiUuid := avicache.ExtractUuid(pkiprof.(string), "pkiprofile-.*.#")
 			pkiName, foundPki := rest.cache.PKIProfileCache.AviCacheGetNameByUuid(pkiUuid)
 			if foundPki {
-				pkiKey = avicache.NamespaceName{Namespace: lib.GetTenant(), Name: pkiName.(string)}
+				pkiKey = avicache.NamespaceName{Namespace: rest_op.Tenant, Name: pkiName.(string)}
 			}
 		}
 
@@ -251,7 +258,7 @@ func (rest *RestOperations) AviPoolCacheAdd(rest_op *utils.RestOp, vsKey avicach
 						Vip:             vs_cache_obj.Vip,
 						ServiceMetadata: svc_mdata_obj,
 						Key:             key,
-					}}, false)
+					}})
 				}
 			}
 		} else {
@@ -295,11 +302,7 @@ func (rest *RestOperations) DeletePoolIngressStatus(poolKey avicache.NamespaceNa
 		if success {
 			if pool_cache_obj.ServiceMetadataObj.IngressName != "" {
 				// SNI VSes use the VS object metadata, delete ingress status for others
-				err := status.DeleteRouteIngressStatus(pool_cache_obj.ServiceMetadataObj, isVSDelete, key)
-				if k8serror.IsNotFound(err) {
-					// Just log and get away
-					utils.AviLog.Infof("key: %s, msg: ingress already deleted, nothing to update in status", key)
-				}
+				status.DeleteRouteIngressStatus(pool_cache_obj.ServiceMetadataObj, isVSDelete, key)
 			}
 		}
 	}
@@ -37,7 +37,7 @@ func (rest *RestOperations) AviVrfGet(key, uuid, name string) *avimodels.VrfCont
 		utils.AviLog.Warnf("key: %s, msg: client in aviRestPoolClient not initialized\n", key)
 		return nil
 	}
-	client := rest.aviRestPoolClient.AviClient[0]
+	client := rest.aviRestPoolClient.GetClient(int(utils.Bkt(key, lib.GetshardSize())))
 	uri := "/api/vrfcontext/" + uuid
 
 	rawData, err := client.AviSession.GetRaw(uri)
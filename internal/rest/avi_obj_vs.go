@@ -60,6 +60,9 @@ func (rest *RestOperations) AviVsBuild(vs_meta *nodes.AviVsNode, rest_method uti
 		svc_mdata := string(svc_mdata_json)
 		vrfContextRef := "/api/vrfcontext?name=" + vs_meta.VrfContext
 		seGroupRef := "/api/serviceenginegroup?name=" + lib.GetSEGName()
+		if vs_meta.ServiceEngineGroup != "" {
+			seGroupRef = "/api/serviceenginegroup?name=" + vs_meta.ServiceEngineGroup
+		}
 		vsDownOnPoolDown := true
 		vs := avimodels.VirtualService{
 			Name:                        &name,
@@ -185,12 +188,18 @@ func (rest *RestOperations) AviVsSniBuild(vs_meta *nodes.AviVsNode, rest_method
 	if vs_meta.AppProfileRef != "" {
 		// hostrule ref overrides defaults
 		app_prof = vs_meta.AppProfileRef
+	} else if vs_meta.ClientAuthAppProfile != nil {
+		// client certificate validation is carried by an AKO created application profile
+		app_prof = "/api/applicationprofile/?name=" + vs_meta.ClientAuthAppProfile.Name
 	}
 
 	cloudRef := "/api/cloud?name=" + utils.CloudName
 	network_prof := "/api/networkprofile/?name=" + "System-TCP-Proxy"
 	vrfContextRef := "/api/vrfcontext?name=" + vs_meta.VrfContext
 	seGroupRef := "/api/serviceenginegroup?name=" + lib.GetSEGName()
+	if vs_meta.ServiceEngineGroup != "" {
+		seGroupRef = "/api/serviceenginegroup?name=" + vs_meta.ServiceEngineGroup
+	}
 	svc_mdata_json, _ := json.Marshal(&vs_meta.ServiceMetadata)
 	svc_mdata := string(svc_mdata_json)
 	sniChild := &avimodels.VirtualService{
@@ -357,7 +366,7 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 					vsVipUuid := avicache.ExtractUuid(resp["vsvip_ref"].(string), "vsvip-.*.#")
 					vsVipName, vipFound := rest.cache.VSVIPCache.AviCacheGetNameByUuid(vsVipUuid)
 					if vipFound {
-						vipKey := avicache.NamespaceName{Namespace: lib.GetTenant(), Name: vsVipName.(string)}
+						vipKey := avicache.NamespaceName{Namespace: rest_op.Tenant, Name: vsVipName.(string)}
 						vsvip_cache, found := rest.cache.VSVIPCache.AviCacheGet(vipKey)
 						if found {
 							vsvip_cache_obj, ok := vsvip_cache.(*avicache.AviVSVIPCache)
@@ -375,7 +384,14 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 				vs_cache_obj.CloudConfigCksum = cksum
 				vs_cache_obj.ServiceMetadataObj = svc_mdata_obj
 				if vhParentKey != nil {
-					vs_cache_obj.ParentVSRef = vhParentKey.(avicache.NamespaceName)
+					newParentKey := vhParentKey.(avicache.NamespaceName)
+					if vs_cache_obj.ParentVSRef.Name != "" && vs_cache_obj.ParentVSRef != newParentKey {
+						// The SNI child was moved to another shard VS, the old parent must not delete it anymore.
+						if oldParentObj := rest.getVsCacheObj(vs_cache_obj.ParentVSRef, key); oldParentObj != nil {
+							oldParentObj.RemoveFromSNIChildCollection(uuid)
+						}
+					}
+					vs_cache_obj.ParentVSRef = newParentKey
 				}
 
 				vs_cache_obj.LastModified = lastModifiedStr
@@ -392,20 +408,20 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 						Vip:             vs_cache_obj.Vip,
 						ServiceMetadata: svc_mdata_obj,
 						Key:             key,
-					}}, false)
+					}})
 				} else if len(svc_mdata_obj.NamespaceServiceName) > 0 {
 					// This service needs an update of the status
 					status.UpdateL4LBStatus([]status.UpdateStatusOptions{{
 						Vip:             vs_cache_obj.Vip,
 						ServiceMetadata: svc_mdata_obj,
 						Key:             key,
-					}}, false)
+					}})
 				} else if (svc_mdata_obj.IngressName != "" || len(svc_mdata_obj.NamespaceIngressName) > 0) && svc_mdata_obj.Namespace != "" && parentVsObj != nil {
 					status.UpdateRouteIngressStatus([]status.UpdateStatusOptions{{
 						Vip:             parentVsObj.Vip,
 						ServiceMetadata: svc_mdata_obj,
 						Key:             key,
-					}}, false)
+					}})
 				}
 				// This code is most likely hit when the first time a shard vs is created and the vs_cache_obj is populated from the pool update.
 				// But before this a pool may have got created as a part of the macro operation, so update the ingress status here.
@@ -421,7 +437,7 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 									Vip:             vs_cache_obj.Vip,
 									ServiceMetadata: pool_cache_obj.ServiceMetadataObj,
 									Key:             key,
-								}}, false)
+								}})
 							}
 						}
 					}
@@ -439,7 +455,7 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 				vsVipUuid := avicache.ExtractUuid(resp["vsvip_ref"].(string), "vsvip-.*.#")
 				vsVipName, vipFound := rest.cache.VSVIPCache.AviCacheGetNameByUuid(vsVipUuid)
 				if vipFound {
-					vipKey := avicache.NamespaceName{Namespace: lib.GetTenant(), Name: vsVipName.(string)}
+					vipKey := avicache.NamespaceName{Namespace: rest_op.Tenant, Name: vsVipName.(string)}
 					vsvip_cache, found := rest.cache.VSVIPCache.AviCacheGet(vipKey)
 					if found {
 						vsvip_cache_obj, ok := vsvip_cache.(*avicache.AviVSVIPCache)
@@ -460,7 +476,7 @@ func (rest *RestOperations) AviVsCacheAdd(rest_op *utils.RestOp, key string) err
 					Vip:             vs_cache_obj.Vip,
 					ServiceMetadata: svc_mdata_obj,
 					Key:             key,
-				}}, false)
+				}})
 			}
 			rest.cache.VsCacheMeta.AviCacheAdd(k, &vs_cache_obj)
 			utils.AviLog.Info(spew.Sprintf("key: %s, msg: added VS cache key %v val %v\n", key, k,
@@ -602,7 +618,22 @@ func (rest *RestOperations) AviVsVipBuild(vsvip_meta *nodes.AviVSVIPNode, cache_
 		var vips []*avimodels.Vip
 		var vip avimodels.Vip
 		networkRef := lib.GetNetworkName()
-		if lib.IsPublicCloud() && lib.GetNetworkName() != "" {
+		if vsvip_meta.NetworkName != "" && lib.IsPublicCloud() {
+			// the network of an AviInfraSetting
+			networkRef = vsvip_meta.NetworkName
+			vip = avimodels.Vip{
+				AutoAllocateIP: &auto_alloc,
+				SubnetUUID:     &networkRef,
+			}
+		} else if vsvip_meta.NetworkName != "" {
+			networkRef = "/api/network/?name=" + vsvip_meta.NetworkName
+			vip = avimodels.Vip{
+				AutoAllocateIP: &auto_alloc,
+				IPAMNetworkSubnet: &avimodels.IPNetworkSubnet{
+					NetworkRef: &networkRef,
+				},
+			}
+		} else if lib.IsPublicCloud() && lib.GetNetworkName() != "" {
 			vip = avimodels.Vip{
 				AutoAllocateIP: &auto_alloc,
 				SubnetUUID:     &networkRef,
@@ -725,7 +756,7 @@ func (rest *RestOperations) AviVsVipGet(key, uuid, name string) (*avimodels.VsVi
 		utils.AviLog.Warnf("key: %s, msg: client in aviRestPoolClient during vsvip not initialized\n", key)
 		return nil, errors.New("client in aviRestPoolClient during vsvip not initialized")
 	}
-	client := rest.aviRestPoolClient.AviClient[0]
+	client := rest.aviRestPoolClient.GetClient(int(utils.Bkt(key, lib.GetshardSize())))
 	uri := "/api/vsvip/" + uuid
 
 	rawData, err := client.AviSession.GetRaw(uri)
@@ -19,6 +19,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	avicache "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
@@ -65,11 +66,20 @@ func (rest *RestOperations) DeQueueNodes(key string) {
 
 	vsKey := avicache.NamespaceName{Namespace: namespace, Name: name}
 	vs_cache_obj := rest.getVsCacheObj(vsKey, key)
+	if name == lib.DummyVSForOrphanGC {
+		// The objects collected by the orphan garbage collector are removed along with the dummy virtualservice.
+		if vs_cache_obj != nil {
+			rest.deleteVSOper(vsKey, vs_cache_obj, namespace, key, true, false)
+			rest.cache.VsCacheMeta.AviCacheDelete(vsKey)
+		}
+		return
+	}
 	if !ok || avimodelIntf == nil {
 		if lib.StaticRouteSyncChan != nil {
 			close(lib.StaticRouteSyncChan)
 			lib.StaticRouteSyncChan = nil
 		}
+		ClearRetryState(key)
 		if vs_cache_obj != nil {
 			utils.AviLog.Infof("key: %s, msg: nil model found, this is a vs deletion case", key)
 			rest.deleteVSOper(vsKey, vs_cache_obj, namespace, key, false, false)
@@ -90,11 +100,12 @@ func (rest *RestOperations) DeQueueNodes(key string) {
 			rest.vrfCU(key, name, avimodel)
 			return
 		}
-		utils.AviLog.Debugf("key: %s, msg: VS create/update.", key)
+		utils.AviLog.With(utils.LogFieldVS, name).Debugf("key: %s, msg: VS create/update.", key)
 		if len(avimodel.GetAviVS()) != 1 {
 			utils.AviLog.Warnf("key: %s, msg: virtualservice in the model is not equal to 1:%v", key, avimodel.GetAviVS())
 			return
 		}
+		ClearRetryStateOnChange(key, avimodel.GraphChecksum)
 		rest.RestOperation(name, namespace, avimodel, false, vs_cache_obj, key)
 	}
 
@@ -359,6 +370,7 @@ func (rest *RestOperations) deleteSniVs(vsKey avicache.NamespaceName, vs_cache_o
 		if ok {
 			rest_ops = append(rest_ops, rest_op)
 		}
+		rest_ops = rest.ClientAuthDelete(vsKey.Name, namespace, rest_ops, key)
 		rest_ops = rest.DataScriptDelete(vs_cache_obj.DSKeyCollection, namespace, rest_ops, key)
 		rest_ops = rest.SSLKeyCertDelete(vs_cache_obj.SSLKeyCertCollection, namespace, rest_ops, key)
 		rest_ops = rest.HTTPPolicyDelete(vs_cache_obj.HTTPKeyCollection, namespace, rest_ops, key)
@@ -373,17 +385,30 @@ func (rest *RestOperations) deleteSniVs(vsKey avicache.NamespaceName, vs_cache_o
 func (rest *RestOperations) ExecuteRestAndPopulateCache(rest_ops []*utils.RestOp, aviObjKey avicache.NamespaceName, avimodel *nodes.AviObjectGraph, key string, sslKey ...utils.NamespaceName) {
 	// Choose a avi client based on the model name hash. This would ensure that the same worker queue processes updates for a given VS all the time.
 	shardSize := lib.GetshardSize()
-	var fastRetry, retry bool
+	var retry, retryExhausted bool
+	var errClass string
+	var lastErr error
 	if shardSize != 0 {
 		bkt := utils.Bkt(key, shardSize)
 		utils.AviLog.Infof("key: %s, msg: processing in rest queue number: %v", key, bkt)
 		if len(rest.aviRestPoolClient.AviClient) > 0 && len(rest_ops) > 0 {
-			aviclient := rest.aviRestPoolClient.AviClient[bkt]
-			err := rest.aviRestPoolClient.AviRestOperate(aviclient, rest_ops)
+			aviclient := rest.aviRestPoolClient.GetClient(int(bkt))
+			for _, rest_op := range rest_ops {
+				rest_op.Key = key
+			}
+			var err error
+			if lib.IsRestMacroBatchEnabled() {
+				err = rest.aviRestPoolClient.AviRestOperateMacro(aviclient, rest_ops)
+			} else {
+				err = rest.aviRestPoolClient.AviRestOperate(aviclient, rest_ops)
+			}
+			for _, rest_op := range rest_ops {
+				utils.AddModelTraceEvent(key, utils.RestLayer, string(rest_op.Method)+" "+rest_op.Model+" "+utils.RestOpObjName(rest_op), rest_op.Err)
+			}
 			if err != nil {
 				var publishKey string
 				if avimodel != nil && len(avimodel.GetAviVS()) > 0 {
-					publishKey = avimodel.GetAviVS()[0].Name
+					publishKey = lib.GetModelName(aviObjKey.Namespace, avimodel.GetAviVS()[0].Name)
 				}
 				utils.AviLog.Warnf("key: %s, msg: there was an error sending the macro %v", key, err.Error())
 				models.RestStatus.UpdateAviApiRestStatus("", err)
@@ -393,17 +418,18 @@ func (rest *RestOperations) ExecuteRestAndPopulateCache(rest_ops []*utils.RestOp
 						// If it's for a SNI child, publish the parent VS's key
 						if avimodel != nil && len(avimodel.GetAviVS()) > 0 {
 							utils.AviLog.Warnf("key: %s, msg: Retrieved key for Retry:%s, object: %s", key, publishKey, rest_ops[i].ObjName)
+							aviError, ok := rest_ops[i].Err.(session.AviError)
+							if !ok {
+								utils.AviLog.Infof("key: %s, msg: Error is not of type AviError, err: %v, %T", key, rest_ops[i].Err, rest_ops[i].Err)
+								continue
+							}
+							errClass, lastErr = ClassifyRestError(aviError), aviError
 							if avimodel.GetRetryCounter() != 0 {
-								aviError, ok := rest_ops[i].Err.(session.AviError)
-								if !ok {
-									utils.AviLog.Infof("key: %s, msg: Error is not of type AviError, err: %v, %T", key, rest_ops[i].Err, rest_ops[i].Err)
-									continue
-								}
-								retryable, fastRetryable := rest.RefreshCacheForRetryLayer(publishKey, aviObjKey, rest_ops[i], aviError, aviclient, avimodel, key)
-								fastRetry = fastRetry || fastRetryable
+								retryable, _ := rest.RefreshCacheForRetryLayer(publishKey, aviObjKey, rest_ops[i], aviError, aviclient, avimodel, key)
 								retry = retry || retryable
 							} else {
 								utils.AviLog.Warnf("key: %s, msg: retry count exhausted, skipping", key)
+								retryExhausted = true
 							}
 						} else {
 							utils.AviLog.Warnf("key: %s, msg: Avi model not set", key)
@@ -412,11 +438,15 @@ func (rest *RestOperations) ExecuteRestAndPopulateCache(rest_ops []*utils.RestOp
 						rest.PopulateOneCache(rest_ops[i], aviObjKey, key)
 					}
 				}
-				if retry {
-					rest.PublishKeyToRetryLayer(publishKey, aviclient, key, fastRetry)
+				// the model is not retried when the refreshed cache shows there is nothing left to retry
+				if errClass != "" && (retry || retryExhausted) {
+					rest.RetryOrDeadLetter(publishKey, avimodel.GraphChecksum, errClass, lastErr, retryExhausted, key)
 				}
 			} else {
 				models.RestStatus.UpdateAviApiRestStatus(utils.AVIAPI_CONNECTED, nil)
+				if avimodel != nil && len(avimodel.GetAviVS()) > 0 {
+					ClearRetryState(lib.GetModelName(aviObjKey.Namespace, avimodel.GetAviVS()[0].Name))
+				}
 				utils.AviLog.Debugf("key: %s, msg: rest call executed successfully, will update cache", key)
 				// Add to local obj caches
 				for _, rest_op := range rest_ops {
@@ -433,6 +463,8 @@ func (rest *RestOperations) PopulateOneCache(rest_op *utils.RestOp, aviObjKey av
 		utils.AviLog.Infof("key: %s, msg: creating/updating %s cache, method: %s", key, rest_op.Model, rest_op.Method)
 		if rest_op.Model == "PKIprofile" {
 			rest.AviPkiProfileAdd(rest_op, aviObjKey, key)
+		} else if rest_op.Model == "ApplicationProfile" {
+			rest.AviAppProfileCacheAdd(rest_op, key)
 		} else if rest_op.Model == "Pool" {
 			rest.AviPoolCacheAdd(rest_op, aviObjKey, key)
 		} else if rest_op.Model == "VirtualService" {
@@ -457,6 +489,8 @@ func (rest *RestOperations) PopulateOneCache(rest_op *utils.RestOp, aviObjKey av
 		utils.AviLog.Infof("key: %s, msg: deleting %s cache", key, rest_op.Model)
 		if rest_op.Model == "PKIprofile" {
 			rest.AviPkiProfileCacheDel(rest_op, aviObjKey, key)
+		} else if rest_op.Model == "ApplicationProfile" {
+			rest.AviAppProfileCacheDel(rest_op, key)
 		} else if rest_op.Model == "Pool" {
 			rest.AviPoolCacheDel(rest_op, aviObjKey, key)
 		} else if rest_op.Model == "VirtualService" {
@@ -491,12 +525,12 @@ func (rest *RestOperations) DataScriptDelete(dsToDelete []avicache.NamespaceName
 	return restOps
 }
 
-func (rest *RestOperations) PublishKeyToRetryLayer(parentVsKey string, c *clients.AviClient, key string, fastRetry bool) {
+func (rest *RestOperations) PublishKeyToRetryLayer(modelName string, key string, delay time.Duration) {
 	var bkt uint32
 	bkt = 0
 	fastRetryQueue := utils.SharedWorkQueue().GetQueueByName(lib.FAST_RETRY_LAYER)
-	fastRetryQueue.Workqueue[bkt].AddRateLimited(parentVsKey)
-	utils.AviLog.Infof("key: %s, msg: Published key with vs_key to fast path retry queue: %s", key, parentVsKey)
+	fastRetryQueue.Workqueue[bkt].AddAfter(modelName, delay)
+	utils.AviLog.Infof("key: %s, msg: Published key with model name to fast path retry queue: %s", key, modelName)
 }
 
 func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObjKey avicache.NamespaceName, rest_op *utils.RestOp, aviError session.AviError, c *clients.AviClient, avimodel *nodes.AviObjectGraph, key string) (bool, bool) {
@@ -537,9 +571,9 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				rest_op.ObjName = pgObjName
 				if strings.Contains(errorStr, "Pool object not found!") {
 					// PG error with pool object not found.
-					aviObjCache.AviPopulateOnePGCache(c, utils.CloudName, pgObjName)
+					aviObjCache.AviPopulateOnePGCache(c, utils.CloudName, pgObjName, rest_op.Tenant)
 					// After the refresh - get the members
-					pgKey := avicache.NamespaceName{Namespace: utils.ADMIN_NS, Name: pgObjName}
+					pgKey := avicache.NamespaceName{Namespace: rest_op.Tenant, Name: pgObjName}
 					pgCache, ok := rest.cache.PgCache.AviCacheGet(pgKey)
 					if ok {
 						pgCacheObj, _ := pgCache.(*avicache.AviPGCache)
@@ -627,6 +661,16 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				}
 				rest_op.ObjName = PKIprofile
 				rest.AviPkiProfileCacheDel(rest_op, aviObjKey, key)
+			case "ApplicationProfile":
+				var ApplicationProfile string
+				switch rest_op.Obj.(type) {
+				case utils.AviRestObjMacro:
+					ApplicationProfile = *rest_op.Obj.(utils.AviRestObjMacro).Data.(avimodels.ApplicationProfile).Name
+				case avimodels.ApplicationProfile:
+					ApplicationProfile = *rest_op.Obj.(avimodels.ApplicationProfile).Name
+				}
+				rest_op.ObjName = ApplicationProfile
+				rest.AviAppProfileCacheDel(rest_op, key)
 			case "VirtualService":
 				rest.AviVsCacheDel(rest_op, aviObjKey, key)
 			case "VSDataScriptSet":
@@ -655,7 +699,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.Pool:
 					poolObjName = *rest_op.Obj.(avimodels.Pool).Name
 				}
-				aviObjCache.AviPopulateOnePoolCache(c, utils.CloudName, poolObjName)
+				aviObjCache.AviPopulateOnePoolCache(c, utils.CloudName, poolObjName, rest_op.Tenant)
 			case "PoolGroup":
 				var pgObjName string
 				switch rest_op.Obj.(type) {
@@ -664,7 +708,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.PoolGroup:
 					pgObjName = *rest_op.Obj.(avimodels.PoolGroup).Name
 				}
-				aviObjCache.AviPopulateOnePGCache(c, utils.CloudName, pgObjName)
+				aviObjCache.AviPopulateOnePGCache(c, utils.CloudName, pgObjName, rest_op.Tenant)
 			case "VsVip":
 				var VsVip string
 				switch rest_op.Obj.(type) {
@@ -673,7 +717,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.VsVip:
 					VsVip = *rest_op.Obj.(avimodels.VsVip).Name
 				}
-				aviObjCache.AviPopulateOneVsVipCache(c, utils.CloudName, VsVip)
+				aviObjCache.AviPopulateOneVsVipCache(c, utils.CloudName, VsVip, rest_op.Tenant)
 			case "HTTPPolicySet":
 				var HTTPPolicySet string
 				switch rest_op.Obj.(type) {
@@ -682,7 +726,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.HTTPPolicySet:
 					HTTPPolicySet = *rest_op.Obj.(avimodels.HTTPPolicySet).Name
 				}
-				aviObjCache.AviPopulateOneVsHttpPolCache(c, utils.CloudName, HTTPPolicySet)
+				aviObjCache.AviPopulateOneVsHttpPolCache(c, utils.CloudName, HTTPPolicySet, rest_op.Tenant)
 			case "L4PolicySet":
 				var L4PolicySet string
 				switch rest_op.Obj.(type) {
@@ -691,7 +735,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.L4PolicySet:
 					L4PolicySet = *rest_op.Obj.(avimodels.L4PolicySet).Name
 				}
-				aviObjCache.AviPopulateOneVsL4PolCache(c, utils.CloudName, L4PolicySet)
+				aviObjCache.AviPopulateOneVsL4PolCache(c, utils.CloudName, L4PolicySet, rest_op.Tenant)
 			case "SSLKeyAndCertificate":
 				var SSLKeyAndCertificate string
 				switch rest_op.Obj.(type) {
@@ -700,7 +744,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.SSLKeyAndCertificate:
 					SSLKeyAndCertificate = *rest_op.Obj.(avimodels.SSLKeyAndCertificate).Name
 				}
-				aviObjCache.AviPopulateOneSSLCache(c, utils.CloudName, SSLKeyAndCertificate)
+				aviObjCache.AviPopulateOneSSLCache(c, utils.CloudName, SSLKeyAndCertificate, rest_op.Tenant)
 			case "PKIprofile":
 				var PKIprofile string
 				switch rest_op.Obj.(type) {
@@ -709,9 +753,18 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.PKIprofile:
 					PKIprofile = *rest_op.Obj.(avimodels.PKIprofile).Name
 				}
-				aviObjCache.AviPopulateOnePKICache(c, utils.CloudName, PKIprofile)
+				aviObjCache.AviPopulateOnePKICache(c, utils.CloudName, PKIprofile, rest_op.Tenant)
+			case "ApplicationProfile":
+				var ApplicationProfile string
+				switch rest_op.Obj.(type) {
+				case utils.AviRestObjMacro:
+					ApplicationProfile = *rest_op.Obj.(utils.AviRestObjMacro).Data.(avimodels.ApplicationProfile).Name
+				case avimodels.ApplicationProfile:
+					ApplicationProfile = *rest_op.Obj.(avimodels.ApplicationProfile).Name
+				}
+				aviObjCache.AviPopulateOneAppProfileCache(c, utils.CloudName, ApplicationProfile, rest_op.Tenant)
 			case "VirtualService":
-				aviObjCache.AviObjOneVSCachePopulate(c, utils.CloudName, aviObjKey.Name)
+				aviObjCache.AviObjOneVSCachePopulate(c, utils.CloudName, aviObjKey.Name, aviObjKey.Namespace)
 				vsObjMeta, ok := rest.cache.VsCacheMeta.AviCacheGet(aviObjKey)
 				if !ok {
 					// Object deleted
@@ -730,7 +783,7 @@ func (rest *RestOperations) RefreshCacheForRetryLayer(parentVsKey string, aviObj
 				case avimodels.VSDataScript:
 					VSDataScriptSet = *rest_op.Obj.(avimodels.VSDataScriptSet).Name
 				}
-				aviObjCache.AviPopulateOneVsDSCache(c, utils.CloudName, VSDataScriptSet)
+				aviObjCache.AviPopulateOneVsDSCache(c, utils.CloudName, VSDataScriptSet, rest_op.Tenant)
 			}
 		} else if statuscode == 408 {
 			// This status code refers to a problem with the controller timeouts. We need to re-init the session object.
@@ -829,13 +882,13 @@ func (rest *RestOperations) DSDelete(ds_to_delete []avicache.NamespaceName, name
 
 func (rest *RestOperations) PoolCU(pool_nodes []*nodes.AviPoolNode, vs_cache_obj *avicache.AviVsCache, namespace string, rest_ops []*utils.RestOp, key string) ([]avicache.NamespaceName, []*utils.RestOp) {
 	var cache_pool_nodes []avicache.NamespaceName
-	var pool_pkiprofile_delete []avicache.NamespaceName
 	if vs_cache_obj != nil {
 		cache_pool_nodes = make([]avicache.NamespaceName, len(vs_cache_obj.PoolKeyCollection))
 		copy(cache_pool_nodes, vs_cache_obj.PoolKeyCollection)
 		utils.AviLog.Debugf("key: %s, msg: the cached pools are: %v", key, utils.Stringify(cache_pool_nodes))
 		if cache_pool_nodes != nil {
 			for _, pool := range pool_nodes {
+				var pool_pkiprofile_delete []avicache.NamespaceName
 				// check in the pool cache to see if this pool exists in AVI
 				pool_key := avicache.NamespaceName{Namespace: namespace, Name: pool.Name}
 				found := utils.HasElem(cache_pool_nodes, pool_key)
@@ -902,8 +955,10 @@ func (rest *RestOperations) SNINodeDelete(del_sni avicache.NamespaceName, namesp
 				bkt := utils.Bkt(key, shardSize)
 				utils.AviLog.Warnf("key: %s, msg: corrupted sni cache found, retrying in bkt: %v", key, bkt)
 				if len(rest.aviRestPoolClient.AviClient) > 0 {
-					aviclient := rest.aviRestPoolClient.AviClient[bkt]
-					aviObjCache.AviObjOneVSCachePopulate(aviclient, utils.CloudName, del_sni.Name)
+					aviclient := rest.aviRestPoolClient.GetClient(int(bkt))
+					SetTenant := session.SetTenant(namespace)
+					SetTenant(aviclient.AviSession)
+					aviObjCache.AviObjOneVSCachePopulate(aviclient, utils.CloudName, del_sni.Name, namespace)
 					vsObjMeta, ok := rest.cache.VsCacheMeta.AviCacheGet(sni_key)
 					if !ok {
 						// Object deleted
@@ -924,18 +979,37 @@ func (rest *RestOperations) SNINodeDelete(del_sni avicache.NamespaceName, namesp
 
 }
 
+// isSniChildOfOtherParent returns true if the SNI child is in the cache under a parent other than the one of the
+// node, which happens when its host was moved to another shard VS.
+func (rest *RestOperations) isSniChildOfOtherParent(sniKey avicache.NamespaceName, sni_node *nodes.AviVsNode, key string) bool {
+	sniCache, found := rest.cache.VsCacheMeta.AviCacheGet(sniKey)
+	if !found {
+		return false
+	}
+	sniCacheObj, ok := sniCache.(*avicache.AviVsCache)
+	if !ok || sniCacheObj.ParentVSRef.Name == "" || sniCacheObj.ParentVSRef.Name == sni_node.VHParentName {
+		return false
+	}
+	utils.AviLog.Infof("key: %s, msg: sni child %s moves from parent %s to %s", key, sniKey.Name, sniCacheObj.ParentVSRef.Name, sni_node.VHParentName)
+	return true
+}
+
 func (rest *RestOperations) SNINodeCU(sni_node *nodes.AviVsNode, vs_cache_obj *avicache.AviVsCache, namespace string, cache_sni_nodes []avicache.NamespaceName, rest_ops []*utils.RestOp, key string) ([]avicache.NamespaceName, []*utils.RestOp) {
 	var sni_pools_to_delete []avicache.NamespaceName
 	var sni_pgs_to_delete []avicache.NamespaceName
 	var http_policies_to_delete []avicache.NamespaceName
 	var sslkey_cert_delete []avicache.NamespaceName
-	if vs_cache_obj != nil {
-		sni_key := avicache.NamespaceName{Namespace: namespace, Name: sni_node.Name}
+	sni_key := avicache.NamespaceName{Namespace: namespace, Name: sni_node.Name}
+	// A host moved to another shard keeps its SNI child, which is moved under the new parent with a PUT.
+	reparent := rest.isSniChildOfOtherParent(sni_key, sni_node, key)
+	if vs_cache_obj != nil || reparent {
 		// Search the VS cache and obtain the UUID of this VS. Then see if this UUID is part of the SNIChildCollection or not.
 		found := utils.HasElem(cache_sni_nodes, sni_key)
 		utils.AviLog.Debugf("key: %s, msg: processing node key: %v", key, sni_key)
-		if found && cache_sni_nodes != nil {
-			cache_sni_nodes = Remove(cache_sni_nodes, sni_key)
+		if (found && cache_sni_nodes != nil) || reparent {
+			if found {
+				cache_sni_nodes = Remove(cache_sni_nodes, sni_key)
+			}
 			utils.AviLog.Debugf("key: %s, msg: the cache sni nodes are: %v", key, cache_sni_nodes)
 			sni_cache_obj := rest.getVsCacheObj(sni_key, key)
 			if sni_cache_obj != nil {
@@ -947,14 +1021,18 @@ func (rest *RestOperations) SNINodeCU(sni_node *nodes.AviVsNode, vs_cache_obj *a
 				sni_pools_to_delete, rest_ops = rest.PoolCU(sni_node.PoolRefs, sni_cache_obj, namespace, rest_ops, key)
 				sni_pgs_to_delete, rest_ops = rest.PoolGroupCU(sni_node.PoolGroupRefs, sni_cache_obj, namespace, rest_ops, key)
 				http_policies_to_delete, rest_ops = rest.HTTPPolicyCU(sni_node.HttpPolicyRefs, sni_cache_obj, namespace, rest_ops, key)
+				rest_ops = rest.ClientAuthCU(sni_node.ClientAuthAppProfile, namespace, rest_ops, key)
 
 				// The checksums are different, so it should be a PUT call.
-				if sni_cache_obj.CloudConfigCksum != strconv.Itoa(int(sni_node.GetCheckSum())) {
+				if reparent || sni_cache_obj.CloudConfigCksum != strconv.Itoa(int(sni_node.GetCheckSum())) {
 					restOp := rest.AviVsBuild(sni_node, utils.RestPut, sni_cache_obj, key)
 					rest_ops = append(rest_ops, restOp...)
 					utils.AviLog.Infof("key: %s, msg: the checksums are different for sni child %s, operation: PUT", key, sni_node.Name)
 
 				}
+				if sni_node.ClientAuthAppProfile == nil {
+					rest_ops = rest.ClientAuthDelete(sni_node.Name, namespace, rest_ops, key)
+				}
 			}
 		} else {
 			utils.AviLog.Debugf("key: %s, msg: sni child %s not found in cache, operation: POST", key, sni_node.Name)
@@ -963,6 +1041,7 @@ func (rest *RestOperations) SNINodeCU(sni_node *nodes.AviVsNode, vs_cache_obj *a
 			_, rest_ops = rest.PoolGroupCU(sni_node.PoolGroupRefs, nil, namespace, rest_ops, key)
 			_, rest_ops = rest.HTTPPolicyCU(sni_node.HttpPolicyRefs, nil, namespace, rest_ops, key)
 			_, rest_ops = rest.CACertCU(sni_node.CACertRefs, []avicache.NamespaceName{}, namespace, rest_ops, key)
+			rest_ops = rest.ClientAuthCU(sni_node.ClientAuthAppProfile, namespace, rest_ops, key)
 
 			// Not found - it should be a POST call.
 			restOp := rest.AviVsBuild(sni_node, utils.RestPost, nil, key)
@@ -980,6 +1059,7 @@ func (rest *RestOperations) SNINodeCU(sni_node *nodes.AviVsNode, vs_cache_obj *a
 		_, rest_ops = rest.PoolGroupCU(sni_node.PoolGroupRefs, nil, namespace, rest_ops, key)
 		_, rest_ops = rest.HTTPPolicyCU(sni_node.HttpPolicyRefs, nil, namespace, rest_ops, key)
 		_, rest_ops = rest.CACertCU(sni_node.CACertRefs, []avicache.NamespaceName{}, namespace, rest_ops, key)
+		rest_ops = rest.ClientAuthCU(sni_node.ClientAuthAppProfile, namespace, rest_ops, key)
 
 		// Not found - it should be a POST call.
 		restOp := rest.AviVsBuild(sni_node, utils.RestPost, nil, key)
@@ -1348,6 +1428,9 @@ func (rest *RestOperations) PkiProfileCU(pki_node *nodes.AviPkiProfileNode, pool
 			}
 		}
 
+	} else if pool_cache_obj != nil && pool_cache_obj.PkiProfileCollection.Name != "" {
+		// the pool no longer validates the server certificate, remove its pki profile
+		cache_pki_nodes = []avicache.NamespaceName{pool_cache_obj.PkiProfileCollection}
 	} else {
 		if pki_node != nil {
 			// Everything is a POST call
@@ -1375,6 +1458,63 @@ func (rest *RestOperations) PkiProfileDelete(pkiProfileDelete []avicache.Namespa
 	return rest_ops
 }
 
+// ClientAuthCU creates or updates the PKI profile and the application profile used for
+// client certificate validation on a SNI child. Both have to precede the VS operation.
+func (rest *RestOperations) ClientAuthCU(appProfNode *nodes.AviAppProfileNode, namespace string, rest_ops []*utils.RestOp, key string) []*utils.RestOp {
+	if appProfNode == nil {
+		return rest_ops
+	}
+
+	if pkiNode := appProfNode.PkiProfile; pkiNode != nil {
+		pkiKey := avicache.NamespaceName{Namespace: namespace, Name: pkiNode.Name}
+		pkiCache, ok := rest.cache.PKIProfileCache.AviCacheGet(pkiKey)
+		if ok {
+			pkiCacheObj, _ := pkiCache.(*avicache.AviPkiProfileCache)
+			if pkiCacheObj.CloudConfigCksum != pkiNode.GetCheckSum() {
+				utils.AviLog.Debugf("key: %s, msg: the checksums are different for pki profile %s, operation: PUT", key, pkiNode.Name)
+				rest_ops = append(rest_ops, rest.AviPkiProfileBuild(pkiNode, pkiCacheObj))
+			}
+		} else {
+			utils.AviLog.Debugf("key: %s, msg: pki profile %s not found in cache, operation: POST", key, pkiNode.Name)
+			rest_ops = append(rest_ops, rest.AviPkiProfileBuild(pkiNode, nil))
+		}
+	}
+
+	appProfKey := avicache.NamespaceName{Namespace: namespace, Name: appProfNode.Name}
+	appProfCache, ok := rest.cache.AppProfileCache.AviCacheGet(appProfKey)
+	if ok {
+		appProfCacheObj, _ := appProfCache.(*avicache.AviAppProfileCache)
+		if appProfCacheObj.CloudConfigCksum != strconv.Itoa(int(appProfNode.GetCheckSum())) {
+			utils.AviLog.Debugf("key: %s, msg: the checksums are different for application profile %s, operation: PUT", key, appProfNode.Name)
+			rest_ops = append(rest_ops, rest.AviAppProfileBuild(appProfNode, appProfCacheObj))
+		}
+	} else {
+		utils.AviLog.Debugf("key: %s, msg: application profile %s not found in cache, operation: POST", key, appProfNode.Name)
+		rest_ops = append(rest_ops, rest.AviAppProfileBuild(appProfNode, nil))
+	}
+	return rest_ops
+}
+
+// ClientAuthDelete removes the client certificate validation profiles created for a SNI child,
+// it must follow the VS operation which drops the references.
+func (rest *RestOperations) ClientAuthDelete(sniNodeName string, namespace string, rest_ops []*utils.RestOp, key string) []*utils.RestOp {
+	appProfKey := avicache.NamespaceName{Namespace: namespace, Name: lib.GetClientAuthAppProfileName(sniNodeName)}
+	appProfCache, ok := rest.cache.AppProfileCache.AviCacheGet(appProfKey)
+	if ok {
+		appProfCacheObj, _ := appProfCache.(*avicache.AviAppProfileCache)
+		utils.AviLog.Infof("key: %s, msg: about to delete application profile %s", key, appProfKey.Name)
+		restOp := rest.AviAppProfileDel(appProfCacheObj.Uuid, namespace)
+		restOp.ObjName = appProfKey.Name
+		rest_ops = append(rest_ops, restOp)
+	}
+
+	pkiKey := avicache.NamespaceName{Namespace: namespace, Name: lib.GetClientAuthPkiProfileName(sniNodeName)}
+	if _, ok := rest.cache.PKIProfileCache.AviCacheGet(pkiKey); ok {
+		rest_ops = rest.PkiProfileDelete([]avicache.NamespaceName{pkiKey}, namespace, rest_ops, key)
+	}
+	return rest_ops
+}
+
 func Remove(s []avicache.NamespaceName, r avicache.NamespaceName) []avicache.NamespaceName {
 	for i, v := range s {
 		if v == r {
@@ -104,10 +104,10 @@ func (rest *RestOperations) SyncIngressStatus() {
 	}
 
 	if lib.GetAdvancedL4() {
-		status.UpdateGatewayStatusAddress(allGatewayUpdateOptions, true)
+		status.UpdateGatewayStatusAddress(allGatewayUpdateOptions)
 	} else {
-		status.UpdateRouteIngressStatus(allIngressUpdateOptions, true)
-		status.UpdateL4LBStatus(allServiceLBUpdateOptions, true)
+		status.UpdateRouteIngressStatus(allIngressUpdateOptions)
+		status.UpdateL4LBStatus(allServiceLBUpdateOptions)
 	}
 	utils.AviLog.Infof("Status syncing completed")
 	return
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avinetworks/sdk/go/session"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	RetryClassConflict   = "conflict"
+	RetryClassNotFound   = "not-found"
+	RetryClassQuota      = "quota"
+	RetryClassValidation = "validation"
+	RetryClassTransient  = "transient"
+)
+
+// RetryBackoff is the backoff curve of an error class, the delays are in seconds. The delay starts at
+// InitialDelay and is multiplied by Factor on every attempt, up to MaxDelay. A model is dead lettered once
+// it failed MaxRetries times in a row, right away when MaxRetries is 0.
+type RetryBackoff struct {
+	InitialDelay int     `json:"initialDelay"`
+	MaxDelay     int     `json:"maxDelay"`
+	Factor       float64 `json:"factor"`
+	MaxRetries   int     `json:"maxRetries"`
+}
+
+var defaultRetryPolicy = map[string]RetryBackoff{
+	// the cache is refreshed for the conflicting or missing object, the next attempt is expected to go through
+	RetryClassConflict: {InitialDelay: 1, MaxDelay: 30, Factor: 2, MaxRetries: 10},
+	RetryClassNotFound: {InitialDelay: 1, MaxDelay: 30, Factor: 2, MaxRetries: 10},
+	// it takes an admin to raise the limits on the controller
+	RetryClassQuota: {InitialDelay: 30, MaxDelay: 600, Factor: 2, MaxRetries: 10},
+	// the model needs to change for the controller to accept it
+	RetryClassValidation: {MaxRetries: 0},
+	RetryClassTransient:  {InitialDelay: 2, MaxDelay: 300, Factor: 2, MaxRetries: 10},
+}
+
+var retryPolicy map[string]RetryBackoff
+var retrypolicyonce sync.Once
+
+// modelRetryState holds the consecutive failed attempts of a model, along with the checksum of the model they
+// were made for
+type modelRetryState struct {
+	checksum uint32
+	attempts int
+}
+
+// modelRetries holds the retry state of each failing model, by model name
+var modelRetries = make(map[string]*modelRetryState)
+var modelRetriesLock sync.Mutex
+
+// GetRetryPolicy returns the backoff curves of the error classes, the defaults overridden by the ones set
+// in RETRY_POLICY as a JSON object keyed by error class.
+func GetRetryPolicy() map[string]RetryBackoff {
+	retrypolicyonce.Do(func() {
+		retryPolicy = make(map[string]RetryBackoff)
+		for errClass, backoff := range defaultRetryPolicy {
+			retryPolicy[errClass] = backoff
+		}
+		policyStr := os.Getenv(lib.RETRY_POLICY)
+		if policyStr == "" {
+			return
+		}
+		var policy map[string]RetryBackoff
+		if err := json.Unmarshal([]byte(policyStr), &policy); err != nil {
+			utils.AviLog.Warnf("Invalid retry policy %s, using the default policy, err: %v", policyStr, err)
+			return
+		}
+		for errClass, backoff := range policy {
+			if _, ok := defaultRetryPolicy[errClass]; !ok {
+				utils.AviLog.Warnf("Unknown error class %s in the retry policy, ignoring it", errClass)
+				continue
+			}
+			if backoff.Factor < 1 {
+				backoff.Factor = 1
+			}
+			retryPolicy[errClass] = backoff
+		}
+	})
+	return retryPolicy
+}
+
+// ClassifyRestError maps the error returned by the controller for an object to the error class deciding how
+// the model is retried.
+func ClassifyRestError(aviError session.AviError) string {
+	statuscode := aviError.HttpStatusCode
+	errorStr := strings.ToLower(aviError.Error())
+	switch {
+	case statuscode == 409:
+		return RetryClassConflict
+	case statuscode == 404:
+		return RetryClassNotFound
+	case statuscode == 429 || statuscode >= 400 && statuscode < 500 &&
+		(strings.Contains(errorStr, "quota") || strings.Contains(errorStr, "limit")):
+		return RetryClassQuota
+	case statuscode == 408 || statuscode == 419 || statuscode == 0 || statuscode >= 500:
+		return RetryClassTransient
+	default:
+		return RetryClassValidation
+	}
+}
+
+// retryDelay returns the delay before the given attempt of a model failing with the error class
+func retryDelay(backoff RetryBackoff, attempt int) time.Duration {
+	delay := float64(backoff.InitialDelay) * math.Pow(backoff.Factor, float64(attempt-1))
+	if delay > float64(backoff.MaxDelay) {
+		delay = float64(backoff.MaxDelay)
+	}
+	return time.Duration(delay * float64(time.Second))
+}
+
+// RetryOrDeadLetter schedules the retry of the model that failed with the error class, after the delay of its
+// backoff curve. A model which failed as many times as the curve allows, or whose retries are exhausted, is added
+// to the dead letter set instead. The attempts start over once the checksum of the model changes.
+func (rest *RestOperations) RetryOrDeadLetter(modelName string, checksum uint32, errClass string, err error, exhausted bool, key string) {
+	backoff := GetRetryPolicy()[errClass]
+
+	modelRetriesLock.Lock()
+	state, ok := modelRetries[modelName]
+	if !ok || state.checksum != checksum {
+		state = &modelRetryState{checksum: checksum}
+		modelRetries[modelName] = state
+	}
+	state.attempts++
+	attempts := state.attempts
+	modelRetriesLock.Unlock()
+
+	if exhausted || attempts > backoff.MaxRetries {
+		utils.AviLog.Warnf("key: %s, msg: model %s failed %d times with %s error, not retrying it anymore: %v", key, modelName, attempts, errClass, err)
+		models.DeadLetterStatus.AddDeadLetteredModel(models.DeadLetteredModel{
+			Model:          modelName,
+			ErrorClass:     errClass,
+			Error:          err.Error(),
+			Attempts:       attempts,
+			DeadLetteredAt: time.Now(),
+		})
+		recordDeadLetterEvent(modelName, errClass, attempts)
+		return
+	}
+	delay := retryDelay(backoff, attempts)
+	utils.AviLog.Infof("key: %s, msg: retrying model %s after %v, attempt %d for %s error", key, modelName, delay, attempts, errClass)
+	rest.PublishKeyToRetryLayer(modelName, key, delay)
+}
+
+// ClearRetryState forgets the failed attempts of the model, once it went through, is deleted or is retried by hand
+func ClearRetryState(modelName string) {
+	modelRetriesLock.Lock()
+	_, failed := modelRetries[modelName]
+	delete(modelRetries, modelName)
+	modelRetriesLock.Unlock()
+	if failed {
+		models.DeadLetterStatus.RemoveDeadLetteredModel(modelName)
+	}
+}
+
+// ClearRetryStateOnChange forgets the failed attempts of the model if its checksum changed since they were made,
+// the changed model may well go through.
+func ClearRetryStateOnChange(modelName string, checksum uint32) {
+	modelRetriesLock.Lock()
+	state, failed := modelRetries[modelName]
+	changed := failed && state.checksum != checksum
+	modelRetriesLock.Unlock()
+	if changed {
+		ClearRetryState(modelName)
+	}
+}
+
+func recordDeadLetterEvent(modelName, errClass string, attempts int) {
+	recorder := lib.GetEventRecorder()
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      "ConfigMap",
+		Namespace: lib.AviNS,
+		Name:      lib.AviConfigMap,
+	}
+	recorder.Event(ref, corev1.EventTypeWarning, lib.RetryExhausted,
+		fmt.Sprintf("Model %s failed %d times with %s error, it is not retried until it changes or is retried via the API", modelName, attempts, errClass))
+}
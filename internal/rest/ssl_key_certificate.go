@@ -188,6 +188,12 @@ func (rest *RestOperations) AviPkiProfileBuild(pki_node *nodes.AviPkiProfileNode
 		}),
 	}
 
+	if pki_node.CRL != "" {
+		crl := pki_node.CRL
+		crlcheck = true
+		pkiobject.Crls = []*avimodels.CRL{&avimodels.CRL{Body: &crl}}
+	}
+
 	macro := utils.AviRestObjMacro{ModelName: "PKIprofile", Data: pkiobject}
 
 	var path string
@@ -238,15 +244,20 @@ func (rest *RestOperations) AviPkiProfileAdd(rest_op *utils.RestOp, poolKey avic
 			continue
 		}
 
-		var pkiCertificate string
+		var pkiProfile avimodels.PKIprofile
 		switch rest_op.Obj.(type) {
 		case utils.AviRestObjMacro:
-			pkiCertificate = *rest_op.Obj.(utils.AviRestObjMacro).Data.(avimodels.PKIprofile).CaCerts[0].Certificate
+			pkiProfile = rest_op.Obj.(utils.AviRestObjMacro).Data.(avimodels.PKIprofile)
 		case avimodels.PKIprofile:
-			pkiCertificate = *rest_op.Obj.(avimodels.PKIprofile).CaCerts[0].Certificate
+			pkiProfile = rest_op.Obj.(avimodels.PKIprofile)
+		}
+		pkiCertificate := *pkiProfile.CaCerts[0].Certificate
+		var crl string
+		if len(pkiProfile.Crls) > 0 {
+			crl = *pkiProfile.Crls[0].Body
 		}
 
-		checksum := lib.SSLKeyCertChecksum(name, pkiCertificate, "")
+		checksum := lib.SSLKeyCertChecksum(name, pkiCertificate, crl)
 		pki_cache_obj := avicache.AviPkiProfileCache{
 			Name:             name,
 			Tenant:           rest_op.Tenant,
@@ -15,14 +15,31 @@ package retry
 
 import (
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/rest"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 )
 
-func DequeueFastRetry(vsKey string) {
-	utils.AviLog.Infof("Retrieved the key for fast retry: %s", vsKey)
+// DequeueFastRetry republishes the model, keyed by tenant and VS name, to the rest layer
+func DequeueFastRetry(modelName string) {
+	utils.AviLog.Infof("Retrieved the key for fast retry: %s", modelName)
 	sharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
-	modelName := utils.ADMIN_NS + "/" + vsKey
 	nodes.PublishKeyToRestLayer(modelName, "retry", sharedQueue)
 
 }
+
+// RetryModelNow republishes the model to the rest layer right away, with its retries reset. This is used for
+// the models in the dead letter set, once what the controller rejected them for is fixed.
+func RetryModelNow(modelName string) {
+	utils.AviLog.Infof("Retrying model %s on request", modelName)
+	rest.ClearRetryState(modelName)
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if found && aviModel != nil {
+		if avimodel, ok := aviModel.(*nodes.AviObjectGraph); ok {
+			avimodel.SetRetryCounter()
+		}
+	}
+	sharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
+	nodes.PublishKeyToRestLayer(modelName, "retry", sharedQueue)
+}
@@ -25,6 +25,7 @@ import (
 
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // Type: specific condition enums must be part of independent update functions
@@ -35,26 +36,19 @@ type UpdateGWStatusConditionOptions struct {
 	LastTransitionTime metav1.Time          // send in time of function call
 }
 
-// TODO: handle bulk during bootup
-func UpdateGatewayStatusAddress(options []UpdateStatusOptions, bulk bool) {
+// UpdateGatewayStatusAddress queues the status update of the gateways with their VIP, along with the one of
+// their services.
+func UpdateGatewayStatusAddress(options []UpdateStatusOptions) {
 	for _, option := range options {
 		gatewayNSName := strings.Split(option.ServiceMetadata.Gateway, "/")
-		gw, err := lib.GetAdvL4Clientset().NetworkingV1alpha1pre1().Gateways(gatewayNSName[0]).Get(gatewayNSName[1], metav1.GetOptions{})
-		if err != nil {
-			utils.AviLog.Infof("key: %s, msg: unable to find gateway object %s", option.Key, option.ServiceMetadata.Gateway)
-		}
-
+		vip := option.Vip
 		// assuming 1 IP per gateway
-		gwStatus := gw.Status
-		if len(gwStatus.Addresses) > 0 && gwStatus.Addresses[0].Value == option.Vip {
-			continue
-		}
-
-		gwStatus.Addresses = []advl4v1alpha1pre1.GatewayAddress{{
-			Value: option.Vip,
-			Type:  advl4v1alpha1pre1.IPAddressType,
-		}}
-		updateGatewayStatusObject(gw, &gwStatus)
+		SharedStatusQueue().Enqueue(lib.Gateway, gatewayNSName[0], gatewayNSName[1], option.Key, func(obj runtime.Object) {
+			obj.(*advl4v1alpha1pre1.Gateway).Status.Addresses = []advl4v1alpha1pre1.GatewayAddress{{
+				Value: vip,
+				Type:  advl4v1alpha1pre1.IPAddressType,
+			}}
+		})
 
 		utils.AviLog.Debugf("key: %s, msg: Updating corresponding service %v statuses for gateway %s",
 			option.Key, option.ServiceMetadata.NamespaceServiceName, option.ServiceMetadata.Gateway)
@@ -65,33 +59,17 @@ func UpdateGatewayStatusAddress(options []UpdateStatusOptions, bulk bool) {
 				ServiceMetadata: avicache.ServiceMetadataObj{
 					NamespaceServiceName: []string{svcData},
 				},
-			}}, false)
+			}})
 		}
 	}
-
-	return
 }
 
-func DeleteGatewayStatusAddress(svcMetadataObj avicache.ServiceMetadataObj, key string) error {
+// DeleteGatewayStatusAddress queues the reset of the address of the gateway, along with the status of its services.
+func DeleteGatewayStatusAddress(svcMetadataObj avicache.ServiceMetadataObj, key string) {
 	gwNSName := strings.Split(svcMetadataObj.Gateway, "/")
-	gw, err := lib.GetAdvL4Clientset().NetworkingV1alpha1pre1().Gateways(gwNSName[0]).Get(gwNSName[1], metav1.GetOptions{})
-	if err != nil {
-		utils.AviLog.Warnf("key: %s, msg: there was a problem in resetting the gateway address status: %s", key, err)
-		return err
-	}
-
-	if len(gw.Status.Addresses) == 0 ||
-		(len(gw.Status.Addresses) > 0 && gw.Status.Addresses[0].Value == "") {
-		return nil
-	}
-
-	// assuming 1 IP per gateway
-	gw.Status.Addresses = []advl4v1alpha1pre1.GatewayAddress{}
-	_, err = lib.GetAdvL4Clientset().NetworkingV1alpha1pre1().Gateways(gwNSName[0]).UpdateStatus(gw)
-	if err != nil {
-		utils.AviLog.Errorf("key: %s, msg: there was an error in resetting the gateway status: %v", key, err)
-		return err
-	}
+	SharedStatusQueue().Enqueue(lib.Gateway, gwNSName[0], gwNSName[1], key, func(obj runtime.Object) {
+		obj.(*advl4v1alpha1pre1.Gateway).Status.Addresses = []advl4v1alpha1pre1.GatewayAddress{}
+	})
 
 	utils.AviLog.Debugf("key: %s, msg: Deleting corresponding service %v statuses for gateway %s",
 		key, svcMetadataObj.NamespaceServiceName, svcMetadataObj.Gateway)
@@ -100,9 +78,6 @@ func DeleteGatewayStatusAddress(svcMetadataObj avicache.ServiceMetadataObj, key
 			NamespaceServiceName: []string{svcData},
 		}, key)
 	}
-
-	utils.AviLog.Infof("key: %s, msg: Successfully reset the address status of gateway: %s", key, svcMetadataObj.Gateway)
-	return nil
 }
 
 // supported GatewayConditionTypes
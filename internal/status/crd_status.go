@@ -92,3 +92,65 @@ func UpdateHTTPRuleStatus(rr *akov1alpha1.HTTPRule, updateStatus UpdateCRDStatus
 	utils.AviLog.Infof("msg: Successfully updated the httprule %s/%s status %+v", rr.Namespace, rr.Name, utils.Stringify(updateStatus))
 	return nil
 }
+
+// UpdateHostnameClaimStatus HostnameClaim status updates
+func UpdateHostnameClaimStatus(hc *akov1alpha1.HostnameClaim, updateStatus UpdateCRDStatusOptions, retryNum ...int) error {
+	retry := 0
+	if len(retryNum) > 0 {
+		retry = retryNum[0]
+		if retry >= 2 {
+			return errors.New("msg: UpdateHostnameClaimStatus retried 3 times, aborting")
+		}
+	}
+
+	hc.Status.Status = updateStatus.Status
+	hc.Status.Error = updateStatus.Error
+
+	_, err := lib.GetCRDClientset().AkoV1alpha1().HostnameClaims(hc.Namespace).UpdateStatus(hc)
+	if err != nil {
+		utils.AviLog.Errorf("msg: %d there was an error in updating the hostnameclaim status: %+v", retry, err)
+		updatedHc, err := lib.GetCRDClientset().AkoV1alpha1().HostnameClaims(hc.Namespace).Get(hc.Name, metav1.GetOptions{})
+		if err != nil {
+			utils.AviLog.Warnf("hostnameclaim not found %v", err)
+			if strings.Contains(err.Error(), utils.K8S_ETIMEDOUT) {
+				return UpdateHostnameClaimStatus(updatedHc, updateStatus, retry+1)
+			}
+			return err
+		}
+		return UpdateHostnameClaimStatus(updatedHc, updateStatus, retry+1)
+	}
+
+	utils.AviLog.Infof("msg: Successfully updated the hostnameclaim %s/%s status %+v", hc.Namespace, hc.Name, utils.Stringify(updateStatus))
+	return nil
+}
+
+// UpdateAviInfraSettingStatus AviInfraSetting status updates
+func UpdateAviInfraSettingStatus(infraSetting *akov1alpha1.AviInfraSetting, updateStatus UpdateCRDStatusOptions, retryNum ...int) error {
+	retry := 0
+	if len(retryNum) > 0 {
+		retry = retryNum[0]
+		if retry >= 2 {
+			return errors.New("msg: UpdateAviInfraSettingStatus retried 3 times, aborting")
+		}
+	}
+
+	infraSetting.Status.Status = updateStatus.Status
+	infraSetting.Status.Error = updateStatus.Error
+
+	_, err := lib.GetCRDClientset().AkoV1alpha1().AviInfraSettings().UpdateStatus(infraSetting)
+	if err != nil {
+		utils.AviLog.Errorf("msg: %d there was an error in updating the aviinfrasetting status: %+v", retry, err)
+		updatedInfraSetting, err := lib.GetCRDClientset().AkoV1alpha1().AviInfraSettings().Get(infraSetting.Name, metav1.GetOptions{})
+		if err != nil {
+			utils.AviLog.Warnf("aviinfrasetting not found %v", err)
+			if strings.Contains(err.Error(), utils.K8S_ETIMEDOUT) {
+				return UpdateAviInfraSettingStatus(updatedInfraSetting, updateStatus, retry+1)
+			}
+			return err
+		}
+		return UpdateAviInfraSettingStatus(updatedInfraSetting, updateStatus, retry+1)
+	}
+
+	utils.AviLog.Infof("msg: Successfully updated the aviinfrasetting %s status %+v", infraSetting.Name, utils.Stringify(updateStatus))
+	return nil
+}
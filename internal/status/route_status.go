@@ -24,11 +24,12 @@ import (
 
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func ParseOptionsFromMetadata(options []UpdateStatusOptions, bulk bool) ([]string, []UpdateStatusOptions) {
-	var objectsToUpdate []string
+// ParseOptionsFromMetadata returns an update option per ingress/route of the options, with the namespace/name
+// of the ingress/route set.
+func ParseOptionsFromMetadata(options []UpdateStatusOptions) []UpdateStatusOptions {
 	var updateIngressOptions []UpdateStatusOptions
 
 	for _, option := range options {
@@ -41,19 +42,15 @@ func ParseOptionsFromMetadata(options []UpdateStatusOptions, bulk bool) ([]strin
 					continue
 				}
 
-				ingress := ingressArr[0] + "/" + ingressArr[1]
-				option.IngSvc = ingress
-				objectsToUpdate = append(objectsToUpdate, ingress)
+				option.IngSvc = ingressArr[0] + "/" + ingressArr[1]
 				updateIngressOptions = append(updateIngressOptions, option)
 			}
 		} else {
-			ingress := option.ServiceMetadata.Namespace + "/" + option.ServiceMetadata.IngressName
-			option.IngSvc = ingress
-			objectsToUpdate = append(objectsToUpdate, ingress)
+			option.IngSvc = option.ServiceMetadata.Namespace + "/" + option.ServiceMetadata.IngressName
 			updateIngressOptions = append(updateIngressOptions, option)
 		}
 	}
-	return objectsToUpdate, updateIngressOptions
+	return updateIngressOptions
 }
 
 // To Do: Check if it is possible to do update operations under same functions for both
@@ -61,11 +58,11 @@ func ParseOptionsFromMetadata(options []UpdateStatusOptions, bulk bool) ([]strin
 // Currently there are too many api calls, which are different for routes and ingresses,
 // to have them under same function.
 
-func UpdateRouteIngressStatus(options []UpdateStatusOptions, bulk bool) {
+func UpdateRouteIngressStatus(options []UpdateStatusOptions) {
 	if utils.GetInformers().IngressInformer != nil {
-		UpdateIngressStatus(options, bulk)
+		UpdateIngressStatus(options)
 	} else if utils.GetInformers().RouteInformer != nil {
-		UpdateRouteStatus(options, bulk)
+		UpdateRouteStatus(options)
 	} else {
 		utils.AviLog.Errorf("Status update failed, no suitable informers found")
 	}
@@ -82,124 +79,58 @@ func DeleteRouteIngressStatus(svc_mdata_obj avicache.ServiceMetadataObj, isVSDel
 	}
 }
 
-func UpdateRouteStatus(options []UpdateStatusOptions, bulk bool) {
-	var err error
-	routesToUpdate, updateRouteOptions := ParseOptionsFromMetadata(options, bulk)
-
-	// routeMap: {ns/Route: routeObj}
-	// this pre-fetches all routes to be candidates for status update
-	// after pre-fetching, if a status update comes for that route, then the pre-fetched route would be stale
-	// in which case route will be fetched again in updateObject, as part of a retry
-	routeMap := getRoutes(routesToUpdate, bulk)
-	for _, option := range updateRouteOptions {
-		if route := routeMap[option.IngSvc]; route != nil {
-			if err = updateRouteObject(route, option); err != nil {
-				utils.AviLog.Error(err)
-			}
-		}
+// UpdateRouteStatus queues the status update of the routes with the VIP of their hosts
+func UpdateRouteStatus(options []UpdateStatusOptions) {
+	for _, option := range ParseOptionsFromMetadata(options) {
+		option := option
+		nsName := strings.Split(option.IngSvc, "/")
+		SharedStatusQueue().Enqueue(utils.OshiftRoute, nsName[0], nsName[1], option.Key, func(obj runtime.Object) {
+			updateRouteObject(obj.(*routev1.Route), option)
+		})
 	}
-
-	return
 }
 
-func getRoutes(routeNSNames []string, bulk bool, retryNum ...int) map[string]*routev1.Route {
-	retry := 0
-	routeMap := make(map[string]*routev1.Route)
-	if len(retryNum) > 0 {
-		utils.AviLog.Infof("msg: Retrying to get the routes for status update")
-		retry = retryNum[0]
-		if retry >= 2 {
-			utils.AviLog.Errorf("msg: getRoutes for status update retried 3 times, aborting")
-			return routeMap
+// UpdateRouteStatusWithErrMsg queues the update of the status of a route AKO does not admit, with the reason.
+func UpdateRouteStatusWithErrMsg(routeName, namespace, msg string) {
+	key := utils.OshiftRoute + "/" + namespace + "/" + routeName
+	SharedStatusQueue().Enqueue(utils.OshiftRoute, namespace, routeName, key, func(obj runtime.Object) {
+		mRoute := obj.(*routev1.Route)
+		mRoute.Status.Ingress = otherRouterStatus(mRoute.Status.Ingress)
+		condition := routev1.RouteIngressCondition{
+			Status: corev1.ConditionFalse,
+			Reason: msg,
+			Type:   routev1.RouteAdmitted,
 		}
-	}
 
-	if bulk {
-		routeList, err := utils.GetInformers().OshiftClient.RouteV1().Routes("").List(metav1.ListOptions{})
-		if err != nil {
-			utils.AviLog.Warnf("Could not get the route object for UpdateStatus: %s", err)
-			// retry get if request timeout
-			if strings.Contains(err.Error(), utils.K8S_ETIMEDOUT) {
-				return getRoutes(routeNSNames, bulk, retry+1)
-			}
+		rtIngress := routev1.RouteIngress{
+			Host:       mRoute.Spec.Host,
+			RouterName: lib.AKOUser,
+			Conditions: []routev1.RouteIngressCondition{
+				condition,
+			},
 		}
-		for i := range routeList.Items {
-			route := routeList.Items[i]
-			routeMap[route.Namespace+"/"+route.Name] = &route
-		}
-
-		return routeMap
-	}
-	utils.AviLog.Infof("routeNSNames: %v", routeNSNames)
-	for _, namespaceName := range routeNSNames {
-		nsNameSplit := strings.Split(namespaceName, "/")
-		if len(nsNameSplit) != 2 {
-			utils.AviLog.Warnf("msg: namespaceName %s has wrong format", namespaceName)
-			continue
-		}
-		route, err := utils.GetInformers().OshiftClient.RouteV1().Routes(nsNameSplit[0]).Get(nsNameSplit[1], metav1.GetOptions{})
-		if err != nil {
-			utils.AviLog.Warnf("msg: Could not get the route object for UpdateStatus: %s", err)
-			// retry get if request timeout
-			if strings.Contains(err.Error(), utils.K8S_ETIMEDOUT) {
-				return getRoutes(routeNSNames, bulk, retry+1)
-			}
-			continue
-		}
-		routeMap[route.Namespace+"/"+route.Name] = route
-	}
-
-	return routeMap
+		mRoute.Status.Ingress = append(mRoute.Status.Ingress, rtIngress)
+	})
 }
 
-func UpdateRouteStatusWithErrMsg(routeName, namespace, msg string, retryNum ...int) error {
-	retry := 0
-	if len(retryNum) > 0 {
-		retry = retryNum[0]
-		if retry >= 2 {
-			return errors.New("msg: UpdateRouteStatus retried 3 times, aborting")
-		}
-	}
-
-	mRoutes := getRoutes([]string{namespace + "/" + routeName}, false)
-	if len(mRoutes) == 0 {
-		return nil
-	}
-	mRoute := mRoutes[namespace+"/"+routeName]
-	oldRouteStatus := mRoute.Status.DeepCopy()
-
-	mRoute.Status.Ingress = []routev1.RouteIngress{}
-	condition := routev1.RouteIngressCondition{
-		Status: corev1.ConditionFalse,
-		Reason: msg,
-		Type:   routev1.RouteAdmitted,
-	}
-
-	rtIngress := routev1.RouteIngress{
-		Host:       mRoute.Spec.Host,
-		RouterName: lib.AKOUser,
-		Conditions: []routev1.RouteIngressCondition{
-			condition,
-		},
-	}
-	mRoute.Status.Ingress = append(mRoute.Status.Ingress, rtIngress)
-
-	if sameStatus := compareRouteStatus(oldRouteStatus.Ingress, mRoute.Status.Ingress); sameStatus {
-		utils.AviLog.Debugf("msg: No changes detected in route status. old: %+v new: %+v",
-			oldRouteStatus.Ingress, mRoute.Status.Ingress)
-		return nil
-	}
+// ResetRouteStatus queues the removal of the status written by AKO from a route, this is required once
+// the route is no longer served by AKO, the status of other routers is left untouched.
+func ResetRouteStatus(routeName, namespace, key string) {
+	SharedStatusQueue().Enqueue(utils.OshiftRoute, namespace, routeName, key, func(obj runtime.Object) {
+		mRoute := obj.(*routev1.Route)
+		mRoute.Status.Ingress = otherRouterStatus(mRoute.Status.Ingress)
+	})
+}
 
-	_, err := utils.GetInformers().OshiftClient.RouteV1().Routes(mRoute.Namespace).UpdateStatus(mRoute)
-	if err != nil {
-		utils.AviLog.Errorf("msg: there was an error in updating the route status: %v", err)
-		// fetch updated route and feed for update status
-		mRoutes := getRoutes([]string{mRoute.Namespace + "/" + mRoute.Name}, false)
-		if len(mRoutes) > 0 {
-			return UpdateRouteStatusWithErrMsg(routeName, namespace, msg, retry+1)
+// otherRouterStatus returns the route status entries that are not owned by AKO.
+func otherRouterStatus(routeStatus []routev1.RouteIngress) []routev1.RouteIngress {
+	otherStatus := []routev1.RouteIngress{}
+	for _, status := range routeStatus {
+		if status.RouterName != lib.AKOUser {
+			otherStatus = append(otherStatus, status)
 		}
 	}
-	return err
+	return otherStatus
 }
 
 func routeStatusCheck(oldStatus []routev1.RouteIngress, hostname string) bool {
@@ -221,19 +152,8 @@ func routeStatusCheck(oldStatus []routev1.RouteIngress, hostname string) bool {
 	return false
 }
 
-func updateRouteObject(mRoute *routev1.Route, updateOption UpdateStatusOptions, retryNum ...int) error {
-	retry := 0
-	if len(retryNum) > 0 {
-		retry = retryNum[0]
-		if retry >= 2 {
-			return errors.New("key: %s, msg: UpdateRouteStatus retried 3 times, aborting")
-		}
-	}
-
-	var err error
-	utils.AviLog.Infof("updateOption: %v", updateOption)
-	hostnames, key := updateOption.ServiceMetadata.HostNames, updateOption.Key
-	oldRouteStatus := mRoute.Status.DeepCopy()
+func updateRouteObject(mRoute *routev1.Route, updateOption UpdateStatusOptions) {
+	hostnames := routeStatusHosts(mRoute, updateOption.ServiceMetadata.HostNames)
 
 	// Clean up all hosts that are not part of the route spec.
 	var hostListIng []string
@@ -241,6 +161,9 @@ func updateRouteObject(mRoute *routev1.Route, updateOption UpdateStatusOptions,
 
 	// If we find a hostname in the present update, let's first remove it from the existing status.
 	for i := len(mRoute.Status.Ingress) - 1; i >= 0; i-- {
+		if mRoute.Status.Ingress[i].RouterName != lib.AKOUser {
+			continue
+		}
 		if utils.HasElem(hostnames, mRoute.Status.Ingress[i].Host) {
 			mRoute.Status.Ingress = append(mRoute.Status.Ingress[:i], mRoute.Status.Ingress[i+1:]...)
 		}
@@ -261,36 +184,36 @@ func updateRouteObject(mRoute *routev1.Route, updateOption UpdateStatusOptions,
 					condition,
 				},
 			}
+			if host == mRoute.Spec.Host {
+				rtIngress.WildcardPolicy = mRoute.Spec.WildcardPolicy
+			}
 			mRoute.Status.Ingress = append(mRoute.Status.Ingress, rtIngress)
 		}
 	}
 
 	// remove the host from status which is not in spec
 	for i := len(mRoute.Status.Ingress) - 1; i >= 0; i-- {
+		if mRoute.Status.Ingress[i].RouterName != lib.AKOUser {
+			continue
+		}
 		if !utils.HasElem(hostListIng, mRoute.Status.Ingress[i].Host) {
 			mRoute.Status.Ingress = append(mRoute.Status.Ingress[:i], mRoute.Status.Ingress[i+1:]...)
 		}
 	}
+}
 
-	if sameStatus := compareRouteStatus(oldRouteStatus.Ingress, mRoute.Status.Ingress); sameStatus {
-		utils.AviLog.Debugf("key: %s, msg: No changes detected in route status. old: %+v new: %+v",
-			key, oldRouteStatus.Ingress, mRoute.Status.Ingress)
-		return nil
-	}
-
-	_, err = utils.GetInformers().OshiftClient.RouteV1().Routes(mRoute.Namespace).UpdateStatus(mRoute)
-	if err != nil {
-		utils.AviLog.Errorf("key: %s, msg: there was an error in updating the route status: %v", key, err)
-		// fetch updated route and feed for update status
-		mRoutes := getRoutes([]string{mRoute.Namespace + "/" + mRoute.Name}, false)
-		if len(mRoutes) > 0 {
-			return updateRouteObject(mRoutes[mRoute.Namespace+"/"+mRoute.Name], updateOption, retry+1)
+// routeStatusHosts maps the hostnames programmed for a route to the hostnames reported in
+// its status, wildcard routes are programmed with *.domain but reported with their spec.host.
+func routeStatusHosts(mRoute *routev1.Route, hostnames []string) []string {
+	routeHost := lib.GetRouteHostName(mRoute.Spec)
+	var statusHosts []string
+	for _, host := range hostnames {
+		if host == routeHost {
+			host = mRoute.Spec.Host
 		}
+		statusHosts = append(statusHosts, host)
 	}
-
-	utils.AviLog.Infof("key: %s, msg: Successfully updated the status of route: %s/%s old: %+v new: %+v",
-		key, mRoute.Namespace, mRoute.Name, oldRouteStatus.Ingress, mRoute.Status.Ingress)
-	return err
+	return statusHosts
 }
 
 func compareRouteStatus(oldStatus, newStatus []routev1.RouteIngress) bool {
@@ -324,82 +247,56 @@ func compareRouteStatus(oldStatus, newStatus []routev1.RouteIngress) bool {
 }
 
 func DeleteRouteStatus(svc_mdata_obj avicache.ServiceMetadataObj, isVSDelete bool, key string) error {
-	var err error
 	if len(svc_mdata_obj.NamespaceIngressName) > 0 {
 		// This is SNI with hostname sharding.
 		for _, ingressns := range svc_mdata_obj.NamespaceIngressName {
 			ingressArr := strings.Split(ingressns, "/")
 			if len(ingressArr) != 2 {
-				return errors.New("key: %s, msg: DeleteRouteStatus IngressNamespace format not correct")
+				err := errors.New("key: %s, msg: DeleteRouteStatus IngressNamespace format not correct")
+				utils.AviLog.Warn(err)
+				return err
 			}
 			svc_mdata_obj.Namespace = ingressArr[0]
 			svc_mdata_obj.IngressName = ingressArr[1]
-			err = deleteRouteObject(svc_mdata_obj, key, isVSDelete)
+			deleteRouteObject(svc_mdata_obj, key, isVSDelete)
 		}
 	} else {
-		err = deleteRouteObject(svc_mdata_obj, key, isVSDelete)
-	}
-
-	if err != nil {
-		utils.AviLog.Warn(err)
+		deleteRouteObject(svc_mdata_obj, key, isVSDelete)
 	}
 
 	return nil
 }
 
-func deleteRouteObject(svc_mdata_obj avicache.ServiceMetadataObj, key string, isVSDelete bool, retryNum ...int) error {
-	retry := 0
-	if len(retryNum) > 0 {
-		utils.AviLog.Infof("key: %s, msg: Retrying to update the route status", key)
-		retry = retryNum[0]
-		if retry >= 2 {
-			return errors.New("key: %s, msg: DeleteRouteStatus retried 3 times, aborting")
-		}
-	}
-
-	mRoute, err := utils.GetInformers().RouteInformer.Lister().Routes(svc_mdata_obj.Namespace).Get(svc_mdata_obj.IngressName)
-
-	if err != nil {
-		utils.AviLog.Warnf("key: %s, msg: Could not get the ingress object for DeleteStatus: %s", key, err)
-		return err
-	}
-
-	oldRouteStatus := mRoute.Status.DeepCopy()
-	if len(svc_mdata_obj.HostNames) > 0 {
-		// If the route status for the host is alresay fasle, then don't delete the status
-		if !routeStatusCheck(oldRouteStatus.Ingress, svc_mdata_obj.HostNames[0]) {
-			return nil
+// deleteRouteObject queues the removal of the hosts from the status of the route, the hosts still in the route
+// spec are kept unless the VS is deleted.
+func deleteRouteObject(svc_mdata_obj avicache.ServiceMetadataObj, key string, isVSDelete bool) {
+	SharedStatusQueue().Enqueue(utils.OshiftRoute, svc_mdata_obj.Namespace, svc_mdata_obj.IngressName, key, func(obj runtime.Object) {
+		mRoute := obj.(*routev1.Route)
+		hostnames := routeStatusHosts(mRoute, svc_mdata_obj.HostNames)
+		if len(hostnames) > 0 {
+			// If the route status for the host is already false, then don't delete the status
+			if !routeStatusCheck(mRoute.Status.Ingress, hostnames[0]) {
+				return
+			}
 		}
-	}
-	var hostListIng []string
-	hostListIng = append(hostListIng, mRoute.Spec.Host)
+		var hostListIng []string
+		hostListIng = append(hostListIng, mRoute.Spec.Host)
 
-	for i := len(mRoute.Status.Ingress) - 1; i >= 0; i-- {
-		for _, host := range svc_mdata_obj.HostNames {
-			if mRoute.Status.Ingress[i].Host == host {
-				// Check if this host is still present in the spec, if so - don't delete it
-				if !utils.HasElem(hostListIng, host) || isVSDelete {
-					mRoute.Status.Ingress = append(mRoute.Status.Ingress[:i], mRoute.Status.Ingress[i+1:]...)
-				} else {
-					utils.AviLog.Debugf("key: %s, msg: skipping status update since host is present in the route: %v", key, host)
+		for i := len(mRoute.Status.Ingress) - 1; i >= 0; i-- {
+			if mRoute.Status.Ingress[i].RouterName != lib.AKOUser {
+				continue
+			}
+			for _, host := range hostnames {
+				if mRoute.Status.Ingress[i].Host == host {
+					// Check if this host is still present in the spec, if so - don't delete it
+					if !utils.HasElem(hostListIng, host) || isVSDelete {
+						mRoute.Status.Ingress = append(mRoute.Status.Ingress[:i], mRoute.Status.Ingress[i+1:]...)
+						break
+					} else {
+						utils.AviLog.Debugf("key: %s, msg: skipping status update since host is present in the route: %v", key, host)
+					}
 				}
 			}
 		}
-	}
-
-	if sameStatus := compareRouteStatus(oldRouteStatus.Ingress, mRoute.Status.Ingress); sameStatus {
-		utils.AviLog.Debugf("key: %s, msg: No changes detected in ingress status. old: %+v new: %+v",
-			key, oldRouteStatus.Ingress, mRoute.Status.Ingress)
-		return nil
-	}
-
-	_, err = utils.GetInformers().OshiftClient.RouteV1().Routes(svc_mdata_obj.Namespace).UpdateStatus(mRoute)
-	if err != nil {
-		utils.AviLog.Errorf("key: %s, msg: there was an error in deleting the ingress status: %v", key, err)
-		return deleteObject(svc_mdata_obj, key, isVSDelete, retry+1)
-	}
-
-	utils.AviLog.Infof("key: %s, msg: Successfully deleted status of route: %s/%s old: %+v new: %+v",
-		key, mRoute.Namespace, mRoute.Name, oldRouteStatus.Ingress, mRoute.Status.Ingress)
-	return nil
+	})
 }
@@ -0,0 +1,306 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	routev1 "github.com/openshift/api/route/v1"
+	advl4v1alpha1pre1 "github.com/vmware-tanzu/service-apis/apis/v1alpha1pre1"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	statusQueueWorkers = 4
+	// maxStatusRetries is the number of times the status of an object is patched again after a conflict or a
+	// timeout, before its updates are dropped
+	maxStatusRetries = 5
+)
+
+// statusUpdate changes the status of the fetched object in place
+type statusUpdate struct {
+	key    string
+	update func(obj runtime.Object)
+}
+
+var statusQueueInstance *StatusQueue
+var statusqueueonce sync.Once
+
+func SharedStatusQueue() *StatusQueue {
+	statusqueueonce.Do(func() {
+		statusQueueInstance = &StatusQueue{
+			queue: workqueue.NewNamedRateLimitingQueue(
+				workqueue.NewItemExponentialFailureRateLimiter(200*time.Millisecond, 10*time.Second), "avi-status"),
+			pending: make(map[string][]statusUpdate),
+		}
+	})
+	return statusQueueInstance
+}
+
+// StatusQueue writes the status of the ingresses, routes, services of type loadbalancer and gateways off the
+// REST layer workers. The updates queued for an object are applied in order on a single copy of it, whose
+// status is then patched once. A patch failing with a conflict is retried with backoff on the object fetched
+// anew.
+// cache sample: Ingress/ns1/ingress1 -> [update1, update2]
+type StatusQueue struct {
+	sync.Mutex
+	queue   workqueue.RateLimitingInterface
+	pending map[string][]statusUpdate
+	patched int
+	failed  int
+}
+
+// Enqueue queues a status update of the object, the update is written along with the ones already queued for it.
+func (q *StatusQueue) Enqueue(objType, namespace, name, key string, update func(obj runtime.Object)) {
+	objKey := objType + "/" + namespace + "/" + name
+	q.Lock()
+	q.pending[objKey] = append(q.pending[objKey], statusUpdate{key: key, update: update})
+	q.Unlock()
+	q.queue.Add(objKey)
+	q.report()
+}
+
+// Run starts the workers writing the queued status updates, until stopCh is closed.
+func (q *StatusQueue) Run(stopCh <-chan struct{}) {
+	for i := 0; i < statusQueueWorkers; i++ {
+		go wait.Until(q.runWorker, time.Second, stopCh)
+	}
+	go func() {
+		<-stopCh
+		q.queue.ShutDown()
+	}()
+	utils.AviLog.Infof("Started the status queue workers")
+}
+
+func (q *StatusQueue) runWorker() {
+	for q.processNextItem() {
+	}
+}
+
+func (q *StatusQueue) processNextItem() bool {
+	obj, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(obj)
+	objKey := obj.(string)
+
+	q.Lock()
+	updates := q.pending[objKey]
+	delete(q.pending, objKey)
+	q.Unlock()
+	if len(updates) == 0 {
+		q.queue.Forget(obj)
+		return true
+	}
+
+	retries := q.queue.NumRequeues(obj)
+	patched, err := writeStatus(objKey, updates)
+	q.Lock()
+	if err != nil && (k8serrors.IsConflict(err) || strings.Contains(err.Error(), utils.K8S_ETIMEDOUT)) && retries < maxStatusRetries {
+		// the updates are applied again on the object fetched anew, before the ones queued meanwhile
+		q.pending[objKey] = append(updates, q.pending[objKey]...)
+		q.Unlock()
+		utils.AviLog.Warnf("keys: %s, msg: retrying the status update of %s, attempt %d: %v", statusUpdateKeys(updates), objKey, retries+1, err)
+		utils.AddTraceEvent(objKey, utils.StatusLayer, fmt.Sprintf("retrying the status update, attempt %d", retries+1), err)
+		q.queue.AddRateLimited(obj)
+		return true
+	}
+	if err != nil {
+		q.failed++
+		utils.AviLog.Errorf("keys: %s, msg: there was an error in updating the status of %s: %v", statusUpdateKeys(updates), objKey, err)
+		utils.AddTraceEvent(objKey, utils.StatusLayer, "status update failed", err)
+	} else if patched {
+		q.patched++
+		utils.AddTraceEvent(objKey, utils.StatusLayer, fmt.Sprintf("status updated with %d updates", len(updates)), nil)
+	}
+	q.Unlock()
+	q.queue.Forget(obj)
+	q.report()
+	return true
+}
+
+// report publishes the number of objects with pending status updates, per object type.
+func (q *StatusQueue) report() {
+	pending := make(map[string]int)
+	q.Lock()
+	for objKey := range q.pending {
+		pending[strings.Split(objKey, "/")[0]]++
+	}
+	patched, failed := q.patched, q.failed
+	q.Unlock()
+	models.StatusQueueStatus.UpdateStatusQueueReport(pending, patched, failed)
+}
+
+// GetPendingCount returns the number of objects with status updates not written yet
+func (q *StatusQueue) GetPendingCount() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.pending)
+}
+
+func statusUpdateKeys(updates []statusUpdate) string {
+	var keys []string
+	for _, update := range updates {
+		if !utils.HasElem(keys, update.key) {
+			keys = append(keys, update.key)
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// writeStatus fetches the object, applies the updates on it and patches its status if it changed. The object
+// is fetched from the API server rather than the informer cache, which lags behind the patches written here.
+func writeStatus(objKey string, updates []statusUpdate) (bool, error) {
+	arr := strings.SplitN(objKey, "/", 3)
+	objType, namespace, name := arr[0], arr[1], arr[2]
+	keys := statusUpdateKeys(updates)
+	obj, err := getStatusObject(objType, namespace, name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.AviLog.Infof("keys: %s, msg: %s not found, nothing to update in status", keys, objKey)
+			return false, nil
+		}
+		return false, err
+	}
+
+	mObj := obj.DeepCopyObject()
+	for _, update := range updates {
+		update.update(mObj)
+	}
+	oldStatus, newStatus := getStatus(obj), getStatus(mObj)
+	if sameStatus(obj, mObj) {
+		utils.AviLog.Debugf("keys: %s, msg: No changes detected in the status of %s. old: %s new: %s",
+			keys, objKey, utils.Stringify(oldStatus), utils.Stringify(newStatus))
+		return false, nil
+	}
+
+	accessor, _ := obj.(metav1.Object)
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]string{"resourceVersion": accessor.GetResourceVersion()},
+		"status":   newStatus,
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := patchStatus(objType, namespace, name, patch); err != nil {
+		return false, err
+	}
+	utils.AviLog.Infof("keys: %s, msg: Successfully updated the status of %s with %d updates. old: %s new: %s",
+		keys, objKey, len(updates), utils.Stringify(oldStatus), utils.Stringify(newStatus))
+	return true, nil
+}
+
+func getStatusObject(objType, namespace, name string) (runtime.Object, error) {
+	mClient := utils.GetInformers().ClientSet
+	switch objType {
+	case utils.Ingress:
+		var ingObj interface{}
+		var err error
+		if lib.GetIngressApi() == utils.ExtV1IngressInformer {
+			ingObj, err = mClient.ExtensionsV1beta1().Ingresses(namespace).Get(name, metav1.GetOptions{})
+		} else {
+			ingObj, err = mClient.NetworkingV1beta1().Ingresses(namespace).Get(name, metav1.GetOptions{})
+		}
+		if err != nil {
+			return nil, err
+		}
+		mIngress, ok := utils.ToNetworkingIngress(ingObj)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert obj type interface to networking/v1beta1 ingress")
+		}
+		return mIngress, nil
+	case utils.OshiftRoute:
+		return utils.GetInformers().OshiftClient.RouteV1().Routes(namespace).Get(name, metav1.GetOptions{})
+	case utils.L4LBService:
+		return mClient.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
+	case lib.Gateway:
+		return lib.GetAdvL4Clientset().NetworkingV1alpha1pre1().Gateways(namespace).Get(name, metav1.GetOptions{})
+	}
+	return nil, fmt.Errorf("status of %s objects is not supported", objType)
+}
+
+// getStatus returns the part of the object status written by AKO, as it is patched. The lists are never nil so
+// that a status emptied by the updates is patched as such.
+func getStatus(obj runtime.Object) map[string]interface{} {
+	switch mObj := obj.(type) {
+	case *networking.Ingress:
+		lbIngress := append([]corev1.LoadBalancerIngress{}, mObj.Status.LoadBalancer.Ingress...)
+		return map[string]interface{}{"loadBalancer": map[string]interface{}{"ingress": lbIngress}}
+	case *routev1.Route:
+		return map[string]interface{}{"ingress": append([]routev1.RouteIngress{}, mObj.Status.Ingress...)}
+	case *corev1.Service:
+		lbIngress := append([]corev1.LoadBalancerIngress{}, mObj.Status.LoadBalancer.Ingress...)
+		return map[string]interface{}{"loadBalancer": map[string]interface{}{"ingress": lbIngress}}
+	case *advl4v1alpha1pre1.Gateway:
+		return map[string]interface{}{"addresses": append([]advl4v1alpha1pre1.GatewayAddress{}, mObj.Status.Addresses...)}
+	}
+	return nil
+}
+
+// sameStatus returns true if the updates left the status of the object unchanged, so the patch is not required
+func sameStatus(oldObj, newObj runtime.Object) bool {
+	switch mObj := newObj.(type) {
+	case *networking.Ingress:
+		return compareLBStatus(&oldObj.(*networking.Ingress).Status.LoadBalancer, &mObj.Status.LoadBalancer)
+	case *routev1.Route:
+		return compareRouteStatus(oldObj.(*routev1.Route).Status.Ingress, mObj.Status.Ingress)
+	case *corev1.Service:
+		return compareLBStatus(&oldObj.(*corev1.Service).Status.LoadBalancer, &mObj.Status.LoadBalancer)
+	case *advl4v1alpha1pre1.Gateway:
+		oldAddresses := oldObj.(*advl4v1alpha1pre1.Gateway).Status.Addresses
+		if len(oldAddresses) == 0 && len(mObj.Status.Addresses) == 0 {
+			return true
+		}
+		return reflect.DeepEqual(oldAddresses, mObj.Status.Addresses)
+	}
+	return true
+}
+
+func patchStatus(objType, namespace, name string, patch []byte) error {
+	mClient := utils.GetInformers().ClientSet
+	var err error
+	switch objType {
+	case utils.Ingress:
+		if lib.GetIngressApi() == utils.ExtV1IngressInformer {
+			_, err = mClient.ExtensionsV1beta1().Ingresses(namespace).Patch(name, types.MergePatchType, patch, "status")
+		} else {
+			_, err = mClient.NetworkingV1beta1().Ingresses(namespace).Patch(name, types.MergePatchType, patch, "status")
+		}
+	case utils.OshiftRoute:
+		_, err = utils.GetInformers().OshiftClient.RouteV1().Routes(namespace).Patch(name, types.MergePatchType, patch, "status")
+	case utils.L4LBService:
+		_, err = mClient.CoreV1().Services(namespace).Patch(name, types.MergePatchType, patch, "status")
+	case lib.Gateway:
+		_, err = lib.GetAdvL4Clientset().NetworkingV1alpha1pre1().Gateways(namespace).Patch(name, types.MergePatchType, patch, "status")
+	}
+	return err
+}
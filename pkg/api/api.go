@@ -71,6 +71,15 @@ func (a *ApiServer) initModels() {
 	// add common models in ApiServer
 	genericModels := []models.ApiModel{
 		models.RestStatus,
+		models.DriftStatus,
+		models.OrphanGCStatus,
+		models.DeadLetterStatus,
+		models.ValidationStatus,
+		models.ShardStatus,
+		models.StatusQueueStatus,
+		models.TraceStatus,
+		models.HealthStatus,
+		models.DiagnosticsStatus,
 	}
 	a.Models = append(a.Models, genericModels...)
 
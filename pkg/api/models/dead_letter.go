@@ -0,0 +1,143 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// DeadLetterReport holds the models which are not retried anymore, either because the controller rejected them
+// with an error retrying does not fix, or because they exhausted the retries allowed for their error.
+type DeadLetterReport struct {
+	sync.Mutex
+	Models []DeadLetteredModel `json:"models"`
+}
+
+type DeadLetteredModel struct {
+	Model          string    `json:"model"`
+	ErrorClass     string    `json:"error_class"`
+	Error          string    `json:"error"`
+	Attempts       int       `json:"attempts"`
+	DeadLetteredAt time.Time `json:"dead_lettered_at"`
+}
+
+var DeadLetterStatus *DeadLetterModel
+var deadletterstatusonce sync.Once
+
+// DeadLetterModel implements ApiModel
+type DeadLetterModel struct {
+	DeadLetter DeadLetterReport `json:"dead_letter"`
+	// retryFunc republishes a model to the rest layer, it is set by the controller once the layers are running
+	retryFunc func(model string)
+}
+
+func (a *DeadLetterModel) InitModel() {
+	deadletterstatusonce.Do(func() {
+		DeadLetterStatus = &DeadLetterModel{
+			DeadLetter: DeadLetterReport{
+				Models: []DeadLetteredModel{},
+			},
+		}
+	})
+}
+
+func (a *DeadLetterModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/deadletter",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			DeadLetterStatus.DeadLetter.Lock()
+			defer DeadLetterStatus.DeadLetter.Unlock()
+			utils.Respond(w, &DeadLetterStatus)
+		},
+	}
+
+	// POST /api/deadletter/retry?model=<namespace>/<name> retries the dead lettered model right away, with its
+	// retries reset. It is served only to the requests bearing the diagnostics token.
+	retry := OperationMap{
+		Route:  "/api/deadletter/retry",
+		Method: "POST",
+		Handler: authorizeToken(func(w http.ResponseWriter, r *http.Request) {
+			model := r.URL.Query().Get("model")
+			if model == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				utils.Respond(w, map[string]string{"error": "model not set"})
+				return
+			}
+			DeadLetterStatus.DeadLetter.Lock()
+			retryFunc := DeadLetterStatus.retryFunc
+			DeadLetterStatus.DeadLetter.Unlock()
+			if retryFunc == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				utils.Respond(w, map[string]string{"error": "retries are not running"})
+				return
+			}
+			if !DeadLetterStatus.RemoveDeadLetteredModel(model) {
+				w.WriteHeader(http.StatusNotFound)
+				utils.Respond(w, map[string]string{"error": "model is not dead lettered"})
+				return
+			}
+			retryFunc(model)
+			utils.Respond(w, map[string]string{"model": model, "status": "retrying"})
+		}),
+	}
+
+	operationMapList = append(operationMapList, get, retry)
+	return operationMapList
+}
+
+func (a *DeadLetterModel) SetRetryFunc(retryFunc func(model string)) {
+	a.DeadLetter.Lock()
+	defer a.DeadLetter.Unlock()
+	a.retryFunc = retryFunc
+}
+
+// AddDeadLetteredModel adds the model to the dead letter set, or replaces its entry if it is already there
+func (a *DeadLetterModel) AddDeadLetteredModel(deadLettered DeadLetteredModel) {
+	a.DeadLetter.Lock()
+	defer a.DeadLetter.Unlock()
+	for i, entry := range a.DeadLetter.Models {
+		if entry.Model == deadLettered.Model {
+			a.DeadLetter.Models[i] = deadLettered
+			return
+		}
+	}
+	a.DeadLetter.Models = append(a.DeadLetter.Models, deadLettered)
+}
+
+// RemoveDeadLetteredModel removes the model from the dead letter set, and returns true if it was there
+func (a *DeadLetterModel) RemoveDeadLetteredModel(model string) bool {
+	a.DeadLetter.Lock()
+	defer a.DeadLetter.Unlock()
+	for i, entry := range a.DeadLetter.Models {
+		if entry.Model == model {
+			a.DeadLetter.Models = append(a.DeadLetter.Models[:i], a.DeadLetter.Models[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (a *DeadLetterModel) GetDeadLetteredModels() []DeadLetteredModel {
+	a.DeadLetter.Lock()
+	defer a.DeadLetter.Unlock()
+	return append([]DeadLetteredModel{}, a.DeadLetter.Models...)
+}
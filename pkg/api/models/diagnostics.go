@@ -0,0 +1,187 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// DiagnosticsReport holds the goroutine count and the state of the workers of each layer queue, along with the
+// stacks of all the goroutines when asked for with ?stacks=true
+type DiagnosticsReport struct {
+	Goroutines int                 `json:"goroutines"`
+	Queues     []WorkerQueueReport `json:"queues"`
+	Stacks     string              `json:"stacks,omitempty"`
+}
+
+type WorkerQueueReport struct {
+	Name         string              `json:"name"`
+	QueueLengths []int               `json:"queue_lengths"`
+	Workers      []utils.WorkerState `json:"workers"`
+}
+
+var DiagnosticsStatus *DiagnosticsModel
+var diagnosticsstatusonce sync.Once
+
+// DiagnosticsModel implements ApiModel. The pprof and diagnostics endpoints are only served once enabled in the
+// configmap, to the requests bearing the diagnostics token of the controller secret.
+type DiagnosticsModel struct {
+	sync.RWMutex
+	enabled bool
+	token   string
+}
+
+func (a *DiagnosticsModel) InitModel() {
+	diagnosticsstatusonce.Do(func() {
+		DiagnosticsStatus = &DiagnosticsModel{}
+	})
+}
+
+func (a *DiagnosticsModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	// GET /api/diagnostics?stacks=true
+	get := OperationMap{
+		Route:  "/api/diagnostics",
+		Method: "GET",
+		Handler: DiagnosticsStatus.authorize(func(w http.ResponseWriter, r *http.Request) {
+			utils.Respond(w, getDiagnosticsReport(r.URL.Query().Get("stacks") == "true"))
+		}),
+	}
+	operationMapList = append(operationMapList, get)
+
+	// the profiles are served by the handlers of net/http/pprof, e.g. /debug/pprof/heap. The CPU profile and the
+	// execution trace have to be shorter than the 10 seconds write timeout of the API server, e.g.
+	// /debug/pprof/profile?seconds=5
+	pprofHandlers := []struct {
+		route   string
+		handler http.HandlerFunc
+	}{
+		{"/debug/pprof/", pprof.Index},
+		{"/debug/pprof/cmdline", pprof.Cmdline},
+		{"/debug/pprof/profile", pprof.Profile},
+		{"/debug/pprof/symbol", pprof.Symbol},
+		{"/debug/pprof/trace", pprof.Trace},
+		// the named profiles, e.g. heap or goroutine, are matched last
+		{"/debug/pprof/{profile}", pprofProfile},
+	}
+	for _, pprofHandler := range pprofHandlers {
+		operationMapList = append(operationMapList, OperationMap{
+			Route:   pprofHandler.route,
+			Method:  "GET",
+			Handler: DiagnosticsStatus.authorize(pprofHandler.handler),
+		})
+	}
+	return operationMapList
+}
+
+// SetEnabled serves or stops serving the diagnostics endpoints, it is set from the configmap
+func (a *DiagnosticsModel) SetEnabled(enabled bool) {
+	a.Lock()
+	defer a.Unlock()
+	if a.enabled != enabled {
+		utils.AviLog.Infof("Setting the diagnostics endpoints enabled to: %v", enabled)
+	}
+	a.enabled = enabled
+}
+
+// SetToken sets the bearer token of the diagnostics endpoints, no request is authorized without one
+func (a *DiagnosticsModel) SetToken(token string) {
+	a.Lock()
+	defer a.Unlock()
+	a.token = token
+}
+
+// authorize serves the request if the diagnostics endpoints are enabled, and if it bears the diagnostics token
+func (a *DiagnosticsModel) authorize(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.RLock()
+		enabled := a.enabled
+		a.RUnlock()
+		if !enabled {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !a.hasToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// hasToken returns true if the request bears the diagnostics token, never while the token is not set
+func (a *DiagnosticsModel) hasToken(r *http.Request) bool {
+	a.RLock()
+	token := a.token
+	a.RUnlock()
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token != "" && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// authorizeToken serves the request if it bears the diagnostics token. It guards the endpoints which act on
+// the models rather than only report on them, so that not everyone who can reach the pod can use them.
+func authorizeToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if DiagnosticsStatus == nil || !DiagnosticsStatus.hasToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func pprofProfile(w http.ResponseWriter, r *http.Request) {
+	pprof.Handler(mux.Vars(r)["profile"]).ServeHTTP(w, r)
+}
+
+func getDiagnosticsReport(stacks bool) *DiagnosticsReport {
+	report := &DiagnosticsReport{
+		Goroutines: runtime.NumGoroutine(),
+		Queues:     []WorkerQueueReport{},
+	}
+	for name, queue := range utils.GetSharedWorkQueues() {
+		report.Queues = append(report.Queues, WorkerQueueReport{
+			Name:         name,
+			QueueLengths: queue.GetQueueLengths(),
+			Workers:      queue.GetWorkerStates(),
+		})
+	}
+	sort.Slice(report.Queues, func(i, j int) bool {
+		return report.Queues[i].Name < report.Queues[j].Name
+	})
+	if stacks {
+		buf := make([]byte, 1<<20)
+		for {
+			n := runtime.Stack(buf, true)
+			if n < len(buf) {
+				report.Stacks = string(buf[:n])
+				break
+			}
+			buf = make([]byte, 2*len(buf))
+		}
+	}
+	return report
+}
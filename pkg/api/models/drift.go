@@ -0,0 +1,86 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// DriftReport holds the result of the last drift check between the models and the controller
+type DriftReport struct {
+	sync.Mutex
+	LastRun        time.Time       `json:"last_run"`
+	DriftedObjects []DriftedObject `json:"drifted_objects"`
+}
+
+type DriftedObject struct {
+	Model      string `json:"model"`
+	ObjectType string `json:"object_type"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`
+}
+
+var DriftStatus *DriftModel
+var driftstatusonce sync.Once
+
+// DriftModel implements ApiModel
+type DriftModel struct {
+	Drift DriftReport `json:"drift"`
+}
+
+func (a *DriftModel) InitModel() {
+	driftstatusonce.Do(func() {
+		DriftStatus = &DriftModel{
+			Drift: DriftReport{
+				DriftedObjects: []DriftedObject{},
+			},
+		}
+	})
+}
+
+func (a *DriftModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/drift",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			DriftStatus.Drift.Lock()
+			defer DriftStatus.Drift.Unlock()
+			utils.Respond(w, &DriftStatus)
+		},
+	}
+
+	operationMapList = append(operationMapList, get)
+	return operationMapList
+}
+
+// UpdateDriftReport replaces the report with the objects found drifted in the latest run
+func (a *DriftModel) UpdateDriftReport(driftedObjects []DriftedObject) {
+	a.Drift.Lock()
+	defer a.Drift.Unlock()
+	a.Drift.LastRun = time.Now()
+	a.Drift.DriftedObjects = append([]DriftedObject{}, driftedObjects...)
+}
+
+func (a *DriftModel) GetDriftedObjects() []DriftedObject {
+	a.Drift.Lock()
+	defer a.Drift.Unlock()
+	return append([]DriftedObject{}, a.Drift.DriftedObjects...)
+}
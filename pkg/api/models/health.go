@@ -0,0 +1,127 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+const (
+	healthOK     = "ok"
+	healthFailed = "failed"
+)
+
+// HealthCheck is a check of the health of AKO. A liveness check failing fails both the liveness and the
+// readiness probes, the other checks only fail the readiness probe.
+type HealthCheck struct {
+	Name     string
+	Liveness bool
+	Check    func() error
+}
+
+// HealthReport holds the outcome of each check run by a probe, ok or the error of the check
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+var HealthStatus *HealthModel
+var healthstatusonce sync.Once
+
+// HealthModel implements ApiModel, the checks are added by the controller as it starts
+type HealthModel struct {
+	sync.Mutex
+	checks []HealthCheck
+}
+
+func (a *HealthModel) InitModel() {
+	healthstatusonce.Do(func() {
+		HealthStatus = &HealthModel{}
+	})
+}
+
+func (a *HealthModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	healthz := OperationMap{
+		Route:  "/healthz",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			HealthStatus.respond(w, true)
+		},
+	}
+
+	readyz := OperationMap{
+		Route:  "/readyz",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			HealthStatus.respond(w, false)
+		},
+	}
+
+	operationMapList = append(operationMapList, healthz, readyz)
+	return operationMapList
+}
+
+// AddHealthCheck adds a check to the probes, replacing the check of the same name if any
+func (a *HealthModel) AddHealthCheck(name string, liveness bool, check func() error) {
+	a.Lock()
+	defer a.Unlock()
+	for i := range a.checks {
+		if a.checks[i].Name == name {
+			a.checks[i] = HealthCheck{Name: name, Liveness: liveness, Check: check}
+			return
+		}
+	}
+	a.checks = append(a.checks, HealthCheck{Name: name, Liveness: liveness, Check: check})
+}
+
+// RunHealthChecks runs the checks of the liveness probe, or all the checks for the readiness probe. AKO is not
+// ready until the controller has added its checks.
+func (a *HealthModel) RunHealthChecks(liveness bool) (HealthReport, bool) {
+	a.Lock()
+	checks := append([]HealthCheck{}, a.checks...)
+	a.Unlock()
+
+	report := HealthReport{Status: healthOK, Checks: make(map[string]string)}
+	if !liveness && len(checks) == 0 {
+		report.Status = healthFailed
+		return report, false
+	}
+	for _, check := range checks {
+		if liveness && !check.Liveness {
+			continue
+		}
+		if err := check.Check(); err != nil {
+			report.Checks[check.Name] = err.Error()
+			report.Status = healthFailed
+		} else {
+			report.Checks[check.Name] = healthOK
+		}
+	}
+	return report, report.Status == healthOK
+}
+
+func (a *HealthModel) respond(w http.ResponseWriter, liveness bool) {
+	report, healthy := a.RunHealthChecks(liveness)
+	if !healthy {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	utils.Respond(w, &report)
+}
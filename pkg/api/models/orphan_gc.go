@@ -0,0 +1,96 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+const (
+	OrphanDeleted       = "deleted"
+	OrphanWouldDelete   = "would delete"
+	OrphanInGracePeriod = "in grace period"
+)
+
+// OrphanGCReport holds the AKO created objects found orphaned on the controller in the last garbage collection run
+type OrphanGCReport struct {
+	sync.Mutex
+	LastRun         time.Time        `json:"last_run"`
+	ReportOnly      bool             `json:"report_only"`
+	OrphanedObjects []OrphanedObject `json:"orphaned_objects"`
+}
+
+type OrphanedObject struct {
+	ObjectType    string    `json:"object_type"`
+	Tenant        string    `json:"tenant"`
+	Name          string    `json:"name"`
+	Uuid          string    `json:"uuid"`
+	OrphanedSince time.Time `json:"orphaned_since"`
+	Action        string    `json:"action"`
+}
+
+var OrphanGCStatus *OrphanGCModel
+var orphangcstatusonce sync.Once
+
+// OrphanGCModel implements ApiModel
+type OrphanGCModel struct {
+	OrphanGC OrphanGCReport `json:"orphan_gc"`
+}
+
+func (a *OrphanGCModel) InitModel() {
+	orphangcstatusonce.Do(func() {
+		OrphanGCStatus = &OrphanGCModel{
+			OrphanGC: OrphanGCReport{
+				OrphanedObjects: []OrphanedObject{},
+			},
+		}
+	})
+}
+
+func (a *OrphanGCModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/orphangc",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			OrphanGCStatus.OrphanGC.Lock()
+			defer OrphanGCStatus.OrphanGC.Unlock()
+			utils.Respond(w, &OrphanGCStatus)
+		},
+	}
+
+	operationMapList = append(operationMapList, get)
+	return operationMapList
+}
+
+// UpdateOrphanGCReport replaces the report with the objects found orphaned in the latest run
+func (a *OrphanGCModel) UpdateOrphanGCReport(orphanedObjects []OrphanedObject, reportOnly bool) {
+	a.OrphanGC.Lock()
+	defer a.OrphanGC.Unlock()
+	a.OrphanGC.LastRun = time.Now()
+	a.OrphanGC.ReportOnly = reportOnly
+	a.OrphanGC.OrphanedObjects = append([]OrphanedObject{}, orphanedObjects...)
+}
+
+func (a *OrphanGCModel) GetOrphanedObjects() []OrphanedObject {
+	a.OrphanGC.Lock()
+	defer a.OrphanGC.Unlock()
+	return append([]OrphanedObject{}, a.OrphanGC.OrphanedObjects...)
+}
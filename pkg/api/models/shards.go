@@ -0,0 +1,142 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// ShardReport holds the load of the shard virtualservices and the hosts being moved between them, when the
+// shard assignment is persisted.
+type ShardReport struct {
+	sync.Mutex
+	ShardSize           uint32           `json:"shard_size"`
+	Shards              []ShardLoad      `json:"shards"`
+	Migrations          []ShardMigration `json:"migrations"`
+	CompletedMigrations int              `json:"completed_migrations"`
+}
+
+type ShardLoad struct {
+	Name string `json:"name"`
+	// Hosts is the number of hosts assigned to the shard, SNIChildren the ones the shard model has built
+	Hosts       int `json:"hosts"`
+	SNIChildren int `json:"sni_children"`
+}
+
+type ShardMigration struct {
+	Host      string    `json:"host"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	State     string    `json:"state"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+var ShardStatus *ShardModel
+var shardstatusonce sync.Once
+
+// ShardModel implements ApiModel
+type ShardModel struct {
+	Shards ShardReport `json:"shards"`
+	// migrateFunc starts moving a host to another shard, it is set by the controller when the shard assignment
+	// is persisted
+	migrateFunc func(host string, shard uint32) error
+}
+
+func (a *ShardModel) InitModel() {
+	shardstatusonce.Do(func() {
+		ShardStatus = &ShardModel{
+			Shards: ShardReport{
+				Shards:     []ShardLoad{},
+				Migrations: []ShardMigration{},
+			},
+		}
+	})
+}
+
+func (a *ShardModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/shards",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			ShardStatus.Shards.Lock()
+			defer ShardStatus.Shards.Unlock()
+			utils.Respond(w, &ShardStatus)
+		},
+	}
+
+	// POST /api/shards/migrate?host=<host>&shard=<shard number> moves the host to the shard, the SNI child is
+	// built on the new shard before it is removed from the old one. It is served only to the requests bearing
+	// the diagnostics token.
+	migrate := OperationMap{
+		Route:  "/api/shards/migrate",
+		Method: "POST",
+		Handler: authorizeToken(func(w http.ResponseWriter, r *http.Request) {
+			host := r.URL.Query().Get("host")
+			shard, err := strconv.ParseUint(r.URL.Query().Get("shard"), 10, 32)
+			if host == "" || err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				utils.Respond(w, map[string]string{"error": "host and shard number must be set"})
+				return
+			}
+			ShardStatus.Shards.Lock()
+			migrateFunc := ShardStatus.migrateFunc
+			ShardStatus.Shards.Unlock()
+			if migrateFunc == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				utils.Respond(w, map[string]string{"error": "shard assignment is not persisted"})
+				return
+			}
+			if err := migrateFunc(host, uint32(shard)); err != nil {
+				w.WriteHeader(http.StatusConflict)
+				utils.Respond(w, map[string]string{"error": err.Error()})
+				return
+			}
+			utils.Respond(w, map[string]string{"host": host, "status": "migrating"})
+		}),
+	}
+
+	operationMapList = append(operationMapList, get, migrate)
+	return operationMapList
+}
+
+func (a *ShardModel) SetMigrateFunc(migrateFunc func(host string, shard uint32) error) {
+	a.Shards.Lock()
+	defer a.Shards.Unlock()
+	a.migrateFunc = migrateFunc
+}
+
+// UpdateShardReport replaces the shard loads and the migrations in progress, completed adds to the count of
+// the migrations done.
+func (a *ShardModel) UpdateShardReport(shardSize uint32, shards []ShardLoad, migrations []ShardMigration, completed int) {
+	a.Shards.Lock()
+	defer a.Shards.Unlock()
+	a.Shards.ShardSize = shardSize
+	a.Shards.Shards = append([]ShardLoad{}, shards...)
+	a.Shards.Migrations = append([]ShardMigration{}, migrations...)
+	a.Shards.CompletedMigrations += completed
+}
+
+func (a *ShardModel) GetShardMigrations() []ShardMigration {
+	a.Shards.Lock()
+	defer a.Shards.Unlock()
+	return append([]ShardMigration{}, a.Shards.Migrations...)
+}
@@ -16,6 +16,7 @@ package models
 
 import (
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -26,8 +27,10 @@ import (
 // AviApiRestStatus holds status details for AKO/AMKO <-> AVI connection
 type AviApiRestStatus struct {
 	sync.Mutex
-	ConnectionStatus string            `json:"connection_status"`
-	Errors           []RestStatusError `json:"errors"`
+	ConnectionStatus string                           `json:"connection_status"`
+	ActiveEndpoint   string                           `json:"active_endpoint"`
+	Endpoints        []utils.ControllerEndpointStatus `json:"endpoints"`
+	Errors           []RestStatusError                `json:"errors"`
 }
 
 type RestStatusError struct {
@@ -35,12 +38,26 @@ type RestStatusError struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// AviCacheStatus holds the progress of the Avi object cache population done at bootup
+type AviCacheStatus struct {
+	sync.Mutex
+	Status  string                       `json:"status"`
+	Objects map[string]AviObjCacheStatus `json:"objects"`
+}
+
+type AviObjCacheStatus struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
 var RestStatus *StatusModel
 var reststatusonce sync.Once
 
 // StatusModel implements ApiModel
 type StatusModel struct {
-	AviApi AviApiRestStatus `json:"avi_api"`
+	AviApi         AviApiRestStatus              `json:"avi_api"`
+	AviCache       AviCacheStatus                `json:"avi_cache"`
+	AviApiThrottle utils.AviRestRateLimiterStats `json:"avi_api_throttle"`
 }
 
 func (a *StatusModel) InitModel() {
@@ -50,6 +67,10 @@ func (a *StatusModel) InitModel() {
 				ConnectionStatus: utils.AVIAPI_INITIATING,
 				Errors:           []RestStatusError{},
 			},
+			AviCache: AviCacheStatus{
+				Status:  utils.AVICACHE_NOT_STARTED,
+				Objects: make(map[string]AviObjCacheStatus),
+			},
 		}
 	})
 }
@@ -61,6 +82,12 @@ func (a *StatusModel) ApiOperationMap() []OperationMap {
 		Route:  "/api/status",
 		Method: "GET",
 		Handler: func(w http.ResponseWriter, r *http.Request) {
+			RestStatus.AviApi.Lock()
+			defer RestStatus.AviApi.Unlock()
+			RestStatus.AviApi.ActiveEndpoint, RestStatus.AviApi.Endpoints = utils.SharedControllerEndpoints(os.Getenv("CTRL_IPADDRESS")).GetStatus()
+			RestStatus.AviCache.Lock()
+			defer RestStatus.AviCache.Unlock()
+			RestStatus.AviApiThrottle = utils.SharedAviRestRateLimiter().GetStats()
 			response := &RestStatus
 			utils.Respond(w, response)
 		},
@@ -104,3 +131,34 @@ func (a *StatusModel) UpdateAviApiRestStatus(connectionStatus string, err error)
 
 	return
 }
+
+// UpdateAviCacheStatus sets the overall state of the Avi object cache population
+func (a *StatusModel) UpdateAviCacheStatus(status string) {
+	a.AviCache.Lock()
+	defer a.AviCache.Unlock()
+	a.AviCache.Status = status
+}
+
+// UpdateAviObjCacheStatus records the progress of the cache population for an object type
+func (a *StatusModel) UpdateAviObjCacheStatus(objType, status string, count int) {
+	a.AviCache.Lock()
+	defer a.AviCache.Unlock()
+	a.AviCache.Objects[objType] = AviObjCacheStatus{
+		Status: status,
+		Count:  count,
+	}
+}
+
+// IsAviCachePopulated returns true once the Avi object cache is populated at bootup
+func (a *StatusModel) IsAviCachePopulated() bool {
+	a.AviCache.Lock()
+	defer a.AviCache.Unlock()
+	return a.AviCache.Status == utils.AVICACHE_POPULATED
+}
+
+// GetConnectionStatus returns the status of the connection to the controller, as of the last rest call
+func (a *StatusModel) GetConnectionStatus() string {
+	a.AviApi.Lock()
+	defer a.AviApi.Unlock()
+	return a.AviApi.ConnectionStatus
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// StatusQueueReport holds the number of objects whose status updates are not written yet, per object type,
+// along with the number of status patches written and given up so far.
+type StatusQueueReport struct {
+	sync.Mutex
+	Pending map[string]int `json:"pending"`
+	Patched int            `json:"patched"`
+	Failed  int            `json:"failed"`
+}
+
+var StatusQueueStatus *StatusQueueModel
+var statusqueuestatusonce sync.Once
+
+// StatusQueueModel implements ApiModel
+type StatusQueueModel struct {
+	StatusQueue StatusQueueReport `json:"status_queue"`
+}
+
+func (a *StatusQueueModel) InitModel() {
+	statusqueuestatusonce.Do(func() {
+		StatusQueueStatus = &StatusQueueModel{
+			StatusQueue: StatusQueueReport{
+				Pending: make(map[string]int),
+			},
+		}
+	})
+}
+
+func (a *StatusQueueModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/statusqueue",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			StatusQueueStatus.StatusQueue.Lock()
+			defer StatusQueueStatus.StatusQueue.Unlock()
+			utils.Respond(w, &StatusQueueStatus)
+		},
+	}
+
+	operationMapList = append(operationMapList, get)
+	return operationMapList
+}
+
+func (a *StatusQueueModel) UpdateStatusQueueReport(pending map[string]int, patched, failed int) {
+	a.StatusQueue.Lock()
+	defer a.StatusQueue.Unlock()
+	a.StatusQueue.Pending = pending
+	a.StatusQueue.Patched = patched
+	a.StatusQueue.Failed = failed
+}
+
+func (a *StatusQueueModel) GetPending() map[string]int {
+	a.StatusQueue.Lock()
+	defer a.StatusQueue.Unlock()
+	pending := make(map[string]int)
+	for objType, count := range a.StatusQueue.Pending {
+		pending[objType] = count
+	}
+	return pending
+}
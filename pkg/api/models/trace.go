@@ -0,0 +1,65 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// TraceReport holds the recent events of an object key, from its ingestion to the write of its status
+type TraceReport struct {
+	Key    string             `json:"key"`
+	Events []utils.TraceEvent `json:"events"`
+}
+
+var TraceStatus *TraceModel
+var tracestatusonce sync.Once
+
+// TraceModel implements ApiModel, the traces are kept by the layers in utils
+type TraceModel struct{}
+
+func (a *TraceModel) InitModel() {
+	tracestatusonce.Do(func() {
+		TraceStatus = &TraceModel{}
+	})
+}
+
+func (a *TraceModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	// GET /api/trace/<objType>/<namespace>/<name>, e.g. /api/trace/Ingress/default/foo
+	get := OperationMap{
+		Route:  "/api/trace/{objType}/{namespace}/{name}",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			vars := mux.Vars(r)
+			key := vars["objType"] + "/" + vars["namespace"] + "/" + vars["name"]
+			events := utils.GetTrace(key)
+			if len(events) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			utils.Respond(w, &TraceReport{Key: key, Events: events})
+		},
+	}
+
+	operationMapList = append(operationMapList, get)
+	return operationMapList
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// ValidationReport holds the result of the last run of the pre-flight checks of the AKO configuration
+type ValidationReport struct {
+	sync.Mutex
+	LastRun time.Time         `json:"last_run"`
+	Valid   bool              `json:"valid"`
+	Checks  []ValidationCheck `json:"checks"`
+}
+
+type ValidationCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// a failed blocking check disables the sync, the others are only reported
+	Blocking bool   `json:"blocking"`
+	Message  string `json:"message,omitempty"`
+}
+
+var ValidationStatus *ValidationModel
+var validationstatusonce sync.Once
+
+// every run of the checks makes a round of calls to the controller, so the checks are run again on request at
+// most validationRunBurst times in validationRunWindow
+const validationRunBurst = 5
+const validationRunWindow = time.Minute
+
+// ValidationModel implements ApiModel
+type ValidationModel struct {
+	Validation ValidationReport `json:"validation"`
+	// runFunc runs the checks again, it is set by the controller once the avi clients are built
+	runFunc func()
+	// runLock has the runs on request go one at a time
+	runLock sync.Mutex
+	// start times of the runs on request within the last validationRunWindow
+	runTimes []time.Time
+}
+
+func (a *ValidationModel) InitModel() {
+	validationstatusonce.Do(func() {
+		ValidationStatus = &ValidationModel{
+			Validation: ValidationReport{
+				Checks: []ValidationCheck{},
+			},
+		}
+	})
+}
+
+func (a *ValidationModel) ApiOperationMap() []OperationMap {
+	var operationMapList []OperationMap
+
+	get := OperationMap{
+		Route:  "/api/validation",
+		Method: "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			ValidationStatus.Validation.Lock()
+			defer ValidationStatus.Validation.Unlock()
+			utils.Respond(w, &ValidationStatus)
+		},
+	}
+
+	// POST /api/validation/run runs the checks again and returns the new report, it does not enable or disable the sync
+	run := OperationMap{
+		Route:  "/api/validation/run",
+		Method: "POST",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			ValidationStatus.Validation.Lock()
+			runFunc := ValidationStatus.runFunc
+			ValidationStatus.Validation.Unlock()
+			if runFunc == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				utils.Respond(w, map[string]string{"error": "avi clients are not ready"})
+				return
+			}
+			if retryAfter := ValidationStatus.allowRun(); retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+				w.WriteHeader(http.StatusTooManyRequests)
+				utils.Respond(w, map[string]string{"error": "the checks were run too many times recently"})
+				return
+			}
+			ValidationStatus.runLock.Lock()
+			runFunc()
+			ValidationStatus.runLock.Unlock()
+			ValidationStatus.Validation.Lock()
+			defer ValidationStatus.Validation.Unlock()
+			utils.Respond(w, &ValidationStatus)
+		},
+	}
+
+	operationMapList = append(operationMapList, get, run)
+	return operationMapList
+}
+
+func (a *ValidationModel) SetRunFunc(runFunc func()) {
+	a.Validation.Lock()
+	defer a.Validation.Unlock()
+	a.runFunc = runFunc
+}
+
+// allowRun records a run on request of the checks and returns 0 if it is within the limit, otherwise the time
+// until the next run is allowed.
+func (a *ValidationModel) allowRun() time.Duration {
+	a.Validation.Lock()
+	defer a.Validation.Unlock()
+	now := time.Now()
+	var runTimes []time.Time
+	for _, runTime := range a.runTimes {
+		if now.Sub(runTime) < validationRunWindow {
+			runTimes = append(runTimes, runTime)
+		}
+	}
+	a.runTimes = runTimes
+	if len(a.runTimes) >= validationRunBurst {
+		return validationRunWindow - now.Sub(a.runTimes[0])
+	}
+	a.runTimes = append(a.runTimes, now)
+	return 0
+}
+
+// UpdateValidationReport replaces the report with the checks of the latest run, and returns true if any of
+// them changed its result since the previous run.
+func (a *ValidationModel) UpdateValidationReport(checks []ValidationCheck) bool {
+	a.Validation.Lock()
+	defer a.Validation.Unlock()
+	changed := len(checks) != len(a.Validation.Checks)
+	valid := true
+	for i, check := range checks {
+		if !changed && check != a.Validation.Checks[i] {
+			changed = true
+		}
+		if check.Blocking && !check.Passed {
+			valid = false
+		}
+	}
+	a.Validation.LastRun = time.Now()
+	a.Validation.Valid = valid
+	a.Validation.Checks = append([]ValidationCheck{}, checks...)
+	return changed
+}
+
+func (a *ValidationModel) GetValidationChecks() []ValidationCheck {
+	a.Validation.Lock()
+	defer a.Validation.Unlock()
+	return append([]ValidationCheck{}, a.Validation.Checks...)
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	auditExportInterval  = 5 * time.Second
+	auditExportBatchSize = 100
+	auditExportQueueSize = 4096
+
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditRecord is written for each create, update or delete sent to the controller. Keys are the kubernetes
+// objects whose changes led to the sync of the model the operation belongs to.
+type AuditRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Tenant     string    `json:"tenant"`
+	Method     string    `json:"method"`
+	ObjectType string    `json:"object_type"`
+	ObjectName string    `json:"object_name,omitempty"`
+	UUID       string    `json:"uuid,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Keys       []string  `json:"keys,omitempty"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	LatencyMs  int64     `json:"latency_ms"`
+}
+
+// auditLog appends the audit records as JSON lines to a rotating file, and sends them in batches to a webhook.
+// Either one is enabled by AUDIT_LOG_FILE_NAME, the file being kept next to the AKO log unless the name is an
+// absolute path, and AUDIT_WEBHOOK_URL.
+type auditLog struct {
+	fileLock sync.Mutex
+	file     io.WriteCloser
+	webhook  string
+	records  chan AuditRecord
+	client   *http.Client
+	// done stops the sending of the records to the webhook, stopped is closed once the pending ones are sent
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+var auditLogInstance *auditLog
+var auditLogInitialized bool
+var auditLogLock sync.RWMutex
+
+// sharedAuditLog returns nil unless the audit file or webhook is set
+func sharedAuditLog() *auditLog {
+	auditLogLock.RLock()
+	if auditLogInitialized {
+		defer auditLogLock.RUnlock()
+		return auditLogInstance
+	}
+	auditLogLock.RUnlock()
+	auditLogLock.Lock()
+	defer auditLogLock.Unlock()
+	if !auditLogInitialized {
+		auditLogInstance = newAuditLog()
+		auditLogInitialized = true
+	}
+	return auditLogInstance
+}
+
+// CloseAuditLog sends the records not yet sent to the webhook and closes the audit file, it is called on shutdown.
+// The audit log is set up again from the environment for the operations sent afterwards.
+func CloseAuditLog() {
+	auditLogLock.Lock()
+	audit := auditLogInstance
+	auditLogInstance = nil
+	auditLogInitialized = false
+	auditLogLock.Unlock()
+	if audit == nil {
+		return
+	}
+	if audit.records != nil {
+		close(audit.done)
+		<-audit.stopped
+	}
+	audit.fileLock.Lock()
+	defer audit.fileLock.Unlock()
+	if audit.file != nil {
+		if err := audit.file.Close(); err != nil {
+			AviLog.Warnf("Failed to close the audit log file: %v", err)
+		}
+		audit.file = nil
+	}
+}
+
+// newAuditLog sets up the audit file and webhook set in the environment, nil is returned if neither is set
+func newAuditLog() *auditLog {
+	fileName := os.Getenv("AUDIT_LOG_FILE_NAME")
+	webhook := os.Getenv("AUDIT_WEBHOOK_URL")
+	if fileName == "" && webhook == "" {
+		return nil
+	}
+	audit := &auditLog{}
+	if fileName != "" {
+		if !filepath.IsAbs(fileName) {
+			fileName = getFilePath() + getPodName() + fileName
+		}
+		audit.file = &lumberjack.Logger{
+			Filename:   fileName,
+			MaxSize:    500, // megabytes after which new file is created
+			MaxBackups: 5,   // number of backups
+			MaxAge:     28,  // days
+			Compress:   true,
+		}
+		AviLog.Infof("Writing the audit log of the controller operations to %s", fileName)
+	}
+	if webhook != "" {
+		audit.webhook = webhook
+		audit.records = make(chan AuditRecord, auditExportQueueSize)
+		audit.client = &http.Client{Timeout: 10 * time.Second}
+		audit.done = make(chan struct{})
+		audit.stopped = make(chan struct{})
+		go audit.run()
+		AviLog.Infof("Sending the audit log of the controller operations to %s", webhook)
+	}
+	return audit
+}
+
+// auditRestOp records the outcome of the operation, sent to the controller at start. Reads are not recorded.
+func auditRestOp(op *RestOp, start time.Time) {
+	if op.Method == RestGet {
+		return
+	}
+	audit := sharedAuditLog()
+	if audit == nil {
+		return
+	}
+	record := AuditRecord{
+		Timestamp:  start,
+		Tenant:     op.Tenant,
+		Method:     string(op.Method),
+		ObjectType: restOpObjType(op),
+		ObjectName: RestOpObjName(op),
+		Model:      op.Key,
+		Keys:       GetModelSyncKeys(op.Key),
+		Result:     AuditResultSuccess,
+		LatencyMs:  time.Since(start).Nanoseconds() / int64(time.Millisecond),
+	}
+	record.UUID = restOpUUID(op, record.ObjectType, record.ObjectName)
+	if op.Err != nil {
+		record.Result = AuditResultFailure
+		record.Error = op.Err.Error()
+	}
+	audit.write(record)
+}
+
+func (a *auditLog) write(record AuditRecord) {
+	a.writeFile(record)
+	if a.records == nil {
+		return
+	}
+	select {
+	case <-a.done:
+	case a.records <- record:
+	default:
+		AviLog.Warnf("Audit webhook queue is full, dropping the record of %s %s", record.Method, record.ObjectName)
+	}
+}
+
+// writeFile appends the record to the audit file, unless it is closed
+func (a *auditLog) writeFile(record AuditRecord) {
+	a.fileLock.Lock()
+	defer a.fileLock.Unlock()
+	if a.file == nil {
+		return
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		AviLog.Warnf("Failed to encode the audit record of %s %s: %v", record.Method, record.ObjectName, err)
+		return
+	}
+	// a single write per record, so that the lines of concurrent writes are not mixed
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		AviLog.Warnf("Failed to write the audit record of %s %s: %v", record.Method, record.ObjectName, err)
+	}
+}
+
+func (a *auditLog) run() {
+	defer close(a.stopped)
+	ticker := time.NewTicker(auditExportInterval)
+	defer ticker.Stop()
+	var batch []AuditRecord
+	for {
+		select {
+		case <-a.done:
+			a.flush(batch)
+			return
+		case record := <-a.records:
+			batch = append(batch, record)
+			if len(batch) < auditExportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := a.send(batch); err != nil {
+			AviLog.Warnf("Failed to send %d audit records to %s: %v", len(batch), a.webhook, err)
+		}
+		batch = nil
+	}
+}
+
+// flush sends the batch along with the records still queued, once the audit log is closed
+func (a *auditLog) flush(batch []AuditRecord) {
+DRAIN:
+	for {
+		select {
+		case record := <-a.records:
+			batch = append(batch, record)
+		default:
+			break DRAIN
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := a.send(batch); err != nil {
+		AviLog.Warnf("Failed to send %d audit records to %s: %v", len(batch), a.webhook, err)
+	}
+}
+
+func (a *auditLog) send(records []AuditRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Post(a.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// restOpObjType returns the type of the object of the operation, as found in the controller urls
+func restOpObjType(op *RestOp) string {
+	if macro, ok := op.Obj.(AviRestObjMacro); ok && macro.ModelName != "" {
+		return strings.ToLower(macro.ModelName)
+	}
+	if op.Model != "" {
+		return strings.ToLower(op.Model)
+	}
+	pathElems := strings.Split(strings.Trim(op.Path, "/"), "/")
+	if len(pathElems) > 1 {
+		return pathElems[1]
+	}
+	return ""
+}
+
+// restOpUUID returns the uuid of the object of the operation, taken from the response of a create
+func restOpUUID(op *RestOp, objType, name string) string {
+	if op.Method != RestPost {
+		pathElems := strings.Split(strings.Trim(op.Path, "/"), "/")
+		if len(pathElems) > 2 {
+			return pathElems[len(pathElems)-1]
+		}
+		return ""
+	}
+	var obj map[string]interface{}
+	switch resp := op.Response.(type) {
+	case []interface{}:
+		obj = macroObjResponse(resp, objType, name)
+	case map[string]interface{}:
+		obj = resp
+	}
+	uuid, _ := obj["uuid"].(string)
+	return uuid
+}
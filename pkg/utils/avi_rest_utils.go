@@ -15,18 +15,54 @@
 package utils
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/avinetworks/sdk/go/clients"
 	"github.com/avinetworks/sdk/go/session"
 	"github.com/davecgh/go-spew/spew"
 )
 
+// AviControllerCredentials are used by the avi clients to log in to the controller, with the auth token
+// taking precedence over the password. The controller certificate is verified against the CA certificate
+// when one is given.
+type AviControllerCredentials struct {
+	Username  string
+	Password  string
+	AuthToken string
+	CACert    string
+}
+
+// AviRestClientPool holds a client per graph layer worker. A client logged in again is swapped into the slot
+// of the one it replaces, so the clients are looked up with GetClient once the workers are running.
 type AviRestClientPool struct {
-	AviClient []*clients.AviClient
+	AviClient   []*clients.AviClient
+	RateLimiter *AviRestRateLimiter
+	Endpoints   *ControllerEndpoints
+	tenant      string
+	version     string
+	lock        sync.Mutex
+	credentials AviControllerCredentials
+	// bumped every time the credentials are updated
+	credentialsVersion int
+	// endpoint and credentials each client is logged in with
+	clientLogin map[*clients.AviClient]aviClientLogin
+	// clients outside of the pool, by the name of the caller they are dedicated to
+	dedicatedClients map[string]*clients.AviClient
+}
+
+type aviClientLogin struct {
+	endpoint           string
+	credentialsVersion int
 }
 
 var AviClientInstance *AviRestClientPool
@@ -49,29 +85,258 @@ func SharedAVIClients() *AviRestClientPool {
 
 func NewAviRestClientPool(num uint32, api_ep string, username string,
 	password string) (*AviRestClientPool, error) {
-	var p AviRestClientPool
+	return NewAviRestClientPoolWithCredentials(num, api_ep, AviControllerCredentials{
+		Username: username,
+		Password: password,
+	})
+}
 
+// NewAviRestClientPoolWithCredentials builds the clients against the active one of the comma separated controller
+// endpoints in api_ep. If it does not let the clients log in, they are built against the next healthy endpoint.
+func NewAviRestClientPoolWithCredentials(num uint32, api_ep string, credentials AviControllerCredentials) (*AviRestClientPool, error) {
+	p := &AviRestClientPool{
+		RateLimiter:      SharedAviRestRateLimiter(),
+		Endpoints:        SharedControllerEndpoints(api_ep),
+		credentials:      credentials,
+		clientLogin:      make(map[*clients.AviClient]aviClientLogin),
+		dedicatedClients: make(map[string]*clients.AviClient),
+	}
+	if credentials.CACert != "" {
+		tlsConfig, err := controllerTLSConfig(credentials.CACert)
+		if err != nil {
+			AviLog.Warnf("Invalid controller CA certificate, err %v", err)
+			return p, err
+		}
+		p.Endpoints.SetTLSConfig(tlsConfig)
+	}
+
+	endpoint := p.Endpoints.Active()
 	for i := uint32(0); i < num; i++ {
-		// Retry 20 times with an interval of 10 seconds each.
-		aviClient, err := clients.NewAviClient(api_ep, username,
-			session.SetPassword(password), session.SetControllerStatusCheckLimits(20, 10), session.SetInsecure)
+		aviClient, err := p.newAviClient(endpoint, credentials)
+		if err != nil {
+			if next := p.Endpoints.CheckEndpoints(); next != endpoint {
+				endpoint = next
+				aviClient, err = p.newAviClient(endpoint, credentials)
+			}
+		}
 		if err != nil {
 			AviLog.Warnf("NewAviClient returned err %v", err)
-			return &p, err
+			return p, err
 		}
 
 		p.AviClient = append(p.AviClient, aviClient)
+		p.clientLogin[aviClient] = aviClientLogin{endpoint: endpoint}
+	}
+
+	return p, nil
+}
+
+func (p *AviRestClientPool) newAviClient(endpoint string, credentials AviControllerCredentials) (*clients.AviClient, error) {
+	// Retry 20 times with an interval of 10 seconds each.
+	options := []func(*session.AviSession) error{session.SetControllerStatusCheckLimits(20, 10)}
+	if credentials.AuthToken != "" {
+		// the session logs in again with the latest token, once the one it logged in with has expired
+		options = append(options, session.SetAuthToken(credentials.AuthToken),
+			session.SetRefreshAuthTokenCallback(p.authToken))
+	} else {
+		options = append(options, session.SetPassword(credentials.Password))
+	}
+	if credentials.CACert != "" {
+		tlsConfig, err := controllerTLSConfig(credentials.CACert)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, session.SetTransport(&http.Transport{TLSClientConfig: tlsConfig}))
+	} else {
+		options = append(options, session.SetInsecure)
+	}
+	aviClient, err := clients.NewAviClient(endpoint, credentials.Username, options...)
+	if err != nil {
+		return aviClient, err
+	}
+	if p.tenant != "" {
+		SetTenant := session.SetTenant(p.tenant)
+		SetTenant(aviClient.AviSession)
+	}
+	if p.version != "" {
+		SetVersion := session.SetVersion(p.version)
+		SetVersion(aviClient.AviSession)
+	}
+	return aviClient, nil
+}
+
+func controllerTLSConfig(caCert string) (*tls.Config, error) {
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("no certificate found in the CA bundle")
+	}
+	return &tls.Config{RootCAs: rootCAs}, nil
+}
+
+func (p *AviRestClientPool) authToken() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.credentials.AuthToken
+}
+
+// UpdateCredentials has every client of the pool log in again with the given credentials, right before its
+// next calls.
+func (p *AviRestClientPool) UpdateCredentials(credentials AviControllerCredentials) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.Endpoints != nil && credentials.CACert != p.credentials.CACert {
+		var tlsConfig *tls.Config
+		if credentials.CACert != "" {
+			var err error
+			if tlsConfig, err = controllerTLSConfig(credentials.CACert); err != nil {
+				AviLog.Warnf("Invalid controller CA certificate, keeping the current credentials, err %v", err)
+				return
+			}
+		}
+		p.Endpoints.SetTLSConfig(tlsConfig)
+	}
+	p.credentials = credentials
+	p.credentialsVersion++
+}
+
+// SetTenantAndVersion sets the tenant and controller version in the sessions of the clients, and in the ones
+// the clients log in to after failing over to another endpoint.
+func (p *AviRestClientPool) SetTenantAndVersion(tenant, version string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.tenant, p.version = tenant, version
+	for _, client := range p.AviClient {
+		SetTenant := session.SetTenant(tenant)
+		SetTenant(client.AviSession)
+		SetVersion := session.SetVersion(version)
+		SetVersion(client.AviSession)
+	}
+}
+
+// GetClient returns the client in the given slot of the pool.
+func (p *AviRestClientPool) GetClient(i int) *clients.AviClient {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.AviClient[i]
+}
+
+// DedicatedClient returns the client outside of the pool dedicated to the named caller, for the callers other than
+// the graph layer workers, which would otherwise share the session of a worker. The client is logged in on the
+// first call and reused on the next ones, logged in again after a failover or an update of the credentials.
+func (p *AviRestClientPool) DedicatedClient(name string) (*clients.AviClient, error) {
+	p.lock.Lock()
+	aviClient, ok := p.dedicatedClients[name]
+	credentials, credentialsVersion := p.credentials, p.credentialsVersion
+	p.lock.Unlock()
+	if ok {
+		return p.reloginClient(aviClient), nil
+	}
+	endpoint := p.Endpoints.Active()
+	aviClient, err := p.newAviClient(endpoint, credentials)
+	if err != nil {
+		return nil, err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if current, ok := p.dedicatedClients[name]; ok {
+		// logged in concurrently by another call
+		logoutClient(aviClient)
+		return current, nil
+	}
+	p.dedicatedClients[name] = aviClient
+	p.clientLogin[aviClient] = aviClientLogin{endpoint: endpoint, credentialsVersion: credentialsVersion}
+	return aviClient, nil
+}
+
+// logoutClient ends the session of a client which is no longer used, in the background since the controller
+// endpoint it is logged in to may be down.
+func logoutClient(c *clients.AviClient) {
+	go func() {
+		if err := c.AviSession.Logout(); err != nil {
+			AviLog.Debugf("Failed to log out the session of a replaced Avi client, err %v", err)
+		}
+	}()
+}
+
+// reloginClient logs the client in again, if it is still on the controller endpoint which was active earlier,
+// or still logged in with the credentials from before they were updated. The new client takes the slot of the
+// old one in the pool, and is returned for the calls of the worker the slot is assigned to. The session of the
+// old client is logged out, anyone still holding it gets logged in again by the session on its next call.
+func (p *AviRestClientPool) reloginClient(c *clients.AviClient) *clients.AviClient {
+	if p.Endpoints == nil {
+		return c
+	}
+	active := p.Endpoints.Active()
+	p.lock.Lock()
+	current, ok := p.clientLogin[c]
+	credentials, credentialsVersion := p.credentials, p.credentialsVersion
+	p.lock.Unlock()
+	if !ok || current.endpoint == active && current.credentialsVersion == credentialsVersion {
+		return c
+	}
+	aviClient, err := p.newAviClient(active, credentials)
+	if err != nil {
+		AviLog.Warnf("Failed to log in to the controller endpoint %s, err %v", active, err)
+		return c
+	}
+	if current.endpoint != active {
+		AviLog.Infof("Avi client moved from controller endpoint %s to %s", current.endpoint, active)
+	} else {
+		AviLog.Infof("Avi client logged in to the controller endpoint %s with the updated credentials", active)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.clientLogin[c]; !ok {
+		// logged in again concurrently by another call
+		logoutClient(aviClient)
+		return c
+	}
+	if p.tenant != "" {
+		SetTenant := session.SetTenant(p.tenant)
+		SetTenant(aviClient.AviSession)
+	}
+	if p.version != "" {
+		SetVersion := session.SetVersion(p.version)
+		SetVersion(aviClient.AviSession)
+	}
+	for i := range p.AviClient {
+		if p.AviClient[i] == c {
+			p.AviClient[i] = aviClient
+		}
+	}
+	for name := range p.dedicatedClients {
+		if p.dedicatedClients[name] == c {
+			p.dedicatedClients[name] = aviClient
+		}
 	}
+	delete(p.clientLogin, c)
+	logoutClient(c)
+	p.clientLogin[aviClient] = aviClientLogin{endpoint: active, credentialsVersion: credentialsVersion}
+	return aviClient
+}
 
-	return &p, nil
+// checkEndpointsOnError has the controller endpoints health checked in the background when a call failed without
+// an answer from the controller, or with the controller unavailable, so that the retry of the call goes to
+// another endpoint if the active one is down.
+func (p *AviRestClientPool) checkEndpointsOnError(err error) {
+	if p.Endpoints == nil || len(p.Endpoints.Endpoints()) < 2 {
+		return
+	}
+	if aviError, ok := err.(session.AviError); ok && aviError.HttpStatusCode != 0 && aviError.HttpStatusCode < 500 {
+		return
+	}
+	p.Endpoints.CheckEndpointsInBackground()
 }
 
 func (p *AviRestClientPool) AviRestOperate(c *clients.AviClient, rest_ops []*RestOp) error {
+	c = p.reloginClient(c)
 	for i, op := range rest_ops {
 		SetTenant := session.SetTenant(op.Tenant)
 		SetTenant(c.AviSession)
 		SetVersion := session.SetVersion(op.Version)
 		SetVersion(c.AviSession)
+		release := p.RateLimiter.Acquire(restOpPriority(op))
+		start := time.Now()
 		switch op.Method {
 		case RestPost:
 			op.Err = c.AviSession.Post(op.Path, op.Obj, &op.Response)
@@ -88,10 +353,13 @@ func (p *AviRestClientPool) AviRestOperate(c *clients.AviClient, rest_ops []*Res
 			AviLog.Errorf("Unknown RestOp %v", op.Method)
 			op.Err = fmt.Errorf("Unknown RestOp %v", op.Method)
 		}
+		release()
+		auditRestOp(op, start)
 		if op.Err != nil {
 			AviLog.Warnf(`RestOp method %v path %v tenant %v Obj %s 
                     returned err %v`, op.Method, op.Path, op.Tenant,
 				spew.Sprint(op.Obj), Stringify(op.Response))
+			p.checkEndpointsOnError(op.Err)
 			for j := i + 1; j < len(rest_ops); j++ {
 				rest_ops[j].Err = errors.New("Aborted due to prev error")
 			}
@@ -106,6 +374,179 @@ func (p *AviRestClientPool) AviRestOperate(c *clients.AviClient, rest_ops []*Res
 	return nil
 }
 
+// AviRestOperateMacro sends the create and update operations at the head of rest_ops to the controller as
+// a single macro, instead of one request per object. The controller applies the objects of a macro in order,
+// and rolls back the ones already applied if an object fails, so a VS tree is never left partially updated.
+// The operations following them, like the deletes of the objects no longer referred to, are sent one by one.
+// If the controller rejects the macro, the operations are sent one by one as well, so that the retry layer
+// gets the error of the object which failed.
+func (p *AviRestClientPool) AviRestOperateMacro(c *clients.AviClient, rest_ops []*RestOp) error {
+	var macros []AviRestObjMacro
+	var objNames []string
+	for _, op := range rest_ops {
+		if op.Method != RestPost && op.Method != RestPut || op.Tenant != rest_ops[0].Tenant {
+			break
+		}
+		macro, name, err := restOpToMacro(op)
+		if err != nil {
+			AviLog.Debugf("RestOp method %v path %v can not be sent in a macro: %v", op.Method, op.Path, err)
+			break
+		}
+		macros = append(macros, macro)
+		objNames = append(objNames, name)
+	}
+	if len(macros) < 2 {
+		return p.AviRestOperate(c, rest_ops)
+	}
+
+	c = p.reloginClient(c)
+	SetTenant := session.SetTenant(rest_ops[0].Tenant)
+	SetTenant(c.AviSession)
+	SetVersion := session.SetVersion(rest_ops[0].Version)
+	SetVersion(c.AviSession)
+	var response []interface{}
+	// the macro goes with the priority of the virtualservice it usually carries
+	priority := RestPriorityLow
+	for _, op := range rest_ops[:len(macros)] {
+		if restOpPriority(op) == RestPriorityHigh {
+			priority = RestPriorityHigh
+		}
+	}
+	release := p.RateLimiter.Acquire(priority)
+	start := time.Now()
+	err := c.AviSession.Post("/api/macro", macros, &response)
+	release()
+	if err != nil {
+		if isMacroFallbackError(err) {
+			AviLog.Warnf("Macro with %d objects returned err %v, sending the objects one by one", len(macros), err)
+			return p.AviRestOperate(c, rest_ops)
+		}
+		AviLog.Warnf("Macro with %d objects tenant %v returned err %v", len(macros), rest_ops[0].Tenant, err)
+		p.checkEndpointsOnError(err)
+		for i, op := range rest_ops {
+			if i < len(macros) {
+				op.Err = err
+				auditRestOp(op, start)
+			} else {
+				op.Err = errors.New("Aborted due to prev error")
+			}
+		}
+		return &WebSyncError{err: err, operation: string(RestPost)}
+	}
+	AviLog.Debugf("Macro with %d objects tenant %v response %v", len(macros), rest_ops[0].Tenant, Stringify(response))
+
+	// Each operation gets the object it created or updated, in the form it would have been returned on its own.
+	for i, op := range rest_ops[:len(macros)] {
+		objResp := macroObjResponse(response, strings.ToLower(macros[i].ModelName), objNames[i])
+		if objResp == nil {
+			AviLog.Warnf("Object %s of type %s not found in the macro response", objNames[i], macros[i].ModelName)
+			continue
+		}
+		if op.Method == RestPut {
+			op.Response = objResp
+		} else {
+			op.Response = []interface{}{objResp}
+		}
+	}
+	for _, op := range rest_ops[:len(macros)] {
+		auditRestOp(op, start)
+	}
+	return p.AviRestOperate(c, rest_ops[len(macros):])
+}
+
+// restOpToMacro returns the macro for a create or update operation, along with the name of the object. The
+// uuid of an updated object is set in the macro, which makes the controller update it in place.
+func restOpToMacro(op *RestOp) (AviRestObjMacro, string, error) {
+	macro, ok := op.Obj.(AviRestObjMacro)
+	if !ok {
+		if op.Model == "" {
+			return macro, "", errors.New("model not set")
+		}
+		macro = AviRestObjMacro{ModelName: op.Model, Data: op.Obj}
+	}
+	objJson, err := json.Marshal(macro.Data)
+	if err != nil {
+		return macro, "", err
+	}
+	var data map[string]interface{}
+	if err = json.Unmarshal(objJson, &data); err != nil {
+		return macro, "", err
+	}
+	name, ok := data["name"].(string)
+	if !ok {
+		return macro, "", errors.New("name not set")
+	}
+	if op.Method == RestPut {
+		pathElems := strings.Split(strings.Trim(op.Path, "/"), "/")
+		data["uuid"] = pathElems[len(pathElems)-1]
+	}
+	return AviRestObjMacro{ModelName: macro.ModelName, Data: data}, name, nil
+}
+
+// RestOpObjName returns the name of the object of the rest op, read from the object sent for the creates and
+// updates, which do not set ObjName.
+func RestOpObjName(op *RestOp) string {
+	if op.ObjName != "" {
+		return op.ObjName
+	}
+	obj := op.Obj
+	if macro, ok := obj.(AviRestObjMacro); ok {
+		obj = macro.Data
+	}
+	if data, ok := obj.(map[string]interface{}); ok {
+		name, _ := data["name"].(string)
+		return name
+	}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	name := v.FieldByName("Name")
+	if name.Kind() == reflect.Ptr && !name.IsNil() {
+		name = name.Elem()
+	}
+	if name.Kind() != reflect.String {
+		return ""
+	}
+	return name.String()
+}
+
+func macroObjResponse(response []interface{}, objType, name string) map[string]interface{} {
+	for _, elem := range response {
+		obj, ok := elem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		objURL, ok := obj["url"].(string)
+		if !ok || len(strings.Split(objURL, "/")) < 5 {
+			continue
+		}
+		if respType, err := AviUrlToObjType(objURL); err != nil || respType != objType {
+			continue
+		}
+		if obj["name"] == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+// isMacroFallbackError returns true for the errors with which the controller rejects the objects of a macro,
+// as opposed to the controller being unavailable, where sending the objects one by one would fail as well.
+func isMacroFallbackError(err error) bool {
+	aviError, ok := err.(session.AviError)
+	if !ok {
+		return false
+	}
+	return aviError.HttpStatusCode >= 400 && aviError.HttpStatusCode < 500
+}
+
 func AviModelToUrl(model string) string {
 	switch model {
 	case "Pool":
@@ -17,6 +17,8 @@ package utils
 const (
 	GraphLayer                    = "GraphLayer"
 	ObjectIngestionLayer          = "ObjectIngestionLayer"
+	RestLayer                     = "RestLayer"
+	StatusLayer                   = "StatusLayer"
 	LeastConnection               = "LB_ALGORITHM_LEAST_CONNECTIONS"
 	RandomConnection              = "RANDOM_CONN"
 	PassthroughConnection         = "PASSTHROUGH_CONN"
@@ -65,6 +67,9 @@ const (
 	GlobalVRF                     = "global"
 	VRF_CONTEXT                   = "VRF_CONTEXT"
 	FULL_SYNC_INTERVAL            = "FULL_SYNC_INTERVAL"
+	AVI_API_RATE_LIMIT            = "AVI_API_RATE_LIMIT"
+	AVI_API_BURST                 = "AVI_API_BURST"
+	AVI_API_MAX_INFLIGHT          = "AVI_API_MAX_INFLIGHT"
 	DEFAULT_FILE_SUFFIX           = "avi.log"
 	K8S_ETIMEDOUT                 = "timed out"
 
@@ -72,4 +77,9 @@ const (
 	AVIAPI_INITIATING   = "INITIATING"
 	AVIAPI_CONNECTED    = "CONNECTED"
 	AVIAPI_DISCONNECTED = "DISCONNECTED"
+
+	AVICACHE_NOT_STARTED = "NOT_STARTED"
+	AVICACHE_IN_PROGRESS = "IN_PROGRESS"
+	AVICACHE_POPULATED   = "POPULATED"
+	AVICACHE_FAILED      = "FAILED"
 )
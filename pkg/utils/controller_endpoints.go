@@ -0,0 +1,217 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const controllerHealthCheckTimeout = 5 * time.Second
+
+// ControllerEndpointStatus holds the result of the last health check of a controller endpoint
+type ControllerEndpointStatus struct {
+	Endpoint  string    `json:"endpoint"`
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"last_check"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ControllerEndpoints tracks the endpoints of the controller cluster, usually the cluster VIP followed by the
+// node IPs, and the one the avi clients are pointed to. The active endpoint only changes once it fails a
+// health check, to the first healthy endpoint in the order they were given.
+type ControllerEndpoints struct {
+	lock       sync.RWMutex
+	endpoints  []string
+	active     string
+	status     map[string]ControllerEndpointStatus
+	httpClient *http.Client
+	// set while a health check started by CheckEndpointsInBackground runs
+	checking int32
+}
+
+var controllerEndpoints = make(map[string]*ControllerEndpoints)
+var controllerEndpointsLock sync.Mutex
+
+// ParseControllerEndpoints splits the comma separated list of controller endpoints set in CTRL_IPADDRESS
+func ParseControllerEndpoints(api_ep string) []string {
+	var endpoints []string
+	for _, ep := range strings.Split(api_ep, ",") {
+		ep = strings.TrimSpace(ep)
+		if ep != "" && !HasElem(endpoints, ep) {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints
+}
+
+// SharedControllerEndpoints returns the endpoints for the given list, shared by the client pools built with it
+func SharedControllerEndpoints(api_ep string) *ControllerEndpoints {
+	controllerEndpointsLock.Lock()
+	defer controllerEndpointsLock.Unlock()
+	if e, ok := controllerEndpoints[api_ep]; ok {
+		return e
+	}
+	e := NewControllerEndpoints(ParseControllerEndpoints(api_ep))
+	controllerEndpoints[api_ep] = e
+	return e
+}
+
+func NewControllerEndpoints(endpoints []string) *ControllerEndpoints {
+	e := &ControllerEndpoints{
+		endpoints: endpoints,
+		status:    make(map[string]ControllerEndpointStatus),
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+			Timeout:   controllerHealthCheckTimeout,
+		},
+	}
+	if len(endpoints) > 0 {
+		e.active = endpoints[0]
+	}
+	return e
+}
+
+// SetTLSConfig has the health checks verify the controller certificate with the given config, they skip the
+// verification with a nil config.
+func (e *ControllerEndpoints) SetTLSConfig(tlsConfig *tls.Config) {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.httpClient = &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   controllerHealthCheckTimeout,
+	}
+}
+
+func (e *ControllerEndpoints) Endpoints() []string {
+	return append([]string{}, e.endpoints...)
+}
+
+func (e *ControllerEndpoints) Active() string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.active
+}
+
+// CheckEndpoints health checks all the endpoints, and moves away from the active endpoint if it is down.
+// It returns the endpoint active after the check.
+func (e *ControllerEndpoints) CheckEndpoints() string {
+	statuses := make([]ControllerEndpointStatus, len(e.endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range e.endpoints {
+		wg.Add(1)
+		go func(i int, ep string) {
+			defer wg.Done()
+			statuses[i] = e.checkEndpoint(ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	activeHealthy := false
+	for _, status := range statuses {
+		e.status[status.Endpoint] = status
+		if status.Endpoint == e.active {
+			activeHealthy = status.Healthy
+		}
+	}
+	if activeHealthy {
+		return e.active
+	}
+	for _, status := range statuses {
+		if status.Healthy {
+			AviLog.Warnf("Controller endpoint %s is down, failing over to %s", e.active, status.Endpoint)
+			e.active = status.Endpoint
+			return e.active
+		}
+	}
+	AviLog.Errorf("None of the controller endpoints %v is reachable", e.endpoints)
+	return e.active
+}
+
+// CheckEndpointsInBackground health checks the endpoints without waiting for the checks, unless a check started
+// this way is still running.
+func (e *ControllerEndpoints) CheckEndpointsInBackground() {
+	if !atomic.CompareAndSwapInt32(&e.checking, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&e.checking, 0)
+		e.CheckEndpoints()
+	}()
+}
+
+// checkEndpoint treats the endpoint as healthy when the controller cluster answers on it, in the same way as the
+// avi session checks the controller status before retrying a failed call.
+func (e *ControllerEndpoints) checkEndpoint(ep string) ControllerEndpointStatus {
+	status := ControllerEndpointStatus{Endpoint: ep, LastCheck: time.Now()}
+	e.lock.RLock()
+	httpClient := e.httpClient
+	e.lock.RUnlock()
+	resp, err := httpClient.Get("https://" + ep + "/api/cluster/status")
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusInternalServerError || resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable {
+		status.Error = fmt.Sprintf("cluster status returned %d", resp.StatusCode)
+		return status
+	}
+	status.Healthy = true
+	return status
+}
+
+// MonitorEndpoints health checks the endpoints every interval, until stopCh is closed
+func (e *ControllerEndpoints) MonitorEndpoints(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.CheckEndpoints()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStatus returns the active endpoint, and the status of all the endpoints as of their last health check
+func (e *ControllerEndpoints) GetStatus() (string, []ControllerEndpointStatus) {
+	if e == nil {
+		return "", nil
+	}
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	statuses := make([]ControllerEndpointStatus, 0, len(e.endpoints))
+	for _, ep := range e.endpoints {
+		status, ok := e.status[ep]
+		if !ok {
+			status = ControllerEndpointStatus{Endpoint: ep}
+		}
+		statuses = append(statuses, status)
+	}
+	return e.active, statuses
+}
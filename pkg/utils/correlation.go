@@ -0,0 +1,90 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// keyLogs holds the correlation id of the keys published across the layers, and the fields added to the logs of
+// the keys being processed by the layer workers. The correlation id of an object key is passed on to the models
+// it is published to, so that the logs of an event can be followed from the ingestion layer to the rest layer.
+// cache sample: Ingress/ns1/ingress1 -> 5f1e0c2a9b7d4e31, admin/cluster--Shared-L7-0 -> 5f1e0c2a9b7d4e31
+var keyLogs = struct {
+	sync.RWMutex
+	correlationIDs map[string]string
+	fields         map[string][]interface{}
+}{
+	correlationIDs: make(map[string]string),
+	fields:         make(map[string][]interface{}),
+}
+
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// GetCorrelationID returns the correlation id of the key, empty if the key is not published or processed
+func GetCorrelationID(key string) string {
+	keyLogs.RLock()
+	defer keyLogs.RUnlock()
+	return keyLogs.correlationIDs[key]
+}
+
+// SetCorrelationID sets the correlation id of the key, the id is kept until the key is processed
+func SetCorrelationID(key, correlationID string) {
+	if correlationID == "" {
+		return
+	}
+	keyLogs.Lock()
+	defer keyLogs.Unlock()
+	keyLogs.correlationIDs[key] = correlationID
+}
+
+func setKeyLogFields(key string, fields []interface{}) {
+	keyLogs.Lock()
+	defer keyLogs.Unlock()
+	keyLogs.fields[key] = fields
+}
+
+// getKeyLogFields returns the fields of the logs of the key, the correlation id alone if the key is published but
+// not being processed.
+func getKeyLogFields(key string) []interface{} {
+	keyLogs.RLock()
+	defer keyLogs.RUnlock()
+	if fields, ok := keyLogs.fields[key]; ok {
+		return fields
+	}
+	if correlationID, ok := keyLogs.correlationIDs[key]; ok {
+		return []interface{}{LogFieldCorrelationID, correlationID}
+	}
+	return nil
+}
+
+// clearKeyLog removes the log fields of the processed key, along with its correlation id unless the key was
+// published again meanwhile with another one.
+func clearKeyLog(key, correlationID string) {
+	keyLogs.Lock()
+	defer keyLogs.Unlock()
+	delete(keyLogs.fields, key)
+	if keyLogs.correlationIDs[key] == correlationID {
+		delete(keyLogs.correlationIDs, key)
+	}
+}
@@ -16,7 +16,10 @@ package utils
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -37,42 +40,157 @@ var LogLevelMap = map[string]zapcore.Level{
 	"ERROR": ErrorLevel,
 }
 
+// Names of the structured fields of the logs
+const (
+	LogFieldKey           = "key"
+	LogFieldModel         = "model"
+	LogFieldVS            = "vs"
+	LogFieldLayer         = "layer"
+	LogFieldWorker        = "worker"
+	LogFieldCorrelationID = "correlation_id"
+)
+
+// keyLogPrefix is the prefix of the logs of an object key, the key being the first argument of the log
+const keyLogPrefix = "key: %s, msg: "
+
 type AviLogger struct {
 	sugar  *zap.SugaredLogger
 	logger *zap.Logger // sugar is obtained from this logger
 	// Sugaring a Logger is quite inexpensive, so it's reasonable for a single application to use both Loggers and SugaredLoggers, converting between them on the boundaries of performance-sensitive code.
-	atom zap.AtomicLevel
+	atom   zap.AtomicLevel
+	levels *logLevels
+	// json is set when the logs are written by the JSON encoder, the key of the logs is then written as a field
+	json bool
+}
+
+// logLevels holds the levels overriding the log level for the logs of some subsystems, a subsystem being the
+// package the log is written from, e.g. rest=DEBUG,nodes=WARN
+type logLevels struct {
+	sync.RWMutex
+	atom       zap.AtomicLevel
+	subsystems map[string]zapcore.Level
+}
+
+// Enabled lets the core write the logs of the lowest level in use, the level of the subsystem of a log is checked
+// by the AviLogger before it is written.
+func (l *logLevels) Enabled(lvl zapcore.Level) bool {
+	if lvl >= l.atom.Level() {
+		return true
+	}
+	l.RLock()
+	defer l.RUnlock()
+	for _, level := range l.subsystems {
+		if lvl >= level {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled returns true if the log is to be written, as per the level of the subsystem of the caller of the
+// AviLogger method. The caller is only looked up when some subsystem levels are set, so that the logs below the
+// log level are dropped before their key fields are built.
+func (aviLogger *AviLogger) enabled(lvl zapcore.Level) bool {
+	if aviLogger.levels == nil {
+		return true
+	}
+	aviLogger.levels.RLock()
+	defer aviLogger.levels.RUnlock()
+	if len(aviLogger.levels.subsystems) == 0 {
+		return lvl >= aviLogger.atom.Level()
+	}
+	if _, file, _, ok := runtime.Caller(2); ok {
+		if level, found := aviLogger.levels.subsystems[filepath.Base(filepath.Dir(file))]; found {
+			return lvl >= level
+		}
+	}
+	return lvl >= aviLogger.atom.Level()
+}
+
+// keyed adds the fields of the key being processed to the logs of the key. With the JSON encoder, the key is
+// moved from the message to its own field.
+func (aviLogger *AviLogger) keyed(template string, args []interface{}) (*zap.SugaredLogger, string, []interface{}) {
+	if len(args) == 0 || !strings.HasPrefix(template, keyLogPrefix) {
+		return aviLogger.sugar, template, args
+	}
+	key, ok := args[0].(string)
+	if !ok {
+		return aviLogger.sugar, template, args
+	}
+	sugar := aviLogger.sugar
+	if fields := getKeyLogFields(key); len(fields) != 0 {
+		sugar = sugar.With(fields...)
+	}
+	if aviLogger.json {
+		return sugar.With(LogFieldKey, key), strings.TrimPrefix(template, keyLogPrefix), args[1:]
+	}
+	return sugar, template, args
+}
+
+// With returns a logger adding the given fields to its logs, e.g. AviLog.With(LogFieldVS, vsName)
+func (aviLogger *AviLogger) With(args ...interface{}) *AviLogger {
+	logger := *aviLogger
+	logger.sugar = aviLogger.sugar.With(args...)
+	return &logger
 }
 
 func (aviLogger *AviLogger) Infof(template string, args ...interface{}) {
-	aviLogger.sugar.Infof(template, args...)
+	if !aviLogger.enabled(InfoLevel) {
+		return
+	}
+	sugar, template, args := aviLogger.keyed(template, args)
+	sugar.Infof(template, args...)
 }
 
 func (aviLogger *AviLogger) Info(args ...interface{}) {
+	if !aviLogger.enabled(InfoLevel) {
+		return
+	}
 	aviLogger.sugar.Info(args...)
 }
 
 func (aviLogger *AviLogger) Warnf(template string, args ...interface{}) {
-	aviLogger.sugar.Warnf(template, args...)
+	if !aviLogger.enabled(WarnLevel) {
+		return
+	}
+	sugar, template, args := aviLogger.keyed(template, args)
+	sugar.Warnf(template, args...)
 }
 
 func (aviLogger *AviLogger) Warn(args ...interface{}) {
+	if !aviLogger.enabled(WarnLevel) {
+		return
+	}
 	aviLogger.sugar.Warn(args...)
 }
 
 func (aviLogger *AviLogger) Errorf(template string, args ...interface{}) {
-	aviLogger.sugar.Errorf(template, args...)
+	if !aviLogger.enabled(ErrorLevel) {
+		return
+	}
+	sugar, template, args := aviLogger.keyed(template, args)
+	sugar.Errorf(template, args...)
 }
 
 func (aviLogger *AviLogger) Error(args ...interface{}) {
+	if !aviLogger.enabled(ErrorLevel) {
+		return
+	}
 	aviLogger.sugar.Error(args...)
 }
 
 func (aviLogger *AviLogger) Debugf(template string, args ...interface{}) {
-	aviLogger.sugar.Debugf(template, args...)
+	if !aviLogger.enabled(DebugLevel) {
+		return
+	}
+	sugar, template, args := aviLogger.keyed(template, args)
+	sugar.Debugf(template, args...)
 }
 
 func (aviLogger *AviLogger) Debug(args ...interface{}) {
+	if !aviLogger.enabled(DebugLevel) {
+		return
+	}
 	aviLogger.sugar.Debug(args...)
 }
 
@@ -89,6 +207,32 @@ func (aviLogger *AviLogger) SetLevel(l string) {
 	aviLogger.atom.SetLevel(LogLevelMap[l])
 }
 
+// SetSubsystemLevels changes the levels of the subsystems during runtime, the levels are given as comma
+// separated subsystem=LEVEL pairs, e.g. rest=DEBUG,nodes=WARN. The other subsystems log at the log level.
+func (aviLogger *AviLogger) SetSubsystemLevels(l string) {
+	subsystems := make(map[string]zapcore.Level)
+	for _, pair := range strings.Split(l, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		arr := strings.SplitN(pair, "=", 2)
+		if len(arr) != 2 {
+			aviLogger.Warnf("invalid subsystem log level %s, expected subsystem=LEVEL", pair)
+			continue
+		}
+		level, ok := LogLevelMap[strings.ToUpper(strings.TrimSpace(arr[1]))]
+		if !ok {
+			aviLogger.Warnf("invalid log level %s for subsystem %s", arr[1], arr[0])
+			continue
+		}
+		subsystems[strings.TrimSpace(arr[0])] = level
+	}
+	aviLogger.levels.Lock()
+	aviLogger.levels.subsystems = subsystems
+	aviLogger.levels.Unlock()
+}
+
 // log file sample name /log/ako-12345.avi.log
 func getFileName() string {
 	input := os.Getenv("LOG_FILE_NAME")
@@ -118,22 +262,29 @@ func init() {
 	var err error
 
 	usePVC := os.Getenv("USE_PVC")
+	useJSON := os.Getenv("LOG_FORMAT") == "json"
+	levels := &logLevels{atom: atom}
 
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder // colored capital case LEVEL
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder        // format 2020-05-08T03:26:08.943+0530
 	encoderCfg.EncodeCaller = zapcore.ShortCallerEncoder      // caller format package_name/filename.go
+	newEncoder := zapcore.NewConsoleEncoder
+	if useJSON {
+		encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		newEncoder = zapcore.NewJSONEncoder
+	}
 
 	if usePVC != "true" {
 		logger := zap.New(zapcore.NewCore(
-			zapcore.NewConsoleEncoder(encoderCfg),
+			newEncoder(encoderCfg),
 			zapcore.Lock(os.Stdout),
-			atom,
+			levels,
 		))
 
 		logger = logger.WithOptions(zap.AddCaller(), zap.AddCallerSkip(1))
 		sugar := logger.Sugar()
-		AviLog = AviLogger{sugar, logger, atom}
+		AviLog = AviLogger{sugar: sugar, logger: logger, atom: atom, levels: levels, json: useJSON}
 		return
 	}
 
@@ -146,9 +297,6 @@ func init() {
 	}
 	file.Close()
 
-	level := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= atom.Level()
-	})
 	w := zapcore.AddSync(&lumberjack.Logger{
 		Filename:   logpath,
 		MaxSize:    500, // megabytes after which new file is created
@@ -156,15 +304,15 @@ func init() {
 		MaxAge:     28,  // days
 		Compress:   true,
 	})
-	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderCfg),
+	core := zapcore.NewCore(newEncoder(encoderCfg),
 		w,
-		level,
+		levels,
 	)
 
 	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 	sugar := logger.Sugar()
 	defer sugar.Sync()
-	AviLog = AviLogger{sugar, logger, atom}
+	AviLog = AviLogger{sugar: sugar, logger: logger, atom: atom, levels: levels, json: useJSON}
 
 	return
 }
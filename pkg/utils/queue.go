@@ -17,6 +17,7 @@ package utils
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -25,6 +26,10 @@ import (
 
 var queuewrapper sync.Once
 var queueInstance *WorkQueueWrapper
+
+// queuesInitialized is set once the shared queues are built, the queues are looked up without building them
+// with the default parameters through it.
+var queuesInitialized int32
 var fixedQueues = [...]WorkerQueue{WorkerQueue{NumWorkers: NumWorkersIngestion, WorkqueueName: ObjectIngestionLayer}, WorkerQueue{NumWorkers: NumWorkersGraph, WorkqueueName: GraphLayer}}
 
 type WorkQueueWrapper struct {
@@ -52,10 +57,19 @@ func SharedWorkQueue(queueParams ...WorkerQueue) *WorkQueueWrapper {
 				queueInstance.queueCollection[queue.WorkqueueName] = workqueue
 			}
 		}
+		atomic.StoreInt32(&queuesInitialized, 1)
 	})
 	return queueInstance
 }
 
+// GetSharedWorkQueues returns the shared queues by name, nil if they are not built yet
+func GetSharedWorkQueues() map[string]*WorkerQueue {
+	if atomic.LoadInt32(&queuesInitialized) == 0 {
+		return nil
+	}
+	return queueInstance.queueCollection
+}
+
 //Common utils like processing worker queue, that is common for all objects.
 type WorkerQueue struct {
 	NumWorkers    uint32
@@ -65,6 +79,52 @@ type WorkerQueue struct {
 	workerId      uint32
 	SyncFunc      func(string, *sync.WaitGroup) error
 	SlowSyncTime  int
+	stateLock     sync.Mutex
+	workerStates  map[uint32]WorkerState
+}
+
+// WorkerState is the key a worker of the queue is processing and since when, the key is empty for an idle worker
+type WorkerState struct {
+	WorkerId uint32    `json:"worker_id"`
+	Key      string    `json:"key,omitempty"`
+	Since    time.Time `json:"since,omitempty"`
+}
+
+func (c *WorkerQueue) setWorkerState(workerId uint32, key string) {
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+	if c.workerStates == nil {
+		c.workerStates = make(map[uint32]WorkerState)
+	}
+	if key == "" {
+		delete(c.workerStates, workerId)
+		return
+	}
+	c.workerStates[workerId] = WorkerState{WorkerId: workerId, Key: key, Since: time.Now()}
+}
+
+// GetQueueLengths returns the number of keys waiting in the queue of each worker
+func (c *WorkerQueue) GetQueueLengths() []int {
+	lengths := make([]int, 0, len(c.Workqueue))
+	for _, queue := range c.Workqueue {
+		lengths = append(lengths, queue.Len())
+	}
+	return lengths
+}
+
+// GetWorkerStates returns the state of each worker of the queue
+func (c *WorkerQueue) GetWorkerStates() []WorkerState {
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+	states := make([]WorkerState, 0, c.NumWorkers)
+	for i := uint32(0); i < c.NumWorkers; i++ {
+		state, ok := c.workerStates[i]
+		if !ok {
+			state = WorkerState{WorkerId: i}
+		}
+		states = append(states, state)
+	}
+	return states
 }
 
 func NewWorkQueue(num_workers uint32, workerQueueName string, slowSyncTime ...int) *WorkerQueue {
@@ -136,6 +196,10 @@ func (c *WorkerQueue) processNextWorkItem(worker_id uint32, wg *sync.WaitGroup)
 }
 
 func (c *WorkerQueue) processSingleWorkItem(worker_id uint32, wg *sync.WaitGroup) bool {
+	if c.WorkqueueName == GraphLayer {
+		// The models are left in the queue while the calls to the controller are throttled.
+		SharedAviRestRateLimiter().WaitForCapacity()
+	}
 	obj, shutdown := c.Workqueue[worker_id].Get()
 	if shutdown {
 		return false
@@ -159,10 +223,28 @@ func (c *WorkerQueue) processSingleWorkItem(worker_id uint32, wg *sync.WaitGroup
 			runtime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
 			return nil
 		}
+		correlationID := GetCorrelationID(ev)
+		if correlationID == "" {
+			correlationID = NewCorrelationID()
+			SetCorrelationID(ev, correlationID)
+		}
+		fields := []interface{}{LogFieldLayer, c.WorkqueueName, LogFieldWorker, worker_id, LogFieldCorrelationID, correlationID}
+		if c.WorkqueueName == GraphLayer {
+			// the keys of the graph layer are the model names
+			fields = append(fields, LogFieldModel, ev)
+		}
+		setKeyLogFields(ev, fields)
+		startKeyTrace(ev, c.WorkqueueName)
+		c.setWorkerState(worker_id, ev)
+		start := time.Now()
 		// Run the syncToAvi, passing it the ev resource to be synced.
 		err := c.SyncFunc(ev, wg)
+		c.setWorkerState(worker_id, "")
+		exportSpan(c.WorkqueueName, ev, correlationID, worker_id, start, time.Now(), err)
+		endKeyTrace(ev, c.WorkqueueName)
+		clearKeyLog(ev, correlationID)
 		if err != nil {
-			AviLog.Errorf("There was an error while syncing the key: %s", ev)
+			AviLog.With(fields...).Errorf("There was an error while syncing the key: %s", ev)
 		}
 		c.Workqueue[worker_id].Forget(obj)
 
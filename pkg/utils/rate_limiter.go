@@ -0,0 +1,213 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RestPriority int
+
+const (
+	RestPriorityLow RestPriority = iota
+	RestPriorityHigh
+)
+
+// AviRestRateLimiterStats holds the throttling done by the rate limiter, the wait times are in milliseconds
+type AviRestRateLimiterStats struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	MaxInFlight       int     `json:"max_in_flight"`
+	InFlight          int     `json:"in_flight"`
+	Waiting           int     `json:"waiting"`
+	Requests          int64   `json:"requests"`
+	ThrottledRequests int64   `json:"throttled_requests"`
+	ThrottledWaitMs   int64   `json:"throttled_wait_ms"`
+	MaxWaitMs         int64   `json:"max_wait_ms"`
+}
+
+// AviRestRateLimiter limits the calls made to the controller with a token bucket, refilled at the configured
+// rate up to the burst size, and caps the number of calls in flight. A call waiting with high priority goes
+// ahead of all the ones waiting with low priority. While calls are waiting, the graph layer workers hold off
+// taking the next model from their queue, see WaitForCapacity, so the updates for a model are coalesced meanwhile.
+type AviRestRateLimiter struct {
+	lock        sync.Mutex
+	cond        *sync.Cond
+	rate        float64
+	burst       float64
+	tokens      float64
+	lastRefill  time.Time
+	maxInFlight int
+	inFlight    int
+	waiting     [2]int
+	stats       AviRestRateLimiterStats
+}
+
+var aviRestRateLimiter *AviRestRateLimiter
+var ratelimiteronce sync.Once
+
+// SharedAviRestRateLimiter returns the rate limiter shared by all the avi client pools, configured through
+// AVI_API_RATE_LIMIT (calls per second), AVI_API_BURST and AVI_API_MAX_INFLIGHT. It is nil when neither
+// a rate nor a cap on the calls in flight is set.
+func SharedAviRestRateLimiter() *AviRestRateLimiter {
+	ratelimiteronce.Do(func() {
+		rate, _ := strconv.ParseFloat(os.Getenv(AVI_API_RATE_LIMIT), 64)
+		burst, _ := strconv.Atoi(os.Getenv(AVI_API_BURST))
+		maxInFlight, _ := strconv.Atoi(os.Getenv(AVI_API_MAX_INFLIGHT))
+		aviRestRateLimiter = NewAviRestRateLimiter(rate, burst, maxInFlight)
+	})
+	return aviRestRateLimiter
+}
+
+func NewAviRestRateLimiter(rate float64, burst, maxInFlight int) *AviRestRateLimiter {
+	if rate < 0 {
+		rate = 0
+	}
+	if maxInFlight < 0 {
+		maxInFlight = 0
+	}
+	if rate == 0 && maxInFlight == 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	AviLog.Infof("Limiting the calls to the controller to %v per second with a burst of %d, at most %d in flight", rate, burst, maxInFlight)
+	l := &AviRestRateLimiter{
+		rate:        rate,
+		burst:       float64(burst),
+		tokens:      float64(burst),
+		lastRefill:  time.Now(),
+		maxInFlight: maxInFlight,
+	}
+	l.stats.RequestsPerSecond = rate
+	l.stats.Burst = burst
+	l.stats.MaxInFlight = maxInFlight
+	l.cond = sync.NewCond(&l.lock)
+	return l
+}
+
+// Acquire blocks until a call with the given priority is allowed, and returns the function to call once it is done.
+func (l *AviRestRateLimiter) Acquire(priority RestPriority) func() {
+	if l == nil {
+		return func() {}
+	}
+	start := time.Now()
+	throttled := false
+	l.lock.Lock()
+	l.waiting[priority]++
+	var refillTimer *time.Timer
+	for !l.admit(priority) {
+		throttled = true
+		if l.rate > 0 && l.tokens < 1 {
+			// wake up the waiters once the next token is available
+			refill := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+			if refillTimer == nil {
+				refillTimer = time.AfterFunc(refill, l.cond.Broadcast)
+			} else {
+				refillTimer.Reset(refill)
+			}
+		}
+		l.cond.Wait()
+	}
+	if refillTimer != nil {
+		refillTimer.Stop()
+	}
+	l.waiting[priority]--
+	if l.rate > 0 {
+		l.tokens--
+	}
+	l.inFlight++
+	l.stats.Requests++
+	if throttled {
+		waitMs := time.Since(start).Milliseconds()
+		l.stats.ThrottledRequests++
+		l.stats.ThrottledWaitMs += waitMs
+		if waitMs > l.stats.MaxWaitMs {
+			l.stats.MaxWaitMs = waitMs
+		}
+	}
+	// the low priority waiters held back by this one can go ahead now, and the workers waiting for capacity
+	// once none are left
+	wakeUp := priority == RestPriorityHigh || l.waiting[RestPriorityLow]+l.waiting[RestPriorityHigh] == 0
+	l.lock.Unlock()
+	if wakeUp {
+		l.cond.Broadcast()
+	}
+
+	return func() {
+		l.lock.Lock()
+		l.inFlight--
+		l.lock.Unlock()
+		l.cond.Broadcast()
+	}
+}
+
+// WaitForCapacity blocks as long as calls are waiting to be admitted.
+func (l *AviRestRateLimiter) WaitForCapacity() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for l.waiting[RestPriorityLow]+l.waiting[RestPriorityHigh] > 0 {
+		l.cond.Wait()
+	}
+}
+
+func (l *AviRestRateLimiter) admit(priority RestPriority) bool {
+	if priority == RestPriorityLow && l.waiting[RestPriorityHigh] > 0 {
+		return false
+	}
+	if l.maxInFlight > 0 && l.inFlight >= l.maxInFlight {
+		return false
+	}
+	if l.rate > 0 {
+		now := time.Now()
+		l.tokens += now.Sub(l.lastRefill).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.lastRefill = now
+		if l.tokens < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *AviRestRateLimiter) GetStats() AviRestRateLimiterStats {
+	if l == nil {
+		return AviRestRateLimiterStats{}
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	stats := l.stats
+	stats.InFlight = l.inFlight
+	stats.Waiting = l.waiting[RestPriorityLow] + l.waiting[RestPriorityHigh]
+	return stats
+}
+
+// restOpPriority gives priority to the deletes, and to the virtualservice and vsvip calls which decide the
+// status of the ingresses, routes and services.
+func restOpPriority(op *RestOp) RestPriority {
+	if op.Method == RestDelete || op.Model == "VirtualService" || op.Model == "VsVip" {
+		return RestPriorityHigh
+	}
+	return RestPriorityLow
+}
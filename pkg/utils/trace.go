@@ -0,0 +1,192 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// maxTraceEvents is the number of events kept per object key, the oldest ones are dropped first
+	maxTraceEvents = 100
+	// maxTracedKeys is the number of object keys traced, the key updated least recently is dropped first
+	maxTracedKeys = 2000
+)
+
+// TraceEvent is a step of the processing of an object key, from its ingestion to the write of its status
+type TraceEvent struct {
+	Time          time.Time `json:"time"`
+	Layer         string    `json:"layer"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Message       string    `json:"message"`
+	Error         string    `json:"error,omitempty"`
+}
+
+type objectTrace struct {
+	key    string
+	events []TraceEvent
+}
+
+// traces holds the recent events of the object keys. The events of a key are recorded as well on the keys it
+// fans out to while it is processed, e.g. the ingresses of a service, and the rest layer events of a model on
+// the keys published to it since its last sync.
+// cache sample: Ingress/ns1/ingress1 -> [ADD, published to admin/cluster--Shared-L7-0, PUT virtualservice ...]
+var traces = struct {
+	sync.Mutex
+	objects map[string]*list.Element
+	// lru holds the object traces, the most recently updated first
+	lru *list.List
+	// related holds the keys an object key being processed fans out to
+	related map[string][]string
+	// publishers holds the keys published to a model, which are not synced yet
+	publishers map[string][]string
+	// syncing holds the keys published to a model being synced by the rest layer
+	syncing map[string][]string
+}{
+	objects:    make(map[string]*list.Element),
+	lru:        list.New(),
+	related:    make(map[string][]string),
+	publishers: make(map[string][]string),
+	syncing:    make(map[string][]string),
+}
+
+// AddTraceEvent records an event of the object key, the error is the outcome of the event if any
+func AddTraceEvent(key, layer, message string, err error) {
+	event := newTraceEvent(key, layer, message, err)
+	traces.Lock()
+	defer traces.Unlock()
+	addTraceEvent(key, event)
+	for _, relatedKey := range traces.related[key] {
+		addTraceEvent(relatedKey, event)
+	}
+}
+
+// TraceFanOut records that the object key being processed changes the given keys. The events of the key are
+// recorded on them until it is processed.
+func TraceFanOut(key string, keys []string) {
+	event := newTraceEvent(key, GraphLayer, "changed by "+key, nil)
+	traces.Lock()
+	defer traces.Unlock()
+	for _, relatedKey := range keys {
+		if relatedKey == key || HasElem(traces.related[key], relatedKey) {
+			continue
+		}
+		traces.related[key] = append(traces.related[key], relatedKey)
+		addTraceEvent(relatedKey, event)
+	}
+}
+
+// TraceModelPublish records that the object key is published to the model, the rest layer events of the next
+// sync of the model are recorded on the key.
+func TraceModelPublish(key, modelName string) {
+	event := newTraceEvent(key, GraphLayer, "published to model "+modelName, nil)
+	traces.Lock()
+	defer traces.Unlock()
+	for _, publisher := range append([]string{key}, traces.related[key]...) {
+		addTraceEvent(publisher, event)
+		if !HasElem(traces.publishers[modelName], publisher) {
+			traces.publishers[modelName] = append(traces.publishers[modelName], publisher)
+		}
+	}
+}
+
+// AddModelTraceEvent records an event of the sync of the model on the object keys published to it
+func AddModelTraceEvent(modelName, layer, message string, err error) {
+	event := newTraceEvent(modelName, layer, "model "+modelName+": "+message, err)
+	traces.Lock()
+	defer traces.Unlock()
+	for _, publisher := range traces.syncing[modelName] {
+		addTraceEvent(publisher, event)
+	}
+}
+
+// GetModelSyncKeys returns the object keys published to the model being synced by the rest layer
+func GetModelSyncKeys(modelName string) []string {
+	traces.Lock()
+	defer traces.Unlock()
+	return append([]string{}, traces.syncing[modelName]...)
+}
+
+// GetTrace returns the recorded events of the object key, the oldest first
+func GetTrace(key string) []TraceEvent {
+	traces.Lock()
+	defer traces.Unlock()
+	elem, ok := traces.objects[key]
+	if !ok {
+		return nil
+	}
+	return append([]TraceEvent{}, elem.Value.(*objectTrace).events...)
+}
+
+// startKeyTrace is called as a layer starts processing a key. The keys published to a model so far are the
+// ones its sync is recorded on.
+func startKeyTrace(key, layer string) {
+	if layer != GraphLayer {
+		return
+	}
+	traces.Lock()
+	defer traces.Unlock()
+	if publishers, ok := traces.publishers[key]; ok {
+		traces.syncing[key] = publishers
+		delete(traces.publishers, key)
+	}
+}
+
+// endKeyTrace is called once a layer is done processing a key
+func endKeyTrace(key, layer string) {
+	traces.Lock()
+	defer traces.Unlock()
+	if layer == GraphLayer {
+		delete(traces.syncing, key)
+		return
+	}
+	delete(traces.related, key)
+}
+
+func newTraceEvent(key, layer, message string, err error) TraceEvent {
+	event := TraceEvent{
+		Time:          time.Now(),
+		Layer:         layer,
+		CorrelationID: GetCorrelationID(key),
+		Message:       message,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// addTraceEvent is called with the traces lock held
+func addTraceEvent(key string, event TraceEvent) {
+	elem, ok := traces.objects[key]
+	if !ok {
+		elem = traces.lru.PushFront(&objectTrace{key: key})
+		traces.objects[key] = elem
+		if traces.lru.Len() > maxTracedKeys {
+			oldest := traces.lru.Back()
+			traces.lru.Remove(oldest)
+			delete(traces.objects, oldest.Value.(*objectTrace).key)
+		}
+	} else {
+		traces.lru.MoveToFront(elem)
+	}
+	trace := elem.Value.(*objectTrace)
+	trace.events = append(trace.events, event)
+	if len(trace.events) > maxTraceEvents {
+		trace.events = trace.events[len(trace.events)-maxTraceEvents:]
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	spanExportInterval  = 5 * time.Second
+	spanExportBatchSize = 512
+	spanExportQueueSize = 4096
+)
+
+// spanExporter sends a span per key processed by a layer to an OpenTelemetry collector, using OTLP over HTTP
+// with the JSON encoding. The spans of an event share the correlation id of its key as their trace id.
+type spanExporter struct {
+	endpoint string
+	spans    chan otlpSpan
+	client   *http.Client
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+var spanExporterInstance *spanExporter
+var spanexporteronce sync.Once
+
+// sharedSpanExporter returns nil unless OTEL_EXPORTER_OTLP_ENDPOINT is set, e.g. http://localhost:4318
+func sharedSpanExporter() *spanExporter {
+	spanexporteronce.Do(func() {
+		endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if endpoint == "" {
+			return
+		}
+		spanExporterInstance = &spanExporter{
+			endpoint: strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+			spans:    make(chan otlpSpan, spanExportQueueSize),
+			client:   &http.Client{Timeout: 10 * time.Second},
+		}
+		go spanExporterInstance.run()
+		AviLog.Infof("Exporting the spans of the layers to %s", spanExporterInstance.endpoint)
+	})
+	return spanExporterInstance
+}
+
+// exportSpan queues the span of the processing of the key by the layer, the span is dropped if the queue is full
+func exportSpan(layer, key, correlationID string, workerId uint32, start, end time.Time, err error) {
+	exporter := sharedSpanExporter()
+	if exporter == nil || correlationID == "" {
+		return
+	}
+	span := otlpSpan{
+		// trace ids are 16 bytes long, correlation ids 8
+		TraceID:           strings.Repeat("0", 16) + correlationID,
+		SpanID:            NewCorrelationID(),
+		Name:              layer,
+		Kind:              1, // SPAN_KIND_INTERNAL
+		StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes: []otlpAttribute{
+			{Key: LogFieldKey, Value: map[string]string{"stringValue": key}},
+			{Key: LogFieldWorker, Value: map[string]string{"intValue": strconv.Itoa(int(workerId))}},
+		},
+	}
+	if err != nil {
+		span.Status = otlpStatus{Code: 2, Message: err.Error()} // STATUS_CODE_ERROR
+	}
+	select {
+	case exporter.spans <- span:
+	default:
+		AviLog.Debugf("key: %s, msg: span export queue is full, dropping the span of %s", key, layer)
+	}
+}
+
+func (e *spanExporter) run() {
+	ticker := time.NewTicker(spanExportInterval)
+	defer ticker.Stop()
+	var batch []otlpSpan
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) < spanExportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := e.export(batch); err != nil {
+			AviLog.Warnf("Failed to export %d spans to %s: %v", len(batch), e.endpoint, err)
+		}
+		batch = nil
+	}
+}
+
+func (e *spanExporter) export(spans []otlpSpan) error {
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{{Key: "service.name", Value: map[string]string{"stringValue": "ako"}}},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "ako"},
+				"spans": spans,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
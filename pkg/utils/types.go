@@ -101,6 +101,7 @@ type RestOp struct {
 	Model    string
 	Version  string
 	ObjName  string // Optional field - right only to be used for delete.
+	Key      string // Optional field - the model synced by the operation, recorded in the audit log.
 }
 
 type ServiceMetadataObj struct {
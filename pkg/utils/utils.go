@@ -137,6 +137,30 @@ func Hash(s string) uint32 {
 	return h.Sum32()
 }
 
+// RendezvousBkt returns the bucket with the highest score for the key, the score of a bucket being the hash of the
+// key mixed with the bucket number. Unlike with Bkt, a change of the number of buckets only moves the keys of the
+// buckets removed, or the share of the keys the buckets added take over.
+func RendezvousBkt(key string, numBkts uint32) uint32 {
+	keyHash := Hash(key)
+	var bkt, maxScore uint32
+	for i := uint32(0); i < numBkts; i++ {
+		if score := mix32(keyHash ^ mix32(i+1)); i == 0 || score > maxScore {
+			bkt, maxScore = i, score
+		}
+	}
+	return bkt
+}
+
+// mix32 is the finalizer of MurmurHash3, it spreads every bit of x over the whole result.
+func mix32(x uint32) uint32 {
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
+
 var letters = []rune("abcdefghijklmnopqrstuvwxyz1234567890")
 
 func RandomSeq(n int) string {
@@ -0,0 +1,332 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package bootuptests
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const pagedPoolCount = 5
+const pagedPoolPageSize = 2
+
+func pagedPoolName(i int) string {
+	return fmt.Sprintf("cluster--paged-pool-%d", i)
+}
+
+// injectMWForPagedPools serves the pools in pages of pagedPoolPageSize, the rest of the objects from the mock files.
+func injectMWForPagedPools(pageRequests *int32) {
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		if r.Method != "GET" || strings.Trim(url, "/") != "api/pool" {
+			integrationtest.FeedMockCollectionData(w, r, mockFilePath)
+			return
+		}
+
+		page := 1
+		if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+			page, _ = strconv.Atoi(pageParam)
+			atomic.AddInt32(pageRequests, 1)
+		}
+		var results []map[string]interface{}
+		for i := (page - 1) * pagedPoolPageSize; i < page*pagedPoolPageSize && i < pagedPoolCount; i++ {
+			results = append(results, map[string]interface{}{
+				"name":               pagedPoolName(i),
+				"uuid":               fmt.Sprintf("pool-paged-%d", i),
+				"cloud_config_cksum": "1234",
+				"service_metadata":   "{}",
+				"_last_modified":     "1600000000000000",
+			})
+		}
+		resp := map[string]interface{}{
+			"count":   pagedPoolCount,
+			"results": results,
+		}
+		if page*pagedPoolPageSize < pagedPoolCount {
+			resp["next"] = fmt.Sprintf("https://%s/api/pool?page_size=%d&page=%d", r.Host, pagedPoolPageSize, page+1)
+		}
+		data, _ := json.Marshal(resp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+}
+
+func TestPaginatedCachePopulation(t *testing.T) {
+	var pageRequests int32
+	injectMWForPagedPools(&pageRequests)
+	defer integrationtest.ResetMiddleware()
+
+	aviClients := cache.SharedAVIClients().AviClient
+	aviObjCache := cache.SharedAviObjCache()
+	aviObjCache.AviRefreshObjectCache(cache.NewAviClientQueue(aviClients...), "CLOUD_VCENTER")
+
+	for i := 0; i < pagedPoolCount; i++ {
+		poolKey := cache.NamespaceName{Namespace: lib.GetTenant(), Name: pagedPoolName(i)}
+		if _, found := aviObjCache.PoolCache.AviCacheGet(poolKey); !found {
+			t.Fatalf("pool %s from page %d not found in the cache", poolKey.Name, i/pagedPoolPageSize+1)
+		}
+		defer aviObjCache.PoolCache.AviCacheDelete(poolKey)
+	}
+	if pageRequests != 2 {
+		t.Fatalf("expected 2 requests for the pages following the first, got %d", pageRequests)
+	}
+
+	poolStatus := apimodels.RestStatus.AviCache.Objects["pool"]
+	if poolStatus.Status != utils.AVICACHE_POPULATED || poolStatus.Count != pagedPoolCount {
+		t.Fatalf("unexpected cache population status for pools: %v", utils.Stringify(poolStatus))
+	}
+}
+
+func pagedVSName(i int) string {
+	return fmt.Sprintf("cluster--paged-vs-%d", i)
+}
+
+// injectMWForPagedVSes serves the virtualservices in pages of pagedPoolPageSize, the last one being an SNI child
+// of the first, the rest of the objects from the mock files.
+func injectMWForPagedVSes(pageRequests *int32) {
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		if r.Method != "GET" || strings.Trim(url, "/") != "api/virtualservice" {
+			integrationtest.FeedMockCollectionData(w, r, mockFilePath)
+			return
+		}
+
+		page := 1
+		if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+			page, _ = strconv.Atoi(pageParam)
+			atomic.AddInt32(pageRequests, 1)
+		}
+		var results []map[string]interface{}
+		for i := (page - 1) * pagedPoolPageSize; i < page*pagedPoolPageSize && i < pagedPoolCount; i++ {
+			vs := map[string]interface{}{
+				"name":               pagedVSName(i),
+				"uuid":               fmt.Sprintf("virtualservice-paged-%d", i),
+				"cloud_config_cksum": "1234",
+				"_last_modified":     "1600000000000000",
+			}
+			if i == pagedPoolCount-1 {
+				vs["vh_parent_vs_ref"] = fmt.Sprintf("https://%s/api/virtualservice/virtualservice-paged-0#%s", r.Host, pagedVSName(0))
+			}
+			results = append(results, vs)
+		}
+		resp := map[string]interface{}{
+			"count":   pagedPoolCount,
+			"results": results,
+		}
+		if page*pagedPoolPageSize < pagedPoolCount {
+			resp["next"] = fmt.Sprintf("https://%s/api/virtualservice?page_size=%d&page=%d", r.Host, pagedPoolPageSize, page+1)
+		}
+		data, _ := json.Marshal(resp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+}
+
+// TestPaginatedVSCachePopulation checks that all the pages of virtualservices are added to the cache, with the SNI
+// children of a later page referring to their parent.
+func TestPaginatedVSCachePopulation(t *testing.T) {
+	var pageRequests int32
+	injectMWForPagedVSes(&pageRequests)
+	defer integrationtest.ResetMiddleware()
+
+	aviClients := cache.SharedAVIClients().AviClient
+	aviObjCache := cache.SharedAviObjCache()
+	var vsKeys []cache.NamespaceName
+	for i := 0; i < pagedPoolCount; i++ {
+		vsKeys = append(vsKeys, cache.NamespaceName{Namespace: utils.ADMIN_NS, Name: pagedVSName(i)})
+	}
+	vsCacheCopy := append([]cache.NamespaceName{}, vsKeys...)
+	if err := aviObjCache.AviObjVSCachePopulate(cache.NewAviClientQueue(aviClients...), "CLOUD_VCENTER", &vsCacheCopy); err != nil {
+		t.Fatalf("error in populating the vs cache: %v", err)
+	}
+
+	for i, vsKey := range vsKeys {
+		vsIntf, found := aviObjCache.VsCacheLocal.AviCacheGet(vsKey)
+		if !found {
+			t.Fatalf("vs %s from page %d not found in the cache", vsKey.Name, i/pagedPoolPageSize+1)
+		}
+		defer aviObjCache.VsCacheLocal.AviCacheDelete(vsKey)
+		if i == pagedPoolCount-1 && vsIntf.(*cache.AviVsCache).ParentVSRef != vsKeys[0] {
+			t.Fatalf("unexpected parent %v of the SNI child %s", vsIntf.(*cache.AviVsCache).ParentVSRef, vsKey.Name)
+		}
+	}
+	if len(vsCacheCopy) != 0 {
+		t.Fatalf("virtualservices found on the controller left in the cache copy: %v", vsCacheCopy)
+	}
+	if pageRequests != 2 {
+		t.Fatalf("expected 2 requests for the pages following the first, got %d", pageRequests)
+	}
+}
+
+// TestCachePopulationFailure checks that a failure to fetch the pools is reported, and that the pool cache and the
+// caches of the later stages are left as they are.
+func TestCachePopulationFailure(t *testing.T) {
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && strings.Trim(r.URL.EscapedPath(), "/") == "api/pool" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "bad request"}`))
+			return
+		}
+		integrationtest.FeedMockCollectionData(w, r, mockFilePath)
+	})
+	defer integrationtest.ResetMiddleware()
+
+	aviObjCache := cache.SharedAviObjCache()
+	poolKey := cache.NamespaceName{Namespace: lib.GetTenant(), Name: "cluster--cached-pool"}
+	aviObjCache.PoolCache.AviCacheAdd(poolKey, &cache.AviPoolCache{Name: poolKey.Name, Uuid: "pool-cached"})
+	defer aviObjCache.PoolCache.AviCacheDelete(poolKey)
+
+	err := aviObjCache.AviRefreshObjectCache(cache.NewAviClientQueue(cache.SharedAVIClients().AviClient...), "CLOUD_VCENTER")
+	if err == nil {
+		t.Fatalf("expected the cache population to fail")
+	}
+	if _, found := aviObjCache.PoolCache.AviCacheGet(poolKey); !found {
+		t.Fatalf("pool %s removed from the cache on a failed population", poolKey.Name)
+	}
+	if status := apimodels.RestStatus.AviCache.Objects["pool"].Status; status != utils.AVICACHE_FAILED {
+		t.Fatalf("unexpected cache population status for pools: %s", status)
+	}
+	if status := apimodels.RestStatus.AviCache.Objects["poolgroup"].Status; status != utils.AVICACHE_NOT_STARTED {
+		t.Fatalf("unexpected cache population status for poolgroups: %s", status)
+	}
+}
+
+func snapshotPool(name, lastModified string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":               name,
+		"uuid":               "pool-" + name,
+		"cloud_config_cksum": "1234",
+		"service_metadata":   "{}",
+		"_last_modified":     lastModified,
+	}
+}
+
+// injectMWForSnapshotPools serves pool-a unchanged and pool-b modified since the snapshot, pool-c is deleted.
+func injectMWForSnapshotPools(poolFetches *[]string, lock *sync.Mutex) {
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		if r.Method != "GET" || strings.Trim(url, "/") != "api/pool" {
+			integrationtest.FeedMockCollectionData(w, r, mockFilePath)
+			return
+		}
+
+		var results []map[string]interface{}
+		query := r.URL.Query()
+		if query.Get("fields") != "" {
+			results = append(results, snapshotPool("cluster--pool-a", "1"), snapshotPool("cluster--pool-b", "2"))
+		} else {
+			lock.Lock()
+			*poolFetches = append(*poolFetches, query.Get("name"))
+			lock.Unlock()
+			if query.Get("name") == "cluster--pool-b" {
+				results = append(results, snapshotPool("cluster--pool-b", "2"))
+			}
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"count":   len(results),
+			"results": results,
+		})
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+}
+
+func TestCacheSnapshotVerification(t *testing.T) {
+	os.Setenv("CACHE_SNAPSHOT", "ConfigMap")
+	defer os.Setenv("CACHE_SNAPSHOT", "")
+
+	modelName := "admin/cluster--Shared-L7-0"
+	aviObjCache := cache.SharedAviObjCache()
+	poolKeys := make(map[string]cache.NamespaceName)
+	for _, name := range []string{"cluster--pool-a", "cluster--pool-b", "cluster--pool-c"} {
+		poolKeys[name] = cache.NamespaceName{Namespace: lib.GetTenant(), Name: name}
+		aviObjCache.PoolCache.AviCacheAdd(poolKeys[name], &cache.AviPoolCache{Name: name, Uuid: "pool-" + name, CloudConfigCksum: "1234", LastModified: "1"})
+	}
+	if err := aviObjCache.SaveCacheSnapshot(map[string]uint32{modelName: 1234}); err != nil {
+		t.Fatalf("error in saving the cache snapshot: %v", err)
+	}
+	defer KubeClient.CoreV1().ConfigMaps("avi-system").Delete(lib.CacheSnapshotConfigMap, nil)
+	for _, poolKey := range poolKeys {
+		aviObjCache.PoolCache.AviCacheDelete(poolKey)
+	}
+
+	var poolFetches []string
+	var lock sync.Mutex
+	injectMWForSnapshotPools(&poolFetches, &lock)
+	defer integrationtest.ResetMiddleware()
+	if _, _, err := aviObjCache.AviObjCachePopulate(cache.SharedAVIClients().AviClient, utils.CtrlVersion, "CLOUD_VCENTER"); err != nil {
+		t.Fatalf("error in populating the cache: %v", err)
+	}
+	defer aviObjCache.ClearSnapshotGraphChecksums()
+
+	// only the pool modified since the snapshot is fetched again
+	if len(poolFetches) != 1 || poolFetches[0] != "cluster--pool-b" {
+		t.Fatalf("expected only cluster--pool-b to be fetched, got %v", poolFetches)
+	}
+	for name, lastModified := range map[string]string{"cluster--pool-a": "1", "cluster--pool-b": "2"} {
+		poolIntf, found := aviObjCache.PoolCache.AviCacheGet(poolKeys[name])
+		if !found {
+			t.Fatalf("pool %s not found in the cache", name)
+		}
+		if poolIntf.(*cache.AviPoolCache).LastModified != lastModified {
+			t.Fatalf("expected last modified %s for pool %s, got %s", lastModified, name, poolIntf.(*cache.AviPoolCache).LastModified)
+		}
+		defer aviObjCache.PoolCache.AviCacheDelete(poolKeys[name])
+	}
+	if _, found := aviObjCache.PoolCache.AviCacheGet(poolKeys["cluster--pool-c"]); found {
+		t.Fatalf("pool cluster--pool-c deleted on the controller is still in the cache")
+	}
+	if !aviObjCache.IsModelInSnapshot(modelName, 1234) {
+		t.Fatalf("graph checksum of model %s not restored from the snapshot", modelName)
+	}
+}
+
+func TestCacheSnapshotConfigMapSizeLimit(t *testing.T) {
+	os.Setenv("CACHE_SNAPSHOT", "ConfigMap")
+	defer os.Setenv("CACHE_SNAPSHOT", "")
+
+	// random uuids do not compress, so that the gzipped snapshot exceeds the size limit of a ConfigMap
+	aviObjCache := cache.SharedAviObjCache()
+	uuid := make([]byte, 32)
+	for i := 0; i < 40000; i++ {
+		rand.Read(uuid)
+		poolKey := cache.NamespaceName{Namespace: lib.GetTenant(), Name: fmt.Sprintf("cluster--large-pool-%d", i)}
+		aviObjCache.PoolCache.AviCacheAdd(poolKey, &cache.AviPoolCache{Name: poolKey.Name, Uuid: hex.EncodeToString(uuid), LastModified: "1"})
+		defer aviObjCache.PoolCache.AviCacheDelete(poolKey)
+	}
+	if err := aviObjCache.SaveCacheSnapshot(nil); err == nil {
+		t.Fatalf("expected an error in saving a cache snapshot larger than the ConfigMap limit")
+	}
+	if _, err := KubeClient.CoreV1().ConfigMaps("avi-system").Get(lib.CacheSnapshotConfigMap, metav1.GetOptions{}); err == nil {
+		t.Fatalf("cache snapshot ConfigMap written despite exceeding the size limit")
+	}
+}
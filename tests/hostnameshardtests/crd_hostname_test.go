@@ -24,6 +24,7 @@ import (
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
 
 	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -548,3 +549,210 @@ func TestHostNameHTTPRuleHostSwitch(t *testing.T) {
 	integrationtest.TeardownHTTPRule(t, rrnameFoo)
 	TearDownIngressForCacheSyncCheck(t, modelName)
 }
+
+func TestHostnameHTTPRuleDestinationCA(t *testing.T) {
+	// ingress secure foo.com/foo /bar
+	// rr1: /foo with destination CA secret, serverName and client certificate
+	// update the secret, pki profile is updated
+	// delete httprule, pool security is detached
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	rrname := "samplerr-foo"
+
+	SetupDomain()
+	SetUpTestForIngress(t, modelName)
+	integrationtest.AddSecret("my-secret", "default", "tlsCert", "tlsKey")
+	integrationtest.PollForCompletion(t, modelName, 5)
+	ingressObject := integrationtest.FakeIngress{
+		Name:        "foo-with-targets",
+		Namespace:   "default",
+		DnsNames:    []string{"foo.com"},
+		Ips:         []string{"8.8.8.8"},
+		HostNames:   []string{"v1"},
+		Paths:       []string{"/foo", "/bar"},
+		ServiceName: "avisvc",
+		TlsSecretDNS: map[string][]string{
+			"my-secret": []string{"foo.com"},
+		},
+	}
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses("default").Create(ingressObject.Ingress(true)); err != nil {
+		t.Fatalf("error in adding Ingress: %v", err)
+	}
+	integrationtest.PollForCompletion(t, modelName, 5)
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dest-ca", Namespace: "default"},
+		Data:       map[string][]byte{"ca.crt": []byte("destCACert")},
+	}
+	if _, err := KubeClient.CoreV1().Secrets("default").Create(caSecret); err != nil {
+		t.Fatalf("error in adding Secret: %v", err)
+	}
+
+	httprule := integrationtest.FakeHTTPRule{
+		Name:           rrname,
+		Namespace:      "default",
+		Fqdn:           "foo.com",
+		PathProperties: []integrationtest.FakeHTTPRulePath{{Path: "/foo"}},
+	}.HTTPRule()
+	httprule.Spec.Paths[0].TLS.DestinationCASecret = "dest-ca"
+	httprule.Spec.Paths[0].TLS.ServerName = "backend.foo.com"
+	httprule.Spec.Paths[0].TLS.SSLKeyCertificate = "thisisahttpruleref-sslkey"
+	if _, err := CRDClient.AkoV1alpha1().HTTPRules("default").Create(httprule); err != nil {
+		t.Fatalf("error in adding HTTPRule: %v", err)
+	}
+
+	poolWithPki := func() *avinodes.AviPoolNode {
+		_, aviModel := objects.SharedAviGraphLister().Get(modelName)
+		nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+		if len(nodes[0].SniNodes) == 0 {
+			return nil
+		}
+		for _, pool := range nodes[0].SniNodes[0].PoolRefs {
+			if pool.PkiProfile != nil {
+				return pool
+			}
+		}
+		return nil
+	}
+	g.Eventually(func() bool {
+		return poolWithPki() != nil
+	}, 50*time.Second).Should(gomega.Equal(true))
+	pool := poolWithPki()
+	g.Expect(pool.Name).To(gomega.ContainSubstring("foo.com_foo"))
+	g.Expect(pool.SniEnabled).To(gomega.Equal(true))
+	g.Expect(pool.ServerName).To(gomega.Equal("backend.foo.com"))
+	g.Expect(pool.SslKeyAndCertificateRef).To(gomega.ContainSubstring("thisisahttpruleref-sslkey"))
+	g.Expect(pool.PkiProfile.Name).To(gomega.Equal(pool.Name + "-pkiprofile"))
+	g.Expect(pool.PkiProfile.CACert).To(gomega.Equal("destCACert"))
+
+	// rotating the CA in the secret updates the pki profile
+	caSecret.Data["ca.crt"] = []byte("newDestCACert")
+	caSecret.ResourceVersion = "2"
+	if _, err := KubeClient.CoreV1().Secrets("default").Update(caSecret); err != nil {
+		t.Fatalf("error in updating Secret: %v", err)
+	}
+	g.Eventually(func() string {
+		if pool := poolWithPki(); pool != nil {
+			return pool.PkiProfile.CACert
+		}
+		return ""
+	}, 50*time.Second).Should(gomega.Equal("newDestCACert"))
+
+	integrationtest.TeardownHTTPRule(t, rrname)
+	g.Eventually(func() bool {
+		return poolWithPki() == nil
+	}, 50*time.Second).Should(gomega.Equal(true))
+
+	if err := KubeClient.CoreV1().Secrets("default").Delete("dest-ca", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Secret %v", err)
+	}
+	TearDownIngressForCacheSyncCheck(t, modelName)
+}
+
+func TestHostnameHTTPRuleDestinationCASecretMissing(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	rrname := "samplerr-foo"
+
+	httprule := integrationtest.FakeHTTPRule{
+		Name:           rrname,
+		Namespace:      "default",
+		Fqdn:           "foo.com",
+		PathProperties: []integrationtest.FakeHTTPRulePath{{Path: "/foo"}},
+	}.HTTPRule()
+	httprule.Spec.Paths[0].TLS.DestinationCASecret = "missing-ca"
+	if _, err := CRDClient.AkoV1alpha1().HTTPRules("default").Create(httprule); err != nil {
+		t.Fatalf("error in adding HTTPRule: %v", err)
+	}
+
+	g.Eventually(func() string {
+		httprule, _ := CRDClient.AkoV1alpha1().HTTPRules("default").Get(rrname, metav1.GetOptions{})
+		return httprule.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Rejected"))
+	httprule, _ = CRDClient.AkoV1alpha1().HTTPRules("default").Get(rrname, metav1.GetOptions{})
+	g.Expect(httprule.Status.Error).To(gomega.Equal("destination CA secret missing-ca not found"))
+
+	integrationtest.TeardownHTTPRule(t, rrname)
+}
+
+func TestHostnameHostRuleClientCertificate(t *testing.T) {
+	// secure ingress, hostrule requiring client certificates signed by the CA of a secret
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	hrname := "samplehr-foo"
+	SetUpIngressForCacheSyncCheck(t, modelName, true, true)
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "client-ca", ResourceVersion: "1"},
+		Data:       map[string][]byte{"ca.crt": []byte("clientcacert")},
+	}
+	if _, err := KubeClient.CoreV1().Secrets("default").Create(caSecret); err != nil {
+		t.Fatalf("error in adding Secret: %v", err)
+	}
+	hrCreate := integrationtest.FakeHostRule{
+		Name:              hrname,
+		Namespace:         "default",
+		Fqdn:              "foo.com",
+		SslKeyCertificate: "thisisahostruleref-sslkey",
+		ClientCASecret:    "client-ca",
+	}.HostRule()
+	if _, err := CRDClient.AkoV1alpha1().HostRules("default").Create(hrCreate); err != nil {
+		t.Fatalf("error in adding HostRule: %v", err)
+	}
+
+	g.Eventually(func() string {
+		hostrule, _ := CRDClient.AkoV1alpha1().HostRules("default").Get(hrname, metav1.GetOptions{})
+		return hostrule.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Accepted"))
+	g.Eventually(func() bool {
+		_, aviModel := objects.SharedAviGraphLister().Get(modelName)
+		nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+		return len(nodes[0].SniNodes) == 1 && nodes[0].SniNodes[0].ClientAuthAppProfile != nil
+	}, 10*time.Second).Should(gomega.Equal(true))
+	_, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	appProfile := nodes[0].SniNodes[0].ClientAuthAppProfile
+	g.Expect(appProfile.ClientCertMode).To(gomega.Equal("SSL_CLIENT_CERTIFICATE_REQUIRE"))
+	g.Expect(appProfile.SubjectHeader).To(gomega.Equal("X-SSL-Client-Subject"))
+	g.Expect(appProfile.PkiProfile.CACert).To(gomega.Equal("clientcacert"))
+	g.Expect(appProfile.PkiProfile.CRL).To(gomega.Equal(""))
+
+	// adding a CRL to the secret rebuilds the PKI profile
+	caSecret.Data["ca.crl"] = []byte("clientcacrl")
+	caSecret.ResourceVersion = "2"
+	if _, err := KubeClient.CoreV1().Secrets("default").Update(caSecret); err != nil {
+		t.Fatalf("error in updating Secret: %v", err)
+	}
+	g.Eventually(func() string {
+		_, aviModel := objects.SharedAviGraphLister().Get(modelName)
+		nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+		if len(nodes[0].SniNodes) == 1 && nodes[0].SniNodes[0].ClientAuthAppProfile != nil {
+			return nodes[0].SniNodes[0].ClientAuthAppProfile.PkiProfile.CRL
+		}
+		return ""
+	}, 10*time.Second).Should(gomega.Equal("clientcacrl"))
+
+	// an unknown client certificate mode is rejected
+	hrUpdate := integrationtest.FakeHostRule{
+		Name:              hrname,
+		Namespace:         "default",
+		Fqdn:              "foo.com",
+		SslKeyCertificate: "thisisahostruleref-sslkey",
+		ClientCASecret:    "client-ca",
+		ClientCertMode:    "optional",
+	}.HostRule()
+	hrUpdate.ResourceVersion = "2"
+	if _, err := CRDClient.AkoV1alpha1().HostRules("default").Update(hrUpdate); err != nil {
+		t.Fatalf("error in updating HostRule: %v", err)
+	}
+	g.Eventually(func() string {
+		hostrule, _ := CRDClient.AkoV1alpha1().HostRules("default").Get(hrname, metav1.GetOptions{})
+		return hostrule.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Rejected"))
+
+	sniVSKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--foo.com"}
+	integrationtest.TeardownHostRule(t, g, sniVSKey, hrname)
+	KubeClient.CoreV1().Secrets("default").Delete("client-ca", nil)
+	TearDownIngressForCacheSyncCheck(t, modelName)
+}
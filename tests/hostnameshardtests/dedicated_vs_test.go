@@ -0,0 +1,156 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package hostnameshardtests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func updateDedicatedVSAnnotation(t *testing.T, dedicated bool, resourceVersion string) {
+	ingressObject := integrationtest.FakeIngress{
+		Name:        "foo-with-targets",
+		Namespace:   "default",
+		DnsNames:    []string{"foo.com"},
+		Ips:         []string{"8.8.8.8"},
+		HostNames:   []string{"v1"},
+		Paths:       []string{"/foo"},
+		ServiceName: "avisvc",
+		TlsSecretDNS: map[string][]string{
+			"my-secret": []string{"foo.com"},
+		},
+	}
+	ingrFake := ingressObject.Ingress()
+	if dedicated {
+		ingrFake.Annotations = map[string]string{lib.DedicatedVSAnnotation: "true"}
+	}
+	ingrFake.ResourceVersion = resourceVersion
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses("default").Update(ingrFake); err != nil {
+		t.Fatalf("error in updating Ingress: %v", err)
+	}
+}
+
+// waitForHostMove waits for the move of the host started by the ingress update to be done
+func waitForHostMove(g *gomega.WithT) {
+	g.Eventually(func() int {
+		avinodes.SharedShardAssignment().Sync()
+		return len(apimodels.ShardStatus.GetShardMigrations())
+	}, 10*time.Second, time.Second).Should(gomega.Equal(1))
+	g.Eventually(func() int {
+		avinodes.SharedShardAssignment().Sync()
+		return len(apimodels.ShardStatus.GetShardMigrations())
+	}, 60*time.Second, time.Second).Should(gomega.Equal(0))
+}
+
+func getIngressStatusLen() int {
+	ingress, _ := KubeClient.ExtensionsV1beta1().Ingresses("default").Get("foo-with-targets", metav1.GetOptions{})
+	return len(ingress.Status.LoadBalancer.Ingress)
+}
+
+func TestHostnameDedicatedVSToggle(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shardModelName := "admin/cluster--Shared-L7-0"
+	dedicatedVSName := "cluster--Dedicated-L7-foo.com"
+	dedicatedModelName := "admin/" + dedicatedVSName
+	SetUpIngressForCacheSyncCheck(t, shardModelName, true, true)
+
+	sniKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--foo.com"}
+	g.Eventually(func() string {
+		return getSniParentName(sniKey)
+	}, 20*time.Second).Should(gomega.Equal("cluster--Shared-L7-0"))
+	sniCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(sniKey)
+	sniUuid := sniCache.(*cache.AviVsCache).Uuid
+
+	// the host is moved to its own parent VS and VSVIP
+	updateDedicatedVSAnnotation(t, true, "2")
+	waitForHostMove(g)
+	g.Eventually(func() string {
+		return getSniParentName(sniKey)
+	}, 20*time.Second).Should(gomega.Equal(dedicatedVSName))
+	sniCache, _ = cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(sniKey)
+	g.Expect(sniCache.(*cache.AviVsCache).Uuid).To(gomega.Equal(sniUuid))
+
+	dedicatedVsCache, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: dedicatedVSName})
+	g.Expect(found).To(gomega.Equal(true))
+	g.Expect(dedicatedVsCache.(*cache.AviVsCache).VSVipKeyCollection).To(gomega.HaveLen(1))
+	g.Expect(dedicatedVsCache.(*cache.AviVsCache).SNIChildCollection).To(gomega.ContainElement(sniUuid))
+
+	_, aviModel := objects.SharedAviGraphLister().Get(dedicatedModelName)
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].SniNodes).To(gomega.HaveLen(1))
+	g.Expect(nodes[0].VSVIPRefs[0].FQDNs).To(gomega.ContainElement("foo.com"))
+	_, aviModel = objects.SharedAviGraphLister().Get(shardModelName)
+	nodes = aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].SniNodes).To(gomega.HaveLen(0))
+	g.Expect(nodes[0].VSVIPRefs[0].FQDNs).NotTo(gomega.ContainElement("foo.com"))
+	g.Eventually(getIngressStatusLen, 10*time.Second).Should(gomega.Equal(1))
+
+	// and back to the shard VS, the dedicated VS is deleted
+	updateDedicatedVSAnnotation(t, false, "3")
+	waitForHostMove(g)
+	g.Eventually(func() string {
+		return getSniParentName(sniKey)
+	}, 20*time.Second).Should(gomega.Equal("cluster--Shared-L7-0"))
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: dedicatedVSName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(false))
+	_, aviModel = objects.SharedAviGraphLister().Get(dedicatedModelName)
+	g.Expect(aviModel).To(gomega.BeNil())
+
+	_, aviModel = objects.SharedAviGraphLister().Get(shardModelName)
+	nodes = aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].SniNodes).To(gomega.HaveLen(1))
+	g.Expect(nodes[0].VSVIPRefs[0].FQDNs).To(gomega.ContainElement("foo.com"))
+	g.Eventually(getIngressStatusLen, 10*time.Second).Should(gomega.Equal(1))
+	g.Expect(avinodes.DeriveHostNameShardVS("foo.com", "test")).To(gomega.Equal("cluster--Shared-L7-0"))
+
+	TearDownIngressForCacheSyncCheck(t, shardModelName)
+}
+
+func TestHostnameDedicatedVSIngressDelete(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shardModelName := "admin/cluster--Shared-L7-0"
+	dedicatedVSName := "cluster--Dedicated-L7-foo.com"
+	SetUpIngressForCacheSyncCheck(t, shardModelName, true, true)
+	updateDedicatedVSAnnotation(t, true, "2")
+	waitForHostMove(g)
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: dedicatedVSName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(true))
+	g.Eventually(getIngressStatusLen, 10*time.Second).Should(gomega.Equal(1))
+
+	// deleting the ingress deletes the dedicated VS right away, there is nothing left to move
+	TearDownIngressForCacheSyncCheck(t, shardModelName)
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: dedicatedVSName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(false))
+	g.Expect(avinodes.SharedDedicatedVSStore().IsDedicated("foo.com")).To(gomega.Equal(false))
+	g.Expect(avinodes.DeriveHostNameShardVS("foo.com", "test")).To(gomega.Equal("cluster--Shared-L7-0"))
+}
@@ -0,0 +1,319 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package hostnameshardtests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func sharedVSPoolNames(modelName string) []string {
+	var poolNames []string
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return poolNames
+	}
+	for _, pool := range aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].PoolRefs {
+		poolNames = append(poolNames, pool.Name)
+	}
+	return poolNames
+}
+
+func setUpOwnershipTest(t *testing.T, modelName, policy string) {
+	os.Setenv("HOSTNAME_OWNERSHIP_POLICY", policy)
+	SetUpTestForIngress(t, modelName)
+	integrationtest.CreateSVC(t, "red", "avisvc", corev1.ServiceTypeClusterIP, false)
+	integrationtest.CreateEP(t, "red", "avisvc", false, false, "1.1.1")
+}
+
+func tearDownOwnershipTest(t *testing.T, modelName string) {
+	os.Setenv("HOSTNAME_OWNERSHIP_POLICY", "")
+	integrationtest.DelSVC(t, "red", "avisvc")
+	integrationtest.DelEP(t, "red", "avisvc")
+	TearDownTestForIngress(t, modelName)
+}
+
+func createFooIngress(t *testing.T, name, namespace, path string) {
+	ingress := (integrationtest.FakeIngress{
+		Name:        name,
+		Namespace:   namespace,
+		DnsNames:    []string{"foo.com"},
+		Paths:       []string{path},
+		ServiceName: "avisvc",
+	}).Ingress()
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses(namespace).Create(ingress); err != nil {
+		t.Fatalf("error in adding Ingress: %v", err)
+	}
+}
+
+func createTimedFooIngress(t *testing.T, name, namespace, path string, created time.Time) {
+	ingress := (integrationtest.FakeIngress{
+		Name:        name,
+		Namespace:   namespace,
+		DnsNames:    []string{"foo.com"},
+		Paths:       []string{path},
+		ServiceName: "avisvc",
+	}).Ingress()
+	ingress.CreationTimestamp = metav1.NewTime(created)
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses(namespace).Create(ingress); err != nil {
+		t.Fatalf("error in adding Ingress: %v", err)
+	}
+}
+
+func TestHostnameFirstClaimPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	setUpOwnershipTest(t, modelName, "FirstClaim")
+
+	createFooIngress(t, "foo-owner", "default", "/foo")
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_foo-default-foo-owner"))
+
+	// foo.com is claimed by the default namespace, the ingress in red does not make it to the model
+	createFooIngress(t, "foo-intruder", "red", "/bar")
+	g.Consistently(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 3*time.Second).Should(gomega.ConsistOf("cluster--foo.com_foo-default-foo-owner"))
+
+	// the host is handed over to red once the owner goes away
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("default").Delete("foo-owner", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-intruder"))
+
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("red").Delete("foo-intruder", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.HaveLen(0))
+	tearDownOwnershipTest(t, modelName)
+}
+
+// TestHostnameFirstClaimOrder checks that the oldest ingress owns the host, whichever order the claims come in.
+func TestHostnameFirstClaimOrder(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	now := time.Now()
+	claims := []struct {
+		key     string
+		created time.Time
+	}{
+		{"Ingress/default/foo-newer", now},
+		{"Ingress/red/foo-older", now.Add(-time.Hour)},
+		{"Ingress/blue/foo-newest", now.Add(time.Hour)},
+		{"Ingress/green/foo-newer", now},
+	}
+	inOrder, reversed := avinodes.NewHostnameClaimStore(), avinodes.NewHostnameClaimStore()
+	for i := range claims {
+		inOrder.UpdateClaims(claims[i].key, claims[i].created, []string{"foo.com"})
+		reversed.UpdateClaims(claims[len(claims)-1-i].key, claims[len(claims)-1-i].created, []string{"foo.com"})
+	}
+	g.Expect(inOrder.FirstClaimNamespace("foo.com")).To(gomega.Equal("red"))
+	g.Expect(reversed.GetClaimants("foo.com")).To(gomega.Equal(inOrder.GetClaimants("foo.com")))
+	g.Expect(inOrder.GetClaimants("foo.com")).To(gomega.Equal([]string{
+		"Ingress/red/foo-older", "Ingress/default/foo-newer", "Ingress/green/foo-newer", "Ingress/blue/foo-newest"}))
+
+	// the ingresses created in the same second go by namespace
+	g.Expect(inOrder.DeleteClaims("Ingress/red/foo-older")).To(gomega.Equal([]string{"foo.com"}))
+	g.Expect(inOrder.FirstClaimNamespace("foo.com")).To(gomega.Equal("default"))
+
+	// an older ingress processed after the owner takes the host over
+	modelName := "admin/cluster--Shared-L7-0"
+	setUpOwnershipTest(t, modelName, "FirstClaim")
+	createTimedFooIngress(t, "foo-newer", "default", "/foo", now)
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_foo-default-foo-newer"))
+	createTimedFooIngress(t, "foo-older", "red", "/bar", now.Add(-time.Hour))
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-older"))
+
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("default").Delete("foo-newer", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("red").Delete("foo-older", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.HaveLen(0))
+	tearDownOwnershipTest(t, modelName)
+}
+
+func TestHostnameNamespaceAllowListPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	os.Setenv("DOMAIN_NAMESPACE_LIST", `[{"domain":"foo.com","namespaces":["red"]}]`)
+	defer os.Setenv("DOMAIN_NAMESPACE_LIST", "")
+	setUpOwnershipTest(t, modelName, "NamespaceAllowList")
+
+	createFooIngress(t, "foo-default", "default", "/foo")
+	createFooIngress(t, "foo-red", "red", "/bar")
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-red"))
+	g.Consistently(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 3*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-red"))
+
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("default").Delete("foo-default", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("red").Delete("foo-red", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.HaveLen(0))
+	tearDownOwnershipTest(t, modelName)
+}
+
+func TestHostnameClaimPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	setUpOwnershipTest(t, modelName, "HostnameClaim")
+
+	// unclaimed hosts are not published
+	createFooIngress(t, "foo-red", "red", "/bar")
+	g.Consistently(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 3*time.Second).Should(gomega.HaveLen(0))
+
+	claim := &akov1alpha1.HostnameClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "red", Name: "foo-claim", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Spec:       akov1alpha1.HostnameClaimSpec{Fqdn: "foo.com"},
+	}
+	if _, err := CRDClient.AkoV1alpha1().HostnameClaims("red").Create(claim); err != nil {
+		t.Fatalf("error in adding HostnameClaim: %v", err)
+	}
+	g.Eventually(func() string {
+		claim, _ := CRDClient.AkoV1alpha1().HostnameClaims("red").Get("foo-claim", metav1.GetOptions{})
+		return claim.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Accepted"))
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-red"))
+
+	// a newer claim for the same host is rejected
+	duplicateClaim := &akov1alpha1.HostnameClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo-claim", CreationTimestamp: metav1.Now()},
+		Spec:       akov1alpha1.HostnameClaimSpec{Fqdn: "foo.com"},
+	}
+	if _, err := CRDClient.AkoV1alpha1().HostnameClaims("default").Create(duplicateClaim); err != nil {
+		t.Fatalf("error in adding HostnameClaim: %v", err)
+	}
+	g.Eventually(func() string {
+		claim, _ := CRDClient.AkoV1alpha1().HostnameClaims("default").Get("foo-claim", metav1.GetOptions{})
+		return claim.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Rejected"))
+
+	// the duplicate claim takes over once the first one is deleted
+	if err := CRDClient.AkoV1alpha1().HostnameClaims("red").Delete("foo-claim", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the HostnameClaim %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.HaveLen(0))
+	g.Eventually(func() string {
+		claim, _ := CRDClient.AkoV1alpha1().HostnameClaims("default").Get("foo-claim", metav1.GetOptions{})
+		return claim.Status.Status
+	}, 10*time.Second).Should(gomega.Equal("Accepted"))
+
+	if err := CRDClient.AkoV1alpha1().HostnameClaims("default").Delete("foo-claim", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the HostnameClaim %v", err)
+	}
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("red").Delete("foo-red", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	tearDownOwnershipTest(t, modelName)
+}
+
+func getHostnameClaimStatus(namespace, name string) string {
+	claim, err := CRDClient.AkoV1alpha1().HostnameClaims(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return claim.Status.Status
+}
+
+// TestHostnameClaimOrder checks that an older HostnameClaim processed after a newer one takes the host over.
+func TestHostnameClaimOrder(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	setUpOwnershipTest(t, modelName, "HostnameClaim")
+	createFooIngress(t, "foo-red", "red", "/bar")
+
+	now := time.Now()
+	newerClaim := &akov1alpha1.HostnameClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo-claim", CreationTimestamp: metav1.NewTime(now)},
+		Spec:       akov1alpha1.HostnameClaimSpec{Fqdn: "foo.com"},
+	}
+	if _, err := CRDClient.AkoV1alpha1().HostnameClaims("default").Create(newerClaim); err != nil {
+		t.Fatalf("error in adding HostnameClaim: %v", err)
+	}
+	g.Eventually(func() string {
+		return getHostnameClaimStatus("default", "foo-claim")
+	}, 10*time.Second).Should(gomega.Equal("Accepted"))
+
+	olderClaim := &akov1alpha1.HostnameClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "red", Name: "foo-claim", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Spec:       akov1alpha1.HostnameClaimSpec{Fqdn: "foo.com"},
+	}
+	if _, err := CRDClient.AkoV1alpha1().HostnameClaims("red").Create(olderClaim); err != nil {
+		t.Fatalf("error in adding HostnameClaim: %v", err)
+	}
+	g.Eventually(func() string {
+		return getHostnameClaimStatus("red", "foo-claim")
+	}, 10*time.Second).Should(gomega.Equal("Accepted"))
+	g.Eventually(func() string {
+		return getHostnameClaimStatus("default", "foo-claim")
+	}, 10*time.Second).Should(gomega.Equal("Rejected"))
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.ConsistOf("cluster--foo.com_bar-red-foo-red"))
+
+	if err := CRDClient.AkoV1alpha1().HostnameClaims("default").Delete("foo-claim", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the HostnameClaim %v", err)
+	}
+	if err := CRDClient.AkoV1alpha1().HostnameClaims("red").Delete("foo-claim", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the HostnameClaim %v", err)
+	}
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("red").Delete("foo-red", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	g.Eventually(func() []string {
+		return sharedVSPoolNames(modelName)
+	}, 10*time.Second).Should(gomega.HaveLen(0))
+	tearDownOwnershipTest(t, modelName)
+}
@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -28,6 +30,8 @@ import (
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
 
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -941,3 +945,217 @@ func TestHostnameMultiHostUpdateIngressStatusCheck(t *testing.T) {
 	}
 	TearDownTestForIngress(t, modelName)
 }
+
+func TestHostnameDriftReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	SetUpIngressForCacheSyncCheck(t, modelName, false, false)
+
+	mcache := cache.SharedAviObjCache()
+	poolKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--foo.com_foo-default-foo-with-targets"}
+	g.Eventually(func() bool {
+		_, found := mcache.PoolCache.AviCacheGet(poolKey)
+		return found
+	}, 10*time.Second).Should(gomega.Equal(true))
+
+	// the pool is deleted on the controller, everything else is left intact
+	var poolUpdated int32
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		if r.Method == "GET" && strings.Contains(r.URL.RawQuery, "cloud_config_cksum") {
+			results := []cache.AviObjCksum{}
+			if strings.Contains(url, "virtualservice") {
+				for _, vsCache := range mcache.VsCacheMeta.ShallowCopy() {
+					vsCacheObj := vsCache.(*cache.AviVsCache)
+					results = append(results, cache.AviObjCksum{
+						Name:             vsCacheObj.Name,
+						CloudConfigCksum: vsCacheObj.CloudConfigCksum,
+						LastModified:     vsCacheObj.LastModified,
+					})
+				}
+			} else {
+				for key, poolCache := range mcache.PoolCache.ShallowCopy() {
+					if key == poolKey {
+						continue
+					}
+					poolCacheObj := poolCache.(*cache.AviPoolCache)
+					results = append(results, cache.AviObjCksum{
+						Name:             poolCacheObj.Name,
+						CloudConfigCksum: poolCacheObj.CloudConfigCksum,
+						LastModified:     poolCacheObj.LastModified,
+					})
+				}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"count": len(results), "results": results})
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
+		if r.Method == "PUT" && strings.Contains(url, "/api/pool/") {
+			atomic.StoreInt32(&poolUpdated, 1)
+		}
+		integrationtest.NormalControllerServer(w, r)
+	})
+	defer integrationtest.ResetMiddleware()
+
+	ctrl.DriftReconcile(cache.SharedAVIClients().AviClient[0])
+	driftedObjects := apimodels.DriftStatus.GetDriftedObjects()
+	g.Expect(driftedObjects).To(gomega.HaveLen(1))
+	g.Expect(driftedObjects[0].ObjectType).To(gomega.Equal("pool"))
+	g.Expect(driftedObjects[0].Name).To(gomega.Equal(poolKey.Name))
+	g.Expect(driftedObjects[0].Reason).To(gomega.Equal("deleted on the controller"))
+
+	// the model is republished and the pool is synced back to the controller
+	g.Eventually(func() int32 {
+		return atomic.LoadInt32(&poolUpdated)
+	}, 10*time.Second).Should(gomega.Equal(int32(1)))
+	g.Eventually(func() string {
+		poolCache, _ := mcache.PoolCache.AviCacheGet(poolKey)
+		return poolCache.(*cache.AviPoolCache).CloudConfigCksum
+	}, 10*time.Second).ShouldNot(gomega.BeEmpty())
+
+	integrationtest.ResetMiddleware()
+	TearDownIngressForCacheSyncCheck(t, modelName)
+}
+
+func getOrphanedObject(objType, name string) *apimodels.OrphanedObject {
+	for _, orphan := range apimodels.OrphanGCStatus.GetOrphanedObjects() {
+		if orphan.ObjectType == objType && orphan.Name == name {
+			return &orphan
+		}
+	}
+	return nil
+}
+
+func TestHostnameOrphanGC(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	SetUpIngressForCacheSyncCheck(t, modelName, false, false)
+
+	mcache := cache.SharedAviObjCache()
+	poolKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--foo.com_foo-default-foo-with-targets"}
+	g.Eventually(func() bool {
+		_, found := mcache.PoolCache.AviCacheGet(poolKey)
+		return found
+	}, 10*time.Second).Should(gomega.Equal(true))
+
+	// a pool left behind on the controller, not referred to by any virtualservice
+	orphanKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--orphan-pool"}
+	mcache.PoolCache.AviCacheAdd(orphanKey, &cache.AviPoolCache{Name: orphanKey.Name, Tenant: "admin", Uuid: "pool-orphan"})
+	defer mcache.PoolCache.AviCacheDelete(orphanKey)
+
+	var orphanDeleted int32
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && strings.Contains(r.URL.EscapedPath(), "/api/pool/pool-orphan") {
+			atomic.StoreInt32(&orphanDeleted, 1)
+		}
+		integrationtest.NormalControllerServer(w, r)
+	})
+	defer integrationtest.ResetMiddleware()
+	defer os.Setenv("ORPHAN_GC_GRACE_PERIOD", "")
+	defer os.Setenv("ORPHAN_GC_REPORT_ONLY", "")
+
+	ctrl.OrphanGC()
+	orphan := getOrphanedObject("pool", orphanKey.Name)
+	g.Expect(orphan).NotTo(gomega.BeNil())
+	g.Expect(orphan.Uuid).To(gomega.Equal("pool-orphan"))
+	g.Expect(orphan.Action).To(gomega.Equal(apimodels.OrphanInGracePeriod))
+	g.Expect(getOrphanedObject("pool", poolKey.Name)).To(gomega.BeNil())
+	g.Expect(getOrphanedObject("virtualservice", "cluster--Shared-L7-0")).To(gomega.BeNil())
+
+	// past the grace period, the pool is only reported in report only mode
+	os.Setenv("ORPHAN_GC_GRACE_PERIOD", "0")
+	os.Setenv("ORPHAN_GC_REPORT_ONLY", "true")
+	ctrl.OrphanGC()
+	orphan = getOrphanedObject("pool", orphanKey.Name)
+	g.Expect(orphan).NotTo(gomega.BeNil())
+	g.Expect(orphan.Action).To(gomega.Equal(apimodels.OrphanWouldDelete))
+	_, found := mcache.PoolCache.AviCacheGet(orphanKey)
+	g.Expect(found).To(gomega.Equal(true))
+	g.Expect(atomic.LoadInt32(&orphanDeleted)).To(gomega.Equal(int32(0)))
+
+	os.Setenv("ORPHAN_GC_REPORT_ONLY", "false")
+	ctrl.OrphanGC()
+	orphan = getOrphanedObject("pool", orphanKey.Name)
+	g.Expect(orphan).NotTo(gomega.BeNil())
+	g.Expect(orphan.Action).To(gomega.Equal(apimodels.OrphanDeleted))
+	g.Eventually(func() int32 {
+		return atomic.LoadInt32(&orphanDeleted)
+	}, 10*time.Second).Should(gomega.Equal(int32(1)))
+	g.Eventually(func() bool {
+		_, found := mcache.PoolCache.AviCacheGet(orphanKey)
+		return found
+	}, 10*time.Second).Should(gomega.Equal(false))
+	_, found = mcache.PoolCache.AviCacheGet(poolKey)
+	g.Expect(found).To(gomega.Equal(true))
+
+	integrationtest.ResetMiddleware()
+	TearDownIngressForCacheSyncCheck(t, modelName)
+}
+
+// TestHostnameOrphanGCTenant checks that the orphans of another tenant, like the ones of a VS placed in the tenant
+// of its AviInfraSetting, are deleted in their tenant.
+func TestHostnameOrphanGCTenant(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	mcache := cache.SharedAviObjCache()
+	// a pool in the same tenant as a pool of the same name in the tenant AKO runs in, which is in use
+	poolKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--tenant-pool"}
+	mcache.PoolCache.AviCacheAdd(poolKey, &cache.AviPoolCache{Name: poolKey.Name, Tenant: "admin", Uuid: "pool-admin"})
+	defer mcache.PoolCache.AviCacheDelete(poolKey)
+	vsKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--tenant-vs"}
+	mcache.VsCacheMeta.AviCacheAdd(vsKey, &cache.AviVsCache{Name: vsKey.Name, Tenant: "admin", Uuid: "vs-admin",
+		PoolKeyCollection: []cache.NamespaceName{poolKey}})
+	defer mcache.VsCacheMeta.AviCacheDelete(vsKey)
+	orphanPoolKey := cache.NamespaceName{Namespace: "my-tenant", Name: poolKey.Name}
+	mcache.PoolCache.AviCacheAdd(orphanPoolKey, &cache.AviPoolCache{Name: orphanPoolKey.Name, Tenant: "my-tenant", Uuid: "pool-tenant-orphan"})
+	defer mcache.PoolCache.AviCacheDelete(orphanPoolKey)
+	orphanVSKey := cache.NamespaceName{Namespace: "my-tenant", Name: "cluster--tenant-orphan-vs"}
+	mcache.VsCacheMeta.AviCacheAdd(orphanVSKey, &cache.AviVsCache{Name: orphanVSKey.Name, Tenant: "my-tenant", Uuid: "vs-tenant-orphan"})
+	defer mcache.VsCacheMeta.AviCacheDelete(orphanVSKey)
+	// the VS of the tenant AKO runs in is in use
+	modelName := "admin/" + vsKey.Name
+	aviModel := avinodes.NewAviObjectGraph()
+	aviModel.AddModelNode(&avinodes.AviVsNode{Name: vsKey.Name, Tenant: "admin",
+		PoolRefs: []*avinodes.AviPoolNode{{Name: poolKey.Name, Tenant: "admin"}}})
+	objects.SharedAviGraphLister().Save(modelName, aviModel)
+	defer objects.SharedAviGraphLister().Delete(modelName)
+
+	deletes := make(chan string, 10)
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && r.Header.Get("X-Avi-Tenant") == "my-tenant" {
+			deletes <- r.Header.Get("X-Avi-Tenant") + " " + r.URL.EscapedPath()
+		}
+		integrationtest.NormalControllerServer(w, r)
+	})
+	defer integrationtest.ResetMiddleware()
+	os.Setenv("ORPHAN_GC_GRACE_PERIOD", "0")
+	defer os.Setenv("ORPHAN_GC_GRACE_PERIOD", "")
+
+	ctrl.OrphanGC()
+	for _, orphan := range apimodels.OrphanGCStatus.GetOrphanedObjects() {
+		g.Expect(orphan.Name).NotTo(gomega.Equal(vsKey.Name))
+		if orphan.Name == poolKey.Name {
+			g.Expect(orphan.Tenant).To(gomega.Equal("my-tenant"))
+		}
+	}
+	var deleted []string
+	g.Eventually(func() []string {
+		select {
+		case path := <-deletes:
+			deleted = append(deleted, path)
+		default:
+		}
+		return deleted
+	}, 10*time.Second).Should(gomega.ConsistOf(
+		gomega.HavePrefix("my-tenant //api/pool/pool-tenant-orphan"),
+		gomega.HavePrefix("my-tenant //api/virtualservice/vs-tenant-orphan")))
+	g.Eventually(func() bool {
+		_, found := mcache.PoolCache.AviCacheGet(orphanPoolKey)
+		return found
+	}, 10*time.Second).Should(gomega.Equal(false))
+	_, found := mcache.PoolCache.AviCacheGet(poolKey)
+	g.Expect(found).To(gomega.Equal(true))
+}
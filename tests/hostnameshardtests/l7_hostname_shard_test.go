@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
 	crdfake "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/client/clientset/versioned/fake"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/k8s"
@@ -50,6 +51,13 @@ func TestMain(m *testing.M) {
 	CRDClient = crdfake.NewSimpleClientset()
 	lib.SetCRDClientset(CRDClient)
 
+	// the api server serves the HostnameClaim CRD
+	KubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: akov1alpha1.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "hostnameclaims"}},
+	}}
+	lib.SetCRDsEnabled(KubeClient)
+
 	registeredInformers := []string{
 		utils.ServiceInformer,
 		utils.EndpointInformer,
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package hostnameshardtests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/k8s"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
+
+	utils "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getModelPoolCount(modelName string) int {
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return 0
+	}
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	if len(nodes) == 0 {
+		return 0
+	}
+	return len(nodes[0].PoolRefs)
+}
+
+func isModelFound(modelName string) bool {
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	return found && aviModel != nil
+}
+
+func getLBServiceStatusLen() int {
+	svc, _ := KubeClient.CoreV1().Services("default").Get("testsvc", metav1.GetOptions{})
+	return len(svc.Status.LoadBalancer.Ingress)
+}
+
+func setNamespaceLabels(t *testing.T, nsLabels map[string]string) {
+	nsObj, err := KubeClient.CoreV1().Namespaces().Get("default", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in getting Namespace: %v", err)
+	}
+	nsObj.Labels = nsLabels
+	nsObj.ResourceVersion = "2"
+	if _, err := KubeClient.CoreV1().Namespaces().Update(nsObj); err != nil {
+		t.Fatalf("error in updating Namespace: %v", err)
+	}
+}
+
+// TestNamespaceSelector checks that the ingresses and services of type loadbalancer of a namespace are built
+// once its labels match NAMESPACE_SELECTOR, and removed when they stop matching.
+func TestNamespaceSelector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	os.Setenv(lib.NAMESPACE_SELECTOR, "ingress=avi")
+	defer os.Setenv(lib.NAMESPACE_SELECTOR, "")
+	utils.GetInformers().NSInformer.Informer().AddEventHandler(k8s.AddNamespaceEventHandler(2, ctrl))
+	nsObj := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "default",
+			ResourceVersion: "1",
+		},
+	}
+	if _, err := KubeClient.CoreV1().Namespaces().Create(nsObj); err != nil {
+		t.Fatalf("error in adding Namespace: %v", err)
+	}
+	defer KubeClient.CoreV1().Namespaces().Delete("default", nil)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	lbModelName := "admin/cluster--default-testsvc"
+	SetUpTestForIngress(t, modelName)
+	objects.SharedAviGraphLister().Delete(lbModelName)
+	integrationtest.CreateSVC(t, "default", "testsvc", corev1.ServiceTypeLoadBalancer, false)
+	integrationtest.CreateEP(t, "default", "testsvc", false, false, "1.1.1")
+	ingrFake := (integrationtest.FakeIngress{
+		Name:        "foo-with-targets",
+		Namespace:   "default",
+		DnsNames:    []string{"foo.com"},
+		Ips:         []string{"8.8.8.8"},
+		HostNames:   []string{"v1"},
+		Paths:       []string{"/foo"},
+		ServiceName: "avisvc",
+	}).Ingress()
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses("default").Create(ingrFake); err != nil {
+		t.Fatalf("error in adding Ingress: %v", err)
+	}
+	integrationtest.PollForCompletion(t, modelName, 5)
+	g.Consistently(func() int {
+		return getModelPoolCount(modelName)
+	}, 3*time.Second).Should(gomega.Equal(0))
+	g.Expect(isModelFound(lbModelName)).To(gomega.Equal(false))
+
+	setNamespaceLabels(t, map[string]string{"ingress": "avi"})
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+	g.Eventually(func() bool {
+		return isModelFound(lbModelName)
+	}, 10*time.Second).Should(gomega.Equal(true))
+	g.Eventually(func() string {
+		ingress, _ := KubeClient.ExtensionsV1beta1().Ingresses("default").Get("foo-with-targets", metav1.GetOptions{})
+		if len(ingress.Status.LoadBalancer.Ingress) != 1 {
+			return ""
+		}
+		return ingress.Status.LoadBalancer.Ingress[0].Hostname
+	}, 10*time.Second).Should(gomega.Equal("foo.com"))
+	g.Eventually(getLBServiceStatusLen, 10*time.Second).Should(gomega.Equal(1))
+
+	// the status written by AKO is reset along with the removal of the avi objects
+	setNamespaceLabels(t, map[string]string{"ingress": "other"})
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(0))
+	g.Eventually(func() bool {
+		return isModelFound(lbModelName)
+	}, 10*time.Second).Should(gomega.Equal(false))
+	g.Eventually(getIngressStatusLen, 10*time.Second).Should(gomega.Equal(0))
+	g.Eventually(getLBServiceStatusLen, 10*time.Second).Should(gomega.Equal(0))
+
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("default").Delete("foo-with-targets", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	integrationtest.DelSVC(t, "default", "testsvc")
+	integrationtest.DelEP(t, "default", "testsvc")
+	objects.SharedAviGraphLister().Delete(lbModelName)
+	TearDownTestForIngress(t, modelName)
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package hostnameshardtests
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	apimodels "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+func getSniParentName(sniKey cache.NamespaceName) string {
+	sniCache, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(sniKey)
+	if !found {
+		return ""
+	}
+	return sniCache.(*cache.AviVsCache).ParentVSRef.Name
+}
+
+func getShardVSName(shard uint32) string {
+	return fmt.Sprintf("cluster--Shared-L7-%d", shard)
+}
+
+func TestHostnameShardMigration(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	os.Setenv("PERSIST_SHARD_ASSIGNMENT", "true")
+	defer os.Setenv("PERSIST_SHARD_ASSIGNMENT", "")
+
+	// foo.com is placed on the shard it hashes to among the LARGE shards of SetUpTestForIngress, and moved to the
+	// next one
+	shardSize, _ := lib.GetShardSizeByName("LARGE")
+	oldVSName := getShardVSName(utils.RendezvousBkt("foo.com", shardSize))
+	newShard := (utils.RendezvousBkt("foo.com", shardSize) + 1) % shardSize
+	newVSName := getShardVSName(newShard)
+	oldModelName, newModelName := "admin/"+oldVSName, "admin/"+newVSName
+	SetUpIngressForCacheSyncCheck(t, oldModelName, true, true)
+
+	sniKey := cache.NamespaceName{Namespace: "admin", Name: "cluster--foo.com"}
+	g.Eventually(func() string {
+		return getSniParentName(sniKey)
+	}, 20*time.Second).Should(gomega.Equal(oldVSName))
+	sniCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(sniKey)
+	sniUuid := sniCache.(*cache.AviVsCache).Uuid
+
+	assignment := avinodes.SharedShardAssignment()
+	g.Expect(assignment.Migrate("foo.com", shardSize)).NotTo(gomega.BeNil())
+	g.Expect(assignment.Migrate("foo.com", utils.RendezvousBkt("foo.com", shardSize))).NotTo(gomega.BeNil())
+	g.Expect(assignment.Migrate("foo.com", newShard)).To(gomega.BeNil())
+	g.Expect(assignment.Migrate("foo.com", (newShard+1)%shardSize)).NotTo(gomega.BeNil())
+
+	g.Eventually(func() int {
+		assignment.Sync()
+		return len(apimodels.ShardStatus.GetShardMigrations())
+	}, 60*time.Second, time.Second).Should(gomega.Equal(0))
+
+	// the SNI child kept its uuid and moved under the new shard VS
+	g.Expect(getSniParentName(sniKey)).To(gomega.Equal(newVSName))
+	sniCache, _ = cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(sniKey)
+	g.Expect(sniCache.(*cache.AviVsCache).Uuid).To(gomega.Equal(sniUuid))
+	oldVsCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: oldVSName})
+	g.Expect(oldVsCache.(*cache.AviVsCache).SNIChildCollection).NotTo(gomega.ContainElement(sniUuid))
+	newVsCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: newVSName})
+	g.Expect(newVsCache.(*cache.AviVsCache).SNIChildCollection).To(gomega.ContainElement(sniUuid))
+
+	_, aviModel := objects.SharedAviGraphLister().Get(oldModelName)
+	oldNodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(oldNodes[0].SniNodes).To(gomega.HaveLen(0))
+	g.Expect(oldNodes[0].VSVIPRefs[0].FQDNs).NotTo(gomega.ContainElement("foo.com"))
+	_, aviModel = objects.SharedAviGraphLister().Get(newModelName)
+	newNodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(newNodes[0].SniNodes).To(gomega.HaveLen(1))
+	g.Expect(newNodes[0].SniNodes[0].VHDomainNames).To(gomega.ContainElement("foo.com"))
+	g.Expect(newNodes[0].VSVIPRefs[0].FQDNs).To(gomega.ContainElement("foo.com"))
+
+	// the host stays on its new shard
+	g.Expect(avinodes.DeriveHostNameShardVS("foo.com", "test")).To(gomega.Equal(newVSName))
+
+	TearDownIngressForCacheSyncCheck(t, oldModelName)
+	objects.SharedAviGraphLister().Delete(newModelName)
+	// the host is forgotten once no shard serves it anymore
+	assignment.Sync()
+	assignment.Sync()
+	os.Setenv("SHARD_VS_SIZE", "LARGE")
+	g.Expect(avinodes.DeriveHostNameShardVS("foo.com", "test")).To(gomega.Equal(getShardVSName(utils.RendezvousBkt("foo.com", 8))))
+	os.Setenv("SHARD_VS_SIZE", "")
+}
+
+// TestHostnameShardMigrationInsecure checks that an insecure host is built on the new shard before it is removed
+// from the old one, and that its pool is handed over to the new shard VS instead of being deleted.
+func TestHostnameShardMigrationInsecure(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	os.Setenv("PERSIST_SHARD_ASSIGNMENT", "true")
+	defer os.Setenv("PERSIST_SHARD_ASSIGNMENT", "")
+
+	// foo.com is placed on the shard it hashes to among the LARGE shards of SetUpTestForIngress, and moved to the
+	// next one
+	shardSize, _ := lib.GetShardSizeByName("LARGE")
+	oldVSName := getShardVSName(utils.RendezvousBkt("foo.com", shardSize))
+	newShard := (utils.RendezvousBkt("foo.com", shardSize) + 1) % shardSize
+	newVSName := getShardVSName(newShard)
+	oldModelName, newModelName := "admin/"+oldVSName, "admin/"+newVSName
+	SetUpIngressForCacheSyncCheck(t, oldModelName, false, false)
+
+	var poolName string
+	g.Eventually(func() string {
+		_, aviModel := objects.SharedAviGraphLister().Get(oldModelName)
+		if aviModel == nil {
+			return ""
+		}
+		for _, pool := range aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].PoolRefs {
+			if strings.HasPrefix(pool.PriorityLabel, "foo.com") {
+				poolName = pool.Name
+			}
+		}
+		return poolName
+	}, 20*time.Second).ShouldNot(gomega.BeEmpty())
+	poolKey := cache.NamespaceName{Namespace: "admin", Name: poolName}
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().PoolCache.AviCacheGet(poolKey)
+		return found
+	}, 20*time.Second).Should(gomega.BeTrue())
+
+	assignment := avinodes.SharedShardAssignment()
+	g.Expect(assignment.Migrate("foo.com", newShard)).To(gomega.BeNil())
+
+	// the old shard keeps serving the host while it is built on the new one
+	assignment.Sync()
+	_, aviModel := objects.SharedAviGraphLister().Get(oldModelName)
+	oldNodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(oldNodes[0].VSVIPRefs[0].FQDNs).To(gomega.ContainElement("foo.com"))
+	var oldPools []string
+	for _, pool := range oldNodes[0].PoolRefs {
+		oldPools = append(oldPools, pool.Name)
+	}
+	g.Expect(oldPools).To(gomega.ContainElement(poolName))
+
+	g.Eventually(func() int {
+		assignment.Sync()
+		return len(apimodels.ShardStatus.GetShardMigrations())
+	}, 60*time.Second, time.Second).Should(gomega.Equal(0))
+
+	_, aviModel = objects.SharedAviGraphLister().Get(oldModelName)
+	g.Expect(aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].VSVIPRefs[0].FQDNs).NotTo(gomega.ContainElement("foo.com"))
+	_, found := cache.SharedAviObjCache().PoolCache.AviCacheGet(poolKey)
+	g.Expect(found).To(gomega.BeTrue())
+	oldVsCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: oldVSName})
+	g.Expect(oldVsCache.(*cache.AviVsCache).PoolKeyCollection).NotTo(gomega.ContainElement(poolKey))
+	newVsCache, _ := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: newVSName})
+	g.Expect(newVsCache.(*cache.AviVsCache).PoolKeyCollection).To(gomega.ContainElement(poolKey))
+
+	TearDownIngressForCacheSyncCheck(t, oldModelName)
+	objects.SharedAviGraphLister().Delete(newModelName)
+	g.Eventually(func() string {
+		assignment.Sync()
+		return avinodes.DeriveHostNameShardVS("foo.com", "test")
+	}, 20*time.Second, time.Second).Should(gomega.Equal(getShardVSName(utils.RendezvousBkt("foo.com", lib.GetshardSize()))))
+}
+
+// TestShardPlacementOnResize checks that growing the shard size only moves the hosts placed on the added shards.
+func TestShardPlacementOnResize(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var moved int
+	for i := 0; i < 1000; i++ {
+		host := fmt.Sprintf("app-%d.com", i)
+		shard := utils.RendezvousBkt(host, 8)
+		if oldShard := utils.RendezvousBkt(host, 4); shard != oldShard {
+			g.Expect(shard).To(gomega.BeNumerically(">=", 4))
+			moved++
+		}
+	}
+	// about half the hosts go to the added shards
+	g.Expect(moved).To(gomega.BeNumerically("~", 500, 100))
+}
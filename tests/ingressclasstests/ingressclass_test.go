@@ -0,0 +1,571 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingressclasstests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/apis/ako/v1alpha1"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	crdfake "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/client/clientset/versioned/fake"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/k8s"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/tests/integrationtest"
+
+	utils "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var KubeClient *k8sfake.Clientset
+var CRDClient *crdfake.Clientset
+var DynamicClient *dynamicfake.FakeDynamicClient
+var ctrl *k8s.AviController
+
+func TestMain(m *testing.M) {
+	os.Setenv("INGRESS_API", "extensionv1")
+	os.Setenv("NETWORK_NAME", "net123")
+	os.Setenv("CLUSTER_NAME", "cluster")
+	os.Setenv("CLOUD_NAME", "CLOUD_VCENTER")
+	os.Setenv("SEG_NAME", "Default-Group")
+	os.Setenv("NODE_NETWORK_LIST", `[{"networkName":"net123","cidrs":["10.79.168.0/22"]}]`)
+	os.Setenv("SHARD_VS_SIZE", "LARGE")
+	os.Setenv("L7_SHARD_SCHEME", "hostname")
+
+	KubeClient = k8sfake.NewSimpleClientset()
+	CRDClient = crdfake.NewSimpleClientset()
+	DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	lib.SetCRDClientset(CRDClient)
+
+	// the api server serves the IngressClass resource and the AviInfraSetting CRD
+	KubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: lib.IngressClassGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: lib.IngressClassGVR.Resource}},
+	}, {
+		GroupVersion: akov1alpha1.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "aviinfrasettings"}},
+	}}
+	lib.SetIngressClassEnabled(KubeClient)
+	lib.SetCRDsEnabled(KubeClient)
+
+	registeredInformers := []string{
+		utils.ServiceInformer,
+		utils.EndpointInformer,
+		utils.IngressInformer,
+		utils.SecretInformer,
+		utils.NSInformer,
+		utils.NodeInformer,
+		utils.ConfigMapInformer,
+	}
+	utils.NewInformers(utils.KubeClientIntf{ClientSet: KubeClient}, registeredInformers)
+	informers := k8s.K8sinformers{Cs: KubeClient, DynamicClient: DynamicClient}
+	k8s.NewCRDInformers(CRDClient)
+
+	mcache := cache.SharedAviObjCache()
+	cloudObj := &cache.AviCloudPropertyCache{Name: "Default-Cloud", VType: "mock"}
+	subdomains := []string{"avi.internal", ".com"}
+	cloudObj.NSIpamDNS = subdomains
+	mcache.CloudKeyCache.AviCacheAdd("Default-Cloud", cloudObj)
+
+	integrationtest.InitializeFakeAKOAPIServer()
+
+	integrationtest.NewAviFakeClientInstance()
+	defer integrationtest.AviFakeClientInstance.Close()
+
+	ctrl = k8s.SharedAviController()
+	stopCh := utils.SetupSignalHandler()
+	ctrlCh := make(chan struct{})
+	quickSyncCh := make(chan struct{})
+	waitGroupMap := make(map[string]*sync.WaitGroup)
+	wgIngestion := &sync.WaitGroup{}
+	waitGroupMap["ingestion"] = wgIngestion
+	wgFastRetry := &sync.WaitGroup{}
+	waitGroupMap["fastretry"] = wgFastRetry
+	wgGraph := &sync.WaitGroup{}
+	waitGroupMap["graph"] = wgGraph
+	ctrl.HandleConfigMap(informers, ctrlCh, stopCh, quickSyncCh)
+	go ctrl.InitController(informers, registeredInformers, ctrlCh, stopCh, quickSyncCh, waitGroupMap)
+	AddConfigMap()
+	integrationtest.KubeClient = KubeClient
+	os.Exit(m.Run())
+}
+
+func AddConfigMap() {
+	aviCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "avi-system",
+			Name:      "avi-k8s-config",
+		},
+	}
+	KubeClient.CoreV1().ConfigMaps("avi-system").Create(aviCM)
+
+	integrationtest.PollForSyncStart(ctrl, 10)
+}
+
+func SetUpTestForIngress(t *testing.T, modelNames ...string) {
+	for _, modelName := range modelNames {
+		objects.SharedAviGraphLister().Delete(modelName)
+	}
+	integrationtest.CreateSVC(t, "default", "avisvc", corev1.ServiceTypeClusterIP, false)
+	integrationtest.CreateEP(t, "default", "avisvc", false, false, "1.1.1")
+}
+
+func TearDownTestForIngress(t *testing.T, modelNames ...string) {
+	for _, modelName := range modelNames {
+		objects.SharedAviGraphLister().Delete(modelName)
+	}
+	integrationtest.DelSVC(t, "default", "avisvc")
+	integrationtest.DelEP(t, "default", "avisvc")
+}
+
+func setupIngressClass(t *testing.T, name, controller, infraSetting string) {
+	spec := map[string]interface{}{
+		"controller": controller,
+	}
+	if infraSetting != "" {
+		spec["parameters"] = map[string]interface{}{
+			"apiGroup": akov1alpha1.SchemeGroupVersion.Group,
+			"kind":     lib.AviInfraSetting,
+			"name":     infraSetting,
+		}
+	}
+	ingClass := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": lib.IngressClassGVR.GroupVersion().String(),
+		"kind":       "IngressClass",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"spec": spec,
+	}}
+	if _, err := DynamicClient.Resource(lib.IngressClassGVR).Create(ingClass, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error in adding IngressClass: %v", err)
+	}
+}
+
+func teardownIngressClass(t *testing.T, name string) {
+	if err := DynamicClient.Resource(lib.IngressClassGVR).Delete(name, nil); err != nil {
+		t.Fatalf("error in deleting IngressClass: %v", err)
+	}
+}
+
+func setupAviInfraSetting(t *testing.T, name, tenant string) {
+	infraSetting := &akov1alpha1.AviInfraSetting{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: akov1alpha1.AviInfraSettingSpec{
+			SeGroup:    akov1alpha1.AviInfraSettingSeGroup{Name: "thisisaviref-seGroup"},
+			Network:    akov1alpha1.AviInfraSettingNetwork{Name: "thisisaviref-networkName"},
+			L7Settings: akov1alpha1.AviInfraSettingL7Settings{ShardSize: "SMALL"},
+			Tenant:     tenant,
+		},
+	}
+	if _, err := CRDClient.AkoV1alpha1().AviInfraSettings().Create(infraSetting); err != nil {
+		t.Fatalf("error in adding AviInfraSetting: %v", err)
+	}
+}
+
+func updateAviInfraSetting(t *testing.T, name string, update func(*akov1alpha1.AviInfraSettingSpec)) {
+	infraSetting, err := CRDClient.AkoV1alpha1().AviInfraSettings().Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in getting AviInfraSetting: %v", err)
+	}
+	update(&infraSetting.Spec)
+	if _, err := CRDClient.AkoV1alpha1().AviInfraSettings().Update(infraSetting); err != nil {
+		t.Fatalf("error in updating AviInfraSetting: %v", err)
+	}
+}
+
+func teardownAviInfraSetting(t *testing.T, name string) {
+	if err := CRDClient.AkoV1alpha1().AviInfraSettings().Delete(name, nil); err != nil {
+		t.Fatalf("error in deleting AviInfraSetting: %v", err)
+	}
+}
+
+// setupIngress adds the ingress, with the ingressClassName set on its copy served by the networking.k8s.io api
+func setupIngress(t *testing.T, ingClassName string, annotations map[string]string) {
+	ingrFake := (integrationtest.FakeIngress{
+		Name:        "foo-with-class",
+		Namespace:   "default",
+		DnsNames:    []string{"foo.com"},
+		Ips:         []string{"8.8.8.8"},
+		HostNames:   []string{"v1"},
+		Paths:       []string{"/foo"},
+		ServiceName: "avisvc",
+	}).Ingress()
+	ingrFake.Annotations = annotations
+	if ingClassName != "" {
+		ingress := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": utils.NetworkingIngress.GroupVersion().String(),
+			"kind":       "Ingress",
+			"metadata": map[string]interface{}{
+				"name":      ingrFake.Name,
+				"namespace": ingrFake.Namespace,
+			},
+			"spec": map[string]interface{}{
+				"ingressClassName": ingClassName,
+			},
+		}}
+		if _, err := DynamicClient.Resource(utils.NetworkingIngress).Namespace("default").Create(ingress, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error in adding the networking Ingress: %v", err)
+		}
+	}
+	if _, err := KubeClient.ExtensionsV1beta1().Ingresses("default").Create(ingrFake); err != nil {
+		t.Fatalf("error in adding Ingress: %v", err)
+	}
+}
+
+func teardownIngress(t *testing.T, ingClassName string) {
+	if err := KubeClient.ExtensionsV1beta1().Ingresses("default").Delete("foo-with-class", nil); err != nil {
+		t.Fatalf("Couldn't DELETE the Ingress %v", err)
+	}
+	if ingClassName != "" {
+		DynamicClient.Resource(utils.NetworkingIngress).Namespace("default").Delete("foo-with-class", nil)
+	}
+}
+
+// setupControllerRefs has the fake controller find the tenants among the given ones, and not find the SEGs and
+// networks named missing. It returns the tenants the objects created through it went to.
+func setupControllerRefs(tenants ...string) func() []string {
+	var lock sync.Mutex
+	var createdTenants []string
+	integrationtest.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		name := r.URL.Query().Get("name")
+		if r.Method == "GET" && (strings.Contains(url, "/api/tenant") || name == "missing") {
+			results := ""
+			if strings.Contains(url, "/api/tenant") && utils.HasElem(tenants, name) {
+				results = fmt.Sprintf(`{"name": "%s"}`, name)
+			}
+			w.WriteHeader(http.StatusOK)
+			if results == "" {
+				w.Write([]byte(`{"count": 0, "results": []}`))
+			} else {
+				w.Write([]byte(fmt.Sprintf(`{"count": 1, "results": [%s]}`, results)))
+			}
+			return
+		}
+		if r.Method == "POST" && !strings.Contains(url, "login") {
+			lock.Lock()
+			createdTenants = append(createdTenants, r.Header.Get("X-Avi-Tenant"))
+			lock.Unlock()
+		}
+		integrationtest.NormalControllerServer(w, r)
+	})
+	return func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string{}, createdTenants...)
+	}
+}
+
+func getModelPoolCount(modelName string) int {
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return 0
+	}
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	if len(nodes) == 0 {
+		return 0
+	}
+	return len(nodes[0].PoolRefs)
+}
+
+func TestIngressClassOfAnotherController(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	SetUpTestForIngress(t, modelName)
+	setupIngressClass(t, "other-lb", "example.com/other-lb", "")
+
+	setupIngress(t, "other-lb", nil)
+	g.Consistently(func() int {
+		return getModelPoolCount(modelName)
+	}, 5*time.Second).Should(gomega.Equal(0))
+
+	// the ingress is picked up once its IngressClass names AKO as its controller
+	teardownIngressClass(t, "other-lb")
+	setupIngressClass(t, "other-lb", lib.AviIngressController, "")
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+
+	teardownIngress(t, "other-lb")
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(0))
+	teardownIngressClass(t, "other-lb")
+	TearDownTestForIngress(t, modelName)
+}
+
+func TestIngressClassAnnotationWithoutIngressClass(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	SetUpTestForIngress(t, modelName)
+
+	// without an IngressClass object the ingress class annotation is honoured as before
+	setupIngress(t, "", map[string]string{lib.INGRESS_CLASS_ANNOT: "nginx"})
+	g.Consistently(func() int {
+		return getModelPoolCount(modelName)
+	}, 5*time.Second).Should(gomega.Equal(0))
+	teardownIngress(t, "")
+
+	setupIngress(t, "", map[string]string{lib.INGRESS_CLASS_ANNOT: lib.AVI_INGRESS_CLASS})
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+
+	teardownIngress(t, "")
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(0))
+	TearDownTestForIngress(t, modelName)
+}
+
+func TestDefaultIngressClassOfAnotherController(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-0"
+	SetUpTestForIngress(t, modelName)
+	setupIngressClass(t, "default-lb", "example.com/other-lb", "")
+	ingClass, _ := DynamicClient.Resource(lib.IngressClassGVR).Get("default-lb", metav1.GetOptions{})
+	ingClass.SetAnnotations(map[string]string{lib.DefaultIngressClassAnnotation: "true"})
+	DynamicClient.Resource(lib.IngressClassGVR).Update(ingClass, metav1.UpdateOptions{})
+
+	// an ingress without a class belongs to the default IngressClass
+	g.Eventually(func() bool {
+		_, found := lib.GetDefaultIngressClass()
+		return found
+	}, 10*time.Second).Should(gomega.Equal(true))
+	setupIngress(t, "", nil)
+	g.Consistently(func() int {
+		return getModelPoolCount(modelName)
+	}, 5*time.Second).Should(gomega.Equal(0))
+
+	teardownIngressClass(t, "default-lb")
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+
+	teardownIngress(t, "")
+	g.Eventually(func() int {
+		return getModelPoolCount(modelName)
+	}, 10*time.Second).Should(gomega.Equal(0))
+	TearDownTestForIngress(t, modelName)
+}
+
+func TestAviInfraSettingShardVS(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shardModelName := "admin/cluster--Shared-L7-0"
+	infraModelName := "admin/cluster--my-infrasetting-Shared-L7-0"
+	SetUpTestForIngress(t, shardModelName, infraModelName)
+	setupAviInfraSetting(t, "my-infrasetting", "")
+	setupIngressClass(t, "avi-lb", lib.AviIngressController, "my-infrasetting")
+
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Status
+	}, 10*time.Second).Should(gomega.Equal(lib.StatusAccepted))
+
+	// the host is built on the shard VS of the AviInfraSetting, with its SEG and VIP network
+	setupIngress(t, "avi-lb", nil)
+	g.Eventually(func() int {
+		return getModelPoolCount(infraModelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+	g.Expect(getModelPoolCount(shardModelName)).To(gomega.Equal(0))
+	g.Expect(avinodes.SharedInfraSettingStore().GetHostVSName("foo.com")).To(gomega.Equal("cluster--my-infrasetting-Shared-L7-0"))
+
+	_, aviModel := objects.SharedAviGraphLister().Get(infraModelName)
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].ServiceEngineGroup).To(gomega.Equal("thisisaviref-seGroup"))
+	g.Expect(nodes[0].VSVIPRefs).To(gomega.HaveLen(1))
+	g.Expect(nodes[0].VSVIPRefs[0].NetworkName).To(gomega.Equal("thisisaviref-networkName"))
+
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: "cluster--my-infrasetting-Shared-L7-0"})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(true))
+
+	// the shard VS of the AviInfraSetting is deleted once no host is built on it
+	teardownIngress(t, "avi-lb")
+	g.Eventually(func() bool {
+		avinodes.SharedShardAssignment().Sync()
+		found, aviModel := objects.SharedAviGraphLister().Get(infraModelName)
+		return found && aviModel != nil
+	}, 20*time.Second, time.Second).Should(gomega.Equal(false))
+	g.Expect(avinodes.SharedInfraSettingStore().GetHostVSName("foo.com")).To(gomega.Equal(""))
+
+	teardownIngressClass(t, "avi-lb")
+	teardownAviInfraSetting(t, "my-infrasetting")
+	TearDownTestForIngress(t, shardModelName, infraModelName)
+}
+
+func TestAviInfraSettingInvalidTenant(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shardModelName := "admin/cluster--Shared-L7-0"
+	infraModelName := "admin/cluster--my-infrasetting-Shared-L7-0"
+	setupControllerRefs("my-tenant")
+	defer integrationtest.ResetMiddleware()
+	SetUpTestForIngress(t, shardModelName, infraModelName)
+	setupAviInfraSetting(t, "my-infrasetting", "other-tenant")
+	setupIngressClass(t, "avi-lb", lib.AviIngressController, "my-infrasetting")
+
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Status
+	}, 10*time.Second).Should(gomega.Equal(lib.StatusRejected))
+	infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+	g.Expect(infraSetting.Status.Error).To(gomega.Equal(`tenant "other-tenant" not found on controller`))
+
+	// the host stays on its shard VS when the AviInfraSetting is rejected
+	setupIngress(t, "avi-lb", nil)
+	g.Eventually(func() int {
+		return getModelPoolCount(shardModelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+	found, aviModel := objects.SharedAviGraphLister().Get(infraModelName)
+	g.Expect(found && aviModel != nil).To(gomega.Equal(false))
+
+	teardownIngress(t, "avi-lb")
+	g.Eventually(func() int {
+		return getModelPoolCount(shardModelName)
+	}, 10*time.Second).Should(gomega.Equal(0))
+	teardownIngressClass(t, "avi-lb")
+	teardownAviInfraSetting(t, "my-infrasetting")
+	TearDownTestForIngress(t, shardModelName, infraModelName)
+}
+
+func TestAviInfraSettingSeGroupNotFound(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	setupControllerRefs()
+	defer integrationtest.ResetMiddleware()
+	setupAviInfraSetting(t, "my-infrasetting", "")
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Status
+	}, 10*time.Second).Should(gomega.Equal(lib.StatusAccepted))
+
+	// the SEG and the VIP network are looked up on the controller
+	updateAviInfraSetting(t, "my-infrasetting", func(spec *akov1alpha1.AviInfraSettingSpec) {
+		spec.SeGroup.Name = "missing"
+	})
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Error
+	}, 10*time.Second).Should(gomega.Equal(`serviceenginegroup "missing" not found on controller`))
+
+	updateAviInfraSetting(t, "my-infrasetting", func(spec *akov1alpha1.AviInfraSettingSpec) {
+		spec.SeGroup.Name = "thisisaviref-seGroup"
+		spec.Network.Name = "missing"
+	})
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Error
+	}, 10*time.Second).Should(gomega.Equal(`network "missing" not found on controller`))
+	g.Expect(CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})).
+		Should(gomega.WithTransform(func(infraSetting *akov1alpha1.AviInfraSetting) string {
+			return infraSetting.Status.Status
+		}, gomega.Equal(lib.StatusRejected)))
+
+	teardownAviInfraSetting(t, "my-infrasetting")
+}
+
+// TestAviInfraSettingTenant checks that the shard VS of an AviInfraSetting is created in its tenant, and that it
+// moves to the new tenant once the tenant of the AviInfraSetting changes.
+func TestAviInfraSettingTenant(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shardModelName := "admin/cluster--Shared-L7-0"
+	infraModelName := "admin/cluster--my-infrasetting-Shared-L7-0"
+	tenantModelName := "my-tenant/cluster--my-infrasetting-Shared-L7-0"
+	vsName := "cluster--my-infrasetting-Shared-L7-0"
+	createdTenants := setupControllerRefs("my-tenant")
+	defer integrationtest.ResetMiddleware()
+	SetUpTestForIngress(t, shardModelName, infraModelName, tenantModelName)
+	setupAviInfraSetting(t, "my-infrasetting", "")
+	setupIngressClass(t, "avi-lb", lib.AviIngressController, "my-infrasetting")
+	g.Eventually(func() string {
+		infraSetting, _ := CRDClient.AkoV1alpha1().AviInfraSettings().Get("my-infrasetting", metav1.GetOptions{})
+		return infraSetting.Status.Status
+	}, 10*time.Second).Should(gomega.Equal(lib.StatusAccepted))
+
+	setupIngress(t, "avi-lb", nil)
+	g.Eventually(func() int {
+		return getModelPoolCount(infraModelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: vsName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(true))
+
+	// the VS is deleted from the old tenant, the host is built on the VS in the new one
+	updateAviInfraSetting(t, "my-infrasetting", func(spec *akov1alpha1.AviInfraSettingSpec) {
+		spec.Tenant = "my-tenant"
+	})
+	g.Eventually(func() int {
+		return getModelPoolCount(tenantModelName)
+	}, 10*time.Second).Should(gomega.Equal(1))
+	found, aviModel := objects.SharedAviGraphLister().Get(infraModelName)
+	g.Expect(found && aviModel != nil).To(gomega.Equal(false))
+	g.Expect(avinodes.SharedInfraSettingStore().GetVSTenant(vsName)).To(gomega.Equal("my-tenant"))
+
+	_, aviModel = objects.SharedAviGraphLister().Get(tenantModelName)
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].Tenant).To(gomega.Equal("my-tenant"))
+	g.Expect(nodes[0].PoolRefs[0].Tenant).To(gomega.Equal("my-tenant"))
+	g.Expect(nodes[0].VSVIPRefs[0].Tenant).To(gomega.Equal("my-tenant"))
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "my-tenant", Name: vsName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(true))
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "admin", Name: vsName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(false))
+	g.Expect(createdTenants()).To(gomega.ContainElement("my-tenant"))
+
+	// the VS is deleted from its tenant once no host is built on it
+	teardownIngress(t, "avi-lb")
+	g.Eventually(func() bool {
+		avinodes.SharedShardAssignment().Sync()
+		found, aviModel := objects.SharedAviGraphLister().Get(tenantModelName)
+		return found && aviModel != nil
+	}, 20*time.Second, time.Second).Should(gomega.Equal(false))
+	g.Eventually(func() bool {
+		_, found := cache.SharedAviObjCache().VsCacheMeta.AviCacheGet(cache.NamespaceName{Namespace: "my-tenant", Name: vsName})
+		return found
+	}, 20*time.Second).Should(gomega.Equal(false))
+
+	teardownIngressClass(t, "avi-lb")
+	teardownAviInfraSetting(t, "my-infrasetting")
+	TearDownTestForIngress(t, shardModelName, infraModelName, tenantModelName)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+var auditLogPath string
+var auditWebhookRecords []utils.AuditRecord
+var auditWebhookLock sync.Mutex
+
+// setUpAuditLog has the controller operations audited to a temporary file and a fake webhook, until the returned
+// function is called.
+func setUpAuditLog() func() {
+	auditDir, _ := ioutil.TempDir("", "ako-audit")
+	auditLogPath = filepath.Join(auditDir, "avi-audit.log")
+	auditWebhookRecords = nil
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var records []utils.AuditRecord
+		json.NewDecoder(r.Body).Decode(&records)
+		auditWebhookLock.Lock()
+		auditWebhookRecords = append(auditWebhookRecords, records...)
+		auditWebhookLock.Unlock()
+	}))
+	os.Setenv("AUDIT_LOG_FILE_NAME", auditLogPath)
+	os.Setenv("AUDIT_WEBHOOK_URL", webhook.URL)
+	// the audit log is set up again from the environment for the next operation
+	utils.CloseAuditLog()
+	return func() {
+		os.Unsetenv("AUDIT_LOG_FILE_NAME")
+		os.Unsetenv("AUDIT_WEBHOOK_URL")
+		utils.CloseAuditLog()
+		webhook.Close()
+		os.RemoveAll(auditDir)
+	}
+}
+
+func getAuditLogRecords() []utils.AuditRecord {
+	var records []utils.AuditRecord
+	file, err := os.Open(auditLogPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record utils.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func getAuditWebhookRecords() []utils.AuditRecord {
+	auditWebhookLock.Lock()
+	defer auditWebhookLock.Unlock()
+	return append([]utils.AuditRecord{}, auditWebhookRecords...)
+}
+
+// findAuditRecord returns the successful record of the operation triggered by the key on an object of the type
+func findAuditRecord(records []utils.AuditRecord, key, method, objType string) *utils.AuditRecord {
+	for i, record := range records {
+		if record.Method == method && record.ObjectType == objType && record.Result == utils.AuditResultSuccess &&
+			utils.HasElem(record.Keys, key) {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// TestAuditLog checks that the creates and updates of the objects of an ingress are audited to the file and the
+// webhook, along with the keys which triggered them and the uuid of the object.
+func TestAuditLog(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-6"
+	ingKey := "Ingress/default/foo-with-targets"
+	defer setUpAuditLog()()
+	SetUpIngressForCacheSyncCheck(t, modelName, false, false)
+
+	var created *utils.AuditRecord
+	g.Eventually(func() *utils.AuditRecord {
+		created = findAuditRecord(getAuditLogRecords(), ingKey, string(utils.RestPost), "pool")
+		return created
+	}, 10*time.Second).ShouldNot(gomega.BeNil())
+	g.Expect(created.Tenant).To(gomega.Equal("admin"))
+	g.Expect(created.Model).To(gomega.Equal(modelName))
+	g.Expect(created.ObjectName).To(gomega.ContainSubstring("foo-with-targets"))
+	g.Expect(created.UUID).NotTo(gomega.BeEmpty())
+	g.Expect(created.LatencyMs).To(gomega.BeNumerically(">=", 0))
+
+	// the update of the pool servers is audited along with the endpoints key
+	DelEP(t, "default", "avisvc")
+	CreateEP(t, "default", "avisvc", false, false, "1.1.2")
+	g.Eventually(func() *utils.AuditRecord {
+		return findAuditRecord(getAuditLogRecords(), "Endpoints/default/avisvc", string(utils.RestPut), "pool")
+	}, 10*time.Second).ShouldNot(gomega.BeNil())
+
+	g.Eventually(func() *utils.AuditRecord {
+		return findAuditRecord(getAuditWebhookRecords(), ingKey, string(utils.RestPost), "pool")
+	}, 15*time.Second).ShouldNot(gomega.BeNil())
+
+	TearDownIngressForCacheSyncCheck(t, modelName, g)
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/k8s"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newLoginRecordingController returns a controller which records the credentials of the logins made to it
+func newLoginRecordingController(logins *[]map[string]string, lock *sync.Mutex) (*httptest.Server, string) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.EscapedPath(), "login") {
+			data, _ := ioutil.ReadAll(r.Body)
+			var cred map[string]string
+			json.Unmarshal(data, &cred)
+			lock.Lock()
+			*logins = append(*logins, cred)
+			lock.Unlock()
+		}
+		NormalControllerServer(w, r)
+	}))
+	return ts, strings.TrimPrefix(ts.URL, "https://")
+}
+
+func newSelfSignedCert(g *gomega.WithT) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).To(gomega.BeNil())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).To(gomega.BeNil())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestControllerCredentialsRotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var logins []map[string]string
+	var lock sync.Mutex
+	ts, endpoint := newLoginRecordingController(&logins, &lock)
+	defer ts.Close()
+
+	aviRestPoolClient, err := utils.NewAviRestClientPoolWithCredentials(2, endpoint, utils.AviControllerCredentials{
+		Username: "admin",
+		Password: "password1",
+	})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(logins).To(gomega.HaveLen(2))
+	g.Expect(logins[0]).To(gomega.Equal(map[string]string{"username": "admin", "password": "password1"}))
+
+	// the clients log in again with the new token, each right before its next calls
+	aviRestPoolClient.UpdateCredentials(utils.AviControllerCredentials{Username: "admin", AuthToken: "token1"})
+	for _, client := range aviRestPoolClient.AviClient {
+		err = aviRestPoolClient.AviRestOperate(client, macroTestRestOps())
+		g.Expect(err).To(gomega.BeNil())
+	}
+	g.Expect(logins).To(gomega.HaveLen(4))
+	g.Expect(logins[2]).To(gomega.Equal(map[string]string{"username": "admin", "token": "token1"}))
+	g.Expect(logins[3]).To(gomega.Equal(map[string]string{"username": "admin", "token": "token1"}))
+
+	// the clients stay logged in as long as the credentials do not change
+	err = aviRestPoolClient.AviRestOperate(aviRestPoolClient.AviClient[0], macroTestRestOps())
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(logins).To(gomega.HaveLen(4))
+}
+
+func TestControllerCredentialsCACert(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var logins []map[string]string
+	var lock sync.Mutex
+	ts, endpoint := newLoginRecordingController(&logins, &lock)
+	defer ts.Close()
+
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	otherCACert := newSelfSignedCert(g)
+
+	_, err := utils.NewAviRestClientPoolWithCredentials(1, endpoint, utils.AviControllerCredentials{
+		Username: "admin",
+		Password: "admin",
+		CACert:   caCert,
+	})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(logins).To(gomega.HaveLen(1))
+
+	// the controller certificate is not signed by the given CA
+	_, err = utils.NewAviRestClientPoolWithCredentials(1, endpoint, utils.AviControllerCredentials{
+		Username: "admin",
+		Password: "admin",
+		CACert:   otherCACert,
+	})
+	g.Expect(err).NotTo(gomega.BeNil())
+	g.Expect(logins).To(gomega.HaveLen(1))
+
+	_, err = utils.NewAviRestClientPoolWithCredentials(1, endpoint, utils.AviControllerCredentials{
+		Username: "admin",
+		Password: "admin",
+		CACert:   "not a certificate",
+	})
+	g.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestControllerCredentialsSecret(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var logins []map[string]string
+	var lock sync.Mutex
+	AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.EscapedPath(), "login") {
+			data, _ := ioutil.ReadAll(r.Body)
+			var cred map[string]string
+			json.Unmarshal(data, &cred)
+			lock.Lock()
+			logins = append(logins, cred)
+			lock.Unlock()
+		}
+		NormalControllerServer(w, r)
+	})
+	defer ResetMiddleware()
+
+	os.Setenv("CTRL_SECRET", "avi-secret")
+	defer os.Unsetenv("CTRL_SECRET")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "avi-secret", Namespace: lib.AviNS, ResourceVersion: "1"},
+		Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("password1")},
+	}
+	_, err := KubeClient.CoreV1().Secrets(lib.AviNS).Create(secret)
+	g.Expect(err).To(gomega.BeNil())
+	defer KubeClient.CoreV1().Secrets(lib.AviNS).Delete("avi-secret", nil)
+
+	g.Expect(k8s.LoadControllerCredentials(KubeClient)).To(gomega.BeNil())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	k8s.WatchControllerCredentials(KubeClient, stopCh)
+
+	// rotating the password in the secret has the clients log in again with it
+	secret.Data["password"] = []byte("password2")
+	secret.ResourceVersion = "2"
+	_, err = KubeClient.CoreV1().Secrets(lib.AviNS).Update(secret)
+	g.Expect(err).To(gomega.BeNil())
+	aviRestPoolClient := cache.SharedAVIClients()
+	g.Eventually(func() []map[string]string {
+		aviRestPoolClient.AviRestOperate(aviRestPoolClient.AviClient[0], macroTestRestOps())
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]map[string]string{}, logins...)
+	}, 10*time.Second).Should(gomega.ContainElement(map[string]string{"username": "admin", "password": "password2"}))
+
+	// a secret without a password or token is ignored
+	delete(secret.Data, "password")
+	secret.ResourceVersion = "3"
+	_, err = KubeClient.CoreV1().Secrets(lib.AviNS).Update(secret)
+	g.Expect(err).To(gomega.BeNil())
+	time.Sleep(500 * time.Millisecond)
+	g.Expect(cache.SetControllerCredentials(utils.AviControllerCredentials{Username: "admin", Password: "password2"})).To(gomega.Equal(false))
+
+	// back to the credentials the other tests log in with
+	credentials := utils.AviControllerCredentials{Username: "admin", Password: "admin"}
+	cache.SetControllerCredentials(credentials)
+	aviRestPoolClient.UpdateCredentials(credentials)
+}
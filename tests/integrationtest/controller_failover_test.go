@@ -0,0 +1,163 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+// newFakeControllerNode returns a controller node which answers like the fake controller while up, and with
+// 503 once down. The calls other than the cluster status checks are counted in calls.
+func newFakeControllerNode(down *int32, calls *int32) (*httptest.Server, string) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.LoadInt32(down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !strings.Contains(r.URL.EscapedPath(), "cluster/status") {
+			atomic.AddInt32(calls, 1)
+		}
+		NormalControllerServer(w, r)
+	}))
+	return ts, strings.TrimPrefix(ts.URL, "https://")
+}
+
+func TestControllerEndpointFailover(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var node1Down, node2Down, node1Calls, node2Calls int32
+	node1, node1Ep := newFakeControllerNode(&node1Down, &node1Calls)
+	defer node1.Close()
+	node2, node2Ep := newFakeControllerNode(&node2Down, &node2Calls)
+	defer node2.Close()
+
+	aviRestPoolClient, err := utils.NewAviRestClientPool(1, node1Ep+", "+node2Ep, "admin", "admin")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(aviRestPoolClient.Endpoints.Endpoints()).To(gomega.Equal([]string{node1Ep, node2Ep}))
+	g.Expect(aviRestPoolClient.Endpoints.Active()).To(gomega.Equal(node1Ep))
+
+	restOps := macroTestRestOps()
+	err = aviRestPoolClient.AviRestOperate(aviRestPoolClient.AviClient[0], restOps)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(atomic.LoadInt32(&node1Calls)).To(gomega.BeNumerically(">", 0))
+	g.Expect(atomic.LoadInt32(&node2Calls)).To(gomega.Equal(int32(0)))
+
+	// node1 goes down, the health check moves the clients over to node2
+	atomic.StoreInt32(&node1Down, 1)
+	g.Expect(aviRestPoolClient.Endpoints.CheckEndpoints()).To(gomega.Equal(node2Ep))
+	active, statuses := aviRestPoolClient.Endpoints.GetStatus()
+	g.Expect(active).To(gomega.Equal(node2Ep))
+	g.Expect(statuses).To(gomega.HaveLen(2))
+	g.Expect(statuses[0].Healthy).To(gomega.Equal(false))
+	g.Expect(statuses[0].Error).To(gomega.ContainSubstring("503"))
+	g.Expect(statuses[1].Healthy).To(gomega.Equal(true))
+
+	// the client logs in to node2 before its next calls, and takes the slot of the one logged in to node1
+	node1Client := aviRestPoolClient.GetClient(0)
+	restOps = macroTestRestOps()
+	err = aviRestPoolClient.AviRestOperate(node1Client, restOps)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(atomic.LoadInt32(&node2Calls)).To(gomega.BeNumerically(">", len(restOps)))
+	g.Expect(aviRestPoolClient.GetClient(0)).NotTo(gomega.BeIdenticalTo(node1Client))
+
+	// node1 coming back does not move the clients back, as long as node2 stays up
+	atomic.StoreInt32(&node1Down, 0)
+	g.Expect(aviRestPoolClient.Endpoints.CheckEndpoints()).To(gomega.Equal(node2Ep))
+}
+
+func TestControllerEndpointFailoverOnError(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var node1Down, node2Down, node1Calls, node2Calls int32
+	node1, node1Ep := newFakeControllerNode(&node1Down, &node1Calls)
+	node2, node2Ep := newFakeControllerNode(&node2Down, &node2Calls)
+	defer node2.Close()
+
+	aviRestPoolClient, err := utils.NewAviRestClientPool(1, node1Ep+","+node2Ep, "admin", "admin")
+	g.Expect(err).To(gomega.BeNil())
+
+	// node1 is gone, the failed call has the endpoints checked in the background so that its retry goes to node2
+	node1.Close()
+	restOps := []*utils.RestOp{{
+		Path:    "/api/pool/pool-macro-stale",
+		Method:  utils.RestDelete,
+		Tenant:  "admin",
+		Model:   "Pool",
+		Version: utils.CtrlVersion,
+	}}
+	err = aviRestPoolClient.AviRestOperate(aviRestPoolClient.AviClient[0], restOps)
+	g.Expect(err).NotTo(gomega.BeNil())
+	g.Eventually(aviRestPoolClient.Endpoints.Active, 10*time.Second).Should(gomega.Equal(node2Ep))
+
+	restOps[0].Err = nil
+	err = aviRestPoolClient.AviRestOperate(aviRestPoolClient.GetClient(0), restOps)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(atomic.LoadInt32(&node2Calls)).To(gomega.BeNumerically(">", 0))
+}
+
+func TestDedicatedClientFailover(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var node1Down, node2Down, node2Calls, node1Logouts int32
+	node1 := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.LoadInt32(&node1Down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if strings.HasSuffix(r.URL.EscapedPath(), "logout") {
+			atomic.AddInt32(&node1Logouts, 1)
+		}
+		NormalControllerServer(w, r)
+	}))
+	defer node1.Close()
+	node1Ep := strings.TrimPrefix(node1.URL, "https://")
+	node2, node2Ep := newFakeControllerNode(&node2Down, &node2Calls)
+	defer node2.Close()
+
+	aviRestPoolClient, err := utils.NewAviRestClientPool(1, node1Ep+","+node2Ep, "admin", "admin")
+	g.Expect(err).To(gomega.BeNil())
+
+	// the dedicated client is logged in once, and reused on the next calls
+	node1Client, err := aviRestPoolClient.DedicatedClient("test")
+	g.Expect(err).To(gomega.BeNil())
+	client, err := aviRestPoolClient.DedicatedClient("test")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(client).To(gomega.BeIdenticalTo(node1Client))
+	g.Expect(client).NotTo(gomega.BeIdenticalTo(aviRestPoolClient.GetClient(0)))
+
+	// after the failover to node2 the dedicated client is logged in to node2, and its session on node1 logged out
+	atomic.StoreInt32(&node1Down, 1)
+	g.Expect(aviRestPoolClient.Endpoints.CheckEndpoints()).To(gomega.Equal(node2Ep))
+	atomic.StoreInt32(&node1Down, 0)
+	node2Client, err := aviRestPoolClient.DedicatedClient("test")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(node2Client).NotTo(gomega.BeIdenticalTo(node1Client))
+	g.Expect(atomic.LoadInt32(&node2Calls)).To(gomega.BeNumerically(">", 0))
+	g.Eventually(func() int32 { return atomic.LoadInt32(&node1Logouts) }, 5*time.Second).Should(gomega.Equal(int32(1)))
+	client, err = aviRestPoolClient.DedicatedClient("test")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(client).To(gomega.BeIdenticalTo(node2Client))
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+// TestCorrelationIDPropagation checks that the correlation id of a key is passed on to the model it is published
+// to, and that it is cleared once the model is processed.
+func TestCorrelationIDPropagation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	key := "Ingress/" + NAMESPACE + "/correlation-ing"
+	modelName := "admin/cluster--correlation-model"
+	correlationID := utils.NewCorrelationID()
+	g.Expect(correlationID).To(gomega.HaveLen(16))
+	utils.SetCorrelationID(key, correlationID)
+
+	queue := utils.NewWorkQueue(1, "CorrelationTestLayer")
+	nodes.PublishKeyToRestLayer(modelName, key, queue)
+	g.Expect(utils.GetCorrelationID(modelName)).To(gomega.Equal(correlationID))
+
+	processed := make(chan string, 1)
+	queue.SyncFunc = func(modelKey string, wg *sync.WaitGroup) error {
+		processed <- utils.GetCorrelationID(modelKey)
+		return nil
+	}
+	stopCh := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	queue.Run(stopCh, wg)
+	defer queue.StopWorkers(stopCh)
+	g.Eventually(processed, 10*time.Second).Should(gomega.Receive(gomega.Equal(correlationID)))
+	g.Eventually(func() string {
+		return utils.GetCorrelationID(modelName)
+	}, 10*time.Second).Should(gomega.Equal(""))
+
+	// the correlation id of the ingestion key is left to its own worker
+	g.Expect(utils.GetCorrelationID(key)).To(gomega.Equal(correlationID))
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getDiagnostics(router *mux.Router, path, token string) *httptest.ResponseRecorder {
+	return requestWithToken(router, "GET", path, token)
+}
+
+func requestWithToken(router *mux.Router, method, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func setEnableDiagnostics(t *testing.T, value, resourceVersion string) {
+	aviCM, err := KubeClient.CoreV1().ConfigMaps(lib.AviNS).Get("avi-k8s-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in getting the configmap: %v", err)
+	}
+	if aviCM.Data == nil {
+		aviCM.Data = make(map[string]string)
+	}
+	aviCM.Data[lib.ENABLE_DIAGNOSTICS] = value
+	aviCM.ResourceVersion = resourceVersion
+	if _, err := KubeClient.CoreV1().ConfigMaps(lib.AviNS).Update(aviCM); err != nil {
+		t.Fatalf("error in updating the configmap: %v", err)
+	}
+}
+
+// TestDiagnosticsEndpoints checks that the diagnostics endpoints are served once enabled in the configmap, only to
+// the requests bearing the diagnostics token.
+func TestDiagnosticsEndpoints(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	router := (&api.ApiServer{Models: []models.ApiModel{models.DiagnosticsStatus}}).SetRouter()
+	models.DiagnosticsStatus.SetToken("diagnostics-token")
+	defer models.DiagnosticsStatus.SetToken("")
+	g.Expect(getDiagnostics(router, "/api/diagnostics", "diagnostics-token").Code).To(gomega.Equal(http.StatusNotFound))
+
+	setEnableDiagnostics(t, "true", "diag-1")
+	defer setEnableDiagnostics(t, "false", "diag-2")
+	g.Eventually(func() int {
+		return getDiagnostics(router, "/api/diagnostics", "diagnostics-token").Code
+	}, 10*time.Second).Should(gomega.Equal(http.StatusOK))
+	g.Expect(getDiagnostics(router, "/api/diagnostics", "").Code).To(gomega.Equal(http.StatusUnauthorized))
+	g.Expect(getDiagnostics(router, "/api/diagnostics", "other-token").Code).To(gomega.Equal(http.StatusUnauthorized))
+
+	var report models.DiagnosticsReport
+	w := getDiagnostics(router, "/api/diagnostics?stacks=true", "diagnostics-token")
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(gomega.BeNil())
+	g.Expect(report.Goroutines).To(gomega.BeNumerically(">", 0))
+	g.Expect(report.Stacks).To(gomega.ContainSubstring("goroutine"))
+	var queues []string
+	for _, queue := range report.Queues {
+		queues = append(queues, queue.Name)
+		if queue.Name == utils.GraphLayer {
+			g.Expect(queue.Workers).To(gomega.HaveLen(len(queue.QueueLengths)))
+		}
+	}
+	g.Expect(queues).To(gomega.ContainElement(utils.ObjectIngestionLayer))
+	g.Expect(queues).To(gomega.ContainElement(utils.GraphLayer))
+
+	w = getDiagnostics(router, "/debug/pprof/goroutine?debug=1", "diagnostics-token")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Body.String()).To(gomega.ContainSubstring("goroutine profile"))
+	g.Expect(getDiagnostics(router, "/debug/pprof/cmdline", "diagnostics-token").Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(getDiagnostics(router, "/debug/pprof/goroutine", "").Code).To(gomega.Equal(http.StatusUnauthorized))
+}
+
+// TestShardMigrateToken checks that the hosts are moved between shards only on the requests bearing the
+// diagnostics token, whether or not the diagnostics endpoints are enabled.
+func TestShardMigrateToken(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var migrated []string
+	models.ShardStatus.SetMigrateFunc(func(host string, shard uint32) error {
+		migrated = append(migrated, host)
+		return nil
+	})
+	defer models.ShardStatus.SetMigrateFunc(nil)
+	router := (&api.ApiServer{Models: []models.ApiModel{models.ShardStatus}}).SetRouter()
+	path := "/api/shards/migrate?host=foo.com&shard=1"
+
+	g.Expect(requestWithToken(router, "POST", path, "").Code).To(gomega.Equal(http.StatusUnauthorized))
+	models.DiagnosticsStatus.SetToken("diagnostics-token")
+	defer models.DiagnosticsStatus.SetToken("")
+	g.Expect(requestWithToken(router, "POST", path, "").Code).To(gomega.Equal(http.StatusUnauthorized))
+	g.Expect(requestWithToken(router, "POST", path, "other-token").Code).To(gomega.Equal(http.StatusUnauthorized))
+	g.Expect(migrated).To(gomega.BeEmpty())
+
+	g.Expect(requestWithToken(router, "POST", path, "diagnostics-token").Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(migrated).To(gomega.Equal([]string{"foo.com"}))
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+
+	"github.com/gorilla/mux"
+	"github.com/onsi/gomega"
+)
+
+func getHealthReport(router *mux.Router, path string) (int, models.HealthReport) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	var report models.HealthReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return w.Code, report
+}
+
+// TestHealthProbes checks that AKO is ready once the informers are synced and the cache populated, and that
+// disabling the sync fails the readiness probe only.
+func TestHealthProbes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	router := (&api.ApiServer{Models: []models.ApiModel{models.HealthStatus}}).SetRouter()
+	g.Eventually(func() int {
+		code, _ := getHealthReport(router, "/readyz")
+		return code
+	}, 10*time.Second).Should(gomega.Equal(http.StatusOK))
+	_, report := getHealthReport(router, "/readyz")
+	g.Expect(report.Checks).To(gomega.HaveKeyWithValue("informers", "ok"))
+	g.Expect(report.Checks).To(gomega.HaveKeyWithValue("avi_cache", "ok"))
+	g.Expect(report.Checks).To(gomega.HaveKeyWithValue("workers", "ok"))
+
+	lib.SetDisableSync(true)
+	defer lib.SetDisableSync(false)
+	code, report := getHealthReport(router, "/readyz")
+	g.Expect(code).To(gomega.Equal(http.StatusServiceUnavailable))
+	g.Expect(report.Status).To(gomega.Equal("failed"))
+	g.Expect(report.Checks).To(gomega.HaveKeyWithValue("sync", "sync is disabled"))
+
+	code, report = getHealthReport(router, "/healthz")
+	g.Expect(code).To(gomega.Equal(http.StatusOK))
+	g.Expect(report.Checks).To(gomega.HaveKeyWithValue("workers", "ok"))
+	g.Expect(report.Checks).NotTo(gomega.HaveKey("sync"))
+}
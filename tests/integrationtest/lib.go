@@ -690,6 +690,16 @@ var FakeAviObjects = []string{
 	"serviceenginegroup",
 }
 
+// The objects populated in the cache at bootup, for which the tests have no mock data, are served as empty collections.
+var EmptyAviObjects = []string{
+	"applicationprofile",
+	"httppolicyset",
+	"l4policyset",
+	"pkiprofile",
+	"sslkeyandcertificate",
+	"vsvip",
+}
+
 type InjectFault func(w http.ResponseWriter, r *http.Request)
 
 func AddMiddleware(exec InjectFault) {
@@ -730,6 +740,61 @@ func NewAviFakeClientInstance(skipCachePopulation ...bool) {
 	}
 }
 
+// fakeMacroObject returns the object created by a macro, with the read only fields filled in
+func fakeMacroObject(macro map[string]interface{}) interface{} {
+	var vipAddress, shardVSNum string
+	addrPrefix := "10.250.250"
+	rData, rModelName := macro["data"].(map[string]interface{}), strings.ToLower(macro["model_name"].(string))
+	rName := rData["name"].(string)
+	// a macro updating an existing object carries its uuid
+	uuid, ok := rData["uuid"].(string)
+	if !ok {
+		uuid = fmt.Sprintf("%s-%s-%s", rModelName, rName, RANDOMUUID)
+	}
+	objURL := fmt.Sprintf("https://localhost/api/%s/%s#%s", rModelName, uuid, rName)
+
+	// adding additional 'uuid' and 'url' (read-only) fields in the response
+	rData["url"] = objURL
+	rData["uuid"] = uuid
+
+	if rModelName == "virtualservice" {
+		// handle sni child, fill in vs parent ref
+		if vsType := rData["type"]; vsType == "VS_TYPE_VH_CHILD" {
+			parentVSName := strings.Split(rData["vh_parent_vs_uuid"].(string), "name=")[1]
+			shardVSNum = strings.Split(parentVSName, "cluster--Shared-L7-")[1]
+
+			rData["vh_parent_vs_ref"] = fmt.Sprintf("https://localhost/api/virtualservice/virtualservice-%s-%s#%s", parentVSName, RANDOMUUID, parentVSName)
+			//rData["vsvip_ref"] = fmt.Sprintf("https://localhost/api/vsvip/vsvip-%s-%s#%s", parentVSName, RANDOMUUID, parentVSName)
+			vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
+
+		} else if strings.Contains(rName, "Shared-L7") {
+			shardVSNum = strings.Split(rName, "Shared-L7-")[1]
+			vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
+		} else {
+			vipAddress = "10.250.250.250"
+		}
+
+		// add vip for status update checks
+		// use vh_parent_vs_uuid for sniVS, and name for normal VSes
+
+		rData["vip"] = []interface{}{map[string]interface{}{"ip_address": map[string]string{"addr": vipAddress, "type": "V4"}}}
+		rData["vsvip_ref"] = fmt.Sprintf("https://localhost/api/vsvip/vsvip-%s-%s#%s", rName, RANDOMUUID, rName)
+	} else if rModelName == "vsvip" {
+		if vsType := rData["type"]; vsType == "VS_TYPE_VH_CHILD" {
+			parentVSName := strings.Split(rData["vh_parent_vs_uuid"].(string), "name=")[1]
+			shardVSNum = strings.Split(parentVSName, "cluster--Shared-L7-")[1]
+			vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
+		} else if strings.Contains(rName, "Shared-L7") {
+			shardVSNum = strings.Split(rName, "Shared-L7-")[1]
+			vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
+		} else {
+			vipAddress = "10.250.250.250"
+		}
+		rData["vip"] = []interface{}{map[string]interface{}{"ip_address": map[string]string{"addr": vipAddress, "type": "V4"}}}
+	}
+	return rData
+}
+
 func NormalControllerServer(w http.ResponseWriter, r *http.Request, args ...string) {
 	mockFilePath := defaultMockFilePath
 	if len(args) > 0 {
@@ -738,58 +803,21 @@ func NormalControllerServer(w http.ResponseWriter, r *http.Request, args ...stri
 	url := r.URL.EscapedPath()
 	var resp map[string]interface{}
 	var finalResponse []byte
-	var vipAddress, shardVSNum string
-	addrPrefix := "10.250.250"
 	object := strings.Split(strings.Trim(url, "/"), "/")
 
 	if strings.Contains(url, "macro") && r.Method == "POST" {
 		data, _ := ioutil.ReadAll(r.Body)
-		json.Unmarshal(data, &resp)
-		rData, rModelName := resp["data"].(map[string]interface{}), strings.ToLower(resp["model_name"].(string))
-		rName := rData["name"].(string)
-		objURL := fmt.Sprintf("https://localhost/api/%s/%s-%s-%s#%s", rModelName, rModelName, rName, RANDOMUUID, rName)
-
-		// adding additional 'uuid' and 'url' (read-only) fields in the response
-		rData["url"] = objURL
-		rData["uuid"] = fmt.Sprintf("%s-%s-%s", rModelName, rName, RANDOMUUID)
-
-		if rModelName == "virtualservice" {
-			// handle sni child, fill in vs parent ref
-			if vsType := rData["type"]; vsType == "VS_TYPE_VH_CHILD" {
-				parentVSName := strings.Split(rData["vh_parent_vs_uuid"].(string), "name=")[1]
-				shardVSNum = strings.Split(parentVSName, "cluster--Shared-L7-")[1]
-
-				rData["vh_parent_vs_ref"] = fmt.Sprintf("https://localhost/api/virtualservice/virtualservice-%s-%s#%s", parentVSName, RANDOMUUID, parentVSName)
-				//rData["vsvip_ref"] = fmt.Sprintf("https://localhost/api/vsvip/vsvip-%s-%s#%s", parentVSName, RANDOMUUID, parentVSName)
-				vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
-
-			} else if strings.Contains(rName, "Shared-L7") {
-				shardVSNum = strings.Split(rName, "Shared-L7-")[1]
-				vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
-			} else {
-				vipAddress = "10.250.250.250"
-			}
-
-			// add vip for status update checks
-			// use vh_parent_vs_uuid for sniVS, and name for normal VSes
-
-			rData["vip"] = []interface{}{map[string]interface{}{"ip_address": map[string]string{"addr": vipAddress, "type": "V4"}}}
-			rData["vsvip_ref"] = fmt.Sprintf("https://localhost/api/vsvip/vsvip-%s-%s#%s", rName, RANDOMUUID, rName)
-		} else if rModelName == "vsvip" {
-			if vsType := rData["type"]; vsType == "VS_TYPE_VH_CHILD" {
-				parentVSName := strings.Split(rData["vh_parent_vs_uuid"].(string), "name=")[1]
-				shardVSNum = strings.Split(parentVSName, "cluster--Shared-L7-")[1]
-				vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
-			} else if strings.Contains(rName, "Shared-L7") {
-				shardVSNum = strings.Split(rName, "Shared-L7-")[1]
-				vipAddress = fmt.Sprintf("%s.1%s", addrPrefix, shardVSNum)
-			} else {
-				vipAddress = "10.250.250.250"
-			}
-			rData["vip"] = []interface{}{map[string]interface{}{"ip_address": map[string]string{"addr": vipAddress, "type": "V4"}}}
+		// a batch of macros is answered with all the objects, in order
+		var macros []map[string]interface{}
+		if json.Unmarshal(data, &macros) != nil {
+			json.Unmarshal(data, &resp)
+			macros = []map[string]interface{}{resp}
 		}
-
-		finalResponse, _ = json.Marshal([]interface{}{resp["data"]})
+		var objs []interface{}
+		for _, macro := range macros {
+			objs = append(objs, fakeMacroObject(macro))
+		}
+		finalResponse, _ = json.Marshal(objs)
 		w.WriteHeader(http.StatusOK)
 		w.Write(finalResponse)
 
@@ -797,6 +825,11 @@ func NormalControllerServer(w http.ResponseWriter, r *http.Request, args ...stri
 		data, _ := ioutil.ReadAll(r.Body)
 		json.Unmarshal(data, &resp)
 		resp["uuid"] = strings.Split(strings.Trim(url, "/"), "/")[2]
+		// like the controller, an SNI child is answered with the ref of its parent
+		if parentUuid, ok := resp["vh_parent_vs_uuid"].(string); ok && strings.Contains(parentUuid, "name=") {
+			parentVSName := strings.Split(parentUuid, "name=")[1]
+			resp["vh_parent_vs_ref"] = fmt.Sprintf("https://localhost/api/virtualservice/virtualservice-%s-%s#%s", parentVSName, RANDOMUUID, parentVSName)
+		}
 		finalResponse, _ = json.Marshal(resp)
 		w.WriteHeader(http.StatusOK)
 		w.Write(finalResponse)
@@ -829,6 +862,10 @@ func NormalControllerServer(w http.ResponseWriter, r *http.Request, args ...stri
 	} else if r.Method == "GET" && inArray(FakeAviObjects, object[1]) {
 		FeedMockCollectionData(w, r, mockFilePath)
 
+	} else if r.Method == "GET" && inArray(EmptyAviObjects, object[1]) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"count": 0, "results": []}`))
+
 	} else if strings.Contains(url, "login") {
 		// This is used for /login --> first request to controller
 		w.WriteHeader(http.StatusOK)
@@ -854,7 +891,10 @@ func FeedMockCollectionData(w http.ResponseWriter, r *http.Request, mockFilePath
 	if r.Method == "GET" {
 		var data []byte
 		if len(splitURL) == 2 {
-			data, _ = ioutil.ReadFile(fmt.Sprintf("%s/%s_mock.json", mockFilePath, splitURL[1]))
+			var err error
+			if data, err = ioutil.ReadFile(fmt.Sprintf("%s/%s_mock.json", mockFilePath, splitURL[1])); err != nil {
+				data = []byte(`{"count": 0, "results": []}`)
+			}
 		} else if len(splitURL) == 3 {
 			// with uuid
 			data, _ = ioutil.ReadFile(fmt.Sprintf("%s/%s_uuid_mock.json", mockFilePath, splitURL[1]))
@@ -898,6 +938,8 @@ type FakeHostRule struct {
 	WafPolicy          string
 	ApplicationProfile string
 	HttpPolicySets     []string
+	ClientCASecret     string
+	ClientCertMode     string
 }
 
 func (hr FakeHostRule) HostRule() *akov1alpha1.HostRule {
@@ -915,6 +957,10 @@ func (hr FakeHostRule) HostRule() *akov1alpha1.HostRule {
 						Type: "ref",
 					},
 					Termination: "edge",
+					ClientCertificate: akov1alpha1.HostRuleClientCertificate{
+						CASecret: hr.ClientCASecret,
+						Mode:     hr.ClientCertMode,
+					},
 				},
 				HTTPPolicy: akov1alpha1.HostRuleHTTPPolicy{
 					PolicySets: hr.HttpPolicySets,
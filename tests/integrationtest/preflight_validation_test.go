@@ -0,0 +1,166 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+
+	"github.com/onsi/gomega"
+)
+
+func injectMWForControllerVersion(version string) {
+	AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && strings.Contains(r.URL.EscapedPath(), "initial-data") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"version": {"Version": "` + version + `"}}`))
+			return
+		}
+		NormalControllerServer(w, r)
+	})
+}
+
+func runValidation(g *gomega.WithT) map[string]models.ValidationCheck {
+	var runHandler http.HandlerFunc
+	for _, operation := range models.ValidationStatus.ApiOperationMap() {
+		if operation.Route == "/api/validation/run" && operation.Method == "POST" {
+			runHandler = operation.Handler
+		}
+	}
+	g.Expect(runHandler).NotTo(gomega.BeNil())
+
+	w := httptest.NewRecorder()
+	runHandler(w, httptest.NewRequest("POST", "/api/validation/run", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+
+	var report models.ValidationModel
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(gomega.BeNil())
+	checks := make(map[string]models.ValidationCheck)
+	for _, check := range report.Validation.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestPreflightValidationReport(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	injectMWForControllerVersion("17.2.14")
+	defer ResetMiddleware()
+
+	checks := runValidation(g)
+	g.Expect(checks).To(gomega.HaveKey("cloud"))
+	g.Expect(checks["cloud"].Passed).To(gomega.Equal(true))
+	g.Expect(checks["cloud"].Blocking).To(gomega.Equal(true))
+	g.Expect(checks["requiredValues"].Passed).To(gomega.Equal(true))
+	g.Expect(checks["nodeNetwork"].Passed).To(gomega.Equal(true))
+	g.Expect(checks["ipamDnsProfiles"].Passed).To(gomega.Equal(true))
+
+	// an older controller is reported, without disabling the sync
+	g.Expect(checks["controllerVersion"].Passed).To(gomega.Equal(false))
+	g.Expect(checks["controllerVersion"].Blocking).To(gomega.Equal(false))
+	g.Expect(checks["controllerVersion"].Message).To(gomega.ContainSubstring("17.2.14"))
+
+	// running the checks again picks up the controller upgrade
+	injectMWForControllerVersion("20.1.1-9071")
+	checks = runValidation(g)
+	g.Expect(checks["controllerVersion"].Passed).To(gomega.Equal(true))
+	g.Expect(checks["controllerVersion"].Message).To(gomega.BeEmpty())
+}
+
+// TestPreflightValidationReadOnly checks that running the checks again neither changes the objects on the
+// controller nor the settings AKO runs with.
+func TestPreflightValidationReadOnly(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var writes []string
+	var writesLock sync.Mutex
+	AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		// the re-run logs in with a client of its own
+		if r.Method != "GET" && !strings.Contains(r.URL.EscapedPath(), "login") {
+			writesLock.Lock()
+			writes = append(writes, r.Method+" "+r.URL.EscapedPath())
+			writesLock.Unlock()
+		}
+		if r.Method == "GET" && strings.Contains(r.URL.EscapedPath(), "serviceenginegroup") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"count": 1, "results": [{"name": "Default-Group", "uuid": "serviceenginegroup-preflight"}]}`))
+			return
+		}
+		NormalControllerServer(w, r)
+	})
+	defer ResetMiddleware()
+
+	namePrefix, cloudType, vrf := lib.GetNamePrefix(), lib.GetCloudType(), lib.GetVrf()
+	checks := runValidation(g)
+	g.Expect(checks["serviceEngineGroup"].Passed).To(gomega.Equal(false))
+	g.Expect(checks["serviceEngineGroup"].Message).To(gomega.ContainSubstring("Labels not set"))
+	g.Expect(checks["cloud"].Passed).To(gomega.Equal(true))
+	g.Expect(checks["vrf"].Passed).To(gomega.Equal(true))
+
+	writesLock.Lock()
+	g.Expect(writes).To(gomega.BeEmpty())
+	writesLock.Unlock()
+	g.Expect(lib.GetNamePrefix()).To(gomega.Equal(namePrefix))
+	g.Expect(lib.GetCloudType()).To(gomega.Equal(cloudType))
+	g.Expect(lib.GetVrf()).To(gomega.Equal(vrf))
+}
+
+// TestPreflightValidationRunLimit checks that the checks are run again on request only a few times a minute, on
+// the same client every time.
+func TestPreflightValidationRunLimit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var logins int32
+	AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.EscapedPath(), "login") {
+			atomic.AddInt32(&logins, 1)
+		}
+		NormalControllerServer(w, r)
+	})
+	defer ResetMiddleware()
+
+	var runHandler http.HandlerFunc
+	for _, operation := range models.ValidationStatus.ApiOperationMap() {
+		if operation.Route == "/api/validation/run" && operation.Method == "POST" {
+			runHandler = operation.Handler
+		}
+	}
+	var ok, limited int
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		runHandler(w, httptest.NewRequest("POST", "/api/validation/run", nil))
+		switch w.Code {
+		case http.StatusOK:
+			ok++
+		case http.StatusTooManyRequests:
+			limited++
+			g.Expect(w.Header().Get("Retry-After")).NotTo(gomega.BeEmpty())
+		}
+	}
+	g.Expect(ok).To(gomega.BeNumerically(">", 0))
+	g.Expect(ok + limited).To(gomega.Equal(10))
+	g.Expect(limited).To(gomega.BeNumerically(">=", 5))
+	// the dedicated client was logged in by the earlier runs, if any, and at most once by these
+	g.Expect(atomic.LoadInt32(&logins)).To(gomega.BeNumerically("<=", 1))
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 20 calls per second, the burst lets the first 2 through right away
+	limiter := utils.NewAviRestRateLimiter(20, 2, 0)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		release := limiter.Acquire(utils.RestPriorityLow)
+		release()
+	}
+	g.Expect(time.Since(start)).To(gomega.BeNumerically(">=", 180*time.Millisecond))
+
+	stats := limiter.GetStats()
+	g.Expect(stats.Requests).To(gomega.Equal(int64(6)))
+	g.Expect(stats.ThrottledRequests).To(gomega.Equal(int64(4)))
+	g.Expect(stats.ThrottledWaitMs).To(gomega.BeNumerically(">", 0))
+	g.Expect(stats.MaxWaitMs).To(gomega.BeNumerically(">", 0))
+}
+
+func TestRateLimiterMaxInFlightPriority(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	limiter := utils.NewAviRestRateLimiter(0, 0, 1)
+	release := limiter.Acquire(utils.RestPriorityLow)
+
+	var order []utils.RestPriority
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	acquire := func(priority utils.RestPriority) {
+		defer wg.Done()
+		done := limiter.Acquire(priority)
+		lock.Lock()
+		order = append(order, priority)
+		lock.Unlock()
+		done()
+	}
+
+	// the low priority call waits first, the high priority one still goes ahead of it
+	wg.Add(2)
+	go acquire(utils.RestPriorityLow)
+	g.Eventually(func() int {
+		return limiter.GetStats().Waiting
+	}, 5*time.Second).Should(gomega.Equal(1))
+	go acquire(utils.RestPriorityHigh)
+	g.Eventually(func() int {
+		return limiter.GetStats().Waiting
+	}, 5*time.Second).Should(gomega.Equal(2))
+	g.Expect(limiter.GetStats().InFlight).To(gomega.Equal(1))
+
+	release()
+	wg.Wait()
+	g.Expect(order).To(gomega.Equal([]utils.RestPriority{utils.RestPriorityHigh, utils.RestPriorityLow}))
+	g.Expect(limiter.GetStats().InFlight).To(gomega.Equal(0))
+}
+
+func TestRateLimiterWaitForCapacity(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	limiter := utils.NewAviRestRateLimiter(0, 0, 1)
+	release := limiter.Acquire(utils.RestPriorityLow)
+	// without calls waiting, there is capacity even with one in flight
+	limiter.WaitForCapacity()
+
+	waiterDone := make(chan struct{})
+	go func() {
+		limiter.Acquire(utils.RestPriorityLow)()
+		close(waiterDone)
+	}()
+	g.Eventually(func() int {
+		return limiter.GetStats().Waiting
+	}, 5*time.Second).Should(gomega.Equal(1))
+
+	capacity := make(chan struct{})
+	go func() {
+		limiter.WaitForCapacity()
+		close(capacity)
+	}()
+	g.Consistently(capacity, 200*time.Millisecond).ShouldNot(gomega.BeClosed())
+
+	release()
+	g.Eventually(capacity, 5*time.Second).Should(gomega.BeClosed())
+	g.Eventually(waiterDone, 5*time.Second).Should(gomega.BeClosed())
+}
+
+func TestRateLimiterRestOperate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// a pool without a limiter is not throttled
+	g.Expect(utils.NewAviRestRateLimiter(0, 0, 0)).To(gomega.BeNil())
+
+	aviRestPoolClient := &utils.AviRestClientPool{
+		AviClient:   cache.SharedAVIClients().AviClient,
+		RateLimiter: utils.NewAviRestRateLimiter(100, 1, 1),
+	}
+	err := aviRestPoolClient.AviRestOperate(aviRestPoolClient.AviClient[0], macroTestRestOps())
+	g.Expect(err).To(gomega.BeNil())
+
+	stats := aviRestPoolClient.RateLimiter.GetStats()
+	g.Expect(stats.Requests).To(gomega.Equal(int64(3)))
+	g.Expect(stats.ThrottledRequests).To(gomega.Equal(int64(2)))
+	g.Expect(stats.InFlight).To(gomega.Equal(0))
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	avimodels "github.com/avinetworks/sdk/go/models"
+	"github.com/onsi/gomega"
+)
+
+// macroTestRestOps returns a pool create, a pool update and a pool delete, in the order the rest layer sends them.
+func macroTestRestOps() []*utils.RestOp {
+	newPool, updatedPool := "cluster--macro-pool-new", "cluster--macro-pool-updated"
+	cksum := "1234"
+	return []*utils.RestOp{
+		{
+			Path:    "/api/macro",
+			Method:  utils.RestPost,
+			Obj:     utils.AviRestObjMacro{ModelName: "Pool", Data: avimodels.Pool{Name: &newPool, CloudConfigCksum: &cksum}},
+			Tenant:  "admin",
+			Model:   "Pool",
+			Version: utils.CtrlVersion,
+		},
+		{
+			Path:    "/api/pool/pool-macro-updated",
+			Method:  utils.RestPut,
+			Obj:     avimodels.Pool{Name: &updatedPool, CloudConfigCksum: &cksum},
+			Tenant:  "admin",
+			Model:   "Pool",
+			Version: utils.CtrlVersion,
+		},
+		{
+			Path:    "/api/pool/pool-macro-stale",
+			Method:  utils.RestDelete,
+			Tenant:  "admin",
+			Model:   "Pool",
+			Version: utils.CtrlVersion,
+			ObjName: "cluster--macro-pool-stale",
+		},
+	}
+}
+
+// injectMWForMacroBatch records the requests for the test objects, and answers the batched macros with the given
+// status code. Requests for other objects, from the workers of the earlier tests, are passed on unrecorded.
+func injectMWForMacroBatch(requests *[]string, lock *sync.Mutex, batchStatus int) {
+	AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		request := r.Method + " " + strings.TrimLeft(url, "/")
+		testObj := strings.Contains(url, "pool-macro-")
+		if r.Method == "POST" && strings.Contains(url, "macro") {
+			data, _ := ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(strings.NewReader(string(data)))
+			testObj = strings.Contains(string(data), "cluster--macro-pool-")
+			var macros []map[string]interface{}
+			if testObj && json.Unmarshal(data, &macros) == nil {
+				request += " batch"
+				if batchStatus != http.StatusOK {
+					lock.Lock()
+					*requests = append(*requests, request)
+					lock.Unlock()
+					w.WriteHeader(batchStatus)
+					w.Write([]byte(`{"error": "batch rejected"}`))
+					return
+				}
+			}
+		}
+		if testObj {
+			lock.Lock()
+			*requests = append(*requests, request)
+			lock.Unlock()
+		}
+		NormalControllerServer(w, r)
+	})
+}
+
+func TestRestMacroBatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var requests []string
+	var lock sync.Mutex
+	injectMWForMacroBatch(&requests, &lock, http.StatusOK)
+	defer ResetMiddleware()
+
+	aviRestPoolClient := cache.SharedAVIClients()
+	restOps := macroTestRestOps()
+	err := aviRestPoolClient.AviRestOperateMacro(aviRestPoolClient.AviClient[0], restOps)
+	g.Expect(err).To(gomega.BeNil())
+
+	// the create and update are sent in a single macro, the delete follows on its own
+	g.Expect(requests).To(gomega.Equal([]string{"POST api/macro batch", "DELETE api/pool/pool-macro-stale"}))
+	createResp, ok := restOps[0].Response.([]interface{})
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(createResp).To(gomega.HaveLen(1))
+	g.Expect(createResp[0].(map[string]interface{})["name"]).To(gomega.Equal("cluster--macro-pool-new"))
+	updateResp, ok := restOps[1].Response.(map[string]interface{})
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(updateResp["name"]).To(gomega.Equal("cluster--macro-pool-updated"))
+	g.Expect(updateResp["uuid"]).To(gomega.Equal("pool-macro-updated"))
+}
+
+func TestRestMacroBatchFallback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the controller rejects the batch, the objects are sent one by one
+	var requests []string
+	var lock sync.Mutex
+	injectMWForMacroBatch(&requests, &lock, http.StatusBadRequest)
+	defer ResetMiddleware()
+
+	aviRestPoolClient := cache.SharedAVIClients()
+	restOps := macroTestRestOps()
+	err := aviRestPoolClient.AviRestOperateMacro(aviRestPoolClient.AviClient[0], restOps)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(requests).To(gomega.Equal([]string{
+		"POST api/macro batch",
+		"POST api/macro",
+		"PUT api/pool/pool-macro-updated",
+		"DELETE api/pool/pool-macro-stale",
+	}))
+	for _, restOp := range restOps {
+		g.Expect(restOp.Err).To(gomega.BeNil())
+	}
+}
+
+func TestRestMacroBatchControllerError(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the controller is unable to process the batch, the operations are left to the retry layer
+	var requests []string
+	var lock sync.Mutex
+	injectMWForMacroBatch(&requests, &lock, http.StatusServiceUnavailable)
+	defer ResetMiddleware()
+
+	aviRestPoolClient := cache.SharedAVIClients()
+	restOps := macroTestRestOps()
+	err := aviRestPoolClient.AviRestOperateMacro(aviRestPoolClient.AviClient[0], restOps)
+	g.Expect(err).NotTo(gomega.BeNil())
+	// the session retries the batch on its own, the objects are not sent one by one
+	g.Expect(requests).NotTo(gomega.BeEmpty())
+	for _, request := range requests {
+		g.Expect(request).To(gomega.Equal("POST api/macro batch"))
+	}
+	for _, restOp := range restOps {
+		g.Expect(restOp.Err).NotTo(gomega.BeNil())
+	}
+}
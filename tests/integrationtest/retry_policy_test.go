@@ -0,0 +1,162 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avinetworks/sdk/go/session"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/rest"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/retry"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+
+	"github.com/onsi/gomega"
+)
+
+func aviErrorWithStatus(statuscode int, message string) session.AviError {
+	return session.AviError{HttpStatusCode: statuscode, AviResult: session.AviResult{Code: statuscode, Message: &message}}
+}
+
+func deadLetterHandler(route, method string) http.HandlerFunc {
+	for _, operation := range models.DeadLetterStatus.ApiOperationMap() {
+		if operation.Route == route && operation.Method == method {
+			return operation.Handler
+		}
+	}
+	return nil
+}
+
+func findDeadLetteredModel(model string) *models.DeadLetteredModel {
+	for _, deadLettered := range models.DeadLetterStatus.GetDeadLetteredModels() {
+		if deadLettered.Model == model {
+			return &deadLettered
+		}
+	}
+	return nil
+}
+
+func TestClassifyRestError(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(409, "Cannot create, object already exists"))).To(gomega.Equal(rest.RetryClassConflict))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(404, "Pool object not found!"))).To(gomega.Equal(rest.RetryClassNotFound))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(429, "Too many requests"))).To(gomega.Equal(rest.RetryClassQuota))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(400, "Virtual Service limit reached"))).To(gomega.Equal(rest.RetryClassQuota))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(400, "Invalid port range"))).To(gomega.Equal(rest.RetryClassValidation))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(412, "Object modified"))).To(gomega.Equal(rest.RetryClassValidation))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(408, "Request timed out"))).To(gomega.Equal(rest.RetryClassTransient))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(503, "Service unavailable"))).To(gomega.Equal(rest.RetryClassTransient))
+	g.Expect(rest.ClassifyRestError(aviErrorWithStatus(0, "connection refused"))).To(gomega.Equal(rest.RetryClassTransient))
+
+	policy := rest.GetRetryPolicy()
+	g.Expect(policy).To(gomega.HaveLen(5))
+	g.Expect(policy[rest.RetryClassValidation].MaxRetries).To(gomega.Equal(0))
+	g.Expect(policy[rest.RetryClassQuota].InitialDelay).To(gomega.BeNumerically(">", policy[rest.RetryClassConflict].InitialDelay))
+}
+
+func TestRetryOrDeadLetter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	restOps := rest.NewRestOperations(cache.SharedAviObjCache(), cache.SharedAVIClients())
+	modelName := "admin/cluster--retry-policy-vs"
+	defer rest.ClearRetryState(modelName)
+
+	// a validation error is not retried, the model is dead lettered right away
+	restOps.RetryOrDeadLetter(modelName, 1, rest.RetryClassValidation, errors.New("Invalid port range"), false, "test")
+	deadLettered := findDeadLetteredModel(modelName)
+	g.Expect(deadLettered).NotTo(gomega.BeNil())
+	g.Expect(deadLettered.ErrorClass).To(gomega.Equal(rest.RetryClassValidation))
+	g.Expect(deadLettered.Error).To(gomega.Equal("Invalid port range"))
+	g.Expect(deadLettered.Attempts).To(gomega.Equal(1))
+
+	// the model failing again with its retries exhausted updates its entry
+	restOps.RetryOrDeadLetter(modelName, 1, rest.RetryClassConflict, errors.New("Object already exists"), true, "test")
+	deadLettered = findDeadLetteredModel(modelName)
+	g.Expect(deadLettered).NotTo(gomega.BeNil())
+	g.Expect(deadLettered.ErrorClass).To(gomega.Equal(rest.RetryClassConflict))
+	g.Expect(deadLettered.Attempts).To(gomega.Equal(2))
+
+	// the model going through forgets its attempts
+	rest.ClearRetryState(modelName)
+	g.Expect(findDeadLetteredModel(modelName)).To(gomega.BeNil())
+	restOps.RetryOrDeadLetter(modelName, 1, rest.RetryClassValidation, errors.New("Invalid port range"), false, "test")
+	deadLettered = findDeadLetteredModel(modelName)
+	g.Expect(deadLettered).NotTo(gomega.BeNil())
+	g.Expect(deadLettered.Attempts).To(gomega.Equal(1))
+
+	// so does the model changing, the attempts of the changed model start over
+	rest.ClearRetryStateOnChange(modelName, 1)
+	g.Expect(findDeadLetteredModel(modelName)).NotTo(gomega.BeNil())
+	rest.ClearRetryStateOnChange(modelName, 2)
+	g.Expect(findDeadLetteredModel(modelName)).To(gomega.BeNil())
+	restOps.RetryOrDeadLetter(modelName, 1, rest.RetryClassValidation, errors.New("Invalid port range"), false, "test")
+	restOps.RetryOrDeadLetter(modelName, 2, rest.RetryClassValidation, errors.New("Invalid port range"), false, "test")
+	deadLettered = findDeadLetteredModel(modelName)
+	g.Expect(deadLettered).NotTo(gomega.BeNil())
+	g.Expect(deadLettered.Attempts).To(gomega.Equal(1))
+}
+
+func TestDeadLetterRetryApi(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	retried := make(chan string, 1)
+	defer models.DeadLetterStatus.SetRetryFunc(retry.RetryModelNow)
+	models.DeadLetterStatus.SetRetryFunc(func(model string) {
+		rest.ClearRetryState(model)
+		retried <- model
+	})
+
+	restOps := rest.NewRestOperations(cache.SharedAviObjCache(), cache.SharedAVIClients())
+	modelName := "admin/cluster--retry-api-vs"
+	restOps.RetryOrDeadLetter(modelName, 1, rest.RetryClassValidation, errors.New("Invalid port range"), false, "test")
+	g.Expect(findDeadLetteredModel(modelName)).NotTo(gomega.BeNil())
+
+	retryHandler := deadLetterHandler("/api/deadletter/retry", "POST")
+	g.Expect(retryHandler).NotTo(gomega.BeNil())
+	retryRequest := func(path, token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		retryHandler(w, r)
+		return w.Code
+	}
+
+	// the retries are denied to the requests without the diagnostics token
+	g.Expect(retryRequest("/api/deadletter/retry?model="+modelName, "")).To(gomega.Equal(http.StatusUnauthorized))
+	models.DiagnosticsStatus.SetToken("diagnostics-token")
+	defer models.DiagnosticsStatus.SetToken("")
+	g.Expect(retryRequest("/api/deadletter/retry?model="+modelName, "other-token")).To(gomega.Equal(http.StatusUnauthorized))
+	g.Expect(findDeadLetteredModel(modelName)).NotTo(gomega.BeNil())
+
+	g.Expect(retryRequest("/api/deadletter/retry", "diagnostics-token")).To(gomega.Equal(http.StatusBadRequest))
+	g.Expect(retryRequest("/api/deadletter/retry?model=admin/cluster--not-dead-lettered", "diagnostics-token")).To(gomega.Equal(http.StatusNotFound))
+
+	g.Expect(retryRequest("/api/deadletter/retry?model="+modelName, "diagnostics-token")).To(gomega.Equal(http.StatusOK))
+	g.Expect(<-retried).To(gomega.Equal(modelName))
+	g.Expect(findDeadLetteredModel(modelName)).To(gomega.BeNil())
+
+	// the model is out of the dead letter set once retried
+	g.Expect(retryRequest("/api/deadletter/retry?model="+modelName, "diagnostics-token")).To(gomega.Equal(http.StatusNotFound))
+	g.Expect(retried).To(gomega.BeEmpty())
+
+	models.DeadLetterStatus.SetRetryFunc(nil)
+	g.Expect(retryRequest("/api/deadletter/retry?model="+modelName, "diagnostics-token")).To(gomega.Equal(http.StatusServiceUnavailable))
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/cache"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/status"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getServiceStatusIPs(namespace, name string) []string {
+	svc, err := KubeClient.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	var ips []string
+	for _, lbIngress := range svc.Status.LoadBalancer.Ingress {
+		ips = append(ips, lbIngress.IP)
+	}
+	return ips
+}
+
+// TestStatusQueueUpdates checks that the status updates queued for a service are written in order, and that the
+// updates of a missing object are dropped.
+func TestStatusQueueUpdates(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the status of a ClusterIP service is not written by AKO itself
+	CreateSVC(t, NAMESPACE, "status-queue-svc", corev1.ServiceTypeClusterIP, false)
+	defer DelSVC(t, NAMESPACE, "status-queue-svc")
+	svcMetadata := cache.ServiceMetadataObj{
+		NamespaceServiceName: []string{NAMESPACE + "/status-queue-svc"},
+		HostNames:            []string{"status-queue-svc.com"},
+	}
+
+	for _, vip := range []string{"10.10.10.1", "10.10.10.2", "10.10.10.3"} {
+		status.UpdateL4LBStatus([]status.UpdateStatusOptions{{
+			Vip:             vip,
+			ServiceMetadata: svcMetadata,
+			Key:             "L4LBService/" + NAMESPACE + "/status-queue-svc",
+		}})
+	}
+	g.Eventually(func() []string {
+		return getServiceStatusIPs(NAMESPACE, "status-queue-svc")
+	}, 10*time.Second).Should(gomega.Equal([]string{"10.10.10.3"}))
+
+	status.UpdateL4LBStatus([]status.UpdateStatusOptions{{
+		Vip: "10.10.10.4",
+		ServiceMetadata: cache.ServiceMetadataObj{
+			NamespaceServiceName: []string{NAMESPACE + "/status-queue-missing-svc"},
+			HostNames:            []string{"status-queue-missing-svc.com"},
+		},
+		Key: "L4LBService/" + NAMESPACE + "/status-queue-missing-svc",
+	}})
+	status.DeleteL4LBStatus(svcMetadata, "L4LBService/"+NAMESPACE+"/status-queue-svc")
+	g.Eventually(func() int {
+		return len(getServiceStatusIPs(NAMESPACE, "status-queue-svc"))
+	}, 10*time.Second).Should(gomega.Equal(0))
+	g.Eventually(func() int {
+		return status.SharedStatusQueue().GetPendingCount()
+	}, 10*time.Second).Should(gomega.Equal(0))
+	g.Expect(models.StatusQueueStatus.GetPending()[utils.L4LBService]).To(gomega.Equal(0))
+}
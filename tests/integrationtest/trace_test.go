@@ -0,0 +1,87 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package integrationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+
+	"github.com/onsi/gomega"
+)
+
+func getTraceLayers(key string) map[string]bool {
+	layers := make(map[string]bool)
+	for _, event := range utils.GetTrace(key) {
+		layers[event.Layer] = true
+	}
+	return layers
+}
+
+func isTraceMessageFound(key, message string) bool {
+	for _, event := range utils.GetTrace(key) {
+		if strings.Contains(event.Message, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestIngressTrace checks that the trace of an ingress holds the events of all the layers, along with the
+// changes of its service endpoints, and that it is served by the trace API.
+func TestIngressTrace(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	modelName := "admin/cluster--Shared-L7-6"
+	ingKey := "Ingress/default/foo-with-targets"
+	SetUpIngressForCacheSyncCheck(t, modelName, false, false)
+
+	g.Eventually(func() map[string]bool {
+		return getTraceLayers(ingKey)
+	}, 10*time.Second).Should(gomega.And(
+		gomega.HaveKey(utils.ObjectIngestionLayer),
+		gomega.HaveKey(utils.GraphLayer),
+		gomega.HaveKey(utils.RestLayer),
+		gomega.HaveKey(utils.StatusLayer)))
+	g.Expect(isTraceMessageFound(ingKey, "published to model "+modelName)).To(gomega.Equal(true))
+
+	DelEP(t, "default", "avisvc")
+	CreateEP(t, "default", "avisvc", false, false, "1.1.2")
+	g.Eventually(func() bool {
+		return isTraceMessageFound(ingKey, "changed by Endpoints/default/avisvc")
+	}, 10*time.Second).Should(gomega.Equal(true))
+
+	router := (&api.ApiServer{Models: []models.ApiModel{models.TraceStatus}}).SetRouter()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/trace/"+ingKey, nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	var report models.TraceReport
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(gomega.Succeed())
+	g.Expect(report.Key).To(gomega.Equal(ingKey))
+	g.Expect(report.Events).NotTo(gomega.BeEmpty())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/trace/Ingress/default/no-such-ingress", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNotFound))
+
+	TearDownIngressForCacheSyncCheck(t, modelName, g)
+}
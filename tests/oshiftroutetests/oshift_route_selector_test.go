@@ -0,0 +1,125 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package oshiftroutetests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+
+	"github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func routePoolCount(modelName string) int {
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if !found || aviModel == nil {
+		return 0
+	}
+	return len(aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].PoolRefs)
+}
+
+func akoRouteStatus(name string) []routev1.RouteIngress {
+	var akoStatus []routev1.RouteIngress
+	route, _ := OshiftClient.RouteV1().Routes(DefaultNamespace).Get(name, metav1.GetOptions{})
+	for _, status := range route.Status.Ingress {
+		if status.RouterName == lib.AKOUser {
+			akoStatus = append(akoStatus, status)
+		}
+	}
+	return akoStatus
+}
+
+func TestRouteSelector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	os.Setenv("ROUTE_SELECTOR", "router=avi")
+	defer os.Setenv("ROUTE_SELECTOR", "")
+	SetUpTestForRoute(t, DefaultModelName)
+
+	routeExample := FakeRoute{}.Route()
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Create(routeExample); err != nil {
+		t.Fatalf("error in adding route: %v", err)
+	}
+	g.Consistently(func() int {
+		return routePoolCount(DefaultModelName)
+	}, 2*time.Second).Should(gomega.Equal(0))
+
+	// the route starts matching the selector
+	routeExample = FakeRoute{}.Route()
+	routeExample.Labels = map[string]string{"router": "avi"}
+	routeExample.ResourceVersion = "2"
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Update(routeExample); err != nil {
+		t.Fatalf("error in updating route: %v", err)
+	}
+	g.Eventually(func() int {
+		return routePoolCount(DefaultModelName)
+	}, 20*time.Second).Should(gomega.Equal(1))
+	g.Eventually(func() int {
+		return len(akoRouteStatus(DefaultRouteName))
+	}, 20*time.Second).Should(gomega.Equal(1))
+
+	// the route moves to another router
+	routeExample = FakeRoute{}.Route()
+	routeExample.Labels = map[string]string{"router": "internal"}
+	routeExample.ResourceVersion = "3"
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Update(routeExample); err != nil {
+		t.Fatalf("error in updating route: %v", err)
+	}
+	g.Eventually(func() int {
+		return routePoolCount(DefaultModelName)
+	}, 20*time.Second).Should(gomega.Equal(0))
+	g.Eventually(func() int {
+		return len(akoRouteStatus(DefaultRouteName))
+	}, 20*time.Second).Should(gomega.Equal(0))
+
+	if err := OshiftClient.RouteV1().Routes(DefaultNamespace).Delete(DefaultRouteName, nil); err != nil {
+		t.Fatalf("Couldn't DELETE the route %v", err)
+	}
+	TearDownTestForRoute(t, DefaultModelName)
+}
+
+func TestRouteStatusWithOtherRouter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	SetUpTestForRoute(t, DefaultModelName)
+
+	routeExample := FakeRoute{}.Route()
+	routeExample.Status.Ingress = []routev1.RouteIngress{{
+		Host:       DefaultHostname,
+		RouterName: "default",
+		Conditions: []routev1.RouteIngressCondition{{
+			Type:   routev1.RouteAdmitted,
+			Status: corev1.ConditionTrue,
+		}},
+	}}
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Create(routeExample); err != nil {
+		t.Fatalf("error in adding route: %v", err)
+	}
+
+	g.Eventually(func() int {
+		return len(akoRouteStatus(DefaultRouteName))
+	}, 20*time.Second).Should(gomega.Equal(1))
+	route, _ := OshiftClient.RouteV1().Routes(DefaultNamespace).Get(DefaultRouteName, metav1.GetOptions{})
+	g.Expect(route.Status.Ingress).To(gomega.HaveLen(2))
+
+	_, aviModel := objects.SharedAviGraphLister().Get(DefaultModelName)
+	VerifyRouteDeletion(t, g, aviModel, 0)
+	TearDownTestForRoute(t, DefaultModelName)
+}
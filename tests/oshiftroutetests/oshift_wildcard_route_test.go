@@ -0,0 +1,136 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package oshiftroutetests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/lib"
+	avinodes "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/nodes"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/internal/objects"
+
+	"github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const wildcardRouteHost = "wild.foo.com"
+
+func (rt FakeRoute) WildcardRoute() *routev1.Route {
+	routeExample := rt.Route()
+	routeExample.Spec.WildcardPolicy = routev1.WildcardPolicySubdomain
+	return routeExample
+}
+
+// SetUpTestForWildcardRoute returns the model of the shard that *.foo.com is placed on.
+func SetUpTestForWildcardRoute(t *testing.T) string {
+	os.Setenv("SHARD_VS_SIZE", "LARGE")
+	modelName := "admin/" + avinodes.DeriveHostNameShardVS("*.foo.com", "")
+	SetUpTestForRoute(t, modelName)
+	return modelName
+}
+
+func TestWildcardRoute(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	os.Setenv("WILDCARD_ROUTE_NAMESPACES", DefaultNamespace)
+	defer os.Setenv("WILDCARD_ROUTE_NAMESPACES", "")
+	modelName := SetUpTestForWildcardRoute(t)
+
+	routeExample := FakeRoute{Hostname: wildcardRouteHost}.WildcardRoute()
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Create(routeExample); err != nil {
+		t.Fatalf("error in adding route: %v", err)
+	}
+
+	g.Eventually(func() int {
+		found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+		if !found || aviModel == nil {
+			return 0
+		}
+		return len(aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].PoolRefs)
+	}, 20*time.Second).Should(gomega.Equal(1))
+	_, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	nodes := aviModel.(*avinodes.AviObjectGraph).GetAviVS()
+	g.Expect(nodes[0].PoolRefs[0].Name).To(gomega.Equal("cluster--*.foo.com-default-foo-avisvc"))
+	g.Expect(nodes[0].PoolRefs[0].PriorityLabel).To(gomega.Equal("*.foo.com"))
+
+	dsNodes := aviModel.(*avinodes.AviObjectGraph).GetAviHTTPDSNode()
+	g.Expect(dsNodes).To(gomega.HaveLen(1))
+	g.Expect(dsNodes[0].Script).To(gomega.ContainSubstring(`wildcards = {["foo.com"]=true}`))
+
+	VerifyRouteDeletion(t, g, aviModel, 0)
+	g.Eventually(func() string {
+		return aviModel.(*avinodes.AviObjectGraph).GetAviHTTPDSNode()[0].Script
+	}, 20*time.Second).ShouldNot(gomega.ContainSubstring("wildcards"))
+	TearDownTestForRoute(t, modelName)
+}
+
+func TestSecureWildcardRoute(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	os.Setenv("WILDCARD_ROUTE_NAMESPACES", "*")
+	defer os.Setenv("WILDCARD_ROUTE_NAMESPACES", "")
+	modelName := SetUpTestForWildcardRoute(t)
+
+	routeExample := FakeRoute{Hostname: wildcardRouteHost}.WildcardRoute()
+	routeExample.Spec.TLS = &routev1.TLSConfig{
+		Certificate:   "cert",
+		CACertificate: "cacert",
+		Key:           "key",
+		Termination:   routev1.TLSTerminationEdge,
+	}
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Create(routeExample); err != nil {
+		t.Fatalf("error in adding route: %v", err)
+	}
+
+	aviModel := ValidateSniModel(t, g, modelName)
+	sniVS := aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].SniNodes[0]
+	g.Expect(sniVS.Name).To(gomega.Equal("cluster--*.foo.com"))
+	g.Expect(sniVS.VHDomainNames).To(gomega.Equal([]string{"*.foo.com"}))
+	VerifySniNode(g, sniVS)
+
+	VerifySecureRouteDeletion(t, g, modelName, 0, 0)
+	TearDownTestForRoute(t, modelName)
+}
+
+func TestWildcardRouteNotAllowed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	os.Setenv("WILDCARD_ROUTE_NAMESPACES", "red")
+	defer os.Setenv("WILDCARD_ROUTE_NAMESPACES", "")
+	modelName := SetUpTestForWildcardRoute(t)
+
+	routeExample := FakeRoute{Hostname: wildcardRouteHost}.WildcardRoute()
+	if _, err := OshiftClient.RouteV1().Routes(DefaultNamespace).Create(routeExample); err != nil {
+		t.Fatalf("error in adding route: %v", err)
+	}
+
+	g.Eventually(func() string {
+		route, _ := OshiftClient.RouteV1().Routes(DefaultNamespace).Get(DefaultRouteName, metav1.GetOptions{})
+		if len(route.Status.Ingress) != 1 || len(route.Status.Ingress[0].Conditions) != 1 {
+			return ""
+		}
+		return route.Status.Ingress[0].Conditions[0].Reason
+	}, 20*time.Second).Should(gomega.Equal(lib.WildcardPolicyNotAllowed))
+
+	found, aviModel := objects.SharedAviGraphLister().Get(modelName)
+	if found && aviModel != nil {
+		g.Expect(aviModel.(*avinodes.AviObjectGraph).GetAviVS()[0].PoolRefs).To(gomega.HaveLen(0))
+	}
+
+	if err := OshiftClient.RouteV1().Routes(DefaultNamespace).Delete(DefaultRouteName, nil); err != nil {
+		t.Fatalf("Couldn't DELETE the route %v", err)
+	}
+	TearDownTestForRoute(t, modelName)
+}